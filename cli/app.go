@@ -1,12 +1,21 @@
 package cli
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
 
 	"github.com/stelligent/mu/common"
 	"github.com/stelligent/mu/provider/aws"
+	"github.com/stelligent/mu/provider/local"
+	"github.com/stelligent/mu/workflows"
 	"github.com/urfave/cli"
 )
 
@@ -29,16 +38,30 @@ func NewApp() *cli.App {
 		*newPipelinesCommand(context),
 		*newCatalogCommand(context),
 		*newPurgeCommand(context),
+		*newHistoryCommand(context),
+		*newDriftCommand(context),
 	}
 
 	app.Before = func(c *cli.Context) error {
+		// with jsonl output, stdout only has events and everything meant for people goes to stderr
+		switch c.String("output") {
+		case OutputText:
+			textOut = os.Stdout
+			common.SetupEvents(nil)
+		case OutputJSONL:
+			textOut = os.Stderr
+			common.SetupEvents(os.Stdout)
+		default:
+			return fmt.Errorf("Unknown output '%s', must be one of: %s, %s", c.String("output"), OutputText, OutputJSONL)
+		}
+
 		// setup logging
 		if c.Bool("verbose") {
-			common.SetupLogging(2)
+			common.SetupLogging(2, textOut)
 		} else if c.Bool("silent") {
-			common.SetupLogging(0)
+			common.SetupLogging(0, textOut)
 		} else {
-			common.SetupLogging(1)
+			common.SetupLogging(1, textOut)
 		}
 
 		// initialize context
@@ -48,13 +71,19 @@ func NewApp() *cli.App {
 		}
 		log.Debugf("version:%v", common.GetVersion())
 
-		// TODO: support initializing context from other cloud providers?
 		log.Debugf("dryrun:%v path:%v", c.Bool("dryrun"), c.String("dryrun-output"))
 		dryrunPath := ""
 		if c.Bool("dryrun") {
 			dryrunPath = c.String("dryrun-output")
 		}
-		err = aws.InitializeContext(context, c.String("profile"), c.String("assume-role"), c.String("region"), dryrunPath, c.Bool("skip-version-check"), c.String("proxy"), c.Bool("allow-data-loss"))
+		switch c.String("provider") {
+		case ProviderAws:
+			err = aws.InitializeContext(context, c.String("profile"), c.String("assume-role"), c.String("region"), dryrunPath, c.Bool("skip-version-check"), c.String("proxy"), c.Bool("allow-data-loss"))
+		case ProviderLocal:
+			err = local.InitializeContext(context, os.Getenv("MU_LOCAL_STATE_DIR"))
+		default:
+			err = fmt.Errorf("Unknown provider '%s', must be one of: %s, %s", c.String("provider"), ProviderAws, ProviderLocal)
+		}
 		if err != nil {
 			return err
 		}
@@ -65,8 +94,7 @@ func NewApp() *cli.App {
 				log.Warningf("Unable to load mu config: %v", err)
 			}
 			if err = context.Config.Validate(); err != nil {
-				log.Errorf("Invalid Config: %v", err)
-				return nil
+				return fmt.Errorf("Invalid Config: %v", err)
 			}
 		}
 		context.Config.DryRun = c.Bool("dryrun")
@@ -79,7 +107,7 @@ func NewApp() *cli.App {
 		if c.Bool("silent") {
 			context.DockerOut = ioutil.Discard
 		} else {
-			context.DockerOut = os.Stdout
+			context.DockerOut = textOut
 		}
 
 		// Get the namespace for the stack creation.  This will prefix the stack names
@@ -100,6 +128,16 @@ func NewApp() *cli.App {
 			context.Config.Namespace = "mu"
 		}
 
+		// record the stacks that workflows complete in the journal, so a failed run can be resumed
+		journalStore, err := common.NewJournalStore(c.String("journal"), context.ArtifactManager)
+		if err != nil {
+			return err
+		}
+		context.JournalManager = common.NewJournalManager(journalStore, context.Config.Namespace, strings.Join(c.Args(), " "), c.Bool("resume"))
+		if !context.Config.DryRun {
+			context.StackManager = common.NewJournalStackManager(context.StackManager, context.JournalManager)
+		}
+
 		// initialize extensions
 		return context.InitializeExtensions()
 	}
@@ -159,7 +197,112 @@ func NewApp() *cli.App {
 			Name:  "allow-data-loss",
 			Usage: "temporarily allow delete or replace on RDS or KMS resources",
 		},
+		cli.StringFlag{
+			Name:  "provider",
+			Usage: "provider to deploy with, 'aws' or 'local' to use the local docker daemon",
+			Value: ProviderAws,
+		},
+		cli.DurationFlag{
+			Name:  "timeout",
+			Usage: "stop waiting on stacks and fail after the duration, e.g. 45m",
+		},
+		cli.BoolFlag{
+			Name:  "cancel-stack-updates",
+			Usage: "cancel stack updates that are in progress when interrupted or timed out",
+		},
+		cli.BoolFlag{
+			Name:  "resume",
+			Usage: "skip the stacks that the last failed run of the same command already completed",
+		},
+		cli.StringFlag{
+			Name:  "journal",
+			Usage: "file path or s3:// URL of the journal of runs (default: ~/.mu/journal.json)",
+		},
+		cli.StringFlag{
+			Name:  "output",
+			Usage: "'text', or 'jsonl' to write events as lines of JSON to stdout and everything else to stderr",
+			Value: OutputText,
+		},
 	}
 
 	return app
 }
+
+// planChanges switches the context to only preview stack changes if the plan flag was set
+func planChanges(ctx *common.Context, c *cli.Context) {
+	if c.Bool(PlanFlag) {
+		ctx.Config.Plan = true
+		ctx.StackManager.PlanChanges(true)
+	}
+}
+
+// runWorkflow runs the workflow until it completes, is interrupted with Ctrl-C or times out after the timeout flag.
+// Stacks that are still in progress when the workflow is stopped are left to CloudFormation, unless the
+// cancel-stack-updates flag was set.  A second Ctrl-C exits immediately.
+func runWorkflow(ctx *common.Context, c *cli.Context, workflow workflows.Executor) error {
+	runCtx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	timeout := c.GlobalDuration("timeout")
+	if timeout > 0 {
+		runCtx, cancel = context.WithTimeout(runCtx, timeout)
+		defer cancel()
+	}
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	defer signal.Stop(signals)
+	go func() {
+		select {
+		case <-signals:
+			log.Warning("Interrupted, stopping the workflow. Press Ctrl-C again to exit immediately")
+			signal.Stop(signals)
+			cancel()
+		case <-runCtx.Done():
+		}
+	}()
+
+	if ctx.StackManager != nil {
+		ctx.StackManager.CancelUpdatesOnInterrupt(c.GlobalBool("cancel-stack-updates"))
+	}
+
+	start := time.Now()
+	err := workflow(runCtx)
+	if ctx.JournalManager != nil {
+		if journalErr := ctx.JournalManager.FinishRun(err); journalErr != nil {
+			log.Warningf("Unable to record the run in the journal: %v", journalErr)
+		}
+	}
+
+	result := common.Event{
+		Type:            common.EventTypeResult,
+		Command:         strings.Join(os.Args[1:], " "),
+		Status:          common.EventStatusSucceeded,
+		DurationSeconds: time.Since(start).Seconds(),
+	}
+	if err != nil {
+		result.Status = common.EventStatusFailed
+		result.Reason = err.Error()
+	}
+	if err != nil && runCtx.Err() != nil {
+		if runCtx.Err() == context.DeadlineExceeded {
+			err = fmt.Errorf("Timed out after %v", timeout)
+			result.Status = common.EventStatusTimedOut
+		} else {
+			err = errors.New("Interrupted")
+			result.Status = common.EventStatusInterrupted
+		}
+		log.Errorf("%v", err)
+		result.Reason = err.Error()
+	}
+	common.EmitEvent(result)
+
+	// errors that were already logged only set the exit code, instead of being printed again
+	if err != nil && (workflows.IsLoggedError(err) || runCtx.Err() != nil) {
+		return cli.NewExitError("", FailExitCode)
+	}
+	return err
+}
+
+// textOut is where output meant for people is written, stdout unless it is reserved for events
+var textOut io.Writer = os.Stdout
@@ -1,9 +1,19 @@
 package cli
 
 import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/stelligent/mu/common"
+	"github.com/stelligent/mu/workflows"
 	"github.com/stretchr/testify/assert"
+	"github.com/urfave/cli"
 )
 
 func TestNewApp(t *testing.T) {
@@ -15,7 +25,7 @@ func TestNewApp(t *testing.T) {
 	assert.Equal("1.0.0-local", app.Version, "Version should match")
 	assert.Equal("Microservice Platform on AWS", app.Usage, "usage should match")
 	assert.Equal(true, app.EnableBashCompletion, "bash completion should match")
-	assert.Equal(13, len(app.Flags), "Flags len should match")
+	assert.Equal(19, len(app.Flags), "Flags len should match")
 	assert.Equal("config, c", app.Flags[0].GetName(), "Flags name should match")
 	assert.Equal("region, r", app.Flags[1].GetName(), "Flags name should match")
 	assert.Equal("assume-role, a", app.Flags[2].GetName(), "Flags name should match")
@@ -28,7 +38,14 @@ func TestNewApp(t *testing.T) {
 	assert.Equal("disable-iam, I", app.Flags[9].GetName(), "Flags name should match")
 	assert.Equal("skip-version-check, F", app.Flags[10].GetName(), "Flags name should match")
 	assert.Equal("proxy, P", app.Flags[11].GetName(), "Flags name should match")
-	assert.Equal(8, len(app.Commands), "Commands len should match")
+	assert.Equal("allow-data-loss", app.Flags[12].GetName(), "Flags name should match")
+	assert.Equal("provider", app.Flags[13].GetName(), "Flags name should match")
+	assert.Equal("timeout", app.Flags[14].GetName(), "Flags name should match")
+	assert.Equal("cancel-stack-updates", app.Flags[15].GetName(), "Flags name should match")
+	assert.Equal("resume", app.Flags[16].GetName(), "Flags name should match")
+	assert.Equal("journal", app.Flags[17].GetName(), "Flags name should match")
+	assert.Equal("output", app.Flags[18].GetName(), "Flags name should match")
+	assert.Equal(10, len(app.Commands), "Commands len should match")
 	assert.Equal("init", app.Commands[0].Name, "Command[0].name should match")
 	assert.Equal("validate", app.Commands[1].Name, "Command[1].name should match")
 	assert.Equal("environment", app.Commands[2].Name, "Command[2].name should match")
@@ -37,4 +54,88 @@ func TestNewApp(t *testing.T) {
 	assert.Equal("pipeline", app.Commands[5].Name, "Command[5].name should match")
 	assert.Equal("catalog", app.Commands[6].Name, "Command[6].name should match")
 	assert.Equal("purge", app.Commands[7].Name, "Command[7].name should match")
+	assert.Equal("history", app.Commands[8].Name, "Command[8].name should match")
+	assert.Equal("drift", app.Commands[9].Name, "Command[9].name should match")
+}
+
+func TestRunWorkflow_ResultReason(t *testing.T) {
+	assert := assert.New(t)
+
+	var events bytes.Buffer
+	common.SetupEvents(&events)
+	defer common.SetupEvents(nil)
+
+	set := flag.NewFlagSet("test", 0)
+	set.Duration("timeout", 0, "")
+	set.Bool("cancel-stack-updates", false, "")
+	c := cli.NewContext(NewApp(), set, nil)
+
+	ctx := common.NewContext()
+	ctx.Config.Service.Name = "api"
+
+	// the error of a workflow that already logged it is reported, without printing it again
+	err := runWorkflow(ctx, c, workflows.NewSelectedServicesExecutor(ctx, "web", func() workflows.Executor {
+		return func(context.Context) error { return nil }
+	}))
+	assert.NotNil(err)
+	assert.Equal("", err.Error())
+
+	// timed out workflows report the timeout
+	assert.Nil(set.Set("timeout", "1ms"))
+	err = runWorkflow(ctx, c, func(runCtx context.Context) error {
+		<-runCtx.Done()
+		return runCtx.Err()
+	})
+	assert.NotNil(err)
+
+	lines := strings.Split(strings.TrimSpace(events.String()), "\n")
+	assert.Equal(2, len(lines))
+	results := make([]common.Event, len(lines))
+	for i, line := range lines {
+		assert.Nil(json.Unmarshal([]byte(line), &results[i]))
+	}
+	assert.Equal(common.EventStatusFailed, results[0].Status)
+	assert.Contains(results[0].Reason, "web")
+	assert.Equal(common.EventStatusTimedOut, results[1].Status)
+	assert.Equal("Timed out after "+time.Millisecond.String(), results[1].Reason)
+}
+
+func TestRunWorkflow_ExitCode(t *testing.T) {
+	assert := assert.New(t)
+
+	var events bytes.Buffer
+	common.SetupEvents(&events)
+	defer common.SetupEvents(nil)
+
+	set := flag.NewFlagSet("test", 0)
+	set.Duration("timeout", 0, "")
+	set.Bool("cancel-stack-updates", false, "")
+	c := cli.NewContext(NewApp(), set, nil)
+	ctx := common.NewContext()
+	ctx.Config.Service.Name = "api"
+
+	// an error the workflow already logged only sets the exit code
+	err := runWorkflow(ctx, c, workflows.NewSelectedServicesExecutor(ctx, "web", func() workflows.Executor {
+		return func(context.Context) error { return nil }
+	}))
+	exitErr, ok := err.(cli.ExitCoder)
+	assert.True(ok)
+	assert.Equal(FailExitCode, exitErr.ExitCode())
+	assert.Equal("", exitErr.Error())
+
+	// an error that wasn't logged is returned as is, so that it is printed
+	failure := errors.New("not logged")
+	err = runWorkflow(ctx, c, func(context.Context) error {
+		return failure
+	})
+	assert.Equal(failure, err)
+
+	lines := strings.Split(strings.TrimSpace(events.String()), "\n")
+	assert.Equal(2, len(lines))
+	result := common.Event{}
+	assert.Nil(json.Unmarshal([]byte(lines[0]), &result))
+	assert.Equal(common.EventStatusFailed, result.Status)
+	assert.NotEqual("", result.Reason)
+	assert.Nil(json.Unmarshal([]byte(lines[1]), &result))
+	assert.Equal("not logged", result.Reason)
 }
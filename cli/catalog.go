@@ -26,7 +26,7 @@ func newCatalogTerminateCommand(ctx *common.Context) *cli.Command {
 		Usage:   "terminate catalog",
 		Action: func(c *cli.Context) error {
 			workflow := workflows.NewCatalogTerminator(ctx)
-			return workflow()
+			return runWorkflow(ctx, c, workflow)
 		},
 	}
 
@@ -40,7 +40,7 @@ func newCatalogUpsertCommand(ctx *common.Context) *cli.Command {
 		Usage:   "upsert catalog",
 		Action: func(c *cli.Context) error {
 			workflow := workflows.NewCatalogUpserter(ctx)
-			return workflow()
+			return runWorkflow(ctx, c, workflow)
 		},
 	}
 
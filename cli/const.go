@@ -10,9 +10,9 @@ var log = logging.MustGetLogger("cli")
 
 // Constants for available command names and options
 const (
-	EnvSubCmdCount             = 5
+	EnvSubCmdCount             = 7
 	SingleAliasIndex           = 0
-	SvcSubCmdCount             = 7
+	SvcSubCmdCount             = 9
 	SvcShowFormatFlagIndex     = 0
 	SvcLogFlagCount            = 3
 	EnvLogFollowFlagIndex      = 0
@@ -42,6 +42,9 @@ const (
 	SvcPushKmsKeyFlagUsage     = "kms key to encrypt artifact with"
 	SvcDeployTagFlagUsage      = "docker image tag to deploy"
 	SvcRestartBatchFlagUsage   = "number of tasks to restart concurrently"
+	SvcSelectFlagUsage         = "comma separated names of services in mu.yml (default: all services)"
+	SvcChangedOnlyFlag         = "changed-only"
+	SvcChangedOnlyFlagUsage    = "only build images for services whose source has changed since the last push"
 	TagFlagName                = "tag, t"
 	ProviderFlagName           = "provider, p"
 	KmsKeyFlagName             = "kms-key, k"
@@ -50,6 +53,14 @@ const (
 	EnvUsage                   = "options for managing environments"
 	EnvArgUsage                = "<environment>"
 	EnvsArgUsage               = "<environments...>"
+	KubeconfigCmd              = "kubeconfig"
+	EnvKubeconfigCmdUsage      = "print a kubeconfig for the kubernetes cluster of an environment, or merge it into the current kubeconfig"
+	EnvKubeconfigMergeFlag     = "merge, m"
+	EnvKubeconfigMergeUsage    = "merge the context into the kubeconfig and make it the current context"
+	EnvKubeconfigFlag          = "kubeconfig"
+	EnvKubeconfigFlagUsage     = "kubeconfig to merge into (default: $KUBECONFIG or ~/.kube/config)"
+	TokenCmd                   = "token"
+	EnvTokenCmdUsage           = "print a token for kubectl to authenticate to the kubernetes cluster of an environment"
 	Tag                        = "tag"
 	BatchSize                  = "batch-size"
 	Provider                   = "provider"
@@ -75,8 +86,9 @@ const (
 	LogsUsage                  = "show environment logs"
 	Format                     = "format"
 	FormatFlag                 = "format, f"
-	FormatFlagUsage            = "output format, either 'shell', 'json' or 'cli' (default: cli)"
-	FormatFlagDefault          = "cli"
+	FormatFlagUsage            = "output format, either 'table', 'json', 'yaml' or 'csv'"
+	EnvShowFormatFlagUsage     = "output format, either 'table', 'json-view', 'yaml' or 'csv' for the environment, or 'json' and 'shell' for its BASE_URL"
+	FormatFlagDefault          = "table"
 	Follow                     = "follow"
 	FollowFlag                 = "follow, f"
 	ServiceFlag                = "service, s"
@@ -94,10 +106,37 @@ const (
 	PushCmd                    = "push"
 	SvcPushCmdUsage            = "push service to repository"
 	DeployCmd                  = "deploy"
-	SvcDeployCmdUsage          = "deploy service to environment"
+	SvcDeployCmdUsage          = "deploy service to environment, after the services it depends on"
 	UndeployCmd                = "undeploy"
 	SvcUndeployCmdUsage        = "undeploy service from environment"
 	SvcUndeployArgsUsage       = "<environment> [<service>]"
+	RollbackCmd                = "rollback"
+	SvcRollbackCmdUsage        = "redeploy a previous revision of service to environment"
+	SvcRollbackToFlag          = "to"
+	SvcRollbackToFlagUsage     = "revision or image tag to rollback to (default: the previous revision)"
+	SvcRollbackListFlag        = "list, l"
+	SvcRollbackListFlagUsage   = "list the revisions of the service without rolling back"
+	ExportCmd                  = "export"
+	SvcExportCmdUsage          = "write the kubernetes resources of service for environment to a directory, without applying them"
+	SvcExportFormatFlag        = "format, f"
+	SvcExportFormatFlagUsage   = "layout of the exported resources, either 'raw', 'kustomize' or 'helm'"
+	SvcExportOutputFlag        = "output, o"
+	SvcExportOutputFlagUsage   = "directory to write the exported resources to"
+	SvcExportSecretsFlag       = "include-secrets"
+	SvcExportSecretsFlagUsage  = "also export the secrets of the service, with their values"
+	PlanFlag                   = "plan"
+	PlanFlagUsage              = "preview changes with CloudFormation change sets without applying them"
+	HistoryCmd                 = "history"
+	HistoryCmdUsage            = "show past runs that changed stacks, or the stacks of a run"
+	HistoryArgUsage            = "[<run>]"
+	DriftCmd                   = "drift"
+	DriftCmdUsage              = "detect resources of stacks that were changed outside of CloudFormation, exits with status 2 if any stack drifted"
+	DriftArgUsage              = "[env|svc|db|all]"
+	DriftFoundExitCode         = 2
+	ProviderAws                = "aws"
+	ProviderLocal              = "local"
+	OutputText                 = "text"
+	OutputJSONL                = "jsonl"
 )
 
 // Constants to prevent multiple updates when making changes.
@@ -115,7 +154,7 @@ const (
 const (
 	EnvAliasCount    = 1
 	SvcAliasCount    = 1
-	SvcFlagsCount    = 3
+	SvcFlagsCount    = 5
 	FailExitCode     = 1
 	Test             = "test"
 	TestEnv          = "fooenv"
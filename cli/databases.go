@@ -3,7 +3,6 @@ package cli
 import (
 	"errors"
 	"fmt"
-	"os"
 
 	"github.com/stelligent/mu/common"
 	"github.com/stelligent/mu/workflows"
@@ -32,9 +31,16 @@ func newDatabaseListCommand(ctx *common.Context) *cli.Command {
 		Name:    "list",
 		Aliases: []string{"ls"},
 		Usage:   "list databases",
+		Flags: []cli.Flag{
+			cli.StringFlag{
+				Name:  FormatFlag,
+				Usage: FormatFlagUsage,
+				Value: FormatFlagDefault,
+			},
+		},
 		Action: func(c *cli.Context) error {
-			workflow := workflows.NewDatabaseLister(ctx, os.Stdout)
-			return workflow()
+			workflow := workflows.NewDatabaseLister(ctx, c.String(Format), textOut)
+			return runWorkflow(ctx, c, workflow)
 		},
 	}
 
@@ -55,7 +61,7 @@ func newDatabaseTerminateCommand(ctx *common.Context) *cli.Command {
 			}
 			serviceName := c.Args().Get(1)
 			workflow := workflows.NewDatabaseTerminator(ctx, serviceName, environmentName)
-			return workflow()
+			return runWorkflow(ctx, c, workflow)
 		},
 	}
 
@@ -68,14 +74,28 @@ func newDatabaseUpsertCommand(ctx *common.Context) *cli.Command {
 		Aliases:   []string{"up"},
 		Usage:     "upsert database",
 		ArgsUsage: "<environment>",
+		Flags: []cli.Flag{
+			cli.BoolFlag{
+				Name:  PlanFlag,
+				Usage: PlanFlagUsage,
+			},
+			cli.StringFlag{
+				Name:   ServiceFlag,
+				Usage:  SvcSelectFlagUsage,
+				EnvVar: "MU_SERVICE",
+			},
+		},
 		Action: func(c *cli.Context) error {
 			environmentName := c.Args().First()
 			if len(environmentName) == 0 {
 				cli.ShowCommandHelp(c, "deploy")
 				return errors.New("environment must be provided")
 			}
-			workflow := workflows.NewDatabaseUpserter(ctx, environmentName)
-			return workflow()
+			planChanges(ctx, c)
+			workflow := workflows.NewSelectedServicesExecutor(ctx, c.String("service"), func() workflows.Executor {
+				return workflows.NewDatabaseUpserter(ctx, environmentName)
+			})
+			return runWorkflow(ctx, c, workflow)
 		},
 	}
 
@@ -96,7 +116,7 @@ func newDatabaseGetPasswordCommand(ctx *common.Context) *cli.Command {
 			}
 			serviceName := c.Args().Get(1)
 			workflow := workflows.DatabaseGetPassword(ctx, environmentName, serviceName)
-			return workflow()
+			return runWorkflow(ctx, c, workflow)
 		},
 	}
 
@@ -122,7 +142,7 @@ func newDatabaseSetPasswordCommand(ctx *common.Context) *cli.Command {
 			}
 			serviceName := c.Args().Get(1)
 			workflow := workflows.DatabaseSetPassword(ctx, environmentName, serviceName, newPassword)
-			return workflow()
+			return runWorkflow(ctx, c, workflow)
 		},
 	}
 
@@ -56,6 +56,8 @@ func TestNewDatabaseListCommand(t *testing.T) {
 
 	assert.NotNil(command)
 	assert.Equal("list", command.Name, "Name should match")
+	assert.Equal(1, len(command.Flags), "Flags length")
+	assert.Equal(FormatFlag, command.Flags[0].GetName(), "Flags Name")
 	assert.NotNil(command.Action)
 }
 
@@ -0,0 +1,44 @@
+package cli
+
+import (
+	"context"
+
+	"github.com/stelligent/mu/common"
+	"github.com/stelligent/mu/workflows"
+	"github.com/urfave/cli"
+)
+
+func newDriftCommand(ctx *common.Context) *cli.Command {
+	cmd := &cli.Command{
+		Name:      DriftCmd,
+		Usage:     DriftCmdUsage,
+		ArgsUsage: DriftArgUsage,
+		Flags: []cli.Flag{
+			cli.StringFlag{
+				Name:  FormatFlag,
+				Usage: FormatFlagUsage,
+				Value: FormatFlagDefault,
+			},
+		},
+		Action: func(c *cli.Context) error {
+			scope := c.Args().First()
+			if scope == "" {
+				scope = workflows.DriftScopeAll
+			}
+
+			workflow := workflows.NewDriftDetector(ctx, scope, c.String(Format), textOut)
+			var workflowErr error
+			err := runWorkflow(ctx, c, func(runCtx context.Context) error {
+				workflowErr = workflow(runCtx)
+				return workflowErr
+			})
+
+			// drift exits with its own code, so that CI jobs can tell it from failing to detect drift
+			if workflows.IsDriftedError(workflowErr) {
+				return cli.NewExitError("", DriftFoundExitCode)
+			}
+			return err
+		},
+	}
+	return cmd
+}
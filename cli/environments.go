@@ -2,7 +2,6 @@ package cli
 
 import (
 	"errors"
-	"os"
 	"strings"
 	"time"
 
@@ -23,6 +22,8 @@ func newEnvironmentsCommand(ctx *common.Context) *cli.Command {
 			*newEnvironmentsUpsertCommand(ctx),
 			*newEnvironmentsTerminateCommand(ctx),
 			*newEnvironmentsLogsCommand(ctx),
+			*newEnvironmentsKubeconfigCommand(ctx),
+			*newEnvironmentsTokenCommand(ctx),
 		},
 	}
 
@@ -40,6 +41,10 @@ func newEnvironmentsUpsertCommand(ctx *common.Context) *cli.Command {
 				Name:  "all, A",
 				Usage: "Upsert all environments defined in the config file",
 			},
+			cli.BoolFlag{
+				Name:  PlanFlag,
+				Usage: PlanFlagUsage,
+			},
 		},
 		Action: func(c *cli.Context) error {
 			var environmentNames []string
@@ -61,8 +66,9 @@ func newEnvironmentsUpsertCommand(ctx *common.Context) *cli.Command {
 				environmentNames = c.Args()
 			}
 
+			planChanges(ctx, c)
 			workflow := workflows.NewEnvironmentsUpserter(ctx, environmentNames)
-			return workflow()
+			return runWorkflow(ctx, c, workflow)
 		},
 	}
 
@@ -74,9 +80,16 @@ func newEnvironmentsListCommand(ctx *common.Context) *cli.Command {
 		Name:    ListCmd,
 		Aliases: []string{ListAlias},
 		Usage:   ListUsage,
+		Flags: []cli.Flag{
+			cli.StringFlag{
+				Name:  FormatFlag,
+				Usage: FormatFlagUsage,
+				Value: FormatFlagDefault,
+			},
+		},
 		Action: func(c *cli.Context) error {
-			workflow := workflows.NewEnvironmentLister(ctx, os.Stdout)
-			return workflow()
+			workflow := workflows.NewEnvironmentLister(ctx, c.String(Format), textOut)
+			return runWorkflow(ctx, c, workflow)
 		},
 	}
 
@@ -90,7 +103,7 @@ func newEnvironmentsShowCommand(ctx *common.Context) *cli.Command {
 		Flags: []cli.Flag{
 			cli.StringFlag{
 				Name:  FormatFlag,
-				Usage: FormatFlagUsage,
+				Usage: EnvShowFormatFlagUsage,
 				Value: FormatFlagDefault,
 			},
 			cli.BoolFlag{
@@ -107,13 +120,13 @@ func newEnvironmentsShowCommand(ctx *common.Context) *cli.Command {
 			}
 
 			watch := c.Bool("watch")
-			workflow := workflows.NewEnvironmentViewer(ctx, c.String(Format), environmentName, os.Stdout)
+			workflow := workflows.NewEnvironmentViewer(ctx, c.String(Format), environmentName, textOut)
 			for true {
 				if watch {
 					print("\033[H\033[2J")
 				}
 
-				err := workflow()
+				err := runWorkflow(ctx, c, workflow)
 				if err != nil {
 					return err
 				} else if watch {
@@ -141,7 +154,7 @@ func newEnvironmentsTerminateCommand(ctx *common.Context) *cli.Command {
 				return errors.New(NoEnvValidation)
 			}
 			workflow := workflows.NewEnvironmentsTerminator(ctx, c.Args())
-			return workflow()
+			return runWorkflow(ctx, c, workflow)
 		},
 	}
 
@@ -171,8 +184,59 @@ func newEnvironmentsLogsCommand(ctx *common.Context) *cli.Command {
 				return errors.New(NoEnvValidation)
 			}
 
-			workflow := workflows.NewEnvironmentLogViewer(ctx, c.Duration(SearchDuration), c.Bool(Follow), environmentName, os.Stdout, strings.Join(c.Args().Tail(), Space))
-			return workflow()
+			workflow := workflows.NewEnvironmentLogViewer(ctx, c.Duration(SearchDuration), c.Bool(Follow), environmentName, textOut, strings.Join(c.Args().Tail(), Space))
+			return runWorkflow(ctx, c, workflow)
+		},
+	}
+
+	return cmd
+}
+
+func newEnvironmentsKubeconfigCommand(ctx *common.Context) *cli.Command {
+	cmd := &cli.Command{
+		Name:      KubeconfigCmd,
+		Usage:     EnvKubeconfigCmdUsage,
+		ArgsUsage: EnvArgUsage,
+		Flags: []cli.Flag{
+			cli.BoolFlag{
+				Name:  EnvKubeconfigMergeFlag,
+				Usage: EnvKubeconfigMergeUsage,
+			},
+			cli.StringFlag{
+				Name:  EnvKubeconfigFlag,
+				Usage: EnvKubeconfigFlagUsage,
+			},
+		},
+		Action: func(c *cli.Context) error {
+			environmentName := c.Args().First()
+			if len(environmentName) == Zero {
+				cli.ShowCommandHelp(c, KubeconfigCmd)
+				return errors.New(NoEnvValidation)
+			}
+
+			workflow := workflows.NewEnvironmentKubeconfigWriter(ctx, environmentName, c.Bool("merge"), c.String(EnvKubeconfigFlag),
+				c.GlobalString("profile"), c.GlobalString("assume-role"), textOut)
+			return runWorkflow(ctx, c, workflow)
+		},
+	}
+
+	return cmd
+}
+
+func newEnvironmentsTokenCommand(ctx *common.Context) *cli.Command {
+	cmd := &cli.Command{
+		Name:      TokenCmd,
+		Usage:     EnvTokenCmdUsage,
+		ArgsUsage: EnvArgUsage,
+		Action: func(c *cli.Context) error {
+			environmentName := c.Args().First()
+			if len(environmentName) == Zero {
+				cli.ShowCommandHelp(c, TokenCmd)
+				return errors.New(NoEnvValidation)
+			}
+
+			workflow := workflows.NewEnvironmentTokenPrinter(ctx, environmentName, textOut)
+			return runWorkflow(ctx, c, workflow)
 		},
 	}
 
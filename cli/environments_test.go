@@ -63,6 +63,8 @@ func TestNewEnvironmentsListCommand(t *testing.T) {
 	assertion.Equal(EnvAliasCount, len(command.Aliases), AliasLenMessage)
 	assertion.Equal(ListAlias, command.Aliases[SingleAliasIndex], AliasMessage)
 	assertion.Equal(ListUsage, command.Usage, UsageMessage)
+	assertion.Equal(1, len(command.Flags), FlagLenMessage)
+	assertion.Equal(FormatFlag, command.Flags[0].GetName(), FlagMessage)
 	assertion.NotNil(command.Action)
 }
 
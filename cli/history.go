@@ -0,0 +1,27 @@
+package cli
+
+import (
+	"github.com/stelligent/mu/common"
+	"github.com/stelligent/mu/workflows"
+	"github.com/urfave/cli"
+)
+
+func newHistoryCommand(ctx *common.Context) *cli.Command {
+	cmd := &cli.Command{
+		Name:      HistoryCmd,
+		Usage:     HistoryCmdUsage,
+		ArgsUsage: HistoryArgUsage,
+		Flags: []cli.Flag{
+			cli.StringFlag{
+				Name:  FormatFlag,
+				Usage: FormatFlagUsage,
+				Value: FormatFlagDefault,
+			},
+		},
+		Action: func(c *cli.Context) error {
+			workflow := workflows.NewHistoryViewer(ctx, c.String(Format), c.Args().First(), textOut)
+			return runWorkflow(ctx, c, workflow)
+		},
+	}
+	return cmd
+}
@@ -0,0 +1,22 @@
+package cli
+
+import (
+	"testing"
+
+	"github.com/stelligent/mu/common"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewHistoryCommand(t *testing.T) {
+	assert := assert.New(t)
+	ctx := common.NewContext()
+
+	command := newHistoryCommand(ctx)
+
+	assert.NotNil(command)
+	assert.Equal(HistoryCmd, command.Name, NameMessage)
+	assert.Equal(HistoryArgUsage, command.ArgsUsage, ArgsUsageMessage)
+	assert.Equal(1, len(command.Flags), FlagLenMessage)
+	assert.Equal(FormatFlag, command.Flags[0].GetName(), FlagMessage)
+	assert.NotNil(command.Action)
+}
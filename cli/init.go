@@ -28,7 +28,7 @@ func newInitCommand(ctx *common.Context) *cli.Command {
 		Usage: "initialize mu.yml file",
 		Action: func(c *cli.Context) error {
 			workflow := workflows.NewConfigInitializer(ctx, c.Bool("env"), c.Int("port"), c.Bool("force"))
-			return workflow()
+			return runWorkflow(ctx, c, workflow)
 		},
 	}
 
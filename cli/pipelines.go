@@ -2,7 +2,6 @@ package cli
 
 import (
 	"fmt"
-	"os"
 	"strings"
 	"time"
 
@@ -31,9 +30,16 @@ func newPipelinesListCommand(ctx *common.Context) *cli.Command {
 		Name:    "list",
 		Aliases: []string{"ls"},
 		Usage:   "list pipelines",
+		Flags: []cli.Flag{
+			cli.StringFlag{
+				Name:  FormatFlag,
+				Usage: FormatFlagUsage,
+				Value: FormatFlagDefault,
+			},
+		},
 		Action: func(c *cli.Context) error {
-			workflow := workflows.NewPipelineLister(ctx, os.Stdout)
-			return workflow()
+			workflow := workflows.NewPipelineLister(ctx, c.String(Format), textOut)
+			return runWorkflow(ctx, c, workflow)
 		},
 	}
 
@@ -49,7 +55,7 @@ func newPipelinesTerminateCommand(ctx *common.Context) *cli.Command {
 		Action: func(c *cli.Context) error {
 			service := c.Args().First()
 			workflow := workflows.NewPipelineTerminator(ctx, service)
-			return workflow()
+			return runWorkflow(ctx, c, workflow)
 		},
 	}
 
@@ -66,10 +72,20 @@ func newPipelinesUpsertCommand(ctx *common.Context) *cli.Command {
 				Name:  "token, t",
 				Usage: "GitHub token ",
 			},
+			cli.BoolFlag{
+				Name:  PlanFlag,
+				Usage: PlanFlagUsage,
+			},
+			cli.StringFlag{
+				Name:   ServiceFlag,
+				Usage:  SvcSelectFlagUsage,
+				EnvVar: "MU_SERVICE",
+			},
 		},
 		Action: func(c *cli.Context) error {
 			token := c.String("token")
-			workflow := workflows.NewPipelineUpserter(ctx, func(required bool) string {
+			planChanges(ctx, c)
+			tokenProvider := func(required bool) string {
 				if required && token == "" {
 					fmt.Println("CodePipeline requires a personal access token from GitHub - https://github.com/settings/tokens")
 					cliExtension := new(common.CliAdditions)
@@ -81,8 +97,11 @@ func newPipelinesUpsertCommand(ctx *common.Context) *cli.Command {
 				}
 
 				return token
+			}
+			workflow := workflows.NewSelectedServicesExecutor(ctx, c.String("service"), func() workflows.Executor {
+				return workflows.NewPipelineUpserter(ctx, tokenProvider)
 			})
-			return workflow()
+			return runWorkflow(ctx, c, workflow)
 		},
 	}
 
@@ -111,8 +130,8 @@ func newPipelinesLogsCommand(ctx *common.Context) *cli.Command {
 		Action: func(c *cli.Context) error {
 			serviceName := c.String("service")
 
-			workflow := workflows.NewPipelineLogViewer(ctx, c.Duration("search-duration"), c.Bool("follow"), serviceName, os.Stdout, strings.Join(c.Args(), " "))
-			return workflow()
+			workflow := workflows.NewPipelineLogViewer(ctx, c.Duration("search-duration"), c.Bool("follow"), serviceName, textOut, strings.Join(c.Args(), " "))
+			return runWorkflow(ctx, c, workflow)
 		},
 	}
 
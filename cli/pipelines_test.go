@@ -30,6 +30,8 @@ func TestNewPipelinesListCommand(t *testing.T) {
 	assert.Equal(1, len(command.Aliases), "Aliases len should match")
 	assert.Equal("ls", command.Aliases[0], "Aliases should match")
 	assert.Equal("list pipelines", command.Usage, "Usage should match")
+	assert.Equal(1, len(command.Flags), "Flags length")
+	assert.Equal(FormatFlag, command.Flags[0].GetName(), "Flags Name")
 	assert.NotNil(command.Action)
 }
 func TestNewPipelinesTerminateCommand(t *testing.T) {
@@ -53,8 +55,10 @@ func TestNewPipelinesUpsertCommand(t *testing.T) {
 
 	assert.NotNil(command)
 	assert.Equal("upsert", command.Name, "Name should match")
-	assert.Equal(1, len(command.Flags), "Flag len should match")
+	assert.Equal(3, len(command.Flags), "Flag len should match")
 	assert.Equal("token, t", command.Flags[0].GetName(), "Flag should match")
+	assert.Equal("plan", command.Flags[1].GetName(), "Flag should match")
+	assert.Equal("service, s", command.Flags[2].GetName(), "Flag should match")
 	assert.NotNil(command.Action)
 }
 func TestNewPipelinesLogsCommand(t *testing.T) {
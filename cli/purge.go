@@ -31,7 +31,7 @@ func newPurgeCommand(ctx *common.Context) *cli.Command {
 				}
 			}
 			workflow := workflows.NewPurge(ctx)
-			return workflow()
+			return runWorkflow(ctx, c, workflow)
 		},
 	}
 	return cmd
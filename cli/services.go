@@ -2,7 +2,6 @@ package cli
 
 import (
 	"errors"
-	"os"
 	"strings"
 	"time"
 
@@ -24,6 +23,8 @@ func newServicesCommand(ctx *common.Context) *cli.Command {
 			*newServicesLogsCommand(ctx),
 			*newServicesExecuteCommand(ctx),
 			*newServicesRestartCommand(ctx),
+			*newServicesRollbackCommand(ctx),
+			*newServicesExportCommand(ctx),
 		},
 	}
 
@@ -36,6 +37,11 @@ func newServicesShowCommand(ctx *common.Context) *cli.Command {
 		Usage:     SvcShowUsage,
 		ArgsUsage: SvcShowUsage,
 		Flags: []cli.Flag{
+			cli.StringFlag{
+				Name:  FormatFlag,
+				Usage: FormatFlagUsage,
+				Value: FormatFlagDefault,
+			},
 			cli.BoolFlag{
 				Name:  "watch, w",
 				Usage: "watch results",
@@ -49,13 +55,19 @@ func newServicesShowCommand(ctx *common.Context) *cli.Command {
 			service := c.Args().First()
 			watch := c.Bool("watch")
 			tasks := c.Bool("tasks")
-			workflow := workflows.NewServiceViewer(ctx, service, ctx.DockerOut, tasks)
+			format := c.String(Format)
+			workflow := workflows.NewServiceViewer(ctx, format, service, ctx.DockerOut, tasks)
+			if service == "" && len(ctx.Config.Services) > 0 {
+				workflow = workflows.NewSelectedServicesExecutor(ctx, "", func() workflows.Executor {
+					return workflows.NewServiceViewer(ctx, format, "", ctx.DockerOut, tasks)
+				})
+			}
 			for true {
 				if watch {
 					print("\033[H\033[2J")
 				}
 
-				err := workflow()
+				err := runWorkflow(ctx, c, workflow)
 				if err != nil {
 					return err
 				} else if watch {
@@ -88,13 +100,25 @@ func newServicesPushCommand(ctx *common.Context) *cli.Command {
 				Name:  KmsKeyFlagName,
 				Usage: SvcPushKmsKeyFlagUsage,
 			},
+			cli.StringFlag{
+				Name:   ServiceFlag,
+				Usage:  SvcSelectFlagUsage,
+				EnvVar: "MU_SERVICE",
+			},
+			cli.BoolFlag{
+				Name:  SvcChangedOnlyFlag,
+				Usage: SvcChangedOnlyFlagUsage,
+			},
 		},
 		Action: func(c *cli.Context) error {
 			tag := c.String(Tag)
 			provider := c.String(Provider)
 			kmsKey := c.String(KmsKey)
-			workflow := workflows.NewServicePusher(ctx, tag, provider, kmsKey, ctx.DockerOut)
-			return workflow()
+			changedOnly := c.Bool(SvcChangedOnlyFlag)
+			workflow := workflows.NewSelectedServicesExecutor(ctx, c.String(SvcCmd), func() workflows.Executor {
+				return workflows.NewServicePusher(ctx, tag, provider, kmsKey, changedOnly, ctx.DockerOut)
+			})
+			return runWorkflow(ctx, c, workflow)
 		},
 	}
 
@@ -111,6 +135,15 @@ func newServicesDeployCommand(ctx *common.Context) *cli.Command {
 				Name:  TagFlagName,
 				Usage: SvcDeployTagFlagUsage,
 			},
+			cli.BoolFlag{
+				Name:  PlanFlag,
+				Usage: PlanFlagUsage,
+			},
+			cli.StringFlag{
+				Name:   ServiceFlag,
+				Usage:  SvcSelectFlagUsage,
+				EnvVar: "MU_SERVICE",
+			},
 		},
 		Action: func(c *cli.Context) error {
 			environmentName := c.Args().First()
@@ -119,8 +152,11 @@ func newServicesDeployCommand(ctx *common.Context) *cli.Command {
 				return errors.New(NoEnvValidation)
 			}
 			tag := c.String(Tag)
-			workflow := workflows.NewServiceDeployer(ctx, environmentName, tag)
-			return workflow()
+			planChanges(ctx, c)
+			workflow := workflows.NewOrderedServicesExecutor(ctx, c.String(SvcCmd), func(serviceCtx *common.Context) workflows.Executor {
+				return workflows.NewServiceDeployer(serviceCtx, environmentName, tag)
+			})
+			return runWorkflow(ctx, c, workflow)
 		},
 	}
 
@@ -140,7 +176,7 @@ func newServicesUndeployCommand(ctx *common.Context) *cli.Command {
 			}
 			serviceName := c.Args().Get(SvcUndeploySvcFlagIndex)
 			workflow := workflows.NewServiceUndeployer(ctx, serviceName, environmentName)
-			return workflow()
+			return runWorkflow(ctx, c, workflow)
 		},
 	}
 
@@ -174,9 +210,107 @@ func newServicesRestartCommand(ctx *common.Context) *cli.Command {
 			batchSize := c.Int(BatchSize)
 
 			workflow := workflows.NewServiceRestarter(ctx, environmentName, serviceName, batchSize)
-			return workflow()
+			return runWorkflow(ctx, c, workflow)
+		},
+	}
+	return cmd
+}
+
+func newServicesRollbackCommand(ctx *common.Context) *cli.Command {
+	cmd := &cli.Command{
+		Name:      RollbackCmd,
+		Usage:     SvcRollbackCmdUsage,
+		ArgsUsage: EnvArgUsage,
+		Flags: []cli.Flag{
+			cli.StringFlag{
+				Name:  SvcRollbackToFlag,
+				Usage: SvcRollbackToFlagUsage,
+			},
+			cli.BoolFlag{
+				Name:  SvcRollbackListFlag,
+				Usage: SvcRollbackListFlagUsage,
+			},
+			cli.StringFlag{
+				Name:  FormatFlag,
+				Usage: FormatFlagUsage,
+				Value: FormatFlagDefault,
+			},
+			cli.BoolFlag{
+				Name:  PlanFlag,
+				Usage: PlanFlagUsage,
+			},
+			cli.StringFlag{
+				Name:   ServiceFlag,
+				Usage:  SvcSelectFlagUsage,
+				EnvVar: "MU_SERVICE",
+			},
+		},
+		Action: func(c *cli.Context) error {
+			environmentName := c.Args().First()
+			if len(environmentName) == Zero {
+				cli.ShowCommandHelp(c, RollbackCmd)
+				return errors.New(NoEnvValidation)
+			}
+			revision := c.String(SvcRollbackToFlag)
+			listOnly := c.Bool("list")
+			format := c.String(Format)
+			planChanges(ctx, c)
+			workflow := workflows.NewSelectedServicesExecutor(ctx, c.String(SvcCmd), func() workflows.Executor {
+				return workflows.NewServiceRollbacker(ctx, environmentName, revision, listOnly, format, ctx.DockerOut)
+			})
+			return runWorkflow(ctx, c, workflow)
+		},
+	}
+
+	return cmd
+}
+
+func newServicesExportCommand(ctx *common.Context) *cli.Command {
+	cmd := &cli.Command{
+		Name:      ExportCmd,
+		Usage:     SvcExportCmdUsage,
+		ArgsUsage: EnvArgUsage,
+		Flags: []cli.Flag{
+			cli.StringFlag{
+				Name:  SvcExportFormatFlag,
+				Usage: SvcExportFormatFlagUsage,
+				Value: string(workflows.ServiceExportFormatRaw),
+			},
+			cli.StringFlag{
+				Name:  SvcExportOutputFlag,
+				Usage: SvcExportOutputFlagUsage,
+			},
+			cli.StringFlag{
+				Name:  TagFlagName,
+				Usage: SvcDeployTagFlagUsage,
+			},
+			cli.BoolFlag{
+				Name:  SvcExportSecretsFlag,
+				Usage: SvcExportSecretsFlagUsage,
+			},
+			cli.StringFlag{
+				Name:   ServiceFlag,
+				Usage:  SvcSelectFlagUsage,
+				EnvVar: "MU_SERVICE",
+			},
+		},
+		Action: func(c *cli.Context) error {
+			environmentName := c.Args().First()
+			if len(environmentName) == Zero {
+				cli.ShowCommandHelp(c, ExportCmd)
+				return errors.New(NoEnvValidation)
+			}
+			tag := c.String(Tag)
+			format := workflows.ServiceExportFormat(c.String("format"))
+			outputDir := c.String("output")
+			includeSecrets := c.Bool(SvcExportSecretsFlag)
+			workflow := workflows.NewSelectedServicesExecutor(ctx, c.String(SvcCmd), func() workflows.Executor {
+				return workflows.NewServiceExporter(ctx, environmentName, tag, format, outputDir, includeSecrets)
+			})
+			return runWorkflow(ctx, c, workflow)
 		},
 	}
+
 	return cmd
 }
 
@@ -208,8 +342,8 @@ func newServicesLogsCommand(ctx *common.Context) *cli.Command {
 			}
 			serviceName := c.String(SvcCmd)
 
-			workflow := workflows.NewServiceLogViewer(ctx, c.Duration(SearchDuration), c.Bool(Follow), environmentName, serviceName, os.Stdout, strings.Join(c.Args().Tail(), Space))
-			return workflow()
+			workflow := workflows.NewServiceLogViewer(ctx, c.Duration(SearchDuration), c.Bool(Follow), environmentName, serviceName, textOut, strings.Join(c.Args().Tail(), Space))
+			return runWorkflow(ctx, c, workflow)
 		},
 	}
 
@@ -263,7 +397,7 @@ func newServicesExecuteCommand(ctx *common.Context) *cli.Command {
 			}
 
 			workflow := workflows.NewServiceExecutor(ctx, *task)
-			return workflow()
+			return runWorkflow(ctx, c, workflow)
 		},
 	}
 	return cmd
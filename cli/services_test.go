@@ -35,7 +35,8 @@ func TestNewServicesShowCommand(t *testing.T) {
 
 	assertion.NotNil(command)
 	assertion.Equal(ShowCmd, command.Name, NameMessage)
-	assertion.Equal(2, len(command.Flags), FlagLenMessage)
+	assertion.Equal(3, len(command.Flags), FlagLenMessage)
+	assertion.Equal(FormatFlag, command.Flags[SvcShowFormatFlagIndex].GetName(), FlagMessage)
 	assertion.Equal(SvcShowUsage, command.ArgsUsage, ArgsUsageMessage)
 	assertion.NotNil(command.Action)
 }
@@ -51,6 +52,8 @@ func TestNewServicesPushCommand(t *testing.T) {
 	assertion.Equal(PushCmd, command.Name, NameMessage)
 	assertion.Equal(SvcFlagsCount, len(command.Flags), FlagLenMessage)
 	assertion.Equal(TagFlagName, command.Flags[SvcPushTagFlagIndex].GetName(), FlagMessage)
+	assertion.Equal(ServiceFlag, command.Flags[3].GetName(), FlagMessage)
+	assertion.Equal(SvcChangedOnlyFlag, command.Flags[4].GetName(), FlagMessage)
 	assertion.NotNil(command.Action)
 }
 
@@ -64,8 +67,10 @@ func TestNewServicesDeployCommand(t *testing.T) {
 	assertion.NotNil(command)
 	assertion.Equal(DeployCmd, command.Name, NameMessage)
 	assertion.Equal(EnvArgUsage, command.ArgsUsage, ArgsUsageMessage)
-	assertion.Equal(SvcAliasCount, len(command.Flags), FlagLenMessage)
+	assertion.Equal(3, len(command.Flags), FlagLenMessage)
 	assertion.Equal(TagFlagName, command.Flags[SvcDeployTagFlagIndex].GetName(), FlagMessage)
+	assertion.Equal(PlanFlag, command.Flags[1].GetName(), FlagMessage)
+	assertion.Equal(ServiceFlag, command.Flags[2].GetName(), FlagMessage)
 	assertion.NotNil(command.Action)
 }
 
@@ -95,6 +100,25 @@ func TestNewServiceRestartCommand(t *testing.T) {
 	assertion.NotNil(command.Action)
 }
 
+func TestNewServicesRollbackCommand(t *testing.T) {
+	assertion := assert.New(t)
+
+	ctx := common.NewContext()
+
+	command := newServicesRollbackCommand(ctx)
+
+	assertion.NotNil(command)
+	assertion.Equal(RollbackCmd, command.Name, NameMessage)
+	assertion.Equal(EnvArgUsage, command.ArgsUsage, ArgsUsageMessage)
+	assertion.Equal(5, len(command.Flags), FlagLenMessage)
+	assertion.Equal(SvcRollbackToFlag, command.Flags[0].GetName(), FlagMessage)
+	assertion.Equal(SvcRollbackListFlag, command.Flags[1].GetName(), FlagMessage)
+	assertion.Equal(FormatFlag, command.Flags[2].GetName(), FlagMessage)
+	assertion.Equal(PlanFlag, command.Flags[3].GetName(), FlagMessage)
+	assertion.Equal(ServiceFlag, command.Flags[4].GetName(), FlagMessage)
+	assertion.NotNil(command.Action)
+}
+
 func TestNewServicesLogsCommand(t *testing.T) {
 	assertion := assert.New(t)
 
@@ -1,5 +1,7 @@
 package common
 
+import "context"
+
 // CatalogUpserter for upserting catalogs
 type CatalogUpserter interface {
 	SetProductVersions(productID string, productVersions map[string]string) error
@@ -7,12 +9,12 @@ type CatalogUpserter interface {
 
 // CatalogProvisioner for provisioning products
 type CatalogProvisioner interface {
-	UpsertProvisionedProduct(productID string, version string, name string, params map[string]string) error
+	UpsertProvisionedProduct(ctx context.Context, productID string, version string, name string, params map[string]string) error
 }
 
 // CatalogTerminator for terminating catalogs
 type CatalogTerminator interface {
-	TerminateProvisionedProducts(productID string) error
+	TerminateProvisionedProducts(ctx context.Context, productID string) error
 }
 
 // CatalogManager composite of all catalog capabilities
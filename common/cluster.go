@@ -20,9 +20,15 @@ type RepositoryDeleter interface {
 	DeleteRepository(repoName string) error
 }
 
+// RepositoryImageTagger adds a tag to an image that already exists in a repo
+type RepositoryImageTagger interface {
+	TagRepositoryImage(repoName string, existingTag string, newTag string) (bool, error)
+}
+
 // ClusterManager composite of all cluster capabilities
 type ClusterManager interface {
 	ClusterInstanceLister
 	RepositoryAuthenticator
 	RepositoryDeleter
+	RepositoryImageTagger
 }
@@ -10,6 +10,7 @@ import (
 	"path"
 	"path/filepath"
 	"regexp"
+	"sort"
 	"strings"
 	"time"
 
@@ -193,7 +194,15 @@ func (ctx *Context) InitializeExtensions() error {
 				}
 			}
 		} else if extension.Image != "" {
-			log.Warningf("Docker based extensions is not yet supported!")
+			ext, err := newImageExtension(extension.Image, extension.Hooks, ctx.DockerManager)
+			if err != nil {
+				log.Warningf("Unable to load extension '%s': %s", extension.Image, err)
+			} else {
+				err = extMgr.AddExtension(ext)
+				if err != nil {
+					log.Warningf("Unable to load extension '%s': %s", extension.Image, err)
+				}
+			}
 		}
 	}
 
@@ -224,6 +233,24 @@ func (ctx *Context) InitializeExtensions() error {
 		}
 	}
 
+	// register the kubernetes resource overrides from within the mu.yml, in a stable order since several can match a resource
+	resourcePatterns := make([]string, 0, len(ctx.Config.Kubernetes))
+	for resourcePattern := range ctx.Config.Kubernetes {
+		resourcePatterns = append(resourcePatterns, resourcePattern)
+	}
+	sort.Strings(resourcePatterns)
+	for _, resourcePattern := range resourcePatterns {
+		ext, err := newKubernetesOverrideExtension(resourcePattern, ctx.Config.Kubernetes[resourcePattern])
+		if err != nil {
+			log.Warningf("Unable to load kubernetes override '%s': %s", resourcePattern, err)
+			continue
+		}
+		err = extMgr.AddExtension(ext)
+		if err != nil {
+			log.Warningf("Unable to load extension '%s': %s", ext.ID(), err)
+		}
+	}
+
 	return nil
 }
 
@@ -1,12 +1,13 @@
 package common
 
 import (
+	"context"
 	"time"
 )
 
-// LogsViewer for viewing cloudwatch logs
+// LogsViewer for viewing cloudwatch logs, following them until the context is done
 type LogsViewer interface {
-	ViewLogs(logGroup string, searchDuration time.Duration, follow bool, filter string, callback func(string, string, int64)) error
+	ViewLogs(ctx context.Context, logGroup string, searchDuration time.Duration, follow bool, filter string, callback func(string, string, int64)) error
 }
 
 // LogsManager composite of all logs capabilities
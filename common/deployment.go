@@ -0,0 +1,49 @@
+package common
+
+// EcsDeployment describes the progress of a traffic shifting deployment of an ECS service
+type EcsDeployment struct {
+	ID            string
+	Status        string
+	StatusMessage string
+	TrafficWeight float64
+}
+
+// EcsDeployment statuses
+const (
+	EcsDeploymentInProgress = "InProgress"
+	EcsDeploymentReady      = "Ready"
+	EcsDeploymentBaking     = "Baking"
+	EcsDeploymentSucceeded  = "Succeeded"
+	EcsDeploymentFailed     = "Failed"
+	EcsDeploymentStopped    = "Stopped"
+)
+
+// IsComplete returns whether the deployment has ended, or is waiting for traffic to be rerouted manually
+func (deployment *EcsDeployment) IsComplete() bool {
+	switch deployment.Status {
+	case EcsDeploymentReady, EcsDeploymentBaking, EcsDeploymentSucceeded, EcsDeploymentFailed, EcsDeploymentStopped:
+		return true
+	}
+	return false
+}
+
+// IsSucceeded returns whether all traffic has been shifted, the original tasks may still be waiting to be terminated
+func (deployment *EcsDeployment) IsSucceeded() bool {
+	return deployment.Status == EcsDeploymentSucceeded || deployment.Status == EcsDeploymentBaking
+}
+
+// EcsDeploymentCreator for starting a deployment that shifts traffic to a new task definition
+type EcsDeploymentCreator interface {
+	CreateEcsDeployment(applicationName string, deploymentGroupName string, taskDefinitionArn string, containerName string, containerPort int) (string, error)
+}
+
+// EcsDeploymentGetter for getting the progress of a deployment
+type EcsDeploymentGetter interface {
+	GetEcsDeployment(deploymentID string) (*EcsDeployment, error)
+}
+
+// DeploymentManager composite of all deployment capabilities
+type DeploymentManager interface {
+	EcsDeploymentCreator
+	EcsDeploymentGetter
+}
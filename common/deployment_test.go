@@ -0,0 +1,31 @@
+package common
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEcsDeployment_Status(t *testing.T) {
+	assert := assert.New(t)
+
+	deployment := &EcsDeployment{Status: EcsDeploymentInProgress}
+	assert.False(deployment.IsComplete())
+	assert.False(deployment.IsSucceeded())
+
+	deployment.Status = EcsDeploymentReady
+	assert.True(deployment.IsComplete())
+	assert.False(deployment.IsSucceeded())
+
+	deployment.Status = EcsDeploymentBaking
+	assert.True(deployment.IsComplete())
+	assert.True(deployment.IsSucceeded())
+
+	deployment.Status = EcsDeploymentSucceeded
+	assert.True(deployment.IsComplete())
+	assert.True(deployment.IsSucceeded())
+
+	deployment.Status = EcsDeploymentStopped
+	assert.True(deployment.IsComplete())
+	assert.False(deployment.IsSucceeded())
+}
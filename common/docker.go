@@ -6,13 +6,17 @@ import (
 	"encoding/json"
 	"fmt"
 	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/network"
 	"github.com/docker/docker/builder/dockerignore"
 	"github.com/docker/docker/client"
 	"github.com/docker/docker/pkg/archive"
 	"github.com/docker/docker/pkg/fileutils"
+	"github.com/docker/docker/pkg/stdcopy"
 	"io"
 	"os"
 	"path/filepath"
+	"strings"
 )
 
 // DockerImageBuilder for creating docker images
@@ -25,10 +29,26 @@ type DockerImagePusher interface {
 	ImagePush(image string, registryAuth string, dockerOut io.Writer) error
 }
 
+// DockerContainerRunner for running a container to completion
+type DockerContainerRunner interface {
+	ContainerRun(image string, stdin io.Reader, stdout io.Writer, stderr io.Writer) error
+}
+
 // DockerManager composite of all cluster capabilities
 type DockerManager interface {
 	DockerImageBuilder
 	DockerImagePusher
+	DockerContainerRunner
+}
+
+// ImageTag returns the tag of an image url, or 'latest' if the url has no tag
+func ImageTag(imageURL string) string {
+	imageURL = strings.SplitN(imageURL, "@", 2)[0]
+	idx := strings.LastIndex(imageURL, ":")
+	if idx < 0 || strings.Contains(imageURL[idx:], "/") {
+		return "latest"
+	}
+	return imageURL[idx+1:]
 }
 
 type clientDockerManager struct {
@@ -135,6 +155,74 @@ func (d *clientDockerManager) ImagePush(image string, registryAuth string, docke
 	return handleDockerResponse(resp, dockerOut)
 }
 
+// ContainerRun creates a container from the image, writes stdin to it and copies its output until it exits
+func (d *clientDockerManager) ContainerRun(image string, stdin io.Reader, stdout io.Writer, stderr io.Writer) error {
+	config := &container.Config{
+		Image:        image,
+		AttachStdin:  true,
+		AttachStdout: true,
+		AttachStderr: true,
+		OpenStdin:    true,
+		StdinOnce:    true,
+	}
+
+	log.Debugf("Creating container from image '%s'", image)
+	resp, err := d.dockerClient.ContainerCreate(context.Background(), config, &container.HostConfig{}, &network.NetworkingConfig{}, "")
+	if err != nil && client.IsErrImageNotFound(err) {
+		log.Debugf("Pulling image '%s'", image)
+		out, pullErr := d.dockerClient.ImagePull(context.Background(), image, types.ImagePullOptions{})
+		if pullErr != nil {
+			return pullErr
+		}
+		if pullErr = handleDockerResponse(out, nil); pullErr != nil {
+			return pullErr
+		}
+		resp, err = d.dockerClient.ContainerCreate(context.Background(), config, &container.HostConfig{}, &network.NetworkingConfig{}, "")
+	}
+	if err != nil {
+		return err
+	}
+	defer d.dockerClient.ContainerRemove(context.Background(), resp.ID, types.ContainerRemoveOptions{Force: true})
+
+	attach, err := d.dockerClient.ContainerAttach(context.Background(), resp.ID, types.ContainerAttachOptions{
+		Stream: true,
+		Stdin:  true,
+		Stdout: true,
+		Stderr: true,
+	})
+	if err != nil {
+		return err
+	}
+	defer attach.Close()
+
+	err = d.dockerClient.ContainerStart(context.Background(), resp.ID, types.ContainerStartOptions{})
+	if err != nil {
+		return err
+	}
+
+	if stdin != nil {
+		if _, err = io.Copy(attach.Conn, stdin); err != nil {
+			return err
+		}
+	}
+	if err = attach.CloseWrite(); err != nil {
+		return err
+	}
+
+	if _, err = stdcopy.StdCopy(stdout, stderr, attach.Reader); err != nil {
+		return err
+	}
+
+	exitCode, err := d.dockerClient.ContainerWait(context.Background(), resp.ID)
+	if err != nil {
+		return err
+	}
+	if exitCode != 0 {
+		return fmt.Errorf("container from image '%s' exited with code %d", image, exitCode)
+	}
+	return nil
+}
+
 type dockerMessage struct {
 	ID          string `json:"id"`
 	Stream      string `json:"stream"`
@@ -0,0 +1,17 @@
+package common
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestImageTag(t *testing.T) {
+	assert := assert.New(t)
+
+	assert.Equal("abc123", ImageTag("1234.dkr.ecr.us-east-1.amazonaws.com/mu-foo:abc123"))
+	assert.Equal("1.15", ImageTag("nginx:1.15"))
+	assert.Equal("latest", ImageTag("nginx"))
+	assert.Equal("latest", ImageTag("localhost:5000/nginx"))
+	assert.Equal("v2", ImageTag("localhost:5000/nginx:v2@sha256:abcd"))
+}
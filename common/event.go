@@ -0,0 +1,79 @@
+package common
+
+import (
+	"encoding/json"
+	"io"
+	"sync"
+	"time"
+)
+
+// Event is a machine readable record of the progress of a command, written as a line of JSON when the output is jsonl
+type Event struct {
+	Time            time.Time `json:"time"`
+	Type            string    `json:"type"`
+	Step            string    `json:"step,omitempty"`
+	StackName       string    `json:"stackName,omitempty"`
+	LogicalID       string    `json:"logicalId,omitempty"`
+	ResourceType    string    `json:"resourceType,omitempty"`
+	Environment     string    `json:"environment,omitempty"`
+	Service         string    `json:"service,omitempty"`
+	Task            string    `json:"task,omitempty"`
+	Deployment      string    `json:"deployment,omitempty"`
+	TrafficWeight   *float64  `json:"trafficWeight,omitempty"`
+	Command         string    `json:"command,omitempty"`
+	Status          string    `json:"status,omitempty"`
+	Reason          string    `json:"reason,omitempty"`
+	DurationSeconds float64   `json:"durationSeconds,omitempty"`
+}
+
+// List of event types and the statuses of step and result events
+const (
+	EventTypeStep          = "step"
+	EventTypeStack         = "stack"
+	EventTypeStackResource = "stackResource"
+	EventTypeStackDrift    = "stackDrift"
+	EventTypeTask          = "task"
+	EventTypeDeployment    = "deployment"
+	EventTypeResult        = "result"
+	EventStatusStarted     = "STARTED"
+	EventStatusSucceeded   = "SUCCEEDED"
+	EventStatusFailed      = "FAILED"
+	EventStatusInterrupted = "INTERRUPTED"
+	EventStatusTimedOut    = "TIMED_OUT"
+)
+
+var eventWriter io.Writer
+var eventMutex sync.Mutex
+
+// SetupEvents writes the events of the command to the writer, a nil writer disables events
+func SetupEvents(writer io.Writer) {
+	eventMutex.Lock()
+	defer eventMutex.Unlock()
+	eventWriter = writer
+}
+
+// EventsEnabled returns true if events are being written, so output meant for a terminal can be skipped
+func EventsEnabled() bool {
+	eventMutex.Lock()
+	defer eventMutex.Unlock()
+	return eventWriter != nil
+}
+
+// EmitEvent writes the event as a line of JSON, if events are enabled
+func EmitEvent(event Event) {
+	eventMutex.Lock()
+	defer eventMutex.Unlock()
+	if eventWriter == nil {
+		return
+	}
+
+	if event.Time.IsZero() {
+		event.Time = time.Now()
+	}
+	data, err := json.Marshal(event)
+	if err != nil {
+		log.Debugf("Unable to encode event: %v", err)
+		return
+	}
+	eventWriter.Write(append(data, '\n'))
+}
@@ -0,0 +1,35 @@
+package common
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEmitEvent(t *testing.T) {
+	assert := assert.New(t)
+
+	// nothing is written until events are enabled
+	EmitEvent(Event{Type: EventTypeStack, StackName: "mu-vpc-dev"})
+	assert.False(EventsEnabled())
+
+	out := new(bytes.Buffer)
+	SetupEvents(out)
+	defer SetupEvents(nil)
+	assert.True(EventsEnabled())
+
+	EmitEvent(Event{Type: EventTypeStack, StackName: "mu-vpc-dev", Status: StackStatusCreateComplete})
+
+	line, err := out.ReadBytes('\n')
+	assert.Nil(err)
+	values := make(map[string]interface{})
+	assert.Nil(json.Unmarshal(line, &values))
+	assert.Equal(EventTypeStack, values["type"])
+	assert.Equal("mu-vpc-dev", values["stackName"])
+	assert.Equal(StackStatusCreateComplete, values["status"])
+	assert.NotEmpty(values["time"])
+	assert.NotContains(values, "task")
+	assert.Equal(0, out.Len())
+}
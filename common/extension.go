@@ -2,16 +2,21 @@ package common
 
 import (
 	"bufio"
+	"bytes"
 	"crypto/sha1"
 	"encoding/hex"
+	"encoding/json"
 	"fmt"
 	"io"
 	"io/ioutil"
 	"net/http"
 	"net/url"
 	"os"
+	"os/exec"
 	"path/filepath"
 	"regexp"
+	"sort"
+	"strings"
 
 	"github.com/mholt/archiver"
 	"github.com/mitchellh/go-homedir"
@@ -24,6 +29,26 @@ type ExtensionImpl interface {
 	DecorateStackTemplate(assetName string, stackName string, templateBody io.Reader) (io.Reader, error)
 	DecorateStackParameters(stackName string, stackParameters map[string]string) (map[string]string, error)
 	DecorateStackTags(stackName string, stackTags map[string]string) (map[string]string, error)
+	KubernetesResourceDecorator
+	StackHookRunner
+	DeployHookRunner
+}
+
+// KubernetesResourceDecorator patches the resources rendered for a kubernetes cluster before they are upserted, and may add resources
+type KubernetesResourceDecorator interface {
+	DecorateKubernetesResources(clusterName string, resources []map[string]interface{}) ([]map[string]interface{}, error)
+}
+
+// StackHookRunner runs hooks around a stack upsert, an error aborts the upsert
+type StackHookRunner interface {
+	BeforeStackUpsert(stackName string) error
+	AfterStackUpsert(stack *Stack) error
+}
+
+// DeployHookRunner runs hooks around a service deploy, an error aborts the deploy
+type DeployHookRunner interface {
+	BeforeDeploy(environmentName string, serviceName string) error
+	AfterDeploy(environmentName string, serviceName string) error
 }
 
 // BaseExtensionImpl basic no-op extension
@@ -46,6 +71,31 @@ func (ext *BaseExtensionImpl) DecorateStackTags(stackName string, stackTags map[
 	return stackTags, nil
 }
 
+// DecorateKubernetesResources don't decorate, just return
+func (ext *BaseExtensionImpl) DecorateKubernetesResources(clusterName string, resources []map[string]interface{}) ([]map[string]interface{}, error) {
+	return resources, nil
+}
+
+// BeforeStackUpsert don't run anything, just return
+func (ext *BaseExtensionImpl) BeforeStackUpsert(stackName string) error {
+	return nil
+}
+
+// AfterStackUpsert don't run anything, just return
+func (ext *BaseExtensionImpl) AfterStackUpsert(stack *Stack) error {
+	return nil
+}
+
+// BeforeDeploy don't run anything, just return
+func (ext *BaseExtensionImpl) BeforeDeploy(environmentName string, serviceName string) error {
+	return nil
+}
+
+// AfterDeploy don't run anything, just return
+func (ext *BaseExtensionImpl) AfterDeploy(environmentName string, serviceName string) error {
+	return nil
+}
+
 // ID returns unique id for extension
 func (ext *BaseExtensionImpl) ID() string {
 	return ext.id
@@ -124,6 +174,59 @@ func (extMgr *extensionsManager) DecorateStackTags(stackName string, stackTags m
 	return outTags, nil
 }
 
+// DecorateKubernetesResources for all extensions
+func (extMgr *extensionsManager) DecorateKubernetesResources(clusterName string, resources []map[string]interface{}) ([]map[string]interface{}, error) {
+	outResources := resources
+	for _, ext := range extMgr.extensions {
+		var err error
+		outResources, err = ext.DecorateKubernetesResources(clusterName, outResources)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return outResources, nil
+}
+
+// BeforeStackUpsert for all extensions, stopping at the first failure
+func (extMgr *extensionsManager) BeforeStackUpsert(stackName string) error {
+	for _, ext := range extMgr.extensions {
+		if err := ext.BeforeStackUpsert(stackName); err != nil {
+			return fmt.Errorf("extension '%s' failed before upsert of stack '%s': %v", ext.ID(), stackName, err)
+		}
+	}
+	return nil
+}
+
+// AfterStackUpsert for all extensions, stopping at the first failure
+func (extMgr *extensionsManager) AfterStackUpsert(stack *Stack) error {
+	for _, ext := range extMgr.extensions {
+		if err := ext.AfterStackUpsert(stack); err != nil {
+			return fmt.Errorf("extension '%s' failed after upsert of stack '%s': %v", ext.ID(), stack.Name, err)
+		}
+	}
+	return nil
+}
+
+// BeforeDeploy for all extensions, stopping at the first failure
+func (extMgr *extensionsManager) BeforeDeploy(environmentName string, serviceName string) error {
+	for _, ext := range extMgr.extensions {
+		if err := ext.BeforeDeploy(environmentName, serviceName); err != nil {
+			return fmt.Errorf("extension '%s' failed before deploy of service '%s' to '%s': %v", ext.ID(), serviceName, environmentName, err)
+		}
+	}
+	return nil
+}
+
+// AfterDeploy for all extensions, stopping at the first failure
+func (extMgr *extensionsManager) AfterDeploy(environmentName string, serviceName string) error {
+	for _, ext := range extMgr.extensions {
+		if err := ext.AfterDeploy(environmentName, serviceName); err != nil {
+			return fmt.Errorf("extension '%s' failed after deploy of service '%s' to '%s': %v", ext.ID(), serviceName, environmentName, err)
+		}
+	}
+	return nil
+}
+
 // Extension for template overrides in mu.yml
 type templateOverrideExtension struct {
 	BaseExtensionImpl
@@ -203,13 +306,108 @@ func (ext *paramOverrideExtension) DecorateStackParameters(stackName string, sta
 	return stackParams, nil
 }
 
+// Extension for kubernetes resource overrides in mu.yml
+type kubernetesOverrideExtension struct {
+	BaseExtensionImpl
+	kind        string
+	nameMatcher *regexp.Regexp
+	patch       map[string]interface{}
+	jsonPatch   []JSONPatchOperation
+	resources   []map[string]interface{}
+}
+
+func newKubernetesOverrideExtension(resourcePattern string, override KubernetesOverride) (ExtensionImpl, error) {
+	parts := strings.SplitN(resourcePattern, "/", 2)
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return nil, fmt.Errorf("must be of the form '<kind>/<name>'")
+	}
+	nameMatcher, err := regexp.Compile(fmt.Sprintf("^%s$", parts[1]))
+	if err != nil {
+		return nil, err
+	}
+
+	ext := &kubernetesOverrideExtension{
+		BaseExtensionImpl{fmt.Sprintf("kubernetesOverride:%s", resourcePattern)},
+		parts[0],
+		nameMatcher,
+		nil,
+		make([]JSONPatchOperation, 0),
+		make([]map[string]interface{}, 0),
+	}
+
+	// values from the mu.yml have interface{} keys, like the resources parsed from the templates before conversion
+	if override.Patch != nil {
+		ext.patch = ConvertMapI2MapS(override.Patch).(map[string]interface{})
+	}
+	for _, operation := range override.JSONPatch {
+		operation.Value = ConvertMapI2MapS(operation.Value)
+		ext.jsonPatch = append(ext.jsonPatch, operation)
+	}
+	for _, resource := range override.Resources {
+		ext.resources = append(ext.resources, ConvertMapI2MapS(resource).(map[string]interface{}))
+	}
+	return ext, nil
+}
+
+// DecorateKubernetesResources from overrides in mu.yml, additional resources are upserted right after the matching resource
+func (ext *kubernetesOverrideExtension) DecorateKubernetesResources(clusterName string, resources []map[string]interface{}) ([]map[string]interface{}, error) {
+	outResources := make([]map[string]interface{}, 0, len(resources))
+	for _, resource := range resources {
+		outResources = append(outResources, resource)
+		name := MapGetString(resource, "metadata", "name")
+		if MapGetString(resource, "kind") != ext.kind || !ext.nameMatcher.MatchString(name) {
+			continue
+		}
+
+		log.Debugf("Applying kubernetes override '%s' to %s '%s' in cluster '%s'", ext.id, ext.kind, name, clusterName)
+		if ext.patch != nil {
+			resource = strategicMergePatch(resource, ext.patch)
+		}
+		if len(ext.jsonPatch) > 0 {
+			patched, err := applyJSONPatch(resource, ext.jsonPatch)
+			if err != nil {
+				return nil, fmt.Errorf("kubernetes override '%s' failed for %s '%s': %v", ext.id, ext.kind, name, err)
+			}
+			patchedResource, ok := patched.(map[string]interface{})
+			if !ok {
+				return nil, fmt.Errorf("kubernetes override '%s' failed for %s '%s': patched resource is not an object", ext.id, ext.kind, name)
+			}
+			resource = patchedResource
+		}
+		outResources[len(outResources)-1] = resource
+
+		// additional resources default to the namespace of the matching resource
+		namespace := MapGetString(resource, "metadata", "namespace")
+		for _, additionalResource := range ext.resources {
+			additionalResource = copyValue(additionalResource).(map[string]interface{})
+			if metadata, ok := additionalResource["metadata"].(map[string]interface{}); ok && namespace != "" && MapGetString(metadata, "namespace") == "" {
+				metadata["namespace"] = namespace
+			}
+			outResources = append(outResources, additionalResource)
+		}
+	}
+	return outResources, nil
+}
+
 // Extension for archives of templates
 type templateArchiveExtension struct {
 	BaseExtensionImpl
-	path string
-	mode TemplateUpdateMode
+	path  string
+	mode  TemplateUpdateMode
+	hooks map[ExtensionHook]string
 }
 
+// ExtensionHook of valid hook names in mu-extension.yml
+type ExtensionHook string
+
+// list of extension hooks
+const (
+	ExtensionHookBeforeStackUpsert ExtensionHook = "beforeStackUpsert"
+	ExtensionHookAfterStackUpsert                = "afterStackUpsert"
+	ExtensionHookBeforeDeploy                    = "beforeDeploy"
+	ExtensionHookAfterDeploy                     = "afterDeploy"
+)
+
 // TemplateUpdateMode of valid template update modes
 type TemplateUpdateMode string
 
@@ -285,6 +483,7 @@ func newTemplateArchiveExtension(extensionURL *url.URL, artifactManager Artifact
 		BaseExtensionImpl{extensionURL.String()},
 		filepath.Join(extensionsDirectory, extID),
 		TemplateUpdateMerge,
+		make(map[ExtensionHook]string),
 	}
 
 	if fi, err := os.Stat(extensionURL.Path); extensionURL.Scheme == "file" && err == nil && fi.IsDir() {
@@ -310,6 +509,11 @@ func newTemplateArchiveExtension(extensionURL *url.URL, artifactManager Artifact
 			if v, ok := extManifest["templateUpdateMode"]; ok {
 				ext.mode = TemplateUpdateMode(v.(string))
 			}
+			if hooks, ok := extManifest["hooks"].(map[interface{}]interface{}); ok {
+				for name, command := range hooks {
+					ext.hooks[ExtensionHook(fmt.Sprint(name))] = fmt.Sprint(command)
+				}
+			}
 		}
 	} else {
 		log.Debugf("error reading mu-extension.yml: %s", err)
@@ -369,6 +573,292 @@ func (ext *templateArchiveExtension) DecorateStackTemplate(assetName string, sta
 	return outTemplate, nil
 }
 
+// Extension that runs a docker image for each decoration, and for the hooks it declares in the mu.yml
+type imageExtension struct {
+	BaseExtensionImpl
+	image           string
+	hooks           map[ExtensionHook]bool
+	containerRunner DockerContainerRunner
+}
+
+// list of actions sent to image extensions
+const (
+	imageExtensionActionTemplate   = "decorateTemplate"
+	imageExtensionActionParameters = "decorateParameters"
+	imageExtensionActionTags       = "decorateTags"
+	imageExtensionActionKubernetes = "decorateKubernetesResources"
+)
+
+// imageExtensionRequest is written as JSON to stdin of the container
+type imageExtensionRequest struct {
+	Action     string            `json:"action"`
+	AssetName  string            `json:"assetName,omitempty"`
+	StackName  string            `json:"stackName,omitempty"`
+	Template   string            `json:"template,omitempty"`
+	Parameters map[string]string `json:"parameters,omitempty"`
+	Tags       map[string]string `json:"tags,omitempty"`
+	// StackStatus and Outputs are sent to the afterStackUpsert hook
+	StackStatus string            `json:"stackStatus,omitempty"`
+	Outputs     map[string]string `json:"outputs,omitempty"`
+	// EnvironmentName and ServiceName are sent to the deploy hooks
+	EnvironmentName string `json:"environmentName,omitempty"`
+	ServiceName     string `json:"serviceName,omitempty"`
+	// ClusterName and Resources are sent to decorate kubernetes resources
+	ClusterName string                   `json:"clusterName,omitempty"`
+	Resources   []map[string]interface{} `json:"resources,omitempty"`
+}
+
+// imageExtensionResponse is read as JSON from stdout of the container, missing fields are left undecorated
+type imageExtensionResponse struct {
+	Template   *string                  `json:"template,omitempty"`
+	Parameters map[string]string        `json:"parameters,omitempty"`
+	Tags       map[string]string        `json:"tags,omitempty"`
+	Resources  []map[string]interface{} `json:"resources,omitempty"`
+}
+
+func newImageExtension(image string, hooks []string, containerRunner DockerContainerRunner) (ExtensionImpl, error) {
+	if containerRunner == nil {
+		return nil, fmt.Errorf("docker is not available")
+	}
+
+	declaredHooks := make(map[ExtensionHook]bool)
+	for _, hook := range hooks {
+		switch ExtensionHook(hook) {
+		case ExtensionHookBeforeStackUpsert, ExtensionHookAfterStackUpsert, ExtensionHookBeforeDeploy, ExtensionHookAfterDeploy:
+			declaredHooks[ExtensionHook(hook)] = true
+		default:
+			return nil, fmt.Errorf("unknown hook '%s'", hook)
+		}
+	}
+
+	log.Warningf("Loaded extension %s", image)
+	return &imageExtension{
+		BaseExtensionImpl{fmt.Sprintf("image:%s", image)},
+		image,
+		declaredHooks,
+		containerRunner,
+	}, nil
+}
+
+func (ext *imageExtension) run(request *imageExtensionRequest) (*imageExtensionResponse, error) {
+	requestBody, err := json.Marshal(request)
+	if err != nil {
+		return nil, err
+	}
+
+	stdout := new(bytes.Buffer)
+	stderr := new(bytes.Buffer)
+	log.Debugf("Running extension '%s' for action '%s' on stack '%s'", ext.image, request.Action, request.StackName)
+	err = ext.containerRunner.ContainerRun(ext.image, bytes.NewReader(requestBody), stdout, stderr)
+	if stderr.Len() > 0 {
+		log.Debugf("Extension '%s' stderr: %s", ext.image, stderr.String())
+	}
+	if err != nil {
+		return nil, fmt.Errorf("extension '%s' failed: %v", ext.image, err)
+	}
+
+	response := &imageExtensionResponse{}
+	if stdout.Len() == 0 {
+		return response, nil
+	}
+	err = json.Unmarshal(stdout.Bytes(), response)
+	if err != nil {
+		return nil, fmt.Errorf("extension '%s' returned invalid response: %v", ext.image, err)
+	}
+	return response, nil
+}
+
+// DecorateStackTemplate from the template returned by the container
+func (ext *imageExtension) DecorateStackTemplate(assetName string, stackName string, inTemplate io.Reader) (io.Reader, error) {
+	templateBody, err := ioutil.ReadAll(inTemplate)
+	if err != nil {
+		return nil, err
+	}
+
+	response, err := ext.run(&imageExtensionRequest{
+		Action:    imageExtensionActionTemplate,
+		AssetName: assetName,
+		StackName: stackName,
+		Template:  string(templateBody),
+	})
+	if err != nil {
+		return nil, err
+	}
+	if response.Template == nil {
+		return bytes.NewReader(templateBody), nil
+	}
+	return bytes.NewBufferString(*response.Template), nil
+}
+
+// DecorateStackParameters from the parameters returned by the container
+func (ext *imageExtension) DecorateStackParameters(stackName string, stackParameters map[string]string) (map[string]string, error) {
+	response, err := ext.run(&imageExtensionRequest{
+		Action:     imageExtensionActionParameters,
+		StackName:  stackName,
+		Parameters: stackParameters,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if response.Parameters == nil {
+		return stackParameters, nil
+	}
+	return response.Parameters, nil
+}
+
+// DecorateStackTags from the tags returned by the container
+func (ext *imageExtension) DecorateStackTags(stackName string, stackTags map[string]string) (map[string]string, error) {
+	response, err := ext.run(&imageExtensionRequest{
+		Action:    imageExtensionActionTags,
+		StackName: stackName,
+		Tags:      stackTags,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if response.Tags == nil {
+		return stackTags, nil
+	}
+	return response.Tags, nil
+}
+
+// DecorateKubernetesResources from the resources returned by the container
+func (ext *imageExtension) DecorateKubernetesResources(clusterName string, resources []map[string]interface{}) ([]map[string]interface{}, error) {
+	response, err := ext.run(&imageExtensionRequest{
+		Action:      imageExtensionActionKubernetes,
+		ClusterName: clusterName,
+		Resources:   resources,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if response.Resources == nil {
+		return resources, nil
+	}
+	return response.Resources, nil
+}
+
+// runHook runs the shell command declared for the hook in mu-extension.yml from the extension directory
+func (ext *templateArchiveExtension) runHook(hook ExtensionHook, env map[string]string) error {
+	command, ok := ext.hooks[hook]
+	if !ok || command == "" {
+		return nil
+	}
+
+	log.Noticef("Running %s hook from extension '%s'", hook, ext.id)
+	cmd := exec.Command("sh", "-c", command)
+	cmd.Dir = ext.path
+	cmd.Env = os.Environ()
+	cmd.Env = append(cmd.Env, fmt.Sprintf("MU_HOOK=%s", hook))
+	for _, key := range sortedKeys(env) {
+		cmd.Env = append(cmd.Env, fmt.Sprintf("%s=%s", key, env[key]))
+	}
+
+	out, err := cmd.CombinedOutput()
+	for _, line := range strings.Split(strings.TrimRight(string(out), "\n"), "\n") {
+		if line != "" {
+			log.Infof("  %s", line)
+		}
+	}
+	return err
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+var hookEnvNameInvalidChars = regexp.MustCompile("[^A-Za-z0-9]")
+
+// hookEnvName converts a stack output name to an environment variable name
+func hookEnvName(prefix string, name string) string {
+	return prefix + strings.ToUpper(hookEnvNameInvalidChars.ReplaceAllString(name, "_"))
+}
+
+// BeforeStackUpsert runs the beforeStackUpsert hook
+func (ext *templateArchiveExtension) BeforeStackUpsert(stackName string) error {
+	return ext.runHook(ExtensionHookBeforeStackUpsert, map[string]string{
+		"MU_STACK_NAME": stackName,
+	})
+}
+
+// AfterStackUpsert runs the afterStackUpsert hook with the stack status and outputs
+func (ext *templateArchiveExtension) AfterStackUpsert(stack *Stack) error {
+	env := map[string]string{
+		"MU_STACK_NAME":   stack.Name,
+		"MU_STACK_STATUS": stack.Status,
+	}
+	for key, value := range stack.Outputs {
+		env[hookEnvName("MU_STACK_OUTPUT_", key)] = value
+	}
+	return ext.runHook(ExtensionHookAfterStackUpsert, env)
+}
+
+// BeforeDeploy runs the beforeDeploy hook
+func (ext *templateArchiveExtension) BeforeDeploy(environmentName string, serviceName string) error {
+	return ext.runHook(ExtensionHookBeforeDeploy, map[string]string{
+		"MU_ENVIRONMENT": environmentName,
+		"MU_SERVICE":     serviceName,
+	})
+}
+
+// AfterDeploy runs the afterDeploy hook
+func (ext *templateArchiveExtension) AfterDeploy(environmentName string, serviceName string) error {
+	return ext.runHook(ExtensionHookAfterDeploy, map[string]string{
+		"MU_ENVIRONMENT": environmentName,
+		"MU_SERVICE":     serviceName,
+	})
+}
+
+// runHook runs the container with the action of the hook, only if the extension declared the hook
+func (ext *imageExtension) runHook(request *imageExtensionRequest) error {
+	if !ext.hooks[ExtensionHook(request.Action)] {
+		return nil
+	}
+	_, err := ext.run(request)
+	return err
+}
+
+// BeforeStackUpsert runs the container with the beforeStackUpsert action, a non-zero exit aborts the upsert
+func (ext *imageExtension) BeforeStackUpsert(stackName string) error {
+	return ext.runHook(&imageExtensionRequest{
+		Action:    string(ExtensionHookBeforeStackUpsert),
+		StackName: stackName,
+	})
+}
+
+// AfterStackUpsert runs the container with the afterStackUpsert action
+func (ext *imageExtension) AfterStackUpsert(stack *Stack) error {
+	return ext.runHook(&imageExtensionRequest{
+		Action:      string(ExtensionHookAfterStackUpsert),
+		StackName:   stack.Name,
+		StackStatus: stack.Status,
+		Outputs:     stack.Outputs,
+	})
+}
+
+// BeforeDeploy runs the container with the beforeDeploy action
+func (ext *imageExtension) BeforeDeploy(environmentName string, serviceName string) error {
+	return ext.runHook(&imageExtensionRequest{
+		Action:          string(ExtensionHookBeforeDeploy),
+		EnvironmentName: environmentName,
+		ServiceName:     serviceName,
+	})
+}
+
+// AfterDeploy runs the container with the afterDeploy action
+func (ext *imageExtension) AfterDeploy(environmentName string, serviceName string) error {
+	return ext.runHook(&imageExtensionRequest{
+		Action:          string(ExtensionHookAfterDeploy),
+		EnvironmentName: environmentName,
+		ServiceName:     serviceName,
+	})
+}
+
 func urlToID(u *url.URL) string {
 	h := sha1.New()
 	h.Write([]byte(u.String()))
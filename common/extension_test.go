@@ -0,0 +1,254 @@
+package common
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"gopkg.in/yaml.v2"
+)
+
+type mockedContainerRunner struct {
+	mock.Mock
+	requests []*imageExtensionRequest
+}
+
+func (m *mockedContainerRunner) ContainerRun(image string, stdin io.Reader, stdout io.Writer, stderr io.Writer) error {
+	request := &imageExtensionRequest{}
+	body, _ := ioutil.ReadAll(stdin)
+	json.Unmarshal(body, request)
+	m.requests = append(m.requests, request)
+
+	args := m.Called(image)
+	stdout.Write([]byte(args.String(0)))
+	return args.Error(1)
+}
+
+func TestImageExtension_DecorateStackTemplate(t *testing.T) {
+	assert := assert.New(t)
+
+	runner := new(mockedContainerRunner)
+	runner.On("ContainerRun", "acme/policy:1").Return(`{"template": "Resources: {}"}`, nil).Once()
+	runner.On("ContainerRun", "acme/policy:1").Return(`{}`, nil).Once()
+
+	ext, err := newImageExtension("acme/policy:1", nil, runner)
+	assert.Nil(err)
+	assert.Equal("image:acme/policy:1", ext.ID())
+
+	out, err := ext.DecorateStackTemplate("cloudformation/vpc.yml", "mu-vpc-dev", bytes.NewBufferString("Outputs: {}"))
+	assert.Nil(err)
+	body, _ := ioutil.ReadAll(out)
+	assert.Equal("Resources: {}", string(body))
+
+	assert.Equal(imageExtensionActionTemplate, runner.requests[0].Action)
+	assert.Equal("cloudformation/vpc.yml", runner.requests[0].AssetName)
+	assert.Equal("mu-vpc-dev", runner.requests[0].StackName)
+	assert.Equal("Outputs: {}", runner.requests[0].Template)
+
+	// a response without a template leaves the template undecorated
+	out, err = ext.DecorateStackTemplate("cloudformation/vpc.yml", "mu-vpc-dev", bytes.NewBufferString("Outputs: {}"))
+	assert.Nil(err)
+	body, _ = ioutil.ReadAll(out)
+	assert.Equal("Outputs: {}", string(body))
+
+	runner.AssertExpectations(t)
+}
+
+func TestImageExtension_DecorateStackParameters(t *testing.T) {
+	assert := assert.New(t)
+
+	runner := new(mockedContainerRunner)
+	runner.On("ContainerRun", "acme/policy:1").Return(`{"parameters": {"InstanceType": "t3.small"}}`, nil)
+
+	ext, err := newImageExtension("acme/policy:1", nil, runner)
+	assert.Nil(err)
+
+	params, err := ext.DecorateStackParameters("mu-environment-dev", map[string]string{"InstanceType": "t2.micro"})
+	assert.Nil(err)
+	assert.Equal("t3.small", params["InstanceType"])
+	assert.Equal(imageExtensionActionParameters, runner.requests[0].Action)
+	assert.Equal("t2.micro", runner.requests[0].Parameters["InstanceType"])
+}
+
+func TestImageExtension_DecorateStackTags(t *testing.T) {
+	assert := assert.New(t)
+
+	runner := new(mockedContainerRunner)
+	runner.On("ContainerRun", "acme/policy:1").Return(``, nil)
+
+	ext, err := newImageExtension("acme/policy:1", nil, runner)
+	assert.Nil(err)
+
+	tags, err := ext.DecorateStackTags("mu-environment-dev", map[string]string{"mu:type": "environment"})
+	assert.Nil(err)
+	assert.Equal(map[string]string{"mu:type": "environment"}, tags)
+	assert.Equal(imageExtensionActionTags, runner.requests[0].Action)
+}
+
+func TestImageExtension_Errors(t *testing.T) {
+	assert := assert.New(t)
+
+	_, err := newImageExtension("acme/policy:1", nil, nil)
+	assert.NotNil(err)
+
+	_, err = newImageExtension("acme/policy:1", []string{"afterEverything"}, new(mockedContainerRunner))
+	assert.NotNil(err)
+
+	runner := new(mockedContainerRunner)
+	runner.On("ContainerRun", "acme/broken:1").Return(``, errors.New("exited with code 1"))
+	runner.On("ContainerRun", "acme/invalid:1").Return(`not json`, nil)
+
+	ext, _ := newImageExtension("acme/broken:1", nil, runner)
+	_, err = ext.DecorateStackTags("mu-environment-dev", map[string]string{})
+	assert.NotNil(err)
+
+	ext, _ = newImageExtension("acme/invalid:1", nil, runner)
+	_, err = ext.DecorateStackParameters("mu-environment-dev", map[string]string{})
+	assert.NotNil(err)
+}
+
+func TestTemplateArchiveExtension_Hooks(t *testing.T) {
+	assert := assert.New(t)
+
+	dir, err := ioutil.TempDir("", "mu-extension")
+	assert.Nil(err)
+	defer os.RemoveAll(dir)
+
+	manifest := `
+name: hooks
+hooks:
+  afterStackUpsert: echo "$MU_HOOK $MU_STACK_NAME $MU_STACK_STATUS $MU_STACK_OUTPUT_BASE_URL" > after.txt
+  beforeDeploy: exit 1
+`
+	assert.Nil(ioutil.WriteFile(filepath.Join(dir, "mu-extension.yml"), []byte(manifest), 0644))
+
+	u, _ := url.Parse(fmt.Sprintf("file://%s", dir))
+	ext, err := newTemplateArchiveExtension(u, nil)
+	assert.Nil(err)
+
+	err = ext.AfterStackUpsert(&Stack{
+		Name:    "mu-loadbalancer-dev",
+		Status:  StackStatusCreateComplete,
+		Outputs: map[string]string{"Base.Url": "http://example.com"},
+	})
+	assert.Nil(err)
+	out, err := ioutil.ReadFile(filepath.Join(dir, "after.txt"))
+	assert.Nil(err)
+	assert.Equal("afterStackUpsert mu-loadbalancer-dev CREATE_COMPLETE http://example.com\n", string(out))
+
+	assert.Nil(ext.BeforeStackUpsert("mu-loadbalancer-dev"))
+	assert.NotNil(ext.BeforeDeploy("dev", "api"))
+	assert.Nil(ext.AfterDeploy("dev", "api"))
+}
+
+func TestExtensionsManager_Hooks(t *testing.T) {
+	assert := assert.New(t)
+
+	runner := new(mockedContainerRunner)
+	runner.On("ContainerRun", "acme/catalog:1").Return(``, nil)
+	runner.On("ContainerRun", "acme/smoke:1").Return(``, errors.New("exited with code 1"))
+
+	extMgr, _ := newExtensionsManager()
+	catalogExt, _ := newImageExtension("acme/catalog:1", []string{"afterDeploy"}, runner)
+	smokeExt, _ := newImageExtension("acme/smoke:1", []string{"beforeDeploy", "afterDeploy"}, runner)
+	assert.Nil(extMgr.AddExtension(catalogExt))
+	assert.Nil(extMgr.AddExtension(smokeExt))
+
+	err := extMgr.AfterDeploy("dev", "api")
+	assert.NotNil(err)
+	assert.Contains(err.Error(), "image:acme/smoke:1")
+
+	assert.Equal(2, len(runner.requests))
+	assert.Equal("afterDeploy", runner.requests[0].Action)
+	assert.Equal("dev", runner.requests[0].EnvironmentName)
+	assert.Equal("api", runner.requests[0].ServiceName)
+
+	// hooks that an extension didn't declare don't run its container
+	assert.Nil(extMgr.BeforeStackUpsert("mu-environment-dev"))
+	assert.Nil(extMgr.AfterStackUpsert(&Stack{Name: "mu-environment-dev", Status: StackStatusCreateComplete}))
+	assert.NotNil(extMgr.BeforeDeploy("dev", "api"))
+	assert.Equal(3, len(runner.requests))
+	assert.Equal("beforeDeploy", runner.requests[2].Action)
+}
+
+func TestKubernetesOverrideExtension(t *testing.T) {
+	assert := assert.New(t)
+
+	override := KubernetesOverride{}
+	err := yaml.Unmarshal([]byte(`
+patch:
+  spec:
+    template:
+      spec:
+        nodeSelector:
+          disktype: ssd
+jsonPatch:
+- op: add
+  path: /metadata/annotations/owner
+  value: team-a
+resources:
+- apiVersion: policy/v1beta1
+  kind: PodDisruptionBudget
+  metadata:
+    name: api-pdb
+  spec:
+    minAvailable: 1
+`), &override)
+	assert.Nil(err)
+
+	_, err = newKubernetesOverrideExtension("api-deployment", override)
+	assert.NotNil(err)
+
+	ext, err := newKubernetesOverrideExtension("Deployment/.*-deployment", override)
+	assert.Nil(err)
+	assert.Equal("kubernetesOverride:Deployment/.*-deployment", ext.ID())
+
+	resources, err := ext.DecorateKubernetesResources("mu-environment-dev", []map[string]interface{}{
+		loadYamlAsResource(patchTestDeployment),
+		loadYamlAsResource("kind: Service\nmetadata:\n  name: api-deployment\n  namespace: mu-service-api\n"),
+	})
+	assert.Nil(err)
+	assert.Equal(3, len(resources))
+
+	assert.Equal("ssd", MapGetString(resources[0], "spec", "template", "spec", "nodeSelector", "disktype"))
+	assert.Equal("team-a", MapGetString(resources[0], "metadata", "annotations", "owner"))
+	assert.Equal("PodDisruptionBudget", MapGetString(resources[1], "kind"))
+	assert.Equal("mu-service-api", MapGetString(resources[1], "metadata", "namespace"))
+	assert.Equal(int64(1), MapGet(resources[1], "spec", "minAvailable"))
+	assert.Equal("Service", MapGetString(resources[2], "kind"))
+	assert.Nil(MapGet(resources[2], "spec"))
+}
+
+func TestImageExtension_DecorateKubernetesResources(t *testing.T) {
+	assert := assert.New(t)
+
+	runner := new(mockedContainerRunner)
+	runner.On("ContainerRun", "acme/k8s:1").Return(`{"resources": [{"kind": "Deployment"}, {"kind": "PodDisruptionBudget"}]}`, nil).Once()
+	runner.On("ContainerRun", "acme/k8s:1").Return(`{}`, nil).Once()
+
+	ext, _ := newImageExtension("acme/k8s:1", nil, runner)
+	resources := []map[string]interface{}{{"kind": "Deployment"}}
+
+	out, err := ext.DecorateKubernetesResources("mu-environment-dev", resources)
+	assert.Nil(err)
+	assert.Equal(2, len(out))
+	assert.Equal("PodDisruptionBudget", out[1]["kind"])
+
+	assert.Equal(imageExtensionActionKubernetes, runner.requests[0].Action)
+	assert.Equal("mu-environment-dev", runner.requests[0].ClusterName)
+	assert.Equal(1, len(runner.requests[0].Resources))
+
+	// a response without resources leaves the resources undecorated
+	out, err = ext.DecorateKubernetesResources("mu-environment-dev", resources)
+	assert.Nil(err)
+	assert.Equal(resources, out)
+}
@@ -0,0 +1,486 @@
+package common
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mitchellh/go-homedir"
+	"gopkg.in/yaml.v2"
+)
+
+// JournalStep is a stack that a workflow run upserted or deleted
+type JournalStep struct {
+	StackName string    `json:"stackName" yaml:"stackName"`
+	Action    string    `json:"action" yaml:"action"`
+	Hash      string    `json:"hash,omitempty" yaml:"hash,omitempty"`
+	Status    string    `json:"status" yaml:"status"`
+	Skipped   bool      `json:"skipped,omitempty" yaml:"skipped,omitempty"`
+	Time      time.Time `json:"time" yaml:"time"`
+}
+
+// JournalRun is a run of a workflow that changed stacks, with the steps it completed
+type JournalRun struct {
+	ID        string        `json:"id" yaml:"id"`
+	Command   string        `json:"command" yaml:"command"`
+	Namespace string        `json:"namespace" yaml:"namespace"`
+	ResumeOf  string        `json:"resumeOf,omitempty" yaml:"resumeOf,omitempty"`
+	Status    string        `json:"status" yaml:"status"`
+	StartTime time.Time     `json:"startTime" yaml:"startTime"`
+	EndTime   time.Time     `json:"endTime,omitempty" yaml:"endTime,omitempty"`
+	Steps     []JournalStep `json:"steps" yaml:"steps"`
+}
+
+// JournalStore for loading and saving the runs of the journal
+type JournalStore interface {
+	LoadRuns() ([]*JournalRun, error)
+	SaveRuns(runs []*JournalRun) error
+}
+
+// JournalStepper for recording the steps of the current run and checking the steps of the run being resumed
+type JournalStepper interface {
+	IsStepCompleted(stackName string, action string, hash string) bool
+	CompleteStep(step JournalStep) error
+	FinishRun(err error) error
+}
+
+// JournalLister for listing past runs
+type JournalLister interface {
+	ListRuns() ([]*JournalRun, error)
+}
+
+// JournalManager composite of all journal capabilities
+type JournalManager interface {
+	JournalStepper
+	JournalLister
+}
+
+// List of journal actions and run statuses
+const (
+	JournalActionUpsert    = "upsert"
+	JournalActionDelete    = "delete"
+	JournalRunRunning      = "RUNNING"
+	JournalRunSucceeded    = "SUCCEEDED"
+	JournalRunFailed       = "FAILED"
+	JournalMaxRuns         = 100
+	JournalDefaultFileName = "journal.json"
+)
+
+// runs are identified by their start time
+const journalRunIDFormat = "20060102-150405"
+
+type journalManager struct {
+	store     JournalStore
+	namespace string
+	command   string
+	resume    bool
+	run       *JournalRun
+	saved     bool
+	resumed   *JournalRun
+	looked    bool
+	mutex     sync.Mutex
+}
+
+// NewJournalManager creates a journal that records the steps of a run of the command.  When resume is set, steps
+// completed by the last unfinished run of the same command are reported as completed
+func NewJournalManager(store JournalStore, namespace string, command string, resume bool) JournalManager {
+	return &journalManager{
+		store:     store,
+		namespace: namespace,
+		command:   command,
+		resume:    resume,
+	}
+}
+
+// IsStepCompleted returns true if the run being resumed completed the step with the same inputs
+func (journal *journalManager) IsStepCompleted(stackName string, action string, hash string) bool {
+	journal.mutex.Lock()
+	defer journal.mutex.Unlock()
+
+	if !journal.resume {
+		return false
+	}
+	if !journal.looked {
+		journal.looked = true
+		journal.resumed = journal.findResumableRun()
+	}
+	if journal.resumed == nil {
+		return false
+	}
+	for _, step := range journal.resumed.Steps {
+		if step.StackName == stackName && step.Action == action && step.Hash == hash {
+			log.Noticef("Skipping stack '%s', it was completed by run '%s' that is being resumed", stackName, journal.resumed.ID)
+			return true
+		}
+	}
+	return false
+}
+
+// findResumableRun returns the last run of the command that didn't succeed
+func (journal *journalManager) findResumableRun() *JournalRun {
+	runs, err := journal.store.LoadRuns()
+	if err != nil {
+		log.Warningf("Unable to load journal, nothing will be resumed: %v", err)
+		return nil
+	}
+	for i := len(runs) - 1; i >= 0; i-- {
+		run := runs[i]
+		if run.Namespace != journal.namespace || run.Command != journal.command {
+			continue
+		}
+		if run.Status == JournalRunSucceeded {
+			break
+		}
+		log.Noticef("Resuming run '%s' of '%s'", run.ID, run.Command)
+		return run
+	}
+	log.Warningf("No unfinished run of '%s' to resume, running all steps", journal.command)
+	return nil
+}
+
+// CompleteStep records the step in the current run, the run is saved to the journal with its first step
+func (journal *journalManager) CompleteStep(step JournalStep) error {
+	journal.mutex.Lock()
+	defer journal.mutex.Unlock()
+
+	if journal.run == nil {
+		now := time.Now()
+		journal.run = &JournalRun{
+			ID:        now.UTC().Format(journalRunIDFormat),
+			Command:   journal.command,
+			Namespace: journal.namespace,
+			Status:    JournalRunRunning,
+			StartTime: now,
+			Steps:     make([]JournalStep, 0),
+		}
+		if journal.resumed != nil {
+			journal.run.ResumeOf = journal.resumed.ID
+		}
+	}
+	if step.Time.IsZero() {
+		step.Time = time.Now()
+	}
+	journal.run.Steps = append(journal.run.Steps, step)
+	return journal.saveRun()
+}
+
+// FinishRun records the outcome of the current run, runs that didn't complete any steps aren't recorded
+func (journal *journalManager) FinishRun(err error) error {
+	journal.mutex.Lock()
+	defer journal.mutex.Unlock()
+
+	if journal.run == nil {
+		return nil
+	}
+	journal.run.EndTime = time.Now()
+	if err != nil {
+		journal.run.Status = JournalRunFailed
+	} else {
+		journal.run.Status = JournalRunSucceeded
+	}
+	return journal.saveRun()
+}
+
+// saveRun adds or replaces the current run in the journal, dropping the oldest runs beyond JournalMaxRuns
+func (journal *journalManager) saveRun() error {
+	runs, err := journal.store.LoadRuns()
+	if err != nil {
+		return err
+	}
+
+	if !journal.saved {
+		// runs that start within the same second get a suffix to keep their ids unique
+		baseID := journal.run.ID
+		for suffix := 2; findJournalRun(runs, journal.run.ID) >= 0; suffix++ {
+			journal.run.ID = fmt.Sprintf("%s-%d", baseID, suffix)
+		}
+		runs = append(runs, journal.run)
+		journal.saved = true
+	} else if i := findJournalRun(runs, journal.run.ID); i >= 0 {
+		runs[i] = journal.run
+	} else {
+		runs = append(runs, journal.run)
+	}
+	if len(runs) > JournalMaxRuns {
+		runs = runs[len(runs)-JournalMaxRuns:]
+	}
+	return journal.store.SaveRuns(runs)
+}
+
+func findJournalRun(runs []*JournalRun, runID string) int {
+	for i, run := range runs {
+		if run.ID == runID {
+			return i
+		}
+	}
+	return -1
+}
+
+// ListRuns returns the runs in the journal, oldest first
+func (journal *journalManager) ListRuns() ([]*JournalRun, error) {
+	return journal.store.LoadRuns()
+}
+
+// NewJournalStore creates a store for the journal at the location, either an s3:// URL or a file path.  The journal
+// defaults to ~/.mu/journal.json
+func NewJournalStore(location string, artifactManager ArtifactManager) (JournalStore, error) {
+	if location == "" {
+		userdir, err := homedir.Dir()
+		if err != nil {
+			return nil, err
+		}
+		return &fileJournalStore{path: filepath.Join(userdir, ".mu", JournalDefaultFileName)}, nil
+	}
+
+	u, err := url.Parse(location)
+	if err != nil {
+		return nil, err
+	}
+	switch u.Scheme {
+	case "s3":
+		return &artifactJournalStore{artifactManager: artifactManager, uri: location}, nil
+	case "", "file":
+		return &fileJournalStore{path: u.Path}, nil
+	default:
+		return nil, fmt.Errorf("Unsupported journal location '%s', must be a file path or an s3:// URL", location)
+	}
+}
+
+// fileJournalStore keeps the journal in a local JSON file
+type fileJournalStore struct {
+	path string
+}
+
+func (store *fileJournalStore) LoadRuns() ([]*JournalRun, error) {
+	data, err := ioutil.ReadFile(store.path)
+	if os.IsNotExist(err) {
+		return make([]*JournalRun, 0), nil
+	} else if err != nil {
+		return nil, err
+	}
+	return unmarshalJournal(data)
+}
+
+func (store *fileJournalStore) SaveRuns(runs []*JournalRun) error {
+	if err := os.MkdirAll(filepath.Dir(store.path), 0700); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(runs, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	// write to a temp file first so an interrupted save never corrupts the journal
+	tmpPath := store.path + ".tmp"
+	if err = ioutil.WriteFile(tmpPath, data, 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmpPath, store.path)
+}
+
+// artifactJournalStore keeps the journal in S3, so it can be shared by pipelines and team members
+type artifactJournalStore struct {
+	artifactManager ArtifactManager
+	uri             string
+}
+
+func (store *artifactJournalStore) LoadRuns() ([]*JournalRun, error) {
+	body, _, err := store.artifactManager.GetArtifact(store.uri, "")
+	if err != nil {
+		if os.IsNotExist(err) || strings.Contains(err.Error(), "NoSuchKey") {
+			return make([]*JournalRun, 0), nil
+		}
+		return nil, err
+	}
+	defer body.Close()
+
+	data, err := ioutil.ReadAll(body)
+	if err != nil {
+		return nil, err
+	}
+	return unmarshalJournal(data)
+}
+
+func (store *artifactJournalStore) SaveRuns(runs []*JournalRun) error {
+	data, err := json.MarshalIndent(runs, "", "  ")
+	if err != nil {
+		return err
+	}
+	return store.artifactManager.CreateArtifact(bytes.NewReader(data), store.uri, "")
+}
+
+func unmarshalJournal(data []byte) ([]*JournalRun, error) {
+	runs := make([]*JournalRun, 0)
+	if err := json.Unmarshal(data, &runs); err != nil {
+		return nil, err
+	}
+	return runs, nil
+}
+
+// journalStackManager records the stacks that are upserted or deleted in the journal once they complete, and skips
+// the stacks that the run being resumed already completed with the same inputs
+type journalStackManager struct {
+	StackManager
+	journal  JournalStepper
+	planMode bool
+	pending  map[string]JournalStep
+	mutex    sync.Mutex
+}
+
+// NewJournalStackManager decorates the stack manager to record its stacks in the journal
+func NewJournalStackManager(stackManager StackManager, journal JournalStepper) StackManager {
+	return &journalStackManager{
+		StackManager: stackManager,
+		journal:      journal,
+		pending:      make(map[string]JournalStep),
+	}
+}
+
+// PlanChanges disables the journal while previewing changes, since nothing is applied
+func (stackMgr *journalStackManager) PlanChanges(enabled bool) {
+	stackMgr.planMode = enabled
+	stackMgr.StackManager.PlanChanges(enabled)
+}
+
+// UpsertStack skips the upsert if the run being resumed completed the stack with the same template and parameters
+func (stackMgr *journalStackManager) UpsertStack(ctx context.Context, stackName string, templateName string, templateData interface{}, parameters map[string]string, tags map[string]string, policy string, roleArn string) error {
+	if stackMgr.planMode {
+		return stackMgr.StackManager.UpsertStack(ctx, stackName, templateName, templateData, parameters, tags, policy, roleArn)
+	}
+
+	hash, err := hashStackInputs(templateName, templateData, parameters, tags, policy, roleArn)
+	if err != nil {
+		log.Warningf("Unable to hash the inputs of stack '%s', it won't be skipped when the run is resumed: %v", stackName, err)
+	}
+	step := JournalStep{
+		StackName: stackName,
+		Action:    JournalActionUpsert,
+		Hash:      hash,
+	}
+	if step.Hash != "" && stackMgr.journal.IsStepCompleted(step.StackName, step.Action, step.Hash) {
+		step.Skipped = true
+		stackMgr.setPending(step)
+		return nil
+	}
+
+	err = stackMgr.StackManager.UpsertStack(ctx, stackName, templateName, templateData, parameters, tags, policy, roleArn)
+	if err == nil {
+		stackMgr.setPending(step)
+	}
+	return err
+}
+
+// DeleteStack skips the delete if the run being resumed already deleted the stack
+func (stackMgr *journalStackManager) DeleteStack(stackName string) error {
+	if stackMgr.planMode {
+		return stackMgr.StackManager.DeleteStack(stackName)
+	}
+
+	step := JournalStep{
+		StackName: stackName,
+		Action:    JournalActionDelete,
+	}
+	if stackMgr.journal.IsStepCompleted(step.StackName, step.Action, step.Hash) {
+		step.Skipped = true
+		stackMgr.setPending(step)
+		return nil
+	}
+
+	err := stackMgr.StackManager.DeleteStack(stackName)
+	if err == nil {
+		stackMgr.setPending(step)
+	}
+	return err
+}
+
+// AwaitFinalStatus records the pending upsert or delete of the stack in the journal if it succeeded
+func (stackMgr *journalStackManager) AwaitFinalStatus(ctx context.Context, stackName string) *Stack {
+	stack := stackMgr.StackManager.AwaitFinalStatus(ctx, stackName)
+
+	stackMgr.mutex.Lock()
+	step, ok := stackMgr.pending[stackName]
+	delete(stackMgr.pending, stackName)
+	stackMgr.mutex.Unlock()
+	if !ok {
+		return stack
+	}
+
+	if stack != nil {
+		step.Status = stack.Status
+	} else {
+		step.Status = StackStatusDeleteComplete
+	}
+	if isJournalStepSucceeded(step, stack) {
+		if err := stackMgr.journal.CompleteStep(step); err != nil {
+			log.Warningf("Unable to record stack '%s' in the journal: %v", stackName, err)
+		}
+	}
+	return stack
+}
+
+func (stackMgr *journalStackManager) setPending(step JournalStep) {
+	stackMgr.mutex.Lock()
+	defer stackMgr.mutex.Unlock()
+	stackMgr.pending[step.StackName] = step
+}
+
+func isJournalStepSucceeded(step JournalStep, stack *Stack) bool {
+	if step.Action == JournalActionDelete {
+		return stack == nil || stack.Status == StackStatusDeleteComplete
+	}
+	return stack != nil && strings.HasSuffix(stack.Status, "_COMPLETE") && !strings.Contains(stack.Status, "ROLLBACK") && stack.Status != StackStatusDeleteComplete
+}
+
+// hashStackInputs hashes everything that goes into an upsert, so a resumed step is only skipped if nothing changed
+func hashStackInputs(templateName string, templateData interface{}, parameters map[string]string, tags map[string]string, policy string, roleArn string) (string, error) {
+	inputs := map[string]interface{}{
+		"templateName": templateName,
+		"parameters":   parameters,
+		"tags":         tags,
+		"policy":       policy,
+		"roleArn":      roleArn,
+	}
+	data, err := json.Marshal(inputs)
+	if err != nil {
+		return "", err
+	}
+
+	templateJSON, err := marshalStableJSON(templateData)
+	if err != nil {
+		return "", err
+	}
+
+	hash := sha256.New()
+	hash.Write(data)
+	hash.Write(templateJSON)
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// marshalStableJSON encodes the value as json, with the keys of its maps sorted. Template data from yaml can contain maps
+// with interface keys that json can't encode, those are round tripped through yaml into maps with string keys
+func marshalStableJSON(v interface{}) ([]byte, error) {
+	data, err := json.Marshal(v)
+	if err == nil {
+		return data, nil
+	}
+
+	yamlData, err := yaml.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var generic interface{}
+	if err := yaml.Unmarshal(yamlData, &generic); err != nil {
+		return nil, err
+	}
+	return json.Marshal(ConvertMapI2MapS(generic))
+}
@@ -0,0 +1,193 @@
+package common
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type mockedJournalStackManager struct {
+	mock.Mock
+	StackManager
+	statuses map[string]string
+}
+
+func (m *mockedJournalStackManager) UpsertStack(ctx context.Context, stackName string, templateName string, templateData interface{}, parameters map[string]string, tags map[string]string, policy string, roleArn string) error {
+	m.Called(stackName)
+	return nil
+}
+func (m *mockedJournalStackManager) DeleteStack(stackName string) error {
+	m.Called(stackName)
+	return nil
+}
+func (m *mockedJournalStackManager) PlanChanges(enabled bool) {
+	m.Called(enabled)
+}
+func (m *mockedJournalStackManager) AwaitFinalStatus(ctx context.Context, stackName string) *Stack {
+	if m.statuses[stackName] == "" {
+		return nil
+	}
+	return &Stack{Name: stackName, Status: m.statuses[stackName]}
+}
+
+func TestJournalStackManager_Resume(t *testing.T) {
+	assert := assert.New(t)
+
+	dir, err := ioutil.TempDir("", "mu-journal")
+	assert.Nil(err)
+	defer os.RemoveAll(dir)
+	store, err := NewJournalStore(filepath.Join(dir, "journal.json"), nil)
+	assert.Nil(err)
+
+	// the first run completes the vpc and fails the environment
+	stackManager := &mockedJournalStackManager{statuses: map[string]string{
+		"mu-vpc-dev":         StackStatusCreateComplete,
+		"mu-environment-dev": StackStatusRollbackComplete,
+	}}
+	stackManager.On("UpsertStack", mock.Anything)
+	journal := NewJournalManager(store, "mu", "env up dev", false)
+	journalStackManager := NewJournalStackManager(stackManager, journal)
+
+	assert.Nil(journalStackManager.UpsertStack(context.Background(), "mu-vpc-dev", "vpc.yml", nil, map[string]string{"a": "1"}, nil, "", ""))
+	journalStackManager.AwaitFinalStatus(context.Background(), "mu-vpc-dev")
+	assert.Nil(journalStackManager.UpsertStack(context.Background(), "mu-environment-dev", "env.yml", nil, nil, nil, "", ""))
+	journalStackManager.AwaitFinalStatus(context.Background(), "mu-environment-dev")
+	assert.Nil(journal.FinishRun(errors.New("failed")))
+
+	runs, err := journal.ListRuns()
+	assert.Nil(err)
+	assert.Equal(1, len(runs))
+	assert.Equal(JournalRunFailed, runs[0].Status)
+	assert.Equal(1, len(runs[0].Steps))
+	assert.Equal("mu-vpc-dev", runs[0].Steps[0].StackName)
+	assert.Equal(StackStatusCreateComplete, runs[0].Steps[0].Status)
+
+	// resuming skips the vpc, but not if its parameters changed
+	stackManager = &mockedJournalStackManager{statuses: map[string]string{
+		"mu-vpc-dev":         StackStatusCreateComplete,
+		"mu-environment-dev": StackStatusCreateComplete,
+	}}
+	stackManager.On("UpsertStack", mock.Anything)
+	journal = NewJournalManager(store, "mu", "env up dev", true)
+	journalStackManager = NewJournalStackManager(stackManager, journal)
+
+	assert.Nil(journalStackManager.UpsertStack(context.Background(), "mu-vpc-dev", "vpc.yml", nil, map[string]string{"a": "2"}, nil, "", ""))
+	stackManager.AssertNumberOfCalls(t, "UpsertStack", 1)
+	assert.Nil(journalStackManager.UpsertStack(context.Background(), "mu-vpc-dev", "vpc.yml", nil, map[string]string{"a": "1"}, nil, "", ""))
+	journalStackManager.AwaitFinalStatus(context.Background(), "mu-vpc-dev")
+	stackManager.AssertNumberOfCalls(t, "UpsertStack", 1)
+	assert.Nil(journalStackManager.UpsertStack(context.Background(), "mu-environment-dev", "env.yml", nil, nil, nil, "", ""))
+	journalStackManager.AwaitFinalStatus(context.Background(), "mu-environment-dev")
+	stackManager.AssertNumberOfCalls(t, "UpsertStack", 2)
+	assert.Nil(journal.FinishRun(nil))
+
+	runs, err = journal.ListRuns()
+	assert.Nil(err)
+	assert.Equal(2, len(runs))
+	assert.Equal(JournalRunSucceeded, runs[1].Status)
+	assert.Equal(runs[0].ID, runs[1].ResumeOf)
+	assert.Equal(2, len(runs[1].Steps))
+	assert.True(runs[1].Steps[0].Skipped)
+	assert.False(runs[1].Steps[1].Skipped)
+
+	// nothing is left to resume once the command succeeded
+	journal = NewJournalManager(store, "mu", "env up dev", true)
+	assert.False(journal.IsStepCompleted("mu-vpc-dev", JournalActionUpsert, runs[1].Steps[0].Hash))
+}
+
+func TestJournalStackManager_Plan(t *testing.T) {
+	assert := assert.New(t)
+
+	dir, err := ioutil.TempDir("", "mu-journal")
+	assert.Nil(err)
+	defer os.RemoveAll(dir)
+	store, err := NewJournalStore(filepath.Join(dir, "journal.json"), nil)
+	assert.Nil(err)
+
+	stackManager := &mockedJournalStackManager{statuses: map[string]string{"mu-vpc-dev": StackStatusCreateComplete}}
+	stackManager.On("UpsertStack", mock.Anything)
+	stackManager.On("PlanChanges", true)
+	journal := NewJournalManager(store, "mu", "env up dev --plan", false)
+	journalStackManager := NewJournalStackManager(stackManager, journal)
+	journalStackManager.PlanChanges(true)
+
+	assert.Nil(journalStackManager.UpsertStack(context.Background(), "mu-vpc-dev", "vpc.yml", nil, nil, nil, "", ""))
+	journalStackManager.AwaitFinalStatus(context.Background(), "mu-vpc-dev")
+	assert.Nil(journal.FinishRun(nil))
+
+	runs, err := journal.ListRuns()
+	assert.Nil(err)
+	assert.Empty(runs)
+}
+
+func TestJournalStackManager_Delete(t *testing.T) {
+	assert := assert.New(t)
+
+	dir, err := ioutil.TempDir("", "mu-journal")
+	assert.Nil(err)
+	defer os.RemoveAll(dir)
+	store, err := NewJournalStore(filepath.Join(dir, "journal.json"), nil)
+	assert.Nil(err)
+
+	stackManager := &mockedJournalStackManager{statuses: map[string]string{}}
+	stackManager.On("DeleteStack", mock.Anything)
+	journal := NewJournalManager(store, "mu", "purge", false)
+	journalStackManager := NewJournalStackManager(stackManager, journal)
+	assert.Nil(journalStackManager.DeleteStack("mu-service-api-dev"))
+	journalStackManager.AwaitFinalStatus(context.Background(), "mu-service-api-dev")
+	assert.Nil(journal.FinishRun(errors.New("interrupted")))
+
+	journal = NewJournalManager(store, "mu", "purge", true)
+	journalStackManager = NewJournalStackManager(stackManager, journal)
+	assert.Nil(journalStackManager.DeleteStack("mu-service-api-dev"))
+	stackManager.AssertNumberOfCalls(t, "DeleteStack", 1)
+}
+
+func TestHashStackInputs(t *testing.T) {
+	assert := assert.New(t)
+
+	// template data with pointers and maps from yaml, that json can't encode directly
+	newTemplateData := func(image string) interface{} {
+		return &Service{
+			Name: "api",
+			EnvironmentConfig: EnvironmentOverrides{
+				"dev": map[interface{}]interface{}{
+					"b": map[interface{}]interface{}{"image": image},
+					"a": []interface{}{1, "two"},
+				},
+			},
+		}
+	}
+
+	hash1, err := hashStackInputs("service.yml", newTemplateData("nginx"), map[string]string{"a": "1"}, nil, "", "")
+	assert.Nil(err)
+	hash2, err := hashStackInputs("service.yml", newTemplateData("nginx"), map[string]string{"a": "1"}, nil, "", "")
+	assert.Nil(err)
+	assert.NotEmpty(hash1)
+	assert.Equal(hash1, hash2)
+
+	hash3, err := hashStackInputs("service.yml", newTemplateData("httpd"), map[string]string{"a": "1"}, nil, "", "")
+	assert.Nil(err)
+	assert.NotEqual(hash1, hash3)
+}
+
+func TestNewJournalStore(t *testing.T) {
+	assert := assert.New(t)
+
+	store, err := NewJournalStore("s3://bucket/journal.json", nil)
+	assert.Nil(err)
+	assert.IsType(&artifactJournalStore{}, store)
+
+	store, err = NewJournalStore("/tmp/journal.json", nil)
+	assert.Nil(err)
+	assert.Equal("/tmp/journal.json", store.(*fileJournalStore).path)
+
+	_, err = NewJournalStore("ftp://host/journal.json", nil)
+	assert.NotNil(err)
+}
@@ -1,10 +1,43 @@
 package common
 
-import "k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+import (
+	"bufio"
+	"fmt"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v2"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
 
 // KubernetesResourceManagerProvider for providing kubernetes client
 type KubernetesResourceManagerProvider interface {
 	GetResourceManager(name string) (KubernetesResourceManager, error)
+	KubernetesClusterDescriber
+	KubernetesTokenGenerator
+}
+
+// KubernetesCluster is the connection info of a kubernetes cluster
+type KubernetesCluster struct {
+	Name                     string
+	Endpoint                 string
+	CertificateAuthorityData string // base64 encoded, like in a kubeconfig
+}
+
+// KubernetesToken is a bearer token to authenticate to a kubernetes cluster
+type KubernetesToken struct {
+	Token      string
+	Expiration time.Time
+}
+
+// KubernetesClusterDescriber for getting the connection info of a kubernetes cluster
+type KubernetesClusterDescriber interface {
+	DescribeKubernetesCluster(name string) (*KubernetesCluster, error)
+}
+
+// KubernetesTokenGenerator for getting a token to authenticate to a kubernetes cluster
+type KubernetesTokenGenerator interface {
+	GetKubernetesToken(name string) (*KubernetesToken, error)
 }
 
 // KubernetesResourceManager for managing kubernetes resources
@@ -28,3 +61,48 @@ type KubernetesResourceLister interface {
 type KubernetesResourceDeleter interface {
 	DeleteResource(apiVersion string, kind string, namespace string, name string) error
 }
+
+// ParseKubernetesResources splits a rendered template into its resources, skipping empty documents
+func ParseKubernetesResources(templateBody string) ([]map[string]interface{}, error) {
+	resources := make([]map[string]interface{}, 0)
+	appendResource := func(resourceBody string) error {
+		resource := make(map[interface{}]interface{})
+		err := yaml.Unmarshal([]byte(resourceBody), resource)
+		if err != nil {
+			return err
+		}
+		if len(resource) > 0 {
+			resources = append(resources, ConvertMapI2MapS(resource).(map[string]interface{}))
+		}
+		return nil
+	}
+
+	scanner := bufio.NewScanner(strings.NewReader(templateBody))
+	var b strings.Builder
+	for scanner.Scan() {
+		if scanner.Text() == "---" {
+			// flush current resource
+			if b.Len() > 0 {
+				err := appendResource(b.String())
+				if err != nil {
+					return nil, err
+				}
+				b.Reset()
+			}
+		} else {
+			// append to current resource
+			_, err := fmt.Fprintln(&b, scanner.Text())
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if b.Len() > 0 {
+		err := appendResource(b.String())
+		if err != nil {
+			return nil, err
+		}
+	}
+	return resources, nil
+}
@@ -0,0 +1,34 @@
+package common
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseKubernetesResources(t *testing.T) {
+	assert := assert.New(t)
+
+	resources, err := ParseKubernetesResources(`apiVersion: v1
+kind: Namespace
+metadata:
+  name: mu-service-api
+---
+
+---
+kind: Service
+apiVersion: v1
+metadata:
+  namespace: mu-service-api
+  name: api
+spec:
+  ports:
+  - port: 8080
+`)
+
+	assert.Nil(err)
+	assert.Equal(2, len(resources))
+	assert.Equal("Namespace", resources[0]["kind"])
+	assert.Equal("mu-service-api", MapGetString(resources[1], "metadata", "namespace"))
+	assert.Equal(int64(8080), MapGet(resources[1], "spec", "ports", 0, "port"))
+}
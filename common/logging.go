@@ -1,12 +1,15 @@
 package common
 
 import (
-	"github.com/op/go-logging"
+	"io"
 	"os"
+
+	"github.com/op/go-logging"
 )
 
-// SetupLogging - verbosity 0=error, 1=info, 2=debug
-func SetupLogging(verbosity int) {
+// SetupLogging - verbosity 0=error, 1=info, 2=debug.  Info and debug messages are written to out, warnings and errors
+// always go to stderr
+func SetupLogging(verbosity int, out io.Writer) {
 	errBackend := logging.NewLogBackend(os.Stderr, "", 0)
 	errFormat := logging.MustStringFormatter(
 		`%{color}%{shortfunc} ▶ %{level:.5s} %{color:reset} %{message}`,
@@ -23,7 +26,7 @@ func SetupLogging(verbosity int) {
 	warnLeveled.SetLevel(logging.WARNING, "")
 
 	if verbosity >= 1 {
-		infoBackend := logging.NewLogBackend(os.Stdout, "", 0)
+		infoBackend := logging.NewLogBackend(out, "", 0)
 		infoFormat := logging.MustStringFormatter(
 			`%{color}%{message}%{color:reset}`,
 		)
@@ -32,7 +35,7 @@ func SetupLogging(verbosity int) {
 		infoLeveled.SetLevel(logging.INFO, "")
 
 		if verbosity >= 2 {
-			debugBackend := logging.NewLogBackend(os.Stdout, "", 0)
+			debugBackend := logging.NewLogBackend(out, "", 0)
 			debugFormat := logging.MustStringFormatter(
 				`%{color}%{time:15:04:05.000} %{module} ▶ %{id:03x}%{color:reset} %{message}`,
 			)
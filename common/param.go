@@ -15,10 +15,16 @@ type ParamGetter interface {
 	GetParam(name string) (string, error)
 }
 
+// SecretGetter for getting the value of secrets in Secrets Manager
+type SecretGetter interface {
+	GetSecret(secretID string, versionStage string, versionID string) (string, error)
+}
+
 // ParamManager composite of all param capabilities
 type ParamManager interface {
 	ParamGetter
 	ParamSetter
 	ParamDeleter
+	SecretGetter
 	ParamVersion(name string) (int64, error)
 }
@@ -0,0 +1,307 @@
+package common
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// strategicMergeKeys are the keys used to merge the elements of the lists of a pod spec by the name of the list, the
+// first key that all elements have is used.  Lists that aren't listed here are merged by 'name', like the containers,
+// env and volumes.
+var strategicMergeKeys = map[string][]string{
+	"ports":         {"containerPort", "port"},
+	"volumeMounts":  {"mountPath"},
+	"volumeDevices": {"devicePath"},
+}
+
+// strategicMergePatch recursively merges the patch into the resource, similar to a kubernetes strategic merge patch.
+//
+// Maps are merged, a null value removes the key and lists of maps are merged on the merge key of the list, when all
+// elements have it.  All other values, including the remaining lists, are replaced by the value from the patch.
+func strategicMergePatch(resource map[string]interface{}, patch map[string]interface{}) map[string]interface{} {
+	for key, patchVal := range patch {
+		if patchVal == nil {
+			delete(resource, key)
+			continue
+		}
+		resource[key] = strategicMergeValue(key, resource[key], patchVal)
+	}
+	return resource
+}
+
+func strategicMergeValue(key string, resourceVal interface{}, patchVal interface{}) interface{} {
+	switch patchNode := patchVal.(type) {
+	case map[string]interface{}:
+		if resourceNode, ok := resourceVal.(map[string]interface{}); ok {
+			return strategicMergePatch(resourceNode, patchNode)
+		}
+	case []interface{}:
+		if resourceNode, ok := resourceVal.([]interface{}); ok {
+			if mergeKey := strategicMergeKey(key, resourceNode, patchNode); mergeKey != "" {
+				return strategicMergeList(mergeKey, resourceNode, patchNode)
+			}
+		}
+	}
+	return copyValue(patchVal)
+}
+
+// strategicMergeKey returns the merge key of the list that all the elements of both lists have, or "" if they must be replaced
+func strategicMergeKey(key string, resourceList []interface{}, patchList []interface{}) string {
+	mergeKeys, ok := strategicMergeKeys[key]
+	if !ok {
+		mergeKeys = []string{"name"}
+	}
+	for _, mergeKey := range mergeKeys {
+		if hasStrategicMergeKey(mergeKey, resourceList) && hasStrategicMergeKey(mergeKey, patchList) {
+			return mergeKey
+		}
+	}
+	return ""
+}
+
+func hasStrategicMergeKey(mergeKey string, list []interface{}) bool {
+	for _, element := range list {
+		if MapGet(element, mergeKey) == nil {
+			return false
+		}
+	}
+	return len(list) > 0
+}
+
+func strategicMergeList(mergeKey string, resourceList []interface{}, patchList []interface{}) []interface{} {
+	for _, patchElement := range patchList {
+		// compared as strings, the ports may be decoded as different int types
+		mergeValue := fmt.Sprint(MapGet(patchElement, mergeKey))
+		merged := false
+		for i, resourceElement := range resourceList {
+			if fmt.Sprint(MapGet(resourceElement, mergeKey)) == mergeValue {
+				resourceList[i] = strategicMergeValue("", resourceElement, patchElement)
+				merged = true
+			}
+		}
+		if !merged {
+			resourceList = append(resourceList, copyValue(patchElement))
+		}
+	}
+	return resourceList
+}
+
+// applyJSONPatch applies the RFC 6902 operations in order to the document and returns the patched document
+func applyJSONPatch(doc interface{}, operations []JSONPatchOperation) (interface{}, error) {
+	for _, operation := range operations {
+		var err error
+		doc, err = jsonPatchOperation(doc, operation)
+		if err != nil {
+			return nil, fmt.Errorf("unable to %s '%s': %v", operation.Op, operation.Path, err)
+		}
+	}
+	return doc, nil
+}
+
+func jsonPatchOperation(doc interface{}, operation JSONPatchOperation) (interface{}, error) {
+	path, err := jsonPointerTokens(operation.Path)
+	if err != nil {
+		return nil, err
+	}
+
+	switch operation.Op {
+	case "add":
+		return jsonPointerSet(doc, path, copyValue(operation.Value), false)
+	case "replace":
+		return jsonPointerSet(doc, path, copyValue(operation.Value), true)
+	case "remove":
+		return jsonPointerRemove(doc, path)
+	case "test":
+		value, err := jsonPointerGet(doc, path)
+		if err != nil {
+			return nil, err
+		}
+		if !reflect.DeepEqual(value, operation.Value) {
+			return nil, fmt.Errorf("value is '%v', not '%v'", value, operation.Value)
+		}
+		return doc, nil
+	case "move", "copy":
+		from, err := jsonPointerTokens(operation.From)
+		if err != nil {
+			return nil, err
+		}
+		value, err := jsonPointerGet(doc, from)
+		if err != nil {
+			return nil, err
+		}
+		if operation.Op == "move" {
+			doc, err = jsonPointerRemove(doc, from)
+			if err != nil {
+				return nil, err
+			}
+		} else {
+			value = copyValue(value)
+		}
+		return jsonPointerSet(doc, path, value, false)
+	}
+	return nil, fmt.Errorf("unsupported op '%s'", operation.Op)
+}
+
+// jsonPointerTokens splits an RFC 6901 JSON pointer into its unescaped reference tokens
+func jsonPointerTokens(pointer string) ([]string, error) {
+	if pointer == "" {
+		return []string{}, nil
+	}
+	if !strings.HasPrefix(pointer, "/") {
+		return nil, fmt.Errorf("path must start with '/'")
+	}
+	tokens := strings.Split(pointer[1:], "/")
+	for i, token := range tokens {
+		tokens[i] = strings.Replace(strings.Replace(token, "~1", "/", -1), "~0", "~", -1)
+	}
+	return tokens, nil
+}
+
+func jsonPointerIndex(token string, length int) (int, error) {
+	index, err := strconv.Atoi(token)
+	if err != nil || index < 0 || index >= length {
+		return 0, fmt.Errorf("index '%s' out of range", token)
+	}
+	return index, nil
+}
+
+func jsonPointerGet(doc interface{}, path []string) (interface{}, error) {
+	for _, token := range path {
+		switch node := doc.(type) {
+		case map[string]interface{}:
+			value, ok := node[token]
+			if !ok {
+				return nil, fmt.Errorf("key '%s' not found", token)
+			}
+			doc = value
+		case []interface{}:
+			index, err := jsonPointerIndex(token, len(node))
+			if err != nil {
+				return nil, err
+			}
+			doc = node[index]
+		default:
+			return nil, fmt.Errorf("key '%s' not found", token)
+		}
+	}
+	return doc, nil
+}
+
+// jsonPointerSet adds the value at the path, or replaces the existing value if replace is set
+func jsonPointerSet(doc interface{}, path []string, value interface{}, replace bool) (interface{}, error) {
+	if len(path) == 0 {
+		return value, nil
+	}
+
+	token := path[0]
+	switch node := doc.(type) {
+	case map[string]interface{}:
+		child, ok := node[token]
+		if len(path) == 1 {
+			if replace && !ok {
+				return nil, fmt.Errorf("key '%s' not found", token)
+			}
+			node[token] = value
+			return node, nil
+		}
+		if !ok {
+			return nil, fmt.Errorf("key '%s' not found", token)
+		}
+		child, err := jsonPointerSet(child, path[1:], value, replace)
+		if err != nil {
+			return nil, err
+		}
+		node[token] = child
+		return node, nil
+	case []interface{}:
+		if len(path) == 1 && !replace {
+			index := len(node)
+			if token != "-" {
+				var err error
+				if index, err = jsonPointerIndex(token, len(node)+1); err != nil {
+					return nil, err
+				}
+			}
+			node = append(node, nil)
+			copy(node[index+1:], node[index:])
+			node[index] = value
+			return node, nil
+		}
+		index, err := jsonPointerIndex(token, len(node))
+		if err != nil {
+			return nil, err
+		}
+		if len(path) == 1 {
+			node[index] = value
+			return node, nil
+		}
+		child, err := jsonPointerSet(node[index], path[1:], value, replace)
+		if err != nil {
+			return nil, err
+		}
+		node[index] = child
+		return node, nil
+	}
+	return nil, fmt.Errorf("key '%s' not found", token)
+}
+
+func jsonPointerRemove(doc interface{}, path []string) (interface{}, error) {
+	if len(path) == 0 {
+		return nil, fmt.Errorf("unable to remove the whole document")
+	}
+
+	token := path[0]
+	switch node := doc.(type) {
+	case map[string]interface{}:
+		child, ok := node[token]
+		if !ok {
+			return nil, fmt.Errorf("key '%s' not found", token)
+		}
+		if len(path) == 1 {
+			delete(node, token)
+			return node, nil
+		}
+		child, err := jsonPointerRemove(child, path[1:])
+		if err != nil {
+			return nil, err
+		}
+		node[token] = child
+		return node, nil
+	case []interface{}:
+		index, err := jsonPointerIndex(token, len(node))
+		if err != nil {
+			return nil, err
+		}
+		if len(path) == 1 {
+			return append(node[:index], node[index+1:]...), nil
+		}
+		child, err := jsonPointerRemove(node[index], path[1:])
+		if err != nil {
+			return nil, err
+		}
+		node[index] = child
+		return node, nil
+	}
+	return nil, fmt.Errorf("key '%s' not found", token)
+}
+
+// copyValue returns a deep copy of the maps and lists in the value
+func copyValue(v interface{}) interface{} {
+	switch node := v.(type) {
+	case map[string]interface{}:
+		m := make(map[string]interface{}, len(node))
+		for key, value := range node {
+			m[key] = copyValue(value)
+		}
+		return m
+	case []interface{}:
+		s := make([]interface{}, len(node))
+		for i, value := range node {
+			s[i] = copyValue(value)
+		}
+		return s
+	}
+	return v
+}
@@ -0,0 +1,148 @@
+package common
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/yaml.v2"
+)
+
+func loadYamlAsResource(yamlString string) map[string]interface{} {
+	resource := make(map[interface{}]interface{})
+	yaml.Unmarshal([]byte(yamlString), resource)
+	return ConvertMapI2MapS(resource).(map[string]interface{})
+}
+
+const patchTestDeployment = `
+kind: Deployment
+metadata:
+  name: api-deployment
+  namespace: mu-service-api
+  annotations:
+    mu/type: service
+spec:
+  replicas: 2
+  template:
+    spec:
+      containers:
+      - name: api
+        image: api:1
+        env:
+        - name: FOO
+          value: foo
+      - name: envoy
+        image: envoy:1
+`
+
+func TestStrategicMergePatch(t *testing.T) {
+	assert := assert.New(t)
+
+	resource := strategicMergePatch(loadYamlAsResource(patchTestDeployment), loadYamlAsResource(`
+metadata:
+  annotations:
+    iam.amazonaws.com/role: api
+spec:
+  replicas: null
+  template:
+    spec:
+      nodeSelector:
+        disktype: ssd
+      containers:
+      - name: api
+        env:
+        - name: BAR
+          value: bar
+      - name: xray
+        image: xray:1
+`))
+
+	assert.Equal("service", MapGetString(resource, "metadata", "annotations", "mu/type"))
+	assert.Equal("api", MapGetString(resource, "metadata", "annotations", "iam.amazonaws.com/role"))
+	assert.Nil(MapGet(resource, "spec", "replicas"))
+	assert.Equal("ssd", MapGetString(resource, "spec", "template", "spec", "nodeSelector", "disktype"))
+
+	containers := MapGetSlice(resource, "spec", "template", "spec", "containers")
+	assert.Equal(3, len(containers))
+	assert.Equal("api:1", MapGetString(containers[0], "image"))
+	assert.Equal(2, len(MapGetSlice(containers[0], "env")))
+	assert.Equal("BAR", MapGetString(containers[0], "env", 1, "name"))
+	assert.Equal("envoy", MapGetString(containers[1], "name"))
+	assert.Equal("xray:1", MapGetString(containers[2], "image"))
+}
+
+func TestStrategicMergePatch_MergeKeys(t *testing.T) {
+	assert := assert.New(t)
+
+	resource := strategicMergePatch(loadYamlAsResource(`
+spec:
+  containers:
+  - name: api
+    ports:
+    - containerPort: 8080
+    volumeMounts:
+    - name: data
+      mountPath: /data
+`), loadYamlAsResource(`
+spec:
+  containers:
+  - name: api
+    ports:
+    - containerPort: 8080
+      protocol: TCP
+    - containerPort: 9090
+    volumeMounts:
+    - name: data
+      mountPath: /cache
+`))
+
+	ports := MapGetSlice(resource, "spec", "containers", 0, "ports")
+	assert.Equal(2, len(ports))
+	assert.Equal("TCP", MapGetString(ports[0], "protocol"))
+	assert.EqualValues(9090, MapGet(ports[1], "containerPort"))
+
+	// the same volume can be mounted at several paths
+	volumeMounts := MapGetSlice(resource, "spec", "containers", 0, "volumeMounts")
+	assert.Equal(2, len(volumeMounts))
+	assert.Equal("/data", MapGetString(volumeMounts[0], "mountPath"))
+	assert.Equal("/cache", MapGetString(volumeMounts[1], "mountPath"))
+}
+
+func TestApplyJSONPatch(t *testing.T) {
+	assert := assert.New(t)
+
+	patched, err := applyJSONPatch(loadYamlAsResource(patchTestDeployment), []JSONPatchOperation{
+		{Op: "test", Path: "/spec/replicas", Value: int64(2)},
+		{Op: "replace", Path: "/spec/replicas", Value: int64(3)},
+		{Op: "add", Path: "/spec/template/spec/tolerations", Value: []interface{}{}},
+		{Op: "add", Path: "/spec/template/spec/tolerations/-", Value: map[string]interface{}{"key": "dedicated", "effect": "NoSchedule"}},
+		{Op: "add", Path: "/metadata/annotations/example.com~1owner", Value: "team-a"},
+		{Op: "remove", Path: "/spec/template/spec/containers/1"},
+		{Op: "copy", From: "/spec/template/spec/containers/0/image", Path: "/metadata/annotations/image"},
+		{Op: "move", From: "/metadata/annotations/mu~1type", Path: "/metadata/annotations/type"},
+	})
+	assert.Nil(err)
+
+	assert.Equal(int64(3), MapGet(patched, "spec", "replicas"))
+	assert.Equal("dedicated", MapGetString(patched, "spec", "template", "spec", "tolerations", 0, "key"))
+	assert.Equal("team-a", MapGetString(patched, "metadata", "annotations", "example.com/owner"))
+	assert.Equal(1, len(MapGetSlice(patched, "spec", "template", "spec", "containers")))
+	assert.Equal("api:1", MapGetString(patched, "metadata", "annotations", "image"))
+	assert.Equal("service", MapGetString(patched, "metadata", "annotations", "type"))
+	assert.Nil(MapGet(patched, "metadata", "annotations", "mu/type"))
+}
+
+func TestApplyJSONPatch_Errors(t *testing.T) {
+	assert := assert.New(t)
+
+	for _, operation := range []JSONPatchOperation{
+		{Op: "test", Path: "/spec/replicas", Value: int64(5)},
+		{Op: "replace", Path: "/spec/minReadySeconds", Value: int64(5)},
+		{Op: "remove", Path: "/spec/template/spec/containers/2"},
+		{Op: "add", Path: "/spec/strategy/type", Value: "Recreate"},
+		{Op: "add", Path: "spec", Value: "invalid"},
+		{Op: "merge", Path: "/spec"},
+	} {
+		_, err := applyJSONPatch(loadYamlAsResource(patchTestDeployment), []JSONPatchOperation{operation})
+		assert.NotNil(err, operation.Op+" "+operation.Path)
+	}
+}
@@ -1,31 +1,33 @@
 package common
 
+import "context"
+
 // Roleset is a map of Role ARNs keyed by role type
 type Roleset map[string]string
 
 // RolesetUpserter for managing a roleset
 type RolesetUpserter interface {
-	UpsertCommonRoleset() error
-	UpsertEnvironmentRoleset(environmentName string) error
-	UpsertServiceRoleset(environmentName string, serviceName string, codeDeployBucket string, databaseName string) error
-	UpsertPipelineRoleset(serviceName string, pipelineBucket string, codeDeployBucket string) error
+	UpsertCommonRoleset(ctx context.Context) error
+	UpsertEnvironmentRoleset(ctx context.Context, environmentName string) error
+	UpsertServiceRoleset(ctx context.Context, environmentName string, serviceName string, codeDeployBucket string, databaseName string) error
+	UpsertPipelineRoleset(ctx context.Context, serviceName string, pipelineBucket string, codeDeployBucket string) error
 }
 
 // RolesetGetter for getting a roleset
 type RolesetGetter interface {
-	GetCommonRoleset() (Roleset, error)
-	GetEnvironmentRoleset(environmentName string) (Roleset, error)
-	GetEnvironmentProvider(environmentName string) (string, error)
-	GetServiceRoleset(environmentName string, serviceName string) (Roleset, error)
-	GetPipelineRoleset(serviceName string) (Roleset, error)
+	GetCommonRoleset(ctx context.Context) (Roleset, error)
+	GetEnvironmentRoleset(ctx context.Context, environmentName string) (Roleset, error)
+	GetEnvironmentProvider(ctx context.Context, environmentName string) (string, error)
+	GetServiceRoleset(ctx context.Context, environmentName string, serviceName string) (Roleset, error)
+	GetPipelineRoleset(ctx context.Context, serviceName string) (Roleset, error)
 }
 
 // RolesetDeleter for deleting a roleset
 type RolesetDeleter interface {
-	DeleteCommonRoleset() error
-	DeleteEnvironmentRoleset(environmentName string) error
-	DeleteServiceRoleset(environmentName string, serviceName string) error
-	DeletePipelineRoleset(serviceName string) error
+	DeleteCommonRoleset(ctx context.Context) error
+	DeleteEnvironmentRoleset(ctx context.Context, environmentName string) error
+	DeleteServiceRoleset(ctx context.Context, environmentName string, serviceName string) error
+	DeletePipelineRoleset(ctx context.Context, serviceName string) error
 }
 
 // RolesetManager composite of all roleset capabilities
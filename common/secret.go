@@ -0,0 +1,100 @@
+package common
+
+import (
+	"sort"
+	"strings"
+)
+
+// SecretReference is a value of the `secrets` of a service, which is either the name or ARN of an SSM parameter, or
+// the ARN of a Secrets Manager secret with an optional json key, version stage and version id as supported by ECS
+type SecretReference struct {
+	ValueFrom     string
+	ParameterName string
+	SecretID      string
+	JSONKey       string
+	VersionStage  string
+	VersionID     string
+}
+
+// ParseSecretReference parses the value of a secret
+func ParseSecretReference(valueFrom string) *SecretReference {
+	ref := &SecretReference{
+		ValueFrom: valueFrom,
+	}
+
+	parts := strings.Split(valueFrom, ":")
+	switch {
+	case len(parts) >= 7 && parts[0] == "arn" && parts[2] == "secretsmanager":
+		ref.SecretID = strings.Join(parts[0:7], ":")
+		if len(parts) > 7 {
+			ref.JSONKey = parts[7]
+		}
+		if len(parts) > 8 {
+			ref.VersionStage = parts[8]
+		}
+		if len(parts) > 9 {
+			ref.VersionID = parts[9]
+		}
+	case len(parts) >= 6 && parts[0] == "arn" && parts[2] == "ssm":
+		// hierarchical parameter names start with a '/' that isn't part of the ARN
+		name := strings.TrimPrefix(strings.Join(parts[5:], ":"), "parameter/")
+		if strings.Contains(name, "/") {
+			name = "/" + name
+		}
+		ref.ParameterName = name
+	default:
+		ref.ParameterName = valueFrom
+	}
+	return ref
+}
+
+// IsSecretsManager returns true if the secret is in Secrets Manager, rather than the SSM Parameter Store
+func (ref *SecretReference) IsSecretsManager() bool {
+	return ref.SecretID != ""
+}
+
+// ResourceArn returns the ARN of the secret for IAM policies.  Parameter names are converted to an ARN with
+// pseudo parameters for the partition, region and account, so the ARN must be used in a !Sub
+func (ref *SecretReference) ResourceArn() string {
+	if ref.IsSecretsManager() {
+		return ref.SecretID
+	}
+	if strings.HasPrefix(ref.ValueFrom, "arn:") {
+		return ref.ValueFrom
+	}
+	return "arn:${AWS::Partition}:ssm:${AWS::Region}:${AWS::AccountId}:parameter/" + strings.TrimPrefix(ref.ParameterName, "/")
+}
+
+// SecretParameterArns returns the sorted ARNs of the SSM parameters in the secrets of the service, for use in a !Sub
+func (service Service) SecretParameterArns() []string {
+	return service.secretArns(false)
+}
+
+// SecretsManagerArns returns the sorted ARNs of the Secrets Manager secrets in the secrets of the service
+func (service Service) SecretsManagerArns() []string {
+	return service.secretArns(true)
+}
+
+func (service Service) secretArns(secretsManager bool) []string {
+	found := make(map[string]bool)
+	arns := make([]string, 0)
+	for _, valueFrom := range service.Secrets {
+		ref := ParseSecretReference(valueFrom)
+		arn := ref.ResourceArn()
+		if ref.IsSecretsManager() == secretsManager && !found[arn] {
+			found[arn] = true
+			arns = append(arns, arn)
+		}
+	}
+	sort.Strings(arns)
+	return arns
+}
+
+// SecretReferences returns the parsed secrets of the service, keyed by the name of the environment variable
+func (service Service) SecretReferences() map[string]*SecretReference {
+	refs := make(map[string]*SecretReference)
+	for name, valueFrom := range service.Secrets {
+		refs[name] = ParseSecretReference(valueFrom)
+	}
+	return refs
+}
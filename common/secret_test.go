@@ -0,0 +1,61 @@
+package common
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseSecretReference(t *testing.T) {
+	assert := assert.New(t)
+
+	ref := ParseSecretReference("/mu/api/key")
+	assert.False(ref.IsSecretsManager())
+	assert.Equal("/mu/api/key", ref.ParameterName)
+	assert.Equal("arn:${AWS::Partition}:ssm:${AWS::Region}:${AWS::AccountId}:parameter/mu/api/key", ref.ResourceArn())
+
+	ref = ParseSecretReference("api-key")
+	assert.Equal("api-key", ref.ParameterName)
+	assert.Equal("arn:${AWS::Partition}:ssm:${AWS::Region}:${AWS::AccountId}:parameter/api-key", ref.ResourceArn())
+
+	ref = ParseSecretReference("arn:aws:ssm:us-east-1:123456789012:parameter/mu/api/key")
+	assert.False(ref.IsSecretsManager())
+	assert.Equal("/mu/api/key", ref.ParameterName)
+	assert.Equal("arn:aws:ssm:us-east-1:123456789012:parameter/mu/api/key", ref.ResourceArn())
+
+	ref = ParseSecretReference("arn:aws:ssm:us-east-1:123456789012:parameter/api-key")
+	assert.Equal("api-key", ref.ParameterName)
+
+	ref = ParseSecretReference("arn:aws:secretsmanager:us-east-1:123456789012:secret:api-AbCdEf")
+	assert.True(ref.IsSecretsManager())
+	assert.Equal("arn:aws:secretsmanager:us-east-1:123456789012:secret:api-AbCdEf", ref.SecretID)
+	assert.Equal("", ref.JSONKey)
+	assert.Equal("arn:aws:secretsmanager:us-east-1:123456789012:secret:api-AbCdEf", ref.ResourceArn())
+
+	ref = ParseSecretReference("arn:aws:secretsmanager:us-east-1:123456789012:secret:api-AbCdEf:password:AWSPREVIOUS:")
+	assert.True(ref.IsSecretsManager())
+	assert.Equal("arn:aws:secretsmanager:us-east-1:123456789012:secret:api-AbCdEf", ref.SecretID)
+	assert.Equal("password", ref.JSONKey)
+	assert.Equal("AWSPREVIOUS", ref.VersionStage)
+	assert.Equal("", ref.VersionID)
+}
+
+func TestService_SecretArns(t *testing.T) {
+	assert := assert.New(t)
+
+	service := Service{
+		Secrets: map[string]string{
+			"API_KEY":     "/mu/api/key",
+			"DB_USER":     "arn:aws:secretsmanager:us-east-1:123456789012:secret:db-AbCdEf:username::",
+			"DB_PASSWORD": "arn:aws:secretsmanager:us-east-1:123456789012:secret:db-AbCdEf:password::",
+		},
+	}
+
+	assert.Equal([]string{"arn:${AWS::Partition}:ssm:${AWS::Region}:${AWS::AccountId}:parameter/mu/api/key"}, service.SecretParameterArns())
+	assert.Equal([]string{"arn:aws:secretsmanager:us-east-1:123456789012:secret:db-AbCdEf"}, service.SecretsManagerArns())
+	assert.Equal(3, len(service.SecretReferences()))
+	assert.Equal("password", service.SecretReferences()["DB_PASSWORD"].JSONKey)
+
+	assert.Empty(Service{}.SecretParameterArns())
+	assert.Empty(Service{}.SecretsManagerArns())
+}
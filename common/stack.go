@@ -1,8 +1,11 @@
 package common
 
 import (
+	"context"
 	"fmt"
+	"sort"
 	"strings"
+	"time"
 )
 
 // CreateStackName will create a name for a stack
@@ -10,15 +13,15 @@ func CreateStackName(namespace string, stackType StackType, names ...string) str
 	return fmt.Sprintf("%s-%s-%s", namespace, stackType, strings.Join(names, "-"))
 }
 
-// StackWaiter for waiting on stack status to be final
+// StackWaiter for waiting on stack status to be final, or until the context is done
 type StackWaiter interface {
-	AwaitFinalStatus(stackName string) *Stack
+	AwaitFinalStatus(ctx context.Context, stackName string) *Stack
 }
 
 // StackUpserter for applying changes to a stack
 type StackUpserter interface {
-	UpsertStack(stackName string, templateName string, templateData interface{}, parameters map[string]string, tags map[string]string, policy string, roleArn string) error
-	SetTerminationProtection(stackName string, enabled bool) error
+	UpsertStack(ctx context.Context, stackName string, templateName string, templateData interface{}, parameters map[string]string, tags map[string]string, policy string, roleArn string) error
+	SetTerminationProtection(ctx context.Context, stackName string, enabled bool) error
 }
 
 // StackLister for listing stacks
@@ -46,6 +49,22 @@ type AZCounter interface {
 	CountAZs() (int, error)
 }
 
+// StackPlanner for previewing stack changes with change sets instead of applying them
+type StackPlanner interface {
+	PlanChanges(enabled bool)
+}
+
+// StackInterrupter for cancelling the updates of stacks that are still in progress when the wait on them is interrupted
+type StackInterrupter interface {
+	CancelUpdatesOnInterrupt(enabled bool)
+}
+
+// StackDriftDetector for detecting resources of stacks that were changed outside of CloudFormation
+type StackDriftDetector interface {
+	DetectStackDrift(stackName string) (string, error)
+	AwaitStackDrift(ctx context.Context, stackName string, detectionID string) (*StackDrift, error)
+}
+
 // StackManager composite of all stack capabilities
 type StackManager interface {
 	StackUpserter
@@ -53,7 +72,106 @@ type StackManager interface {
 	StackLister
 	StackGetter
 	StackDeleter
+	StackPlanner
+	StackInterrupter
+	StackDriftDetector
 	ImageFinder
 	AZCounter
 	AllowDataLoss(allow bool)
 }
+
+// StackChangeSet summary of the changes an upsert would make to a stack
+type StackChangeSet struct {
+	StackName        string
+	Type             string
+	Status           string
+	StatusReason     string
+	ResourceChanges  []StackResourceChange
+	ParameterChanges []StackValueChange
+	TagChanges       []StackValueChange
+}
+
+// StackResourceChange describes a change to a single resource in a change set
+type StackResourceChange struct {
+	Action       string
+	LogicalID    string
+	ResourceType string
+	Replacement  string
+}
+
+// StackValueChange describes a change to a parameter or tag value
+type StackValueChange struct {
+	Key      string
+	OldValue string
+	NewValue string
+}
+
+// Replaced returns true if the resource will be replaced by the change
+func (change StackResourceChange) Replaced() bool {
+	return change.Action == StackChangeActionModify && change.Replacement == StackChangeReplacementTrue
+}
+
+// List of change set actions and replacement values
+const (
+	StackChangeActionAdd              = "Add"
+	StackChangeActionModify           = "Modify"
+	StackChangeActionRemove           = "Remove"
+	StackChangeReplacementTrue        = "True"
+	StackChangeReplacementConditional = "Conditional"
+)
+
+// StackDrift result of detecting drift of a stack, with the resources that drifted
+type StackDrift struct {
+	StackName     string               `json:"stackName" yaml:"stackName"`
+	StackType     string               `json:"stackType,omitempty" yaml:"stackType,omitempty"`
+	Status        string               `json:"status" yaml:"status"`
+	StatusReason  string               `json:"statusReason,omitempty" yaml:"statusReason,omitempty"`
+	DetectionTime time.Time            `json:"detectionTime" yaml:"detectionTime"`
+	Resources     []StackResourceDrift `json:"resources" yaml:"resources"`
+}
+
+// StackResourceDrift describes a resource that was modified or deleted outside of CloudFormation
+type StackResourceDrift struct {
+	LogicalID    string                    `json:"logicalId" yaml:"logicalId"`
+	PhysicalID   string                    `json:"physicalId" yaml:"physicalId"`
+	ResourceType string                    `json:"resourceType" yaml:"resourceType"`
+	Status       string                    `json:"status" yaml:"status"`
+	Differences  []StackPropertyDifference `json:"differences" yaml:"differences"`
+}
+
+// StackPropertyDifference describes a property of a resource that differs from the template
+type StackPropertyDifference struct {
+	PropertyPath   string `json:"propertyPath" yaml:"propertyPath"`
+	DifferenceType string `json:"differenceType" yaml:"differenceType"`
+	ExpectedValue  string `json:"expectedValue" yaml:"expectedValue"`
+	ActualValue    string `json:"actualValue" yaml:"actualValue"`
+}
+
+// List of drift statuses of stacks and resources
+const (
+	StackDriftStatusDrifted          = "DRIFTED"
+	StackDriftStatusInSync           = "IN_SYNC"
+	StackDriftStatusUnknown          = "UNKNOWN"
+	StackDriftStatusNotChecked       = "NOT_CHECKED"
+	StackResourceDriftStatusModified = "MODIFIED"
+	StackResourceDriftStatusDeleted  = "DELETED"
+)
+
+// DiffStackValues returns the changes between the old and new maps, sorted by key
+func DiffStackValues(oldValues map[string]string, newValues map[string]string) []StackValueChange {
+	changes := make([]StackValueChange, 0)
+	for key, newValue := range newValues {
+		if oldValue, ok := oldValues[key]; !ok || oldValue != newValue {
+			changes = append(changes, StackValueChange{Key: key, OldValue: oldValues[key], NewValue: newValue})
+		}
+	}
+	for key, oldValue := range oldValues {
+		if _, ok := newValues[key]; !ok {
+			changes = append(changes, StackValueChange{Key: key, OldValue: oldValue})
+		}
+	}
+	sort.Slice(changes, func(i, j int) bool {
+		return changes[i].Key < changes[j].Key
+	})
+	return changes
+}
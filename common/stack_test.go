@@ -0,0 +1,29 @@
+package common
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiffStackValues(t *testing.T) {
+	assert := assert.New(t)
+
+	changes := DiffStackValues(
+		map[string]string{"same": "1", "changed": "a", "removed": "x"},
+		map[string]string{"same": "1", "changed": "b", "added": "y"})
+
+	assert.Equal([]StackValueChange{
+		{Key: "added", OldValue: "", NewValue: "y"},
+		{Key: "changed", OldValue: "a", NewValue: "b"},
+		{Key: "removed", OldValue: "x", NewValue: ""},
+	}, changes)
+}
+
+func TestStackResourceChange_Replaced(t *testing.T) {
+	assert := assert.New(t)
+
+	assert.True(StackResourceChange{Action: StackChangeActionModify, Replacement: StackChangeReplacementTrue}.Replaced())
+	assert.False(StackResourceChange{Action: StackChangeActionModify, Replacement: StackChangeReplacementConditional}.Replaced())
+	assert.False(StackResourceChange{Action: StackChangeActionAdd}.Replaced())
+}
@@ -1,6 +1,8 @@
 package common
 
 import (
+	"time"
+
 	"github.com/aws/aws-sdk-go/service/ecs"
 )
 
@@ -22,9 +24,22 @@ type TaskCommandExecutor interface {
 	ExecuteCommand(namespace string, task Task) (ECSRunTaskResult, error)
 }
 
+// ServiceRevision describes an image that was previously deployed for a service
+type ServiceRevision struct {
+	Revision   string
+	ImageURL   string
+	DeployedAt time.Time
+}
+
+// ServiceRevisionLister for listing the revisions a service has been deployed with, most recent first
+type ServiceRevisionLister interface {
+	ListServiceRevisions(namespace string, environment string, serviceName string) ([]ServiceRevision, error)
+}
+
 // TaskManager composite of all task capabilities
 type TaskManager interface {
 	TaskContainerLister
 	TaskStopper
 	TaskCommandExecutor
+	ServiceRevisionLister
 }
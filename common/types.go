@@ -3,7 +3,10 @@ package common
 import (
 	"fmt"
 	"io"
+	"strings"
 	"time"
+
+	"gopkg.in/yaml.v2"
 )
 
 // Context defines the context object passed around
@@ -30,14 +33,18 @@ type Context struct {
 	RolesetManager                    RolesetManager
 	ExtensionsManager                 ExtensionsManager
 	CatalogManager                    CatalogManager
+	DeploymentManager                 DeploymentManager
+	JournalManager                    JournalManager
 }
 
 // Config defines the structure of the yml file for the mu config
 type Config struct {
 	DryRun       bool          `yaml:"-"`
+	Plan         bool          `yaml:"-"`
 	Namespace    string        `yaml:"namespace,omitempty" validate:"validateAlphaNumericDash"`
 	Environments []Environment `yaml:"environments,omitempty"`
 	Service      Service       `yaml:"service,omitempty"`
+	Services     []Service     `yaml:"services,omitempty"`
 	Basedir      string        `yaml:"-"`
 	RelMuFile    string        `yaml:"-"`
 	Repo         struct {
@@ -47,11 +54,12 @@ type Config struct {
 		Branch   string
 		Provider string
 	} `yaml:"-"`
-	Templates  map[string]interface{}       `yaml:"templates,omitempty"`
-	Parameters map[string]map[string]string `yaml:"parameters,omitempty"`
-	Tags       map[string]map[string]string `yaml:"tags,omitempty"`
-	Extensions []Extension                  `yaml:"extensions,omitempty"`
-	DisableIAM bool                         `yaml:"disableIAM,omitempty"`
+	Templates  map[string]interface{}        `yaml:"templates,omitempty"`
+	Parameters map[string]map[string]string  `yaml:"parameters,omitempty"`
+	Tags       map[string]map[string]string  `yaml:"tags,omitempty"`
+	Kubernetes map[string]KubernetesOverride `yaml:"kubernetes,omitempty"`
+	Extensions []Extension                   `yaml:"extensions,omitempty"`
+	DisableIAM bool                          `yaml:"disableIAM,omitempty"`
 	Roles      struct {
 		CloudFormation string `yaml:"cloudFormation,omitempty" validate:"validateRoleARN"`
 	} `yaml:"roles,omitempty"`
@@ -89,8 +97,24 @@ const (
 
 // Extension defines the structure of the yml file for an extension
 type Extension struct {
-	URL   string `yaml:"url,omitempty"`
-	Image string `yaml:"image,omitempty"`
+	URL   string   `yaml:"url,omitempty"`
+	Image string   `yaml:"image,omitempty"`
+	Hooks []string `yaml:"hooks,omitempty"`
+}
+
+// KubernetesOverride defines the patches for the kubernetes resources matching its '<kind>/<name>' key in the mu.yml
+type KubernetesOverride struct {
+	Patch     map[string]interface{}   `yaml:"patch,omitempty"`
+	JSONPatch []JSONPatchOperation     `yaml:"jsonPatch,omitempty"`
+	Resources []map[string]interface{} `yaml:"resources,omitempty"`
+}
+
+// JSONPatchOperation defines a single RFC 6902 operation of a JSON patch
+type JSONPatchOperation struct {
+	Op    string      `yaml:"op" json:"op"`
+	Path  string      `yaml:"path" json:"path"`
+	From  string      `yaml:"from,omitempty" json:"from,omitempty"`
+	Value interface{} `yaml:"value,omitempty" json:"value,omitempty"`
 }
 
 // Environment defines the structure of the yml file for an environment
@@ -109,10 +133,11 @@ type Environment struct {
 
 // Loadbalancer defines the scructure of the yml file for a loadbalancer
 type Loadbalancer struct {
-	HostedZone  string `yaml:"hostedzone,omitempty" validate:"validateURL"`
-	Name        string `yaml:"name,omitempty"  validate:"validateLeadingAlphaNumericDash=32"`
-	Certificate string `yaml:"certificate,omitempty"`
-	Internal    bool   `yaml:"internal,omitempty"`
+	Type        LoadbalancerType `yaml:"type,omitempty"`
+	HostedZone  string           `yaml:"hostedzone,omitempty" validate:"validateURL"`
+	Name        string           `yaml:"name,omitempty"  validate:"validateLeadingAlphaNumericDash=32"`
+	Certificate string           `yaml:"certificate,omitempty"`
+	Internal    bool             `yaml:"internal,omitempty"`
 	AccessLogs  struct {
 		S3BucketName string `yaml:"s3BucketName,omitempty"`
 		S3Prefix     string `yaml:"s3Prefix,omitempty"`
@@ -134,6 +159,30 @@ type Cluster struct {
 	TargetMemoryReservation int             `yaml:"targetMemoryReservation,omitempty" validate:"max=100"`
 	HTTPProxy               string          `yaml:"httpProxy,omitempty"  validate:"validateURL"`
 	ExtraUserData           string          `yaml:"extraUserData,omitempty"`
+	InstanceTypes           []string        `yaml:"instanceTypes,omitempty" validate:"validateInstanceType"`
+	Spot                    ClusterSpot     `yaml:"spot,omitempty"`
+	CapacityProvider        struct {
+		Enabled        bool `yaml:"enabled,omitempty"`
+		TargetCapacity int  `yaml:"targetCapacity,omitempty" validate:"max=100"`
+	} `yaml:"capacityProvider,omitempty"`
+}
+
+// ClusterSpot defines the split between on-demand and spot instances of the cluster
+type ClusterSpot struct {
+	OnDemandBaseCapacity int                    `yaml:"onDemandBaseCapacity,omitempty"`
+	Percentage           int                    `yaml:"percentage,omitempty" validate:"max=100"`
+	AllocationStrategy   SpotAllocationStrategy `yaml:"allocationStrategy,omitempty"`
+}
+
+// HasMixedInstances returns true when the autoscaling group of the cluster needs a mixed instances policy, for additional instance types or spot instances
+func (cluster Cluster) HasMixedInstances() bool {
+	return len(cluster.InstanceTypes) > 0 || cluster.Spot.Percentage > 0
+}
+
+// UsesLaunchTemplate returns true when the instances of the cluster are launched from a launch template instead of a launch configuration,
+// which the mixed instances policy and the managed termination protection of the capacity provider need
+func (cluster Cluster) UsesLaunchTemplate() bool {
+	return cluster.HasMixedInstances() || cluster.CapacityProvider.Enabled
 }
 
 // VpcTarget defines the structure of the yml file for a cluster VPC
@@ -141,6 +190,7 @@ type VpcTarget struct {
 	VpcID             string   `yaml:"vpcId,omitempty" validate:"validateResourceID=vpc"`
 	InstanceSubnetIds []string `yaml:"instanceSubnetIds,omitempty" validate:"validateResourceID=subnet"`
 	ElbSubnetIds      []string `yaml:"elbSubnetIds,omitempty" validate:"validateResourceID=subnet"`
+	EfsSubnetIds      []string `yaml:"efsSubnetIds,omitempty" validate:"validateResourceID=subnet"`
 	Environment       string   `yaml:"environment" validate:"validateLeadingAlphaNumericDash"`
 	Namespace         string   `yaml:"namespace" validate:"validateLeadingAlphaNumericDash"`
 }
@@ -154,7 +204,10 @@ type EnvironmentRoles struct {
 // Service defines the structure of the yml file for a service
 type Service struct {
 	Name                 string                 `yaml:"name,omitempty" validate:"validateLeadingAlphaNumericDash"`
+	Path                 string                 `yaml:"path,omitempty"`
+	WatchPaths           []string               `yaml:"watchPaths,omitempty"`
 	DeploymentStrategy   DeploymentStrategy     `yaml:"deploymentStrategy,omitempty"`
+	DeploymentAlarms     []string               `yaml:"deploymentAlarms,omitempty"`
 	DesiredCount         int                    `yaml:"desiredCount,omitempty"`
 	MinSize              int                    `yaml:"minSize,omitempty"`
 	MaxSize              int                    `yaml:"maxSize,omitempty"`
@@ -162,13 +215,20 @@ type Service struct {
 	ImageRepository      string                 `yaml:"imageRepository,omitempty"`
 	Port                 int                    `yaml:"port,omitempty" validate:"max=65535"`
 	Protocol             ServiceProtocol        `yaml:"protocol,omitempty"`
+	ListenerPort         int                    `yaml:"listenerPort,omitempty" validate:"max=65535"`
 	HealthEndpoint       string                 `yaml:"healthEndpoint,omitempty" validate:"validateURL"`
 	CPU                  int                    `yaml:"cpu,omitempty"`
 	Memory               int                    `yaml:"memory,omitempty"`
 	NetworkMode          NetworkMode            `yaml:"networkMode,omitempty"`
 	AssignPublicIP       bool                   `yaml:"assignPublicIp,omitempty"`
 	Links                []string               `yaml:"links,omitempty"`
+	DependsOn            []ServiceDependency    `yaml:"dependsOn,omitempty"`
+	Sidecars             []Sidecar              `yaml:"sidecars,omitempty"`
+	Volumes              []Volume               `yaml:"volumes,omitempty"`
+	CapacityProviders    []CapacityProviderItem `yaml:"capacityProviders,omitempty"`
 	Environment          map[string]interface{} `yaml:"environment,omitempty"`
+	Secrets              map[string]string      `yaml:"secrets,omitempty"`
+	SecretsKmsKey        string                 `yaml:"secretsKmsKey,omitempty"`
 	PathPatterns         []string               `yaml:"pathPatterns,omitempty"`
 	HostPatterns         []string               `yaml:"hostPatterns,omitempty"`
 	Priority             int                    `yaml:"priority,omitempty" validate:"max=50000"`
@@ -176,7 +236,9 @@ type Service struct {
 	Database             Database               `yaml:"database,omitempty"`
 	Schedule             []Schedule             `yaml:"schedules,omitempty"`
 	TargetCPUUtilization int                    `yaml:"targetCPUUtilization,omitempty" validate:"max=100"`
+	Autoscaling          Autoscaling            `yaml:"autoscaling,omitempty"`
 	DiscoveryTTL         string                 `yaml:"discoveryTTL,omitempty"`
+	EnvironmentConfig    EnvironmentOverrides   `yaml:"environmentConfig,omitempty"`
 	Roles                struct {
 		Ec2Instance            string `yaml:"ec2Instance,omitempty" validate:"validateRoleARN"`
 		CodeDeploy             string `yaml:"codeDeploy,omitempty" validate:"validateRoleARN"`
@@ -187,6 +249,156 @@ type Service struct {
 	} `yaml:"roles,omitempty"`
 }
 
+// EnvironmentOverrides are the service settings to use for each environment
+type EnvironmentOverrides map[string]map[interface{}]interface{}
+
+// GetServiceConfig returns a copy of the service with the environmentConfig for the environment deep merged over it.
+// Maps are merged key by key, all other values in the environmentConfig replace the value of the service.
+func (service *Service) GetServiceConfig(environmentName string) (*Service, error) {
+	serviceBytes, err := yaml.Marshal(service)
+	if err != nil {
+		return nil, err
+	}
+	serviceMap := make(map[interface{}]interface{})
+	err = yaml.Unmarshal(serviceBytes, serviceMap)
+	if err != nil {
+		return nil, err
+	}
+	delete(serviceMap, "environmentConfig")
+	mergeConfigMaps(serviceMap, service.EnvironmentConfig[environmentName])
+
+	mergedBytes, err := yaml.Marshal(serviceMap)
+	if err != nil {
+		return nil, err
+	}
+	mergedService := new(Service)
+	err = yaml.UnmarshalStrict(mergedBytes, mergedService)
+	if err != nil {
+		return nil, fmt.Errorf("Invalid environmentConfig for environment '%s' in service '%s': %v", environmentName, service.Name, err)
+	}
+	return mergedService, nil
+}
+
+// Copy returns a deep copy of the service, including its environmentConfig, that can be changed without changing the
+// maps and lists of the service
+func (service *Service) Copy() (*Service, error) {
+	serviceBytes, err := yaml.Marshal(service)
+	if err != nil {
+		return nil, err
+	}
+	serviceCopy := new(Service)
+	err = yaml.Unmarshal(serviceBytes, serviceCopy)
+	if err != nil {
+		return nil, err
+	}
+	return serviceCopy, nil
+}
+
+func mergeConfigMaps(dest map[interface{}]interface{}, src map[interface{}]interface{}) {
+	for key, srcValue := range src {
+		srcMap, srcIsMap := srcValue.(map[interface{}]interface{})
+		destMap, destIsMap := dest[key].(map[interface{}]interface{})
+		if srcIsMap && destIsMap {
+			mergeConfigMaps(destMap, srcMap)
+		} else {
+			dest[key] = srcValue
+		}
+	}
+}
+
+// SelectServices returns the services named in the comma separated selector, or all services if the selector is empty.
+// When the config has no services list, the single service is selected by its name or the repo name.
+func (config *Config) SelectServices(selector string) ([]Service, error) {
+	services := config.Services
+	if len(services) == 0 {
+		service := config.Service
+		if service.Name == "" {
+			service.Name = config.Repo.Name
+		}
+		services = []Service{service}
+	}
+
+	if strings.TrimSpace(selector) == "" {
+		return services, nil
+	}
+
+	selected := make([]Service, 0)
+	for _, name := range strings.Split(selector, ",") {
+		name = strings.TrimSpace(name)
+		found := false
+		for _, service := range services {
+			if service.Name == name {
+				selected = append(selected, service)
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("Unable to find service named '%s' in configuration", name)
+		}
+	}
+	return selected, nil
+}
+
+// OrderServicesByDependencies groups the services in the order they can be deployed, each group only depends on the groups
+// before it so the services within a group can be deployed in parallel. Dependencies on services that are not in the
+// list are ignored, they are only checked to be healthy when the service is deployed.
+func OrderServicesByDependencies(services []Service) ([][]Service, error) {
+	remaining := make(map[string]bool)
+	for _, service := range services {
+		remaining[service.Name] = true
+	}
+
+	groups := make([][]Service, 0)
+	for len(remaining) > 0 {
+		group := make([]Service, 0)
+		for _, service := range services {
+			if !remaining[service.Name] {
+				continue
+			}
+			ready := true
+			for _, dependency := range service.DependsOn {
+				if dependency.Service != "" && remaining[dependency.Service] {
+					ready = false
+				}
+			}
+			if ready {
+				group = append(group, service)
+			}
+		}
+		if len(group) == 0 {
+			names := make([]string, 0, len(remaining))
+			for _, service := range services {
+				if remaining[service.Name] {
+					names = append(names, service.Name)
+				}
+			}
+			return nil, fmt.Errorf("Services '%s' have circular dependencies", strings.Join(names, "', '"))
+		}
+		for _, service := range group {
+			delete(remaining, service.Name)
+		}
+		groups = append(groups, group)
+	}
+	return groups, nil
+}
+
+// ServiceDependency definition of another service, or the database of another service, that must be healthy
+// before the service is deployed
+type ServiceDependency struct {
+	Service  string `yaml:"service,omitempty" validate:"validateLeadingAlphaNumericDash"`
+	Database string `yaml:"database,omitempty" validate:"validateLeadingAlphaNumericDash"`
+}
+
+// EnvironmentPrefix is the prefix of the environment variables the dependency is injected as, e.g. 'billing-api' to 'BILLING_API'
+func (dependency ServiceDependency) EnvironmentPrefix() string {
+	name := dependency.Service
+	if name == "" {
+		name = dependency.Database
+	}
+	return strings.ToUpper(strings.Replace(name, "-", "_", -1))
+}
+
 // Database definition
 type Database struct {
 	DatabaseConfig    `yaml:",inline"`
@@ -244,6 +456,168 @@ type Schedule struct {
 	Command    []string `yaml:"command,omitempty"`
 }
 
+// Autoscaling definition for the scaling policies and scheduled actions of a service, the default
+// targetCPUUtilization policy is only used when no target tracking policies are defined
+type Autoscaling struct {
+	TargetTracking []TargetTrackingPolicy `yaml:"targetTracking,omitempty"`
+	StepScaling    []StepScalingPolicy    `yaml:"stepScaling,omitempty"`
+	Scheduled      []ScheduledAction      `yaml:"scheduled,omitempty"`
+}
+
+// TargetTrackingPolicy definition to keep a metric of the service at a target value
+type TargetTrackingPolicy struct {
+	Name             string            `yaml:"name,omitempty" validate:"validateLeadingAlphaNumericDash"`
+	Metric           AutoscalingMetric `yaml:"metric,omitempty"`
+	TargetValue      float64           `yaml:"targetValue,omitempty"`
+	QueueName        string            `yaml:"queueName,omitempty"`
+	CustomMetric     CloudWatchMetric  `yaml:"customMetric,omitempty"`
+	ScaleInCooldown  int               `yaml:"scaleInCooldown,omitempty"`
+	ScaleOutCooldown int               `yaml:"scaleOutCooldown,omitempty"`
+	DisableScaleIn   bool              `yaml:"disableScaleIn,omitempty"`
+}
+
+// StepScalingPolicy definition to adjust the capacity of the service in steps when an alarm on a metric fires
+type StepScalingPolicy struct {
+	Name               string           `yaml:"name,omitempty" validate:"validateLeadingAlphaNumericDash"`
+	Metric             CloudWatchMetric `yaml:"metric,omitempty"`
+	ComparisonOperator string           `yaml:"comparisonOperator,omitempty"`
+	Threshold          float64          `yaml:"threshold,omitempty"`
+	EvaluationPeriods  int              `yaml:"evaluationPeriods,omitempty"`
+	Period             int              `yaml:"period,omitempty"`
+	AdjustmentType     string           `yaml:"adjustmentType,omitempty"`
+	Cooldown           int              `yaml:"cooldown,omitempty"`
+	Steps              []ScalingStep    `yaml:"steps,omitempty"`
+}
+
+// ScalingStep definition of the adjustment for a range of the metric, with bounds relative to the alarm threshold
+type ScalingStep struct {
+	LowerBound *float64 `yaml:"lowerBound,omitempty"`
+	UpperBound *float64 `yaml:"upperBound,omitempty"`
+	Adjustment int      `yaml:"adjustment"`
+}
+
+// ScheduledAction definition to change the capacity of the service on a schedule
+type ScheduledAction struct {
+	Name     string `yaml:"name,omitempty" validate:"validateLeadingAlphaNumericDash"`
+	Schedule string `yaml:"schedule,omitempty"`
+	MinSize  *int   `yaml:"minSize,omitempty"`
+	MaxSize  *int   `yaml:"maxSize,omitempty"`
+}
+
+// CloudWatchMetric definition of a metric to scale on
+type CloudWatchMetric struct {
+	Namespace  string            `yaml:"namespace,omitempty"`
+	MetricName string            `yaml:"metricName,omitempty"`
+	Dimensions map[string]string `yaml:"dimensions,omitempty"`
+	Statistic  string            `yaml:"statistic,omitempty"`
+}
+
+// Sidecar definition for a container that runs next to the service container
+type Sidecar struct {
+	Name        string            `yaml:"name,omitempty" validate:"validateLeadingAlphaNumericDash"`
+	Image       string            `yaml:"image,omitempty" validate:"validateDockerImage"`
+	Port        int               `yaml:"port,omitempty" validate:"max=65535"`
+	Environment map[string]string `yaml:"environment,omitempty"`
+	Essential   bool              `yaml:"essential,omitempty"`
+	DependsOn   []string          `yaml:"dependsOn,omitempty"`
+	CPU         int               `yaml:"cpu,omitempty"`
+	Memory      int               `yaml:"memory,omitempty"`
+}
+
+// Volume definition for persistent storage that is mounted into the service container. EFS volumes are an access point
+// on the filesystem of the environment, on EKS both EFS and EBS volumes are a PersistentVolumeClaim
+type Volume struct {
+	Name          string     `yaml:"name,omitempty" validate:"validateLeadingAlphaNumericDash"`
+	Type          VolumeType `yaml:"type,omitempty"`
+	ContainerPath string     `yaml:"containerPath,omitempty"`
+	ReadOnly      bool       `yaml:"readOnly,omitempty"`
+	UID           int        `yaml:"uid,omitempty"`
+	GID           int        `yaml:"gid,omitempty"`
+	Size          string     `yaml:"size,omitempty"`
+	StorageClass  string     `yaml:"storageClass,omitempty"`
+}
+
+// IsEFS returns true for EFS volumes, which is the default type
+func (volume Volume) IsEFS() bool {
+	return volume.Type == "" || volume.Type == VolumeTypeEFS
+}
+
+// ResourceName converts the name of the volume to the prefix of its CloudFormation resources, e.g. 'app-data' to 'AppData'
+func (volume Volume) ResourceName() string {
+	var resourceName string
+	for _, part := range strings.Split(volume.Name, "-") {
+		if part != "" {
+			resourceName += strings.ToUpper(part[:1]) + part[1:]
+		}
+	}
+	return resourceName
+}
+
+// OwnerUID is the user that owns the root directory of an EFS volume and that files are written as, defaults to 1000
+func (volume Volume) OwnerUID() int {
+	if volume.UID != 0 {
+		return volume.UID
+	}
+	return 1000
+}
+
+// OwnerGID is the group that owns the root directory of an EFS volume and that files are written as, defaults to 1000
+func (volume Volume) OwnerGID() int {
+	if volume.GID != 0 {
+		return volume.GID
+	}
+	return 1000
+}
+
+// KubernetesStorageClass is the storage class of the PersistentVolumeClaim, defaults to 'efs-sc' from the EFS CSI driver or 'gp2' for EBS
+func (volume Volume) KubernetesStorageClass() string {
+	if volume.StorageClass != "" {
+		return volume.StorageClass
+	}
+	if volume.IsEFS() {
+		return "efs-sc"
+	}
+	return "gp2"
+}
+
+// KubernetesAccessMode lets all pods share EFS volumes, EBS volumes can only be attached to a single node
+func (volume Volume) KubernetesAccessMode() string {
+	if volume.IsEFS() {
+		return "ReadWriteMany"
+	}
+	return "ReadWriteOnce"
+}
+
+// KubernetesSize is the storage requested by the PersistentVolumeClaim, EFS is elastic but kubernetes still requires a size
+func (volume Volume) KubernetesSize() string {
+	if volume.Size != "" {
+		return volume.Size
+	}
+	return "5Gi"
+}
+
+// CapacityProviderItem defines the share of the tasks of a service that runs on a capacity provider
+type CapacityProviderItem struct {
+	Provider CapacityProvider `yaml:"provider,omitempty"`
+	Weight   int              `yaml:"weight,omitempty"`
+	Base     int              `yaml:"base,omitempty"`
+}
+
+// IsFargate returns true for the FARGATE and FARGATE_SPOT capacity providers
+func (item CapacityProviderItem) IsFargate() bool {
+	return item.Provider == CapacityProviderFargate || item.Provider == CapacityProviderFargateSpot
+}
+
+// KubernetesCPU converts the CPU units of the sidecar to a kubernetes quantity
+func (sidecar Sidecar) KubernetesCPU() string {
+	return KubernetesCPU(sidecar.CPU)
+}
+
+// KubernetesCPU converts CPU units, where 1024 is one vCPU, to a kubernetes quantity
+func KubernetesCPU(cpu int) string {
+	return fmt.Sprintf("%dm", cpu*1000/1024)
+}
+
 // Pipeline definition
 type Pipeline struct {
 	Catalog struct {
@@ -359,6 +733,9 @@ const (
 
 	// StackStatusReviewInProgress is a StackStatus enum value
 	StackStatusReviewInProgress = "REVIEW_IN_PROGRESS"
+
+	// StackStatusHookFailed is the status of a stack whose AfterStackUpsert extension hook failed
+	StackStatusHookFailed = "HOOK_FAILED"
 )
 
 // StackType describes supported stack types
@@ -412,6 +789,7 @@ const (
 	TemplateK8sCluster              = "kubernetes/cluster.yml"
 	TemplateK8sDeployment           = "kubernetes/deployment.yml"
 	TemplateK8sDatabase             = "kubernetes/database.yml"
+	TemplateK8sSecrets              = "kubernetes/secrets.yml"
 	TemplateK8sIngress              = "kubernetes/ingress.yml"
 	TemplateArtifactPipeline        = "cloudformation/artifact-pipeline.yml"
 )
@@ -421,11 +799,34 @@ type DeploymentStrategy string
 
 // List of supported deployment strategies
 const (
-	BlueGreenDeploymentStrategy DeploymentStrategy = "blue_green"
-	RollingDeploymentStrategy   DeploymentStrategy = "rolling"
-	ReplaceDeploymentStrategy   DeploymentStrategy = "replace"
+	BlueGreenDeploymentStrategy                    DeploymentStrategy = "blue_green"
+	RollingDeploymentStrategy                      DeploymentStrategy = "rolling"
+	ReplaceDeploymentStrategy                      DeploymentStrategy = "replace"
+	Canary10Percent5MinutesDeploymentStrategy      DeploymentStrategy = "canary10percent5minutes"
+	Canary10Percent15MinutesDeploymentStrategy     DeploymentStrategy = "canary10percent15minutes"
+	Linear10PercentEvery1MinuteDeploymentStrategy  DeploymentStrategy = "linear10percentevery1minute"
+	Linear10PercentEvery3MinutesDeploymentStrategy DeploymentStrategy = "linear10percentevery3minutes"
+	AllAtOnceDeploymentStrategy                    DeploymentStrategy = "all_at_once"
 )
 
+// CodeDeployConfigName returns the CodeDeploy deployment configuration for the strategies that shift
+// traffic between task sets, or an empty string for the strategies that are handled by ECS
+func (strategy DeploymentStrategy) CodeDeployConfigName() string {
+	switch strategy {
+	case Canary10Percent5MinutesDeploymentStrategy:
+		return "CodeDeployDefault.ECSCanary10Percent5Minutes"
+	case Canary10Percent15MinutesDeploymentStrategy:
+		return "CodeDeployDefault.ECSCanary10Percent15Minutes"
+	case Linear10PercentEvery1MinuteDeploymentStrategy:
+		return "CodeDeployDefault.ECSLinear10PercentEvery1Minutes"
+	case Linear10PercentEvery3MinutesDeploymentStrategy:
+		return "CodeDeployDefault.ECSLinear10PercentEvery3Minutes"
+	case AllAtOnceDeploymentStrategy:
+		return "CodeDeployDefault.ECSAllAtOnce"
+	}
+	return ""
+}
+
 // EnvProvider describes supported environment strategies
 type EnvProvider string
 
@@ -464,6 +865,81 @@ type ServiceProtocol string
 const (
 	ServiceProtocolHTTP  = "HTTP"
 	ServiceProtocolHTTPS = "HTTPS"
+	ServiceProtocolGRPC  = "GRPC"
+	ServiceProtocolTCP   = "TCP"
+	ServiceProtocolUDP   = "UDP"
+	ServiceProtocolTLS   = "TLS"
+)
+
+// IsNetwork returns whether the protocol is served by a listener of a network load balancer
+func (protocol ServiceProtocol) IsNetwork() bool {
+	return protocol == ServiceProtocolTCP || protocol == ServiceProtocolUDP || protocol == ServiceProtocolTLS
+}
+
+// IsGRPC returns whether the protocol is gRPC, which is served by an application load balancer over HTTP/2
+func (protocol ServiceProtocol) IsGRPC() bool {
+	return protocol == ServiceProtocolGRPC
+}
+
+// TargetGroupProtocol returns the protocol of the target group for the service, TLS is terminated by the load
+// balancer and gRPC is sent to the targets over HTTP/2
+func (protocol ServiceProtocol) TargetGroupProtocol() string {
+	switch protocol {
+	case ServiceProtocolGRPC:
+		return ServiceProtocolHTTP
+	case ServiceProtocolTLS:
+		return ServiceProtocolTCP
+	}
+	return string(protocol)
+}
+
+// AutoscalingMetric describes the metric of a target tracking policy
+type AutoscalingMetric string
+
+// List of supported autoscaling metrics
+const (
+	AutoscalingMetricCPU           AutoscalingMetric = "cpu"
+	AutoscalingMetricMemory                          = "memory"
+	AutoscalingMetricRequestCount                    = "requestCount"
+	AutoscalingMetricSqsQueueDepth                   = "sqsQueueDepth"
+	AutoscalingMetricCustom                          = "custom"
+)
+
+// VolumeType describes the storage of a volume
+type VolumeType string
+
+// List of supported volume types
+const (
+	VolumeTypeEFS VolumeType = "efs"
+	VolumeTypeEBS            = "ebs"
+)
+
+// SpotAllocationStrategy describes how spot instances are allocated across the instance types of a cluster
+type SpotAllocationStrategy string
+
+// List of supported spot allocation strategies
+const (
+	SpotAllocationStrategyCapacityOptimized SpotAllocationStrategy = "capacity-optimized"
+	SpotAllocationStrategyLowestPrice                              = "lowest-price"
+)
+
+// CapacityProvider describes where the tasks of a service run, EC2 is the capacity provider of the environment cluster
+type CapacityProvider string
+
+// List of supported capacity providers
+const (
+	CapacityProviderFargate     CapacityProvider = "FARGATE"
+	CapacityProviderFargateSpot                  = "FARGATE_SPOT"
+	CapacityProviderEC2                          = "EC2"
+)
+
+// LoadbalancerType describes the type of load balancer for an environment
+type LoadbalancerType string
+
+// List of supported load balancer types
+const (
+	LoadbalancerTypeApplication LoadbalancerType = "application"
+	LoadbalancerTypeNetwork                      = "network"
 )
 
 // NetworkMode describes the ecs docker network mode
@@ -4,6 +4,7 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"gopkg.in/yaml.v2"
 )
 
 func TestDatabase_GetDatabaseConfig(t *testing.T) {
@@ -34,3 +35,182 @@ func TestDatabase_GetDatabaseConfig(t *testing.T) {
 	assert.Equal("serverless", acptConfig.EngineMode)
 	assert.Equal("", prodConfig.EngineMode)
 }
+
+func TestService_GetServiceConfig(t *testing.T) {
+	assert := assert.New(t)
+
+	serviceYaml := `
+name: api
+desiredCount: 1
+cpu: 256
+hostPatterns:
+  - dev.example.com
+environment:
+  LOG_LEVEL: debug
+  DB_HOST:
+    production: prod-db
+environmentConfig:
+  production:
+    desiredCount: 10
+    cpu: 2048
+    hostPatterns:
+      - example.com
+    environment:
+      LOG_LEVEL: info
+`
+	service := new(Service)
+	err := yaml.UnmarshalStrict([]byte(serviceYaml), service)
+	assert.Nil(err)
+
+	prodService, err := service.GetServiceConfig("production")
+	assert.Nil(err)
+	assert.Equal("api", prodService.Name)
+	assert.Equal(10, prodService.DesiredCount)
+	assert.Equal(2048, prodService.CPU)
+	assert.Equal([]string{"example.com"}, prodService.HostPatterns)
+	assert.Equal("info", prodService.Environment["LOG_LEVEL"])
+	assert.NotNil(prodService.Environment["DB_HOST"])
+	assert.Nil(prodService.EnvironmentConfig)
+
+	devService, err := service.GetServiceConfig("dev")
+	assert.Nil(err)
+	assert.Equal(1, devService.DesiredCount)
+	assert.Equal(256, devService.CPU)
+	assert.Equal([]string{"dev.example.com"}, devService.HostPatterns)
+	assert.Equal("debug", devService.Environment["LOG_LEVEL"])
+
+	// the service is unchanged
+	assert.Equal(1, service.DesiredCount)
+	assert.Equal("debug", service.Environment["LOG_LEVEL"])
+
+	service.EnvironmentConfig["production"]["desiredCnt"] = 10
+	_, err = service.GetServiceConfig("production")
+	assert.NotNil(err)
+}
+
+func TestConfig_SelectServices(t *testing.T) {
+	assert := assert.New(t)
+
+	config := &Config{
+		Services: []Service{
+			{Name: "api", Path: "api"},
+			{Name: "web", Path: "web"},
+			{Name: "worker", Path: "worker"},
+		},
+	}
+
+	services, err := config.SelectServices("")
+	assert.Nil(err)
+	assert.Equal(3, len(services))
+
+	services, err = config.SelectServices("worker, api")
+	assert.Nil(err)
+	assert.Equal(2, len(services))
+	assert.Equal("worker", services[0].Name)
+	assert.Equal("api", services[1].Name)
+
+	_, err = config.SelectServices("api,missing")
+	assert.NotNil(err)
+}
+
+func TestConfig_SelectServices_Single(t *testing.T) {
+	assert := assert.New(t)
+
+	config := &Config{}
+	config.Repo.Name = "my-repo"
+	config.Service.Port = 8080
+
+	services, err := config.SelectServices("")
+	assert.Nil(err)
+	assert.Equal(1, len(services))
+	assert.Equal("my-repo", services[0].Name)
+	assert.Equal(8080, services[0].Port)
+
+	services, err = config.SelectServices("my-repo")
+	assert.Nil(err)
+	assert.Equal(1, len(services))
+
+	_, err = config.SelectServices("other")
+	assert.NotNil(err)
+}
+
+func TestDeploymentStrategy_CodeDeployConfigName(t *testing.T) {
+	assert := assert.New(t)
+
+	assert.Equal("CodeDeployDefault.ECSCanary10Percent5Minutes", Canary10Percent5MinutesDeploymentStrategy.CodeDeployConfigName())
+	assert.Equal("CodeDeployDefault.ECSLinear10PercentEvery3Minutes", Linear10PercentEvery3MinutesDeploymentStrategy.CodeDeployConfigName())
+	assert.Equal("CodeDeployDefault.ECSAllAtOnce", AllAtOnceDeploymentStrategy.CodeDeployConfigName())
+	assert.Equal("", RollingDeploymentStrategy.CodeDeployConfigName())
+	assert.Equal("", DeploymentStrategy("").CodeDeployConfigName())
+}
+
+func TestVolume_Defaults(t *testing.T) {
+	assert := assert.New(t)
+
+	efs := Volume{Name: "app-data"}
+	assert.True(efs.IsEFS())
+	assert.Equal("AppData", efs.ResourceName())
+	assert.Equal(1000, efs.OwnerUID())
+	assert.Equal(1000, efs.OwnerGID())
+	assert.Equal("efs-sc", efs.KubernetesStorageClass())
+	assert.Equal("ReadWriteMany", efs.KubernetesAccessMode())
+	assert.Equal("5Gi", efs.KubernetesSize())
+
+	ebs := Volume{Name: "cache", Type: VolumeTypeEBS, UID: 999, GID: 998, Size: "20Gi", StorageClass: "io1"}
+	assert.False(ebs.IsEFS())
+	assert.Equal("Cache", ebs.ResourceName())
+	assert.Equal(999, ebs.OwnerUID())
+	assert.Equal(998, ebs.OwnerGID())
+	assert.Equal("io1", ebs.KubernetesStorageClass())
+	assert.Equal("ReadWriteOnce", ebs.KubernetesAccessMode())
+	assert.Equal("20Gi", ebs.KubernetesSize())
+}
+
+func TestOrderServicesByDependencies(t *testing.T) {
+	assert := assert.New(t)
+
+	services := []Service{
+		{Name: "web", DependsOn: []ServiceDependency{{Service: "api"}, {Service: "auth"}}},
+		{Name: "api", DependsOn: []ServiceDependency{{Service: "auth"}, {Database: "api"}, {Service: "external"}}},
+		{Name: "auth"},
+		{Name: "worker"},
+	}
+	groups, err := OrderServicesByDependencies(services)
+	assert.Nil(err)
+	assert.Equal(3, len(groups))
+	assert.Equal(2, len(groups[0]))
+	assert.Equal("auth", groups[0][0].Name)
+	assert.Equal("worker", groups[0][1].Name)
+	assert.Equal("api", groups[1][0].Name)
+	assert.Equal("web", groups[2][0].Name)
+
+	services[2].DependsOn = []ServiceDependency{{Service: "web"}}
+	_, err = OrderServicesByDependencies(services)
+	assert.NotNil(err)
+}
+
+func TestServiceDependency_EnvironmentPrefix(t *testing.T) {
+	assert := assert.New(t)
+
+	assert.Equal("BILLING_API", ServiceDependency{Service: "billing-api"}.EnvironmentPrefix())
+	assert.Equal("ORDERS", ServiceDependency{Database: "orders"}.EnvironmentPrefix())
+}
+
+func TestCluster_HasMixedInstances(t *testing.T) {
+	assert := assert.New(t)
+
+	assert.False(Cluster{InstanceType: "t3.medium"}.HasMixedInstances())
+	assert.True(Cluster{InstanceTypes: []string{"t3a.medium"}}.HasMixedInstances())
+
+	cluster := Cluster{}
+	cluster.Spot.Percentage = 100
+	assert.True(cluster.HasMixedInstances())
+}
+
+func TestCapacityProviderItem_IsFargate(t *testing.T) {
+	assert := assert.New(t)
+
+	assert.True(CapacityProviderItem{Provider: CapacityProviderFargate}.IsFargate())
+	assert.True(CapacityProviderItem{Provider: CapacityProviderFargateSpot}.IsFargate())
+	assert.False(CapacityProviderItem{Provider: CapacityProviderEC2}.IsFargate())
+}
@@ -2,8 +2,10 @@ package common
 
 import (
 	"errors"
+	"fmt"
 	"reflect"
 	"regexp"
+	"sort"
 	"strconv"
 	"strings"
 
@@ -13,7 +15,102 @@ import (
 // Validate validates the config struct
 func (config *Config) Validate() error {
 	validators()
-	return validator.Validate(config)
+	if err := validator.Validate(config); err != nil {
+		return err
+	}
+
+	if len(config.Services) > 0 && !reflect.DeepEqual(config.Service, Service{}) {
+		return fmt.Errorf("service and services can't both be defined, add the service to the services list instead")
+	}
+
+	if err := validateKubernetesOverrides(config.Kubernetes); err != nil {
+		return err
+	}
+
+	for _, environment := range config.Environments {
+		if err := validateLoadbalancerType(environment.Loadbalancer.Type); err != nil {
+			return fmt.Errorf("environment '%s': %v", environment.Name, err)
+		}
+		if err := validateClusterSpot(environment.Cluster.Spot); err != nil {
+			return fmt.Errorf("environment '%s': %v", environment.Name, err)
+		}
+	}
+	for _, service := range append([]Service{config.Service}, config.Services...) {
+		if err := validateServiceProtocol(&service); err != nil {
+			return fmt.Errorf("service '%s': %v", service.Name, err)
+		}
+		if err := validateServiceAutoscaling(&service); err != nil {
+			return fmt.Errorf("service '%s': %v", service.Name, err)
+		}
+		if err := validateServiceVolumes(&service); err != nil {
+			return fmt.Errorf("service '%s': %v", service.Name, err)
+		}
+		if err := validateServiceDependencies(&service); err != nil {
+			return fmt.Errorf("service '%s': %v", service.Name, err)
+		}
+		if err := validateServiceCapacityProviders(&service); err != nil {
+			return fmt.Errorf("service '%s': %v", service.Name, err)
+		}
+	}
+	for _, environment := range config.Environments {
+		if environment.Provider != EnvProviderEks && environment.Provider != EnvProviderEksFargate {
+			continue
+		}
+		for _, service := range append([]Service{config.Service}, config.Services...) {
+			serviceConfig, err := service.GetServiceConfig(environment.Name)
+			if err != nil {
+				return err
+			}
+			if err := validateServiceEksVolumes(serviceConfig); err != nil {
+				return fmt.Errorf("service '%s' in environment '%s': %v", service.Name, environment.Name, err)
+			}
+		}
+	}
+	if _, err := OrderServicesByDependencies(config.Services); err != nil {
+		return err
+	}
+	return nil
+}
+
+// validateKubernetesOverrides validates that the keys of the kubernetes overrides are '<kind>/<name>' patterns
+func validateKubernetesOverrides(overrides map[string]KubernetesOverride) error {
+	resourcePatterns := make([]string, 0, len(overrides))
+	for resourcePattern := range overrides {
+		resourcePatterns = append(resourcePatterns, resourcePattern)
+	}
+	sort.Strings(resourcePatterns)
+	for _, resourcePattern := range resourcePatterns {
+		if _, err := newKubernetesOverrideExtension(resourcePattern, overrides[resourcePattern]); err != nil {
+			return fmt.Errorf("kubernetes override '%s': %v", resourcePattern, err)
+		}
+	}
+	return nil
+}
+
+// validateLoadbalancerType validates that the load balancer is an application or network load balancer
+func validateLoadbalancerType(loadbalancerType LoadbalancerType) error {
+	switch loadbalancerType {
+	case "", LoadbalancerTypeApplication, LoadbalancerTypeNetwork:
+		return nil
+	}
+	return fmt.Errorf("unsupported loadbalancer type '%s'", loadbalancerType)
+}
+
+// validateServiceProtocol validates that the routing of the service is supported by the load balancer for its protocol
+func validateServiceProtocol(service *Service) error {
+	switch service.Protocol {
+	case "", ServiceProtocolHTTP, ServiceProtocolHTTPS, ServiceProtocolGRPC:
+		if service.ListenerPort != 0 {
+			return fmt.Errorf("listenerPort is only supported for protocols %s, %s and %s", ServiceProtocolTCP, ServiceProtocolUDP, ServiceProtocolTLS)
+		}
+	case ServiceProtocolTCP, ServiceProtocolUDP, ServiceProtocolTLS:
+		if len(service.PathPatterns) > 0 || len(service.HostPatterns) > 0 {
+			return fmt.Errorf("pathPatterns and hostPatterns are not supported for protocol %s", service.Protocol)
+		}
+	default:
+		return fmt.Errorf("unsupported protocol '%s'", service.Protocol)
+	}
+	return nil
 }
 
 // Validators registers the custom validators with the default validator
@@ -47,6 +144,163 @@ func validateResourceID(v interface{}, param string) error {
 	return validator.ErrBadParameter
 }
 
+// validateServiceAutoscaling validates that the policies of the service have the settings their metric needs
+func validateServiceAutoscaling(service *Service) error {
+	names := make(map[string]bool)
+	for _, policy := range service.Autoscaling.TargetTracking {
+		if err := validateAutoscalingName(names, policy.Name); err != nil {
+			return err
+		}
+		if policy.TargetValue <= 0 {
+			return fmt.Errorf("autoscaling policy '%s' requires a targetValue", policy.Name)
+		}
+		switch policy.Metric {
+		case AutoscalingMetricCPU, AutoscalingMetricMemory:
+		case AutoscalingMetricRequestCount:
+			if len(service.PathPatterns) == 0 && len(service.HostPatterns) == 0 {
+				return fmt.Errorf("autoscaling policy '%s' requires pathPatterns or hostPatterns for metric %s", policy.Name, policy.Metric)
+			}
+		case AutoscalingMetricSqsQueueDepth:
+			if policy.QueueName == "" {
+				return fmt.Errorf("autoscaling policy '%s' requires a queueName for metric %s", policy.Name, policy.Metric)
+			}
+		case AutoscalingMetricCustom:
+			if policy.CustomMetric.Namespace == "" || policy.CustomMetric.MetricName == "" {
+				return fmt.Errorf("autoscaling policy '%s' requires the namespace and metricName of the customMetric", policy.Name)
+			}
+		default:
+			return fmt.Errorf("autoscaling policy '%s' has unsupported metric '%s'", policy.Name, policy.Metric)
+		}
+	}
+	for _, policy := range service.Autoscaling.StepScaling {
+		if err := validateAutoscalingName(names, policy.Name); err != nil {
+			return err
+		}
+		if policy.Metric.Namespace == "" || policy.Metric.MetricName == "" {
+			return fmt.Errorf("autoscaling policy '%s' requires the namespace and metricName of the metric", policy.Name)
+		}
+		if policy.ComparisonOperator == "" || len(policy.Steps) == 0 {
+			return fmt.Errorf("autoscaling policy '%s' requires a comparisonOperator and steps", policy.Name)
+		}
+	}
+	for _, action := range service.Autoscaling.Scheduled {
+		if err := validateAutoscalingName(names, action.Name); err != nil {
+			return err
+		}
+		if action.Schedule == "" {
+			return fmt.Errorf("scheduled action '%s' requires a schedule", action.Name)
+		}
+		if action.MinSize == nil && action.MaxSize == nil {
+			return fmt.Errorf("scheduled action '%s' requires a minSize or maxSize", action.Name)
+		}
+	}
+	return nil
+}
+
+// validateAutoscalingName validates that the policy or scheduled action has a unique name
+func validateAutoscalingName(names map[string]bool, name string) error {
+	if name == "" {
+		return errors.New("autoscaling policies and scheduled actions require a name")
+	}
+	if names[name] {
+		return fmt.Errorf("autoscaling name '%s' is not unique", name)
+	}
+	names[name] = true
+	return nil
+}
+
+// validateServiceVolumes validates that the volumes of the service have unique names, a supported type and an absolute containerPath
+func validateServiceVolumes(service *Service) error {
+	names := make(map[string]bool)
+	resourceNames := make(map[string]bool)
+	for _, volume := range service.Volumes {
+		if volume.Name == "" {
+			return errors.New("volumes require a name")
+		}
+		if names[volume.Name] || resourceNames[volume.ResourceName()] {
+			return fmt.Errorf("volume name '%s' is not unique", volume.Name)
+		}
+		names[volume.Name] = true
+		resourceNames[volume.ResourceName()] = true
+
+		switch volume.Type {
+		case "", VolumeTypeEFS, VolumeTypeEBS:
+		default:
+			return fmt.Errorf("volume '%s' has unsupported type '%s'", volume.Name, volume.Type)
+		}
+		if !strings.HasPrefix(volume.ContainerPath, "/") {
+			return fmt.Errorf("volume '%s' requires an absolute containerPath", volume.Name)
+		}
+	}
+	return nil
+}
+
+// validateServiceEksVolumes validates that the EFS volumes of a service in an EKS environment have a storage class, mu
+// doesn't create an EFS filesystem or the 'efs-sc' storage class of the EFS CSI driver in EKS clusters
+func validateServiceEksVolumes(service *Service) error {
+	for _, volume := range service.Volumes {
+		if volume.IsEFS() && volume.StorageClass == "" {
+			return fmt.Errorf("EFS volume '%s' requires the storageClass of an EFS filesystem provisioned in the cluster", volume.Name)
+		}
+	}
+	return nil
+}
+
+// validateServiceDependencies validates that each dependency is either a service or a database, and not the service itself
+func validateServiceDependencies(service *Service) error {
+	for _, dependency := range service.DependsOn {
+		if (dependency.Service == "") == (dependency.Database == "") {
+			return errors.New("dependsOn entries require either a service or a database")
+		}
+		if dependency.Service != "" && dependency.Service == service.Name {
+			return errors.New("service can not depend on itself")
+		}
+	}
+	return nil
+}
+
+// validateClusterSpot validates the allocation strategy of the spot instances
+func validateClusterSpot(spot ClusterSpot) error {
+	switch spot.AllocationStrategy {
+	case "", SpotAllocationStrategyCapacityOptimized, SpotAllocationStrategyLowestPrice:
+		return nil
+	}
+	return fmt.Errorf("unsupported spot allocationStrategy '%s'", spot.AllocationStrategy)
+}
+
+// validateServiceCapacityProviders validates the capacity provider strategy of the service against the rules of ECS,
+// the providers are either all Fargate or all EC2 and only one of them can have a base
+func validateServiceCapacityProviders(service *Service) error {
+	var hasWeight, hasFargate, hasEC2, hasBase bool
+	for _, item := range service.CapacityProviders {
+		switch item.Provider {
+		case CapacityProviderFargate, CapacityProviderFargateSpot:
+			hasFargate = true
+		case CapacityProviderEC2:
+			hasEC2 = true
+		default:
+			return fmt.Errorf("unsupported capacity provider '%s'", item.Provider)
+		}
+		if item.Weight < 0 || item.Base < 0 {
+			return fmt.Errorf("capacity provider '%s' can not have a negative weight or base", item.Provider)
+		}
+		if item.Base > 0 {
+			if hasBase {
+				return errors.New("only one capacity provider can have a base")
+			}
+			hasBase = true
+		}
+		hasWeight = hasWeight || item.Weight > 0
+	}
+	if hasFargate && hasEC2 {
+		return fmt.Errorf("capacity provider %s can not be combined with %s or %s", CapacityProviderEC2, CapacityProviderFargate, CapacityProviderFargateSpot)
+	}
+	if len(service.CapacityProviders) > 0 && !hasWeight {
+		return errors.New("capacityProviders require a provider with a weight")
+	}
+	return nil
+}
+
 func isSlice(v interface{}) (reflect.Value, error) {
 	st := reflect.ValueOf(v)
 	kind := st.Kind().String()
@@ -75,6 +329,9 @@ func validateRoleARN(v interface{}, param string) error {
 
 // validateInstanceType validates the value is an instance type https://docs.aws.amazon.com/AWSEC2/latest/UserGuide/instance-types.html
 func validateInstanceType(v interface{}, param string) error {
+	if st, err := isSlice(v); err == nil {
+		return someString(st, param, validateInstanceType)
+	}
 	value := reflect.ValueOf(v).String()
 	pattern := "^[a-zA-Z0-9]{2,3}\\.([a-zA-Z0-9]{2,3}\\.)?[a-zA-Z0-9]{4,10}$"
 	return regexpLength(value, pattern, 95)
@@ -61,6 +61,8 @@ func TestValidateInstanceType(t *testing.T) {
 	assert.Nil(validateInstanceType("db.t2.small", ""))
 	assert.NotNil(validateInstanceType("a.bar", ""))
 	assert.NotNil(validateInstanceType("a2.foo", ""))
+	assert.Nil(validateInstanceType([]string{"m5.large", "m5a.large"}, ""))
+	assert.NotNil(validateInstanceType([]string{"m5.large", "a2.foo"}, ""))
 }
 func TestValidateURL(t *testing.T) {
 	assert := assert.New(t)
@@ -144,6 +146,50 @@ func TestValidateConfigServicePort(t *testing.T) {
 	assert.Nil(config.Validate())
 }
 
+func TestValidateConfigServices(t *testing.T) {
+	assert := assert.New(t)
+
+	config := Config{
+		Services: []Service{{Name: "api", Port: 8080}, {Name: "web", Port: 3000}},
+	}
+	assert.Nil(config.Validate())
+
+	config.Service = Service{Name: "worker"}
+	assert.NotNil(config.Validate())
+}
+
+func TestValidateConfigKubernetes(t *testing.T) {
+	assert := assert.New(t)
+
+	config := Config{
+		Kubernetes: map[string]KubernetesOverride{
+			"Deployment/api-.*": {},
+		},
+	}
+	assert.Nil(config.Validate())
+
+	config.Kubernetes["api-deployment"] = KubernetesOverride{}
+	err := config.Validate()
+	assert.NotNil(err)
+	assert.Contains(err.Error(), "kubernetes override 'api-deployment'")
+}
+
+func TestValidateConfigEksVolumes(t *testing.T) {
+	assert := assert.New(t)
+
+	config := Config{
+		Environments: []Environment{{Name: "kube", Provider: EnvProviderEks}},
+		Service: Service{
+			Name:    "api",
+			Volumes: []Volume{{Name: "data", ContainerPath: "/data"}},
+		},
+	}
+	assert.NotNil(config.Validate())
+
+	config.Service.Volumes[0].StorageClass = "efs-app"
+	assert.Nil(config.Validate())
+}
+
 func TestValidateConfigNamespace(t *testing.T) {
 	assert := assert.New(t)
 
@@ -370,3 +416,135 @@ func TestValidateConfigEnvironmentRoles(t *testing.T) {
 	assert.Nil(configEmpty.Validate())
 	assert.Nil(config.Validate())
 }
+
+func TestValidateServiceProtocol(t *testing.T) {
+	assert := assert.New(t)
+	assert.Nil(validateServiceProtocol(&Service{PathPatterns: []string{"/api/*"}}))
+	assert.Nil(validateServiceProtocol(&Service{Protocol: ServiceProtocolGRPC, PathPatterns: []string{"/helloworld.Greeter/*"}}))
+	assert.Nil(validateServiceProtocol(&Service{Protocol: ServiceProtocolTCP, ListenerPort: 1883}))
+	assert.Nil(validateServiceProtocol(&Service{Protocol: ServiceProtocolUDP}))
+	assert.NotNil(validateServiceProtocol(&Service{Protocol: ServiceProtocolTCP, PathPatterns: []string{"/api/*"}}))
+	assert.NotNil(validateServiceProtocol(&Service{Protocol: ServiceProtocolTLS, HostPatterns: []string{"api.example.com"}}))
+	assert.NotNil(validateServiceProtocol(&Service{Protocol: ServiceProtocolHTTP, ListenerPort: 8080}))
+	assert.NotNil(validateServiceProtocol(&Service{Protocol: "SCTP"}))
+}
+
+func TestValidateLoadbalancerType(t *testing.T) {
+	assert := assert.New(t)
+	assert.Nil(validateLoadbalancerType(""))
+	assert.Nil(validateLoadbalancerType(LoadbalancerTypeApplication))
+	assert.Nil(validateLoadbalancerType(LoadbalancerTypeNetwork))
+	assert.NotNil(validateLoadbalancerType("classic"))
+}
+
+func TestValidateServiceAutoscaling(t *testing.T) {
+	assert := assert.New(t)
+
+	zero := 0
+	service := &Service{PathPatterns: []string{"/api/*"}}
+	service.Autoscaling.TargetTracking = []TargetTrackingPolicy{
+		{Name: "requests", Metric: AutoscalingMetricRequestCount, TargetValue: 1000},
+		{Name: "queue", Metric: AutoscalingMetricSqsQueueDepth, TargetValue: 100, QueueName: "jobs"},
+	}
+	service.Autoscaling.StepScaling = []StepScalingPolicy{
+		{
+			Name:               "latency",
+			Metric:             CloudWatchMetric{Namespace: "AWS/ApplicationELB", MetricName: "TargetResponseTime"},
+			ComparisonOperator: "GreaterThanThreshold",
+			Steps:              []ScalingStep{{Adjustment: 1}},
+		},
+	}
+	service.Autoscaling.Scheduled = []ScheduledAction{{Name: "night", Schedule: "cron(0 20 * * ? *)", MinSize: &zero, MaxSize: &zero}}
+	assert.Nil(validateServiceAutoscaling(service))
+
+	service.Autoscaling.Scheduled[0].Name = "queue"
+	assert.NotNil(validateServiceAutoscaling(service))
+	service.Autoscaling.Scheduled[0].Name = "night"
+
+	service.Autoscaling.TargetTracking[1].QueueName = ""
+	assert.NotNil(validateServiceAutoscaling(service))
+	service.Autoscaling.TargetTracking[1].QueueName = "jobs"
+
+	service.PathPatterns = nil
+	assert.NotNil(validateServiceAutoscaling(service))
+
+	service.Autoscaling.TargetTracking = []TargetTrackingPolicy{{Name: "disk", Metric: "disk", TargetValue: 50}}
+	assert.NotNil(validateServiceAutoscaling(service))
+}
+
+func TestValidateServiceVolumes(t *testing.T) {
+	assert := assert.New(t)
+
+	service := &Service{Volumes: []Volume{
+		{Name: "app-data", ContainerPath: "/var/lib/app"},
+		{Name: "cache", Type: VolumeTypeEBS, ContainerPath: "/cache", Size: "20Gi"},
+	}}
+	assert.Nil(validateServiceVolumes(service))
+
+	service.Volumes[1].Type = "s3"
+	assert.NotNil(validateServiceVolumes(service))
+	service.Volumes[1].Type = VolumeTypeEBS
+
+	service.Volumes[1].ContainerPath = "cache"
+	assert.NotNil(validateServiceVolumes(service))
+	service.Volumes[1].ContainerPath = "/cache"
+
+	service.Volumes[1].Name = "appdata"
+	assert.Nil(validateServiceVolumes(service))
+	service.Volumes[1].Name = "app-data"
+	assert.NotNil(validateServiceVolumes(service))
+	service.Volumes[1].Name = "app--data"
+	assert.NotNil(validateServiceVolumes(service))
+}
+
+func TestValidateServiceDependencies(t *testing.T) {
+	assert := assert.New(t)
+
+	service := &Service{Name: "web", DependsOn: []ServiceDependency{{Service: "api"}, {Database: "api"}}}
+	assert.Nil(validateServiceDependencies(service))
+
+	service.DependsOn = []ServiceDependency{{Service: "api", Database: "api"}}
+	assert.NotNil(validateServiceDependencies(service))
+
+	service.DependsOn = []ServiceDependency{{}}
+	assert.NotNil(validateServiceDependencies(service))
+
+	service.DependsOn = []ServiceDependency{{Service: "web"}}
+	assert.NotNil(validateServiceDependencies(service))
+}
+
+func TestValidateClusterSpot(t *testing.T) {
+	assert := assert.New(t)
+
+	assert.Nil(validateClusterSpot(ClusterSpot{}))
+	assert.Nil(validateClusterSpot(ClusterSpot{Percentage: 80, AllocationStrategy: SpotAllocationStrategyLowestPrice}))
+	assert.NotNil(validateClusterSpot(ClusterSpot{AllocationStrategy: "cheapest"}))
+}
+
+func TestValidateServiceCapacityProviders(t *testing.T) {
+	assert := assert.New(t)
+
+	service := &Service{}
+	assert.Nil(validateServiceCapacityProviders(service))
+
+	service.CapacityProviders = []CapacityProviderItem{{Provider: CapacityProviderFargate, Base: 1, Weight: 1}, {Provider: CapacityProviderFargateSpot, Weight: 3}}
+	assert.Nil(validateServiceCapacityProviders(service))
+
+	service.CapacityProviders = []CapacityProviderItem{{Provider: CapacityProviderEC2, Weight: 1}}
+	assert.Nil(validateServiceCapacityProviders(service))
+
+	service.CapacityProviders = []CapacityProviderItem{{Provider: "SPOT", Weight: 1}}
+	assert.NotNil(validateServiceCapacityProviders(service))
+
+	service.CapacityProviders = []CapacityProviderItem{{Provider: CapacityProviderFargate, Weight: 1}, {Provider: CapacityProviderEC2, Weight: 1}}
+	assert.NotNil(validateServiceCapacityProviders(service))
+
+	service.CapacityProviders = []CapacityProviderItem{{Provider: CapacityProviderFargate, Base: 1, Weight: 1}, {Provider: CapacityProviderFargateSpot, Base: 1, Weight: 1}}
+	assert.NotNil(validateServiceCapacityProviders(service))
+
+	service.CapacityProviders = []CapacityProviderItem{{Provider: CapacityProviderFargateSpot, Base: 2}}
+	assert.NotNil(validateServiceCapacityProviders(service))
+
+	service.CapacityProviders = []CapacityProviderItem{{Provider: CapacityProviderFargateSpot, Weight: -1}}
+	assert.NotNil(validateServiceCapacityProviders(service))
+}
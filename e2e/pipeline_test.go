@@ -5,6 +5,7 @@ package e2e
 import (
 	"bufio"
 	"bytes"
+	"context"
 	hm "crypto/hmac"
 	"crypto/sha256"
 	"fmt"
@@ -101,7 +102,7 @@ func TestPipelineE2E(t *testing.T) {
 
 func validatePipeline(ctx *common.Context) error {
 	// - pipeline up
-	err := workflows.NewPipelineUpserter(ctx, nil)()
+	err := workflows.NewPipelineUpserter(ctx, nil)(context.Background())
 	if err != nil {
 		return err
 	}
@@ -113,7 +114,7 @@ func validatePipeline(ctx *common.Context) error {
 	}
 
 	// - pipeline term
-	err = workflows.NewPipelineTerminator(ctx, "")()
+	err = workflows.NewPipelineTerminator(ctx, "")(context.Background())
 	if err != nil {
 		fmt.Printf("Error on cleanup pipeline '%s': %v", ctx.Config.Repo.Name, err)
 	}
@@ -124,7 +125,7 @@ func validatePipeline(ctx *common.Context) error {
 		envNames = append(envNames, env.Name)
 	}
 
-	err = workflows.NewEnvironmentsTerminator(ctx, envNames)()
+	err = workflows.NewEnvironmentsTerminator(ctx, envNames)(context.Background())
 	if err != nil {
 		fmt.Printf("Error on cleanup envs: %v", err)
 	}
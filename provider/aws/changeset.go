@@ -0,0 +1,188 @@
+package aws
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/cloudformation"
+	"github.com/stelligent/mu/common"
+)
+
+// PlanChanges will cause upserts to create change sets that are summarized and then deleted, rather than executed
+func (cfnMgr *cloudformationStackManager) PlanChanges(enabled bool) {
+	cfnMgr.planMode = enabled
+}
+
+func planStack(stackName string, stackParameters []*cloudformation.Parameter,
+	parameters map[string]string,
+	roleArn string, stackTags []*cloudformation.Tag, tags map[string]string,
+	stack *common.Stack, templateBody *string, cfnMgr *cloudformationStackManager) error {
+
+	changeSetType := cloudformation.ChangeSetTypeUpdate
+	if stack == nil || stack.ID == "" || stack.Status == cloudformation.StackStatusReviewInProgress {
+		changeSetType = cloudformation.ChangeSetTypeCreate
+	} else if stack.Status == cloudformation.StackStatusRollbackComplete {
+		cfnMgr.logInfo("  Plan for stack '%s': stack is in '%s' status and would be deleted and re-created", stackName, stack.Status)
+		return nil
+	}
+
+	changeSetName := fmt.Sprintf("mu-plan-%d", time.Now().Unix())
+	log.Debugf("  Creating change set '%s' of type '%s' for stack '%s'", changeSetName, changeSetType, stackName)
+	params := &cloudformation.CreateChangeSetInput{
+		ChangeSetName: aws.String(changeSetName),
+		ChangeSetType: aws.String(changeSetType),
+		StackName:     aws.String(stackName),
+		Parameters:    stackParameters,
+		TemplateBody:  templateBody,
+		Tags:          stackTags,
+	}
+	cleanParams(params, roleArn, tags)
+
+	_, err := cfnMgr.cfnAPI.CreateChangeSet(params)
+	if err != nil {
+		return err
+	}
+
+	changeSetParams := &cloudformation.DescribeChangeSetInput{
+		ChangeSetName: aws.String(changeSetName),
+		StackName:     aws.String(stackName),
+	}
+	defer cfnMgr.cleanupPlan(stackName, changeSetName, changeSetType)
+
+	// a failed change set is reported in the summary, so the waiter error is only logged
+	if err := cfnMgr.cfnAPI.WaitUntilChangeSetCreateComplete(changeSetParams); err != nil {
+		log.Debugf("  Change set '%s' did not complete: %v", changeSetName, err)
+	}
+
+	changeSet, err := cfnMgr.describeChangeSet(changeSetParams)
+	if err != nil {
+		return err
+	}
+	changeSet.StackName = stackName
+	changeSet.Type = changeSetType
+
+	oldParameters := make(map[string]string)
+	oldTags := make(map[string]string)
+	if changeSetType == cloudformation.ChangeSetTypeUpdate {
+		oldParameters = stack.Parameters
+		for key, value := range stack.Tags {
+			oldTags[fmt.Sprintf("mu:%s", key)] = value
+		}
+	}
+
+	newParameters := make(map[string]string)
+	for key, value := range parameters {
+		if value == "" {
+			// UsePreviousValue
+			value = oldParameters[key]
+		}
+		newParameters[key] = value
+	}
+	changeSet.ParameterChanges = common.DiffStackValues(oldParameters, newParameters)
+
+	newTags := make(map[string]string)
+	for _, tag := range stackTags {
+		key := aws.StringValue(tag.Key)
+		if strings.HasPrefix(key, "mu:") {
+			newTags[key] = aws.StringValue(tag.Value)
+		}
+	}
+	changeSet.TagChanges = common.DiffStackValues(oldTags, newTags)
+
+	cfnMgr.logChangeSet(changeSet)
+	return nil
+}
+
+func (cfnMgr *cloudformationStackManager) describeChangeSet(params *cloudformation.DescribeChangeSetInput) (*common.StackChangeSet, error) {
+	changeSet := &common.StackChangeSet{
+		ResourceChanges: make([]common.StackResourceChange, 0),
+	}
+	input := *params
+	for {
+		resp, err := cfnMgr.cfnAPI.DescribeChangeSet(&input)
+		if err != nil {
+			return nil, err
+		}
+		changeSet.Status = aws.StringValue(resp.Status)
+		changeSet.StatusReason = aws.StringValue(resp.StatusReason)
+		for _, change := range resp.Changes {
+			if change.ResourceChange == nil {
+				continue
+			}
+			changeSet.ResourceChanges = append(changeSet.ResourceChanges, common.StackResourceChange{
+				Action:       aws.StringValue(change.ResourceChange.Action),
+				LogicalID:    aws.StringValue(change.ResourceChange.LogicalResourceId),
+				ResourceType: aws.StringValue(change.ResourceChange.ResourceType),
+				Replacement:  aws.StringValue(change.ResourceChange.Replacement),
+			})
+		}
+		if aws.StringValue(resp.NextToken) == "" {
+			break
+		}
+		input.NextToken = resp.NextToken
+	}
+	return changeSet, nil
+}
+
+// cleanupPlan removes the change set, and the empty stack that CloudFormation creates for a CREATE change set
+func (cfnMgr *cloudformationStackManager) cleanupPlan(stackName string, changeSetName string, changeSetType string) {
+	if changeSetType == cloudformation.ChangeSetTypeCreate {
+		log.Debugf("  Deleting stack '%s' that was created for plan", stackName)
+		_, err := cfnMgr.cfnAPI.DeleteStack(&cloudformation.DeleteStackInput{StackName: aws.String(stackName)})
+		if err != nil {
+			log.Warningf("Unable to delete stack '%s' created for plan: %v", stackName, err)
+		}
+		return
+	}
+
+	log.Debugf("  Deleting change set '%s' for stack '%s'", changeSetName, stackName)
+	_, err := cfnMgr.cfnAPI.DeleteChangeSet(&cloudformation.DeleteChangeSetInput{
+		ChangeSetName: aws.String(changeSetName),
+		StackName:     aws.String(stackName),
+	})
+	if err != nil {
+		log.Warningf("Unable to delete change set '%s' for stack '%s': %v", changeSetName, stackName, err)
+	}
+}
+
+func (cfnMgr *cloudformationStackManager) logChangeSet(changeSet *common.StackChangeSet) {
+	adds, modifies, replaces, removes := 0, 0, 0, 0
+	for _, change := range changeSet.ResourceChanges {
+		switch {
+		case change.Replaced():
+			replaces++
+		case change.Action == common.StackChangeActionAdd:
+			adds++
+		case change.Action == common.StackChangeActionModify:
+			modifies++
+		case change.Action == common.StackChangeActionRemove:
+			removes++
+		}
+	}
+
+	lines := []string{fmt.Sprintf("  Plan for stack '%s' (%s): %d to add, %d to modify, %d to replace, %d to remove",
+		changeSet.StackName, strings.ToLower(changeSet.Type), adds, modifies, replaces, removes)}
+
+	if changeSet.Status == cloudformation.ChangeSetStatusFailed && len(changeSet.ResourceChanges) == 0 {
+		lines = append(lines, fmt.Sprintf("    %s", changeSet.StatusReason))
+	}
+	for _, change := range changeSet.ResourceChanges {
+		action := change.Action
+		if change.Replaced() {
+			action = "Replace"
+		} else if change.Replacement == common.StackChangeReplacementConditional {
+			action = "Modify (may replace)"
+		}
+		lines = append(lines, fmt.Sprintf("    %-20s %s (%s)", action, change.LogicalID, change.ResourceType))
+	}
+	for _, change := range changeSet.ParameterChanges {
+		lines = append(lines, fmt.Sprintf("    Parameter %s: '%s' => '%s'", change.Key, change.OldValue, change.NewValue))
+	}
+	for _, change := range changeSet.TagChanges {
+		lines = append(lines, fmt.Sprintf("    Tag %s: '%s' => '%s'", change.Key, change.OldValue, change.NewValue))
+	}
+
+	cfnMgr.logInfo("%s", strings.Join(lines, "\n"))
+}
@@ -0,0 +1,117 @@
+package aws
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/cloudformation"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func (m *mockedCloudFormation) CreateChangeSet(input *cloudformation.CreateChangeSetInput) (*cloudformation.CreateChangeSetOutput, error) {
+	args := m.Called(input)
+	return args.Get(0).(*cloudformation.CreateChangeSetOutput), args.Error(1)
+}
+func (m *mockedCloudFormation) WaitUntilChangeSetCreateComplete(*cloudformation.DescribeChangeSetInput) error {
+	args := m.Called()
+	return args.Error(0)
+}
+func (m *mockedCloudFormation) DescribeChangeSet(input *cloudformation.DescribeChangeSetInput) (*cloudformation.DescribeChangeSetOutput, error) {
+	args := m.Called()
+	return args.Get(0).(*cloudformation.DescribeChangeSetOutput), args.Error(1)
+}
+func (m *mockedCloudFormation) DeleteChangeSet(input *cloudformation.DeleteChangeSetInput) (*cloudformation.DeleteChangeSetOutput, error) {
+	args := m.Called()
+	return args.Get(0).(*cloudformation.DeleteChangeSetOutput), args.Error(1)
+}
+
+func TestStack_UpsertStack_PlanUpdate(t *testing.T) {
+	assert := assert.New(t)
+
+	cfn := new(mockedCloudFormation)
+	cfn.On("DescribeStacks").Return(
+		&cloudformation.DescribeStacksOutput{
+			Stacks: []*cloudformation.Stack{
+				{
+					StackId:     aws.String("arn:foo"),
+					StackName:   aws.String("foo"),
+					StackStatus: aws.String(cloudformation.StackStatusCreateComplete),
+				},
+			},
+		}, nil)
+	cfn.On("CreateChangeSet", mock.MatchedBy(func(params *cloudformation.CreateChangeSetInput) bool {
+		return aws.StringValue(params.ChangeSetType) == cloudformation.ChangeSetTypeUpdate
+	})).Return(&cloudformation.CreateChangeSetOutput{}, nil)
+	cfn.On("WaitUntilChangeSetCreateComplete").Return(nil)
+	cfn.On("DescribeChangeSet").Return(&cloudformation.DescribeChangeSetOutput{
+		Status: aws.String(cloudformation.ChangeSetStatusCreateComplete),
+		Changes: []*cloudformation.Change{
+			{
+				ResourceChange: &cloudformation.ResourceChange{
+					Action:            aws.String("Modify"),
+					LogicalResourceId: aws.String("Bucket"),
+					ResourceType:      aws.String("AWS::S3::Bucket"),
+					Replacement:       aws.String("True"),
+				},
+			},
+		},
+	}, nil)
+	cfn.On("DeleteChangeSet").Return(&cloudformation.DeleteChangeSetOutput{}, nil)
+
+	extMgr := new(mockedExtensionsManager)
+	extMgr.On("DecorateStackTemplate").Return()
+	extMgr.On("DecorateStackParameters").Return()
+	extMgr.On("DecorateStackTags").Return()
+
+	stackManager := cloudformationStackManager{
+		cfnAPI:            cfn,
+		extensionsManager: extMgr,
+	}
+	stackManager.PlanChanges(true)
+	err := stackManager.UpsertStack(context.Background(), "foo", "cloudformation/bucket.yml", nil, map[string]string{"BucketPrefix": "bar"}, nil, "", "")
+
+	assert.Nil(err)
+	cfn.AssertExpectations(t)
+	cfn.AssertNumberOfCalls(t, "CreateChangeSet", 1)
+	cfn.AssertNumberOfCalls(t, "DeleteChangeSet", 1)
+	cfn.AssertNumberOfCalls(t, "UpdateStack", 0)
+	cfn.AssertNumberOfCalls(t, "DeleteStack", 0)
+}
+
+func TestStack_UpsertStack_PlanCreate(t *testing.T) {
+	assert := assert.New(t)
+
+	cfn := new(mockedCloudFormation)
+	cfn.On("DescribeStacks").Return(&cloudformation.DescribeStacksOutput{}, errors.New("stack not found"))
+	cfn.On("DescribeStacksPages", mock.AnythingOfType("*cloudformation.DescribeStacksInput"), mock.AnythingOfType("func(*cloudformation.DescribeStacksOutput, bool) bool")).
+		Return(nil)
+	cfn.On("CreateChangeSet", mock.MatchedBy(func(params *cloudformation.CreateChangeSetInput) bool {
+		return aws.StringValue(params.ChangeSetType) == cloudformation.ChangeSetTypeCreate
+	})).Return(&cloudformation.CreateChangeSetOutput{}, nil)
+	cfn.On("WaitUntilChangeSetCreateComplete").Return(nil)
+	cfn.On("DescribeChangeSet").Return(&cloudformation.DescribeChangeSetOutput{
+		Status: aws.String(cloudformation.ChangeSetStatusCreateComplete),
+	}, nil)
+	cfn.On("DeleteStack").Return(&cloudformation.DeleteStackOutput{}, nil)
+
+	extMgr := new(mockedExtensionsManager)
+	extMgr.On("DecorateStackTemplate").Return()
+	extMgr.On("DecorateStackParameters").Return()
+	extMgr.On("DecorateStackTags").Return()
+
+	stackManager := cloudformationStackManager{
+		cfnAPI:            cfn,
+		extensionsManager: extMgr,
+	}
+	stackManager.PlanChanges(true)
+	err := stackManager.UpsertStack(context.Background(), "foo", "cloudformation/bucket.yml", nil, nil, nil, "", "")
+
+	assert.Nil(err)
+	cfn.AssertExpectations(t)
+	cfn.AssertNumberOfCalls(t, "CreateChangeSet", 1)
+	cfn.AssertNumberOfCalls(t, "DeleteStack", 1)
+	cfn.AssertNumberOfCalls(t, "CreateStack", 0)
+}
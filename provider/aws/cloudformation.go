@@ -2,6 +2,7 @@ package aws
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -10,6 +11,7 @@ import (
 	"reflect"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/aws/aws-sdk-go/aws"
@@ -34,6 +36,10 @@ type cloudformationStackManager struct {
 	statusSpinner     *spinner.Spinner
 	spinnerRefCnt     int
 	allowDataLoss     bool
+	planMode          bool
+	cancelUpdates     bool
+	pendingUpserts    map[string]bool
+	pendingMutex      sync.Mutex
 }
 
 // NewStackManager creates a new StackManager backed by cloudformation
@@ -49,7 +55,7 @@ func newStackManager(sess *session.Session, extensionsManager common.ExtensionsM
 
 	// initialize Spinner
 	var statusSpinner *spinner.Spinner
-	if terminal.IsTerminal(int(os.Stdout.Fd())) {
+	if terminal.IsTerminal(int(os.Stdout.Fd())) && !common.EventsEnabled() {
 		statusSpinner = spinner.New(spinner.CharSets[9], 100*time.Millisecond)
 	}
 
@@ -265,7 +271,7 @@ func checkVersion(cfnMgr *cloudformationStackManager, stack *common.Stack, stack
 
 // cleanStackIfInRollback will remove a given CloudFormation stack (by stackName)
 // if it is in ROLLBACK_COMPLETE state.
-func (cfnMgr *cloudformationStackManager) cleanStackIfInRollback(stack *common.Stack,
+func (cfnMgr *cloudformationStackManager) cleanStackIfInRollback(ctx context.Context, stack *common.Stack,
 	stackName string) (*common.Stack, error) {
 	// delete stack if in rollback status
 	if stack != nil && stack.Status == cloudformation.StackStatusRollbackComplete {
@@ -274,7 +280,7 @@ func (cfnMgr *cloudformationStackManager) cleanStackIfInRollback(stack *common.S
 		if err != nil {
 			return nil, err
 		}
-		stack = cfnMgr.AwaitFinalStatus(stackName)
+		stack = cfnMgr.AwaitFinalStatus(ctx, stackName)
 	}
 	return stack, nil
 }
@@ -284,12 +290,12 @@ func (cfnMgr *cloudformationStackManager) AllowDataLoss(allow bool) {
 }
 
 // SetTerminationProtection to protect stack from deletion
-func (cfnMgr *cloudformationStackManager) SetTerminationProtection(stackName string, enabled bool) error {
-	if cfnMgr.dryrunPath != "" {
+func (cfnMgr *cloudformationStackManager) SetTerminationProtection(ctx context.Context, stackName string, enabled bool) error {
+	if cfnMgr.dryrunPath != "" || cfnMgr.planMode {
 		return nil
 	}
 
-	stack := cfnMgr.AwaitFinalStatus(stackName)
+	stack := cfnMgr.AwaitFinalStatus(ctx, stackName)
 	if stack == nil || stack.EnableTerminationProtection == enabled {
 		return nil
 	}
@@ -307,12 +313,15 @@ func (cfnMgr *cloudformationStackManager) SetTerminationProtection(stackName str
 }
 
 // UpsertStack will create/update the cloudformation stack
-func (cfnMgr *cloudformationStackManager) UpsertStack(stackName string, templateName string, templateData interface{}, parameters map[string]string, tags map[string]string, policy string, roleArn string) error {
-	stack := cfnMgr.AwaitFinalStatus(stackName)
+func (cfnMgr *cloudformationStackManager) UpsertStack(ctx context.Context, stackName string, templateName string, templateData interface{}, parameters map[string]string, tags map[string]string, policy string, roleArn string) error {
+	stack := cfnMgr.AwaitFinalStatus(ctx, stackName)
 
-	stack, err := cfnMgr.cleanStackIfInRollback(stack, stackName)
-	if err != nil {
-		return err
+	var err error
+	if !cfnMgr.planMode {
+		stack, err = cfnMgr.cleanStackIfInRollback(ctx, stack, stackName)
+		if err != nil {
+			return err
+		}
 	}
 
 	err = checkVersion(cfnMgr, stack, stackName)
@@ -342,6 +351,18 @@ func (cfnMgr *cloudformationStackManager) UpsertStack(stackName string, template
 	}
 	stackTags := buildStackTags(tags)
 
+	if cfnMgr.planMode && cfnMgr.dryrunPath == "" {
+		return planStack(stackName, stackParameters, parameters,
+			roleArn, stackTags, tags, stack, aws.String(templateBody), cfnMgr)
+	}
+
+	if cfnMgr.dryrunPath == "" {
+		err = cfnMgr.extensionsManager.BeforeStackUpsert(stackName)
+		if err != nil {
+			return err
+		}
+	}
+
 	if stack == nil || stack.Status == "" {
 		// Stack should be created
 		err := createStack(stackName, stackParameters, parameters,
@@ -359,14 +380,53 @@ func (cfnMgr *cloudformationStackManager) UpsertStack(stackName string, template
 			if err != nil {
 				return err
 			}
-			stack = cfnMgr.AwaitFinalStatus(stackName)
+			stack = cfnMgr.AwaitFinalStatus(ctx, stackName)
 		} else {
+			cfnMgr.setPendingUpsert(stackName)
 			return nil
 		}
 	}
 	// else, stack should be updated
-	return updateStack(stackName, stackParameters, parameters,
+	err = updateStack(stackName, stackParameters, parameters,
 		roleArn, stackTags, tags, stack, aws.String(templateBody), templateBodyBytes, policy, cfnMgr)
+	if err != nil {
+		return err
+	}
+	cfnMgr.setPendingUpsert(stackName)
+	return nil
+}
+
+// setPendingUpsert records that the AfterStackUpsert hooks need to run when the stack reaches a final status
+func (cfnMgr *cloudformationStackManager) setPendingUpsert(stackName string) {
+	if cfnMgr.dryrunPath != "" {
+		return
+	}
+	cfnMgr.pendingMutex.Lock()
+	defer cfnMgr.pendingMutex.Unlock()
+	if cfnMgr.pendingUpserts == nil {
+		cfnMgr.pendingUpserts = make(map[string]bool)
+	}
+	cfnMgr.pendingUpserts[stackName] = true
+}
+
+// afterStackUpsert runs the AfterStackUpsert hooks once for a successful upsert, a failing hook fails the stack
+func (cfnMgr *cloudformationStackManager) afterStackUpsert(stack *common.Stack) *common.Stack {
+	cfnMgr.pendingMutex.Lock()
+	pending := cfnMgr.pendingUpserts[stack.Name]
+	delete(cfnMgr.pendingUpserts, stack.Name)
+	cfnMgr.pendingMutex.Unlock()
+
+	if !pending || strings.HasSuffix(stack.Status, "ROLLBACK_COMPLETE") || !strings.HasSuffix(stack.Status, "_COMPLETE") {
+		return stack
+	}
+
+	err := cfnMgr.extensionsManager.AfterStackUpsert(stack)
+	if err != nil {
+		log.Errorf("%v", err)
+		stack.Status = common.StackStatusHookFailed
+		stack.StatusReason = err.Error()
+	}
+	return stack
 }
 
 func (cfnMgr *cloudformationStackManager) startSpinner() {
@@ -433,6 +493,15 @@ func (cfnMgr *cloudformationStackManager) logStackEvents(stackName string, prior
 					status,
 					aws.StringValue(e.ResourceStatusReason))
 				cfnMgr.logEventStatus(status, eventMesg)
+				common.EmitEvent(common.Event{
+					Time:         aws.TimeValue(e.Timestamp),
+					Type:         common.EventTypeStackResource,
+					StackName:    stackName,
+					LogicalID:    aws.StringValue(e.LogicalResourceId),
+					ResourceType: aws.StringValue(e.ResourceType),
+					Status:       status,
+					Reason:       aws.StringValue(e.ResourceStatusReason),
+				})
 
 				priorEventTime = e.Timestamp
 			}
@@ -444,7 +513,7 @@ func (cfnMgr *cloudformationStackManager) logStackEvents(stackName string, prior
 
 // AwaitFinalStatus waits for the stack to arrive in a final status
 //  returns: final status, or empty string if stack doesn't exist
-func (cfnMgr *cloudformationStackManager) AwaitFinalStatus(stackName string) *common.Stack {
+func (cfnMgr *cloudformationStackManager) AwaitFinalStatus(ctx context.Context, stackName string) *common.Stack {
 
 	cfnAPI := cfnMgr.cfnAPI
 	params := &cloudformation.DescribeStacksInput{
@@ -470,7 +539,7 @@ func (cfnMgr *cloudformationStackManager) AwaitFinalStatus(stackName string) *co
 			}
 
 			log.Debugf("  Stack doesn't exist ... stack=%s", stackName)
-			if cfnMgr.dryrunPath != "" {
+			if cfnMgr.dryrunPath != "" || cfnMgr.planMode {
 				stack := &common.Stack{
 					Name:           stackName,
 					ID:             "",
@@ -492,14 +561,22 @@ func (cfnMgr *cloudformationStackManager) AwaitFinalStatus(stackName string) *co
 
 		if !strings.HasSuffix(aws.StringValue(resp.Stacks[0].StackStatus), "_IN_PROGRESS") {
 			log.Debugf("  Returning final status for stack:%s ... status=%s", stackName, *resp.Stacks[0].StackStatus)
-			return buildStack(resp.Stacks[0])
+			common.EmitEvent(common.Event{
+				Type:      common.EventTypeStack,
+				StackName: stackName,
+				Status:    aws.StringValue(resp.Stacks[0].StackStatus),
+				Reason:    aws.StringValue(resp.Stacks[0].StackStatusReason),
+			})
+			return cfnMgr.afterStackUpsert(buildStack(resp.Stacks[0]))
 		}
 
 		cfnMgr.logStackEvents(stackName, priorEventTime)
 
 		log.Debugf("  Not in final status (%s)...sleeping for 5 seconds", *resp.Stacks[0].StackStatus)
 		cfnMgr.startSpinner()
-		time.Sleep(time.Second * 5)
+		if common.SleepWithContext(ctx, time.Second*5) != nil {
+			return cfnMgr.interruptStack(stackName, resp.Stacks[0])
+		}
 	}
 }
 
@@ -679,6 +756,10 @@ func (cfnMgr *cloudformationStackManager) DeleteStack(stackName string) error {
 		log.Infof("  DRYRUN: Skipping delete of stack named '%s'", stackName)
 		return nil
 	}
+	if cfnMgr.planMode {
+		cfnMgr.logInfo("  PLAN: Stack '%s' would be deleted", stackName)
+		return nil
+	}
 
 	log.Debugf("Deleting stack named '%s'", stackName)
 
@@ -1,6 +1,7 @@
 package aws
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"io"
@@ -34,6 +35,14 @@ func (m *mockedExtensionsManager) DecorateStackTags(stackName string, stackTags
 	m.Called()
 	return stackTags, nil
 }
+func (m *mockedExtensionsManager) BeforeStackUpsert(stackName string) error {
+	args := m.Called()
+	return args.Error(0)
+}
+func (m *mockedExtensionsManager) AfterStackUpsert(stack *common.Stack) error {
+	args := m.Called()
+	return args.Error(0)
+}
 
 type mockedCloudFormation struct {
 	mock.Mock
@@ -68,6 +77,22 @@ func (m *mockedCloudFormation) UpdateStack(input *cloudformation.UpdateStackInpu
 	args := m.Called(input)
 	return args.Get(0).(*cloudformation.UpdateStackOutput), args.Error(1)
 }
+func (m *mockedCloudFormation) CancelUpdateStack(input *cloudformation.CancelUpdateStackInput) (*cloudformation.CancelUpdateStackOutput, error) {
+	args := m.Called()
+	return args.Get(0).(*cloudformation.CancelUpdateStackOutput), args.Error(1)
+}
+func (m *mockedCloudFormation) DetectStackDrift(input *cloudformation.DetectStackDriftInput) (*cloudformation.DetectStackDriftOutput, error) {
+	args := m.Called(aws.StringValue(input.StackName))
+	return args.Get(0).(*cloudformation.DetectStackDriftOutput), args.Error(1)
+}
+func (m *mockedCloudFormation) DescribeStackDriftDetectionStatus(input *cloudformation.DescribeStackDriftDetectionStatusInput) (*cloudformation.DescribeStackDriftDetectionStatusOutput, error) {
+	args := m.Called(aws.StringValue(input.StackDriftDetectionId))
+	return args.Get(0).(*cloudformation.DescribeStackDriftDetectionStatusOutput), args.Error(1)
+}
+func (m *mockedCloudFormation) DescribeStackResourceDrifts(input *cloudformation.DescribeStackResourceDriftsInput) (*cloudformation.DescribeStackResourceDriftsOutput, error) {
+	args := m.Called(aws.StringValue(input.StackName))
+	return args.Get(0).(*cloudformation.DescribeStackResourceDriftsOutput), args.Error(1)
+}
 
 func TestStack_AwaitFinalStatus_CreateComplete(t *testing.T) {
 	assert := assert.New(t)
@@ -86,7 +111,7 @@ func TestStack_AwaitFinalStatus_CreateComplete(t *testing.T) {
 		cfnAPI: cfn,
 	}
 
-	stack := stackManager.AwaitFinalStatus("foo")
+	stack := stackManager.AwaitFinalStatus(context.Background(), "foo")
 
 	assert.Equal(cloudformation.StackStatusCreateComplete, stack.Status)
 	cfn.AssertExpectations(t)
@@ -123,7 +148,7 @@ func TestStack_AwaitFinalStatus_CreateInProgress(t *testing.T) {
 		cfnAPI: cfn,
 	}
 
-	stack := stackManager.AwaitFinalStatus("foo")
+	stack := stackManager.AwaitFinalStatus(context.Background(), "foo")
 
 	assert.Equal(cloudformation.StackStatusCreateComplete, stack.Status)
 	cfn.AssertExpectations(t)
@@ -149,12 +174,13 @@ func TestStack_UpsertStack_Create(t *testing.T) {
 	extMgr.On("DecorateStackTemplate").Return()
 	extMgr.On("DecorateStackParameters").Return()
 	extMgr.On("DecorateStackTags").Return()
+	extMgr.On("BeforeStackUpsert").Return(nil)
 
 	stackManager := cloudformationStackManager{
 		cfnAPI:            cfn,
 		extensionsManager: extMgr,
 	}
-	err := stackManager.UpsertStack("foo", "cloudformation/bucket.yml", nil, nil, nil, "", "")
+	err := stackManager.UpsertStack(context.Background(), "foo", "cloudformation/bucket.yml", nil, nil, nil, "", "")
 
 	assert.Nil(err)
 	cfn.AssertExpectations(t)
@@ -183,12 +209,13 @@ func TestStack_UpsertStack_Update(t *testing.T) {
 	extMgr.On("DecorateStackTemplate").Return()
 	extMgr.On("DecorateStackParameters").Return()
 	extMgr.On("DecorateStackTags").Return()
+	extMgr.On("BeforeStackUpsert").Return(nil)
 
 	stackManager := cloudformationStackManager{
 		cfnAPI:            cfn,
 		extensionsManager: extMgr,
 	}
-	err := stackManager.UpsertStack("foo", "cloudformation/bucket.yml", nil, nil, nil, "", "")
+	err := stackManager.UpsertStack(context.Background(), "foo", "cloudformation/bucket.yml", nil, nil, nil, "", "")
 
 	assert.Nil(err)
 	cfn.AssertExpectations(t)
@@ -198,6 +225,61 @@ func TestStack_UpsertStack_Update(t *testing.T) {
 	cfn.AssertNumberOfCalls(t, "WaitUntilStackExists", 0)
 }
 
+func TestStack_UpsertStack_BeforeStackUpsertFailed(t *testing.T) {
+	assert := assert.New(t)
+
+	cfn := mockBasicCfnAPI(false)
+
+	extMgr := new(mockedExtensionsManager)
+	extMgr.On("DecorateStackTemplate").Return()
+	extMgr.On("DecorateStackParameters").Return()
+	extMgr.On("DecorateStackTags").Return()
+	extMgr.On("BeforeStackUpsert").Return(errors.New("policy check failed"))
+
+	stackManager := cloudformationStackManager{
+		cfnAPI:            cfn,
+		extensionsManager: extMgr,
+	}
+	err := stackManager.UpsertStack(context.Background(), "foo", "cloudformation/bucket.yml", nil, nil, nil, "", "")
+
+	assert.NotNil(err)
+	extMgr.AssertExpectations(t)
+	cfn.AssertNumberOfCalls(t, "UpdateStack", 0)
+}
+
+func TestStack_AwaitFinalStatus_AfterStackUpsertFailed(t *testing.T) {
+	assert := assert.New(t)
+
+	cfn := new(mockedCloudFormation)
+	cfn.On("DescribeStacks").Return(
+		&cloudformation.DescribeStacksOutput{
+			Stacks: []*cloudformation.Stack{
+				{
+					StackName:   aws.String("foo"),
+					StackStatus: aws.String(cloudformation.StackStatusUpdateComplete),
+				},
+			},
+		}, nil)
+
+	extMgr := new(mockedExtensionsManager)
+	extMgr.On("AfterStackUpsert").Return(errors.New("smoke test failed"))
+
+	stackManager := cloudformationStackManager{
+		cfnAPI:            cfn,
+		extensionsManager: extMgr,
+	}
+	stackManager.setPendingUpsert("foo")
+
+	stack := stackManager.AwaitFinalStatus(context.Background(), "foo")
+	assert.Equal(common.StackStatusHookFailed, stack.Status)
+	assert.Contains(stack.StatusReason, "smoke test failed")
+
+	// hooks only run once per upsert
+	stack = stackManager.AwaitFinalStatus(context.Background(), "foo")
+	assert.Equal(cloudformation.StackStatusUpdateComplete, stack.Status)
+	extMgr.AssertNumberOfCalls(t, "AfterStackUpsert", 1)
+}
+
 func TestCloudformationStackManager_ListStacks(t *testing.T) {
 	assert := assert.New(t)
 
@@ -433,7 +515,7 @@ func TestStack_UpsertStack_CreatePolicy(t *testing.T) {
 		cfnAPI:            cfn,
 		extensionsManager: extMgr,
 	}
-	err := stackManager.UpsertStack(stackName, templateName, templateData, nil, nil, policy, "")
+	err := stackManager.UpsertStack(context.Background(), stackName, templateName, templateData, nil, nil, policy, "")
 
 	assert.Nil(err)
 	cfn.AssertExpectations(t)
@@ -467,7 +549,7 @@ func TestStack_UpsertStack_CreatePolicyAllowDataLoss(t *testing.T) {
 		extensionsManager: extMgr,
 		allowDataLoss:     true,
 	}
-	err := stackManager.UpsertStack(stackName, templateName, templateData, nil, nil, policy, "")
+	err := stackManager.UpsertStack(context.Background(), stackName, templateName, templateData, nil, nil, policy, "")
 
 	assert.Nil(err)
 	cfn.AssertExpectations(t)
@@ -496,7 +578,7 @@ func TestStack_UpsertStack_UpdatePolicy(t *testing.T) {
 		cfnAPI:            cfn,
 		extensionsManager: extMgr,
 	}
-	err := stackManager.UpsertStack(stackName, templateName, templateData, nil, nil, policy, "")
+	err := stackManager.UpsertStack(context.Background(), stackName, templateName, templateData, nil, nil, policy, "")
 
 	assert.Nil(err)
 	cfn.AssertExpectations(t)
@@ -532,7 +614,7 @@ func TestStack_UpsertStack_UpdatePolicyAllowDataLoss(t *testing.T) {
 
 	policy, _ := templates.GetAsset(common.TemplatePolicyDefault)
 
-	err := stackManager.UpsertStack(stackName, templateName, templateData, nil, nil, policy, "")
+	err := stackManager.UpsertStack(context.Background(), stackName, templateName, templateData, nil, nil, policy, "")
 
 	assert.Nil(err)
 	cfn.AssertExpectations(t)
@@ -566,6 +648,7 @@ func mockNilExtensionManager() *mockedExtensionsManager {
 	extMgr.On("DecorateStackTemplate").Return()
 	extMgr.On("DecorateStackParameters").Return()
 	extMgr.On("DecorateStackTags").Return()
+	extMgr.On("BeforeStackUpsert").Return(nil)
 	return extMgr
 }
 
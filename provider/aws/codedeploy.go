@@ -0,0 +1,108 @@
+package aws
+
+import (
+	"fmt"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/codedeploy"
+	"github.com/aws/aws-sdk-go/service/codedeploy/codedeployiface"
+	"github.com/stelligent/mu/common"
+)
+
+// ecsAppSpecFormat is the AppSpec for replacing the task definition of the ECS service
+const ecsAppSpecFormat = `version: 0.0
+Resources:
+- TargetService:
+    Type: AWS::ECS::Service
+    Properties:
+      TaskDefinition: "%s"
+      LoadBalancerInfo:
+        ContainerName: "%s"
+        ContainerPort: %d
+`
+
+type codedeployManager struct {
+	codedeployAPI codedeployiface.CodeDeployAPI
+	dryrun        bool
+}
+
+func newDeploymentManager(sess *session.Session, dryrun bool) (common.DeploymentManager, error) {
+	log.Debug("Connecting to CodeDeploy service")
+	codedeployAPI := codedeploy.New(sess)
+
+	return &codedeployManager{
+		codedeployAPI: codedeployAPI,
+		dryrun:        dryrun,
+	}, nil
+}
+
+// CreateEcsDeployment starts a deployment of the task definition to the ECS service of the deployment group
+func (codedeployMgr *codedeployManager) CreateEcsDeployment(applicationName string, deploymentGroupName string, taskDefinitionArn string, containerName string, containerPort int) (string, error) {
+	if codedeployMgr.dryrun {
+		log.Infof("DRYRUN: Skipping deployment of '%s' to '%s'", taskDefinitionArn, deploymentGroupName)
+		return "", nil
+	}
+
+	appSpec := fmt.Sprintf(ecsAppSpecFormat, taskDefinitionArn, containerName, containerPort)
+
+	log.Debugf("Creating deployment of '%s' to '%s'", taskDefinitionArn, deploymentGroupName)
+	out, err := codedeployMgr.codedeployAPI.CreateDeployment(&codedeploy.CreateDeploymentInput{
+		ApplicationName:     aws.String(applicationName),
+		DeploymentGroupName: aws.String(deploymentGroupName),
+		Revision: &codedeploy.RevisionLocation{
+			RevisionType: aws.String(codedeploy.RevisionLocationTypeAppSpecContent),
+			AppSpecContent: &codedeploy.AppSpecContent{
+				Content: aws.String(appSpec),
+			},
+		},
+	})
+	if err != nil {
+		return "", err
+	}
+	return aws.StringValue(out.DeploymentId), nil
+}
+
+// GetEcsDeployment gets the status of a deployment, and the percent of traffic that has been shifted to the replacement task set
+func (codedeployMgr *codedeployManager) GetEcsDeployment(deploymentID string) (*common.EcsDeployment, error) {
+	out, err := codedeployMgr.codedeployAPI.GetDeployment(&codedeploy.GetDeploymentInput{
+		DeploymentId: aws.String(deploymentID),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	deployment := &common.EcsDeployment{
+		ID:     deploymentID,
+		Status: aws.StringValue(out.DeploymentInfo.Status),
+	}
+	if out.DeploymentInfo.ErrorInformation != nil {
+		deployment.StatusMessage = fmt.Sprintf("%s: %s", aws.StringValue(out.DeploymentInfo.ErrorInformation.Code), aws.StringValue(out.DeploymentInfo.ErrorInformation.Message))
+	}
+
+	targets, err := codedeployMgr.codedeployAPI.ListDeploymentTargets(&codedeploy.ListDeploymentTargetsInput{
+		DeploymentId: aws.String(deploymentID),
+	})
+	if err != nil {
+		return nil, err
+	}
+	for _, targetID := range targets.TargetIds {
+		target, err := codedeployMgr.codedeployAPI.GetDeploymentTarget(&codedeploy.GetDeploymentTargetInput{
+			DeploymentId: aws.String(deploymentID),
+			TargetId:     targetID,
+		})
+		if err != nil {
+			return nil, err
+		}
+		if target.DeploymentTarget.EcsTarget == nil {
+			continue
+		}
+		for _, taskSet := range target.DeploymentTarget.EcsTarget.TaskSetsInfo {
+			if aws.StringValue(taskSet.TaskSetLabel) == codedeploy.TargetLabelGreen {
+				deployment.TrafficWeight = aws.Float64Value(taskSet.TrafficWeight)
+			}
+		}
+	}
+
+	return deployment, nil
+}
@@ -0,0 +1,100 @@
+package aws
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/codedeploy"
+	"github.com/aws/aws-sdk-go/service/codedeploy/codedeployiface"
+	"github.com/stelligent/mu/common"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type mockedCodeDeploy struct {
+	mock.Mock
+	codedeployiface.CodeDeployAPI
+}
+
+func (m *mockedCodeDeploy) CreateDeployment(input *codedeploy.CreateDeploymentInput) (*codedeploy.CreateDeploymentOutput, error) {
+	args := m.Called(input)
+	return args.Get(0).(*codedeploy.CreateDeploymentOutput), args.Error(1)
+}
+
+func (m *mockedCodeDeploy) GetDeployment(input *codedeploy.GetDeploymentInput) (*codedeploy.GetDeploymentOutput, error) {
+	args := m.Called(aws.StringValue(input.DeploymentId))
+	return args.Get(0).(*codedeploy.GetDeploymentOutput), args.Error(1)
+}
+
+func (m *mockedCodeDeploy) ListDeploymentTargets(input *codedeploy.ListDeploymentTargetsInput) (*codedeploy.ListDeploymentTargetsOutput, error) {
+	args := m.Called(aws.StringValue(input.DeploymentId))
+	return args.Get(0).(*codedeploy.ListDeploymentTargetsOutput), args.Error(1)
+}
+
+func (m *mockedCodeDeploy) GetDeploymentTarget(input *codedeploy.GetDeploymentTargetInput) (*codedeploy.GetDeploymentTargetOutput, error) {
+	args := m.Called(aws.StringValue(input.TargetId))
+	return args.Get(0).(*codedeploy.GetDeploymentTargetOutput), args.Error(1)
+}
+
+func TestCodeDeployManager_CreateEcsDeployment(t *testing.T) {
+	assert := assert.New(t)
+
+	m := new(mockedCodeDeploy)
+	m.On("CreateDeployment", mock.MatchedBy(func(input *codedeploy.CreateDeploymentInput) bool {
+		content := aws.StringValue(input.Revision.AppSpecContent.Content)
+		return aws.StringValue(input.ApplicationName) == "mu-service-foo-dev" &&
+			aws.StringValue(input.Revision.RevisionType) == codedeploy.RevisionLocationTypeAppSpecContent &&
+			assert.Contains(content, `TaskDefinition: "arn:aws:ecs:us-east-1:1234:task-definition/mu-service-foo-dev:2"`) &&
+			assert.Contains(content, `ContainerName: "foo"`) &&
+			assert.Contains(content, "ContainerPort: 8080")
+	})).Return(&codedeploy.CreateDeploymentOutput{DeploymentId: aws.String("d-1234")}, nil)
+
+	codedeployMgr := codedeployManager{
+		codedeployAPI: m,
+	}
+
+	deploymentID, err := codedeployMgr.CreateEcsDeployment("mu-service-foo-dev", "mu-service-foo-dev", "arn:aws:ecs:us-east-1:1234:task-definition/mu-service-foo-dev:2", "foo", 8080)
+	assert.Nil(err)
+	assert.Equal("d-1234", deploymentID)
+
+	m.AssertExpectations(t)
+}
+
+func TestCodeDeployManager_GetEcsDeployment(t *testing.T) {
+	assert := assert.New(t)
+
+	m := new(mockedCodeDeploy)
+	m.On("GetDeployment", "d-1234").Return(&codedeploy.GetDeploymentOutput{
+		DeploymentInfo: &codedeploy.DeploymentInfo{
+			Status: aws.String(codedeploy.DeploymentStatusInProgress),
+		},
+	}, nil)
+	m.On("ListDeploymentTargets", "d-1234").Return(&codedeploy.ListDeploymentTargetsOutput{
+		TargetIds: []*string{aws.String("cluster:service")},
+	}, nil)
+	m.On("GetDeploymentTarget", "cluster:service").Return(&codedeploy.GetDeploymentTargetOutput{
+		DeploymentTarget: &codedeploy.DeploymentTarget{
+			EcsTarget: &codedeploy.ECSTarget{
+				TaskSetsInfo: []*codedeploy.ECSTaskSet{
+					{TaskSetLabel: aws.String(codedeploy.TargetLabelBlue), TrafficWeight: aws.Float64(90)},
+					{TaskSetLabel: aws.String(codedeploy.TargetLabelGreen), TrafficWeight: aws.Float64(10)},
+				},
+			},
+		},
+	}, nil)
+
+	codedeployMgr := codedeployManager{
+		codedeployAPI: m,
+	}
+
+	deployment, err := codedeployMgr.GetEcsDeployment("d-1234")
+	assert.Nil(err)
+	assert.Equal(&common.EcsDeployment{
+		ID:            "d-1234",
+		Status:        common.EcsDeploymentInProgress,
+		TrafficWeight: 10,
+	}, deployment)
+	assert.False(deployment.IsComplete())
+
+	m.AssertExpectations(t)
+}
@@ -6,27 +6,31 @@ var log = logging.MustGetLogger("aws")
 
 // Constants to prevent multiple updates when making changes.
 const (
-	Zero                        = 0
-	Empty                       = ""
-	ForwardSlash                = "/"
-	ECSServiceNameParameterKey  = "ServiceName"
-	ListServices                = "ListServices"
-	ListTasks                   = "ListTasks"
-	DescribeTasks               = "DescribeTasks"
-	DescribeContainerInstances  = "DescribeContainerInstances"
-	ECSTaskDefinitionOutputKey  = "MicroserviceTaskDefinitionArn"
-	ECSClusterOutputKey         = "EcsCluster"
-	SvcCmdStackLog              = "Getting stack '%s'..."
-	EcsConnectionLog            = "Connecting to ECS service"
-	ExecuteCommandStartLog      = "Executing command '[%s]' on environment '%s' for service '%s'\n"
-	ExecuteCommandFinishLog     = "Command execution complete\n"
-	ExecuteECSInputParameterLog = "Environment: %s, Service: %s, Cluster: %s, Task: %s"
-	ExecuteECSInputContentsLog  = "ECS Input Contents: %s\n"
-	ExecuteECSResultContentsLog = "ECS Result Contents: %s, %s\n"
-	SvcGetTaskInfoLog           = "Getting task info for task: %s"
-	SvcTaskDetailLog            = "Task Detail: %s"
-	SvcListTasksLog             = "Listing tasks for Environment: %s, Cluster: %s, Service: %s"
-	TaskARNSeparator            = ForwardSlash
+	Zero                          = 0
+	Empty                         = ""
+	ForwardSlash                  = "/"
+	ECSServiceNameParameterKey    = "ServiceName"
+	ListServices                  = "ListServices"
+	ListTasks                     = "ListTasks"
+	DescribeTasks                 = "DescribeTasks"
+	DescribeContainerInstances    = "DescribeContainerInstances"
+	ECSTaskDefinitionOutputKey    = "MicroserviceTaskDefinitionArn"
+	ECSTaskDefinitionResourceName = "MicroserviceTaskDefinition"
+	ECSClusterOutputKey           = "EcsCluster"
+	SvcCmdStackLog                = "Getting stack '%s'..."
+	EcsConnectionLog              = "Connecting to ECS service"
+	ExecuteCommandStartLog        = "Executing command '[%s]' on environment '%s' for service '%s'\n"
+	ExecuteCommandFinishLog       = "Command execution complete\n"
+	ExecuteECSInputParameterLog   = "Environment: %s, Service: %s, Cluster: %s, Task: %s"
+	ExecuteECSInputContentsLog    = "ECS Input Contents: %s\n"
+	ExecuteECSResultContentsLog   = "ECS Result Contents: %s, %s\n"
+	SvcGetTaskInfoLog             = "Getting task info for task: %s"
+	SvcTaskDetailLog              = "Task Detail: %s"
+	SvcListTasksLog               = "Listing tasks for Environment: %s, Cluster: %s, Service: %s"
+	SvcListRevisionsLog           = "Listing revisions from stack events of '%s'"
+	SvcSkipRevisionLog            = "Skipping revision '%s', unable to describe task definition: %v"
+	SvcMaxRevisions               = 25
+	TaskARNSeparator              = ForwardSlash
 )
 
 // Constants used during testing
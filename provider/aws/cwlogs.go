@@ -1,6 +1,7 @@
 package aws
 
 import (
+	"context"
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/aws/session"
 	"github.com/aws/aws-sdk-go/service/cloudwatchlogs"
@@ -23,7 +24,7 @@ func newLogsManager(sess *session.Session) (common.LogsManager, error) {
 }
 
 // ViewLogs view the logs in CW
-func (logsMgr *logsManager) ViewLogs(logGroup string, searchDuration time.Duration, follow bool, filter string, callback func(string, string, int64)) error {
+func (logsMgr *logsManager) ViewLogs(ctx context.Context, logGroup string, searchDuration time.Duration, follow bool, filter string, callback func(string, string, int64)) error {
 	logsAPI := logsMgr.logsAPI
 
 	startTime := time.Now().Add(-searchDuration).Unix() * 1000
@@ -56,7 +57,11 @@ func (logsMgr *logsManager) ViewLogs(logGroup string, searchDuration time.Durati
 		if !follow {
 			break
 		}
-		time.Sleep(5 * time.Second)
+
+		// following stops once the workflow is interrupted
+		if common.SleepWithContext(ctx, 5*time.Second) != nil {
+			break
+		}
 	}
 
 	return nil
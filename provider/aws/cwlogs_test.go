@@ -1,6 +1,7 @@
 package aws
 
 import (
+	"context"
 	"testing"
 	"time"
 
@@ -52,10 +53,29 @@ func TestLogsManager_ViewLogs(t *testing.T) {
 
 	searchDuration := 30 * time.Second
 
-	err := lm.ViewLogs("foo", searchDuration, false, "", cb)
+	err := lm.ViewLogs(context.Background(), "foo", searchDuration, false, "", cb)
 	assert.Nil(err)
 	assert.Equal(2, events)
 
 	m.AssertExpectations(t)
 	m.AssertNumberOfCalls(t, "FilterLogEventsPages", 1)
 }
+
+func TestLogsManager_ViewLogs_FollowInterrupted(t *testing.T) {
+	assert := assert.New(t)
+
+	m := new(mockedCwLogs)
+	m.On("FilterLogEventsPages", mock.AnythingOfType("*cloudwatchlogs.FilterLogEventsInput"), mock.AnythingOfType("func(*cloudwatchlogs.FilterLogEventsOutput, bool) bool")).
+		Return(nil)
+
+	lm := logsManager{
+		logsAPI: m,
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	err := lm.ViewLogs(ctx, "foo", 30*time.Second, true, "", func(string, string, int64) {})
+	assert.Nil(err)
+
+	m.AssertNumberOfCalls(t, "FilterLogEventsPages", 1)
+}
@@ -0,0 +1,104 @@
+package aws
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/cloudformation"
+	"github.com/stelligent/mu/common"
+)
+
+// DetectStackDrift starts detecting drift of the stack, returning the id of the detection to await
+func (cfnMgr *cloudformationStackManager) DetectStackDrift(stackName string) (string, error) {
+	log.Debugf("Detecting drift of stack '%s'", stackName)
+	resp, err := cfnMgr.cfnAPI.DetectStackDrift(&cloudformation.DetectStackDriftInput{
+		StackName: aws.String(stackName),
+	})
+	if err != nil {
+		return "", err
+	}
+	return aws.StringValue(resp.StackDriftDetectionId), nil
+}
+
+// AwaitStackDrift waits for the drift detection to complete, and describes the resources of the stack that drifted
+func (cfnMgr *cloudformationStackManager) AwaitStackDrift(ctx context.Context, stackName string, detectionID string) (*common.StackDrift, error) {
+	params := &cloudformation.DescribeStackDriftDetectionStatusInput{
+		StackDriftDetectionId: aws.String(detectionID),
+	}
+	for {
+		resp, err := cfnMgr.cfnAPI.DescribeStackDriftDetectionStatus(params)
+		if err != nil {
+			return nil, err
+		}
+
+		detectionStatus := aws.StringValue(resp.DetectionStatus)
+		if detectionStatus != cloudformation.StackDriftDetectionStatusDetectionInProgress {
+			drift := &common.StackDrift{
+				StackName:     stackName,
+				Status:        aws.StringValue(resp.StackDriftStatus),
+				StatusReason:  aws.StringValue(resp.DetectionStatusReason),
+				DetectionTime: aws.TimeValue(resp.Timestamp),
+				Resources:     make([]common.StackResourceDrift, 0),
+			}
+
+			// a failed detection still reports the resources it found drifted, otherwise the stack is unknown
+			if detectionStatus == cloudformation.StackDriftDetectionStatusDetectionFailed && drift.Status != common.StackDriftStatusDrifted {
+				drift.Status = common.StackDriftStatusUnknown
+			}
+			if drift.Status == common.StackDriftStatusDrifted {
+				drift.Resources, err = cfnMgr.describeResourceDrifts(stackName)
+				if err != nil {
+					return nil, err
+				}
+			}
+			return drift, nil
+		}
+
+		log.Debugf("  Drift detection of stack '%s' is in progress", stackName)
+		if common.SleepWithContext(ctx, time.Second*5) != nil {
+			return nil, fmt.Errorf("Stopped waiting on drift detection of stack '%s'", stackName)
+		}
+	}
+}
+
+func (cfnMgr *cloudformationStackManager) describeResourceDrifts(stackName string) ([]common.StackResourceDrift, error) {
+	resources := make([]common.StackResourceDrift, 0)
+	params := &cloudformation.DescribeStackResourceDriftsInput{
+		StackName: aws.String(stackName),
+		StackResourceDriftStatusFilters: aws.StringSlice([]string{
+			cloudformation.StackResourceDriftStatusModified,
+			cloudformation.StackResourceDriftStatusDeleted,
+		}),
+	}
+	for {
+		resp, err := cfnMgr.cfnAPI.DescribeStackResourceDrifts(params)
+		if err != nil {
+			return nil, err
+		}
+		for _, resourceDrift := range resp.StackResourceDrifts {
+			resource := common.StackResourceDrift{
+				LogicalID:    aws.StringValue(resourceDrift.LogicalResourceId),
+				PhysicalID:   aws.StringValue(resourceDrift.PhysicalResourceId),
+				ResourceType: aws.StringValue(resourceDrift.ResourceType),
+				Status:       aws.StringValue(resourceDrift.StackResourceDriftStatus),
+				Differences:  make([]common.StackPropertyDifference, 0, len(resourceDrift.PropertyDifferences)),
+			}
+			for _, difference := range resourceDrift.PropertyDifferences {
+				resource.Differences = append(resource.Differences, common.StackPropertyDifference{
+					PropertyPath:   aws.StringValue(difference.PropertyPath),
+					DifferenceType: aws.StringValue(difference.DifferenceType),
+					ExpectedValue:  aws.StringValue(difference.ExpectedValue),
+					ActualValue:    aws.StringValue(difference.ActualValue),
+				})
+			}
+			resources = append(resources, resource)
+		}
+		if aws.StringValue(resp.NextToken) == "" {
+			break
+		}
+		params.NextToken = resp.NextToken
+	}
+	return resources, nil
+}
@@ -0,0 +1,90 @@
+package aws
+
+import (
+	"context"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/cloudformation"
+	"github.com/stelligent/mu/common"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStack_DetectStackDrift(t *testing.T) {
+	assert := assert.New(t)
+
+	cfn := new(mockedCloudFormation)
+	cfn.On("DetectStackDrift", "mu-vpc-dev").Return(
+		&cloudformation.DetectStackDriftOutput{
+			StackDriftDetectionId: aws.String("detection-1"),
+		}, nil)
+	cfn.On("DescribeStackDriftDetectionStatus", "detection-1").Return(
+		&cloudformation.DescribeStackDriftDetectionStatusOutput{
+			DetectionStatus:  aws.String(cloudformation.StackDriftDetectionStatusDetectionComplete),
+			StackDriftStatus: aws.String(cloudformation.StackDriftStatusDrifted),
+		}, nil)
+	cfn.On("DescribeStackResourceDrifts", "mu-vpc-dev").Return(
+		&cloudformation.DescribeStackResourceDriftsOutput{
+			StackResourceDrifts: []*cloudformation.StackResourceDrift{
+				{
+					LogicalResourceId:        aws.String("InstanceSecurityGroup"),
+					PhysicalResourceId:       aws.String("sg-123"),
+					ResourceType:             aws.String("AWS::EC2::SecurityGroup"),
+					StackResourceDriftStatus: aws.String(cloudformation.StackResourceDriftStatusModified),
+					PropertyDifferences: []*cloudformation.PropertyDifference{
+						{
+							PropertyPath:   aws.String("/SecurityGroupIngress/0/CidrIp"),
+							DifferenceType: aws.String(cloudformation.DifferenceTypeNotEqual),
+							ExpectedValue:  aws.String("10.0.0.0/16"),
+							ActualValue:    aws.String("0.0.0.0/0"),
+						},
+					},
+				},
+			},
+		}, nil)
+
+	stackManager := cloudformationStackManager{
+		cfnAPI: cfn,
+	}
+
+	detectionID, err := stackManager.DetectStackDrift("mu-vpc-dev")
+	assert.Nil(err)
+	assert.Equal("detection-1", detectionID)
+
+	drift, err := stackManager.AwaitStackDrift(context.Background(), "mu-vpc-dev", detectionID)
+	assert.Nil(err)
+	assert.Equal(common.StackDriftStatusDrifted, drift.Status)
+	assert.Equal(1, len(drift.Resources))
+	assert.Equal("InstanceSecurityGroup", drift.Resources[0].LogicalID)
+	assert.Equal(common.StackResourceDriftStatusModified, drift.Resources[0].Status)
+	assert.Equal(1, len(drift.Resources[0].Differences))
+	assert.Equal("/SecurityGroupIngress/0/CidrIp", drift.Resources[0].Differences[0].PropertyPath)
+	assert.Equal("0.0.0.0/0", drift.Resources[0].Differences[0].ActualValue)
+
+	cfn.AssertExpectations(t)
+}
+
+func TestStack_AwaitStackDrift_Failed(t *testing.T) {
+	assert := assert.New(t)
+
+	cfn := new(mockedCloudFormation)
+	cfn.On("DescribeStackDriftDetectionStatus", "detection-1").Return(
+		&cloudformation.DescribeStackDriftDetectionStatusOutput{
+			DetectionStatus:       aws.String(cloudformation.StackDriftDetectionStatusDetectionFailed),
+			DetectionStatusReason: aws.String("Failed to detect drift on resources"),
+			StackDriftStatus:      aws.String(cloudformation.StackDriftStatusInSync),
+		}, nil)
+
+	stackManager := cloudformationStackManager{
+		cfnAPI: cfn,
+	}
+
+	drift, err := stackManager.AwaitStackDrift(context.Background(), "mu-vpc-dev", "detection-1")
+	assert.Nil(err)
+	assert.Equal(common.StackDriftStatusUnknown, drift.Status)
+	assert.Equal("Failed to detect drift on resources", drift.StatusReason)
+	assert.Empty(drift.Resources)
+
+	cfn.AssertExpectations(t)
+	cfn.AssertNotCalled(t, "DescribeStackResourceDrifts", "mu-vpc-dev")
+}
@@ -5,6 +5,7 @@ import (
 	"strings"
 
 	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
 	"github.com/aws/aws-sdk-go/aws/session"
 	"github.com/aws/aws-sdk-go/service/ecr"
 	"github.com/aws/aws-sdk-go/service/ecr/ecriface"
@@ -102,3 +103,45 @@ func (ecsMgr *ecsClusterManager) DeleteRepository(repoName string) error {
 	})
 	return nil
 }
+
+// TagRepositoryImage puts the manifest of the image with the existing tag under the new tag, returns false if there is no image with the existing tag
+func (ecsMgr *ecsClusterManager) TagRepositoryImage(repoName string, existingTag string, newTag string) (bool, error) {
+	ecrAPI := ecsMgr.ecrAPI
+
+	resp, err := ecrAPI.BatchGetImage(&ecr.BatchGetImageInput{
+		RepositoryName: aws.String(repoName),
+		ImageIds: []*ecr.ImageIdentifier{
+			{
+				ImageTag: aws.String(existingTag),
+			},
+		},
+	})
+	if err != nil {
+		if awsErr, ok := err.(awserr.Error); ok && awsErr.Code() == ecr.ErrCodeRepositoryNotFoundException {
+			return false, nil
+		}
+		return false, err
+	}
+	if len(resp.Images) == 0 {
+		return false, nil
+	}
+
+	if ecsMgr.dryrun {
+		log.Infof("  DRYRUN: Skipping tag of image '%s:%s' as '%s'", repoName, existingTag, newTag)
+		return true, nil
+	}
+
+	log.Infof("  Tagging image '%s:%s' as '%s'", repoName, existingTag, newTag)
+	_, err = ecrAPI.PutImage(&ecr.PutImageInput{
+		RepositoryName: aws.String(repoName),
+		ImageManifest:  resp.Images[0].ImageManifest,
+		ImageTag:       aws.String(newTag),
+	})
+	if err != nil {
+		if awsErr, ok := err.(awserr.Error); ok && awsErr.Code() == ecr.ErrCodeImageAlreadyExistsException {
+			return true, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
@@ -108,3 +108,43 @@ func TestEcsClusterManager_AuthenticateRepository(t *testing.T) {
 	m.AssertNumberOfCalls(t, "GetAuthorizationToken", 4)
 
 }
+
+func (m *mockedECR) BatchGetImage(input *ecr.BatchGetImageInput) (*ecr.BatchGetImageOutput, error) {
+	args := m.Called(aws.StringValue(input.ImageIds[0].ImageTag))
+	return args.Get(0).(*ecr.BatchGetImageOutput), args.Error(1)
+}
+func (m *mockedECR) PutImage(input *ecr.PutImageInput) (*ecr.PutImageOutput, error) {
+	args := m.Called(aws.StringValue(input.ImageManifest), aws.StringValue(input.ImageTag))
+	return args.Get(0).(*ecr.PutImageOutput), args.Error(1)
+}
+
+func TestEcsClusterManager_TagRepositoryImage(t *testing.T) {
+	assert := assert.New(t)
+
+	m := new(mockedECR)
+	m.On("BatchGetImage", "src-abc").Return(
+		&ecr.BatchGetImageOutput{
+			Images: []*ecr.Image{
+				{
+					ImageManifest: aws.String("{}"),
+				},
+			},
+		}, nil)
+	m.On("BatchGetImage", "src-def").Return(&ecr.BatchGetImageOutput{}, nil)
+	m.On("PutImage", "{}", "1234").Return(&ecr.PutImageOutput{}, nil)
+
+	clusterManager := ecsClusterManager{
+		ecrAPI: m,
+	}
+
+	found, err := clusterManager.TagRepositoryImage("mu-api", "src-abc", "1234")
+	assert.Nil(err)
+	assert.True(found)
+
+	found, err = clusterManager.TagRepositoryImage("mu-api", "src-def", "1234")
+	assert.Nil(err)
+	assert.False(found)
+
+	m.AssertExpectations(t)
+	m.AssertNumberOfCalls(t, "PutImage", 1)
+}
@@ -1,12 +1,10 @@
 package aws
 
 import (
-	"bufio"
 	"encoding/base64"
 	"encoding/json"
 	"fmt"
 	"os"
-	"strings"
 	"time"
 
 	"k8s.io/apimachinery/pkg/api/errors"
@@ -31,6 +29,7 @@ import (
 const (
 	v1Prefix        = "k8s-aws-v1."
 	clusterIDHeader = "x-k8s-aws-id"
+	tokenExpiration = 14 * time.Minute
 )
 
 type eksKubernetesResourceManagerProvider struct {
@@ -77,9 +76,6 @@ func dryRunRestConfig() dynamic.Interface {
 
 // GetResourceManager get a connection to eks cluster
 func (eksMgrProvider *eksKubernetesResourceManagerProvider) GetResourceManager(name string) (common.KubernetesResourceManager, error) {
-	eksAPI := eksMgrProvider.eksAPI
-	stsAPI := eksMgrProvider.stsAPI
-
 	if eksMgrProvider.dryrunPath != "" {
 		return &eksKubernetesResourceManager{
 			name:              name,
@@ -89,36 +85,28 @@ func (eksMgrProvider *eksKubernetesResourceManagerProvider) GetResourceManager(n
 		}, nil
 	}
 
-	resp, err := eksAPI.DescribeCluster(&eks.DescribeClusterInput{
-		Name: aws.String(name),
-	})
-
+	cluster, err := eksMgrProvider.DescribeKubernetesCluster(name)
 	if err != nil {
 		return nil, err
 	}
 
-	certData, err := base64.StdEncoding.DecodeString(aws.StringValue(resp.Cluster.CertificateAuthority.Data))
+	certData, err := base64.StdEncoding.DecodeString(cluster.CertificateAuthorityData)
 	if err != nil {
 		return nil, err
 	}
 
-	request, _ := stsAPI.GetCallerIdentityRequest(&sts.GetCallerIdentityInput{})
-	request.HTTPRequest.Header.Add(clusterIDHeader, name)
-
-	// sign the request
-	presignedURLString, err := request.Presign(60 * time.Second)
+	token, err := eksMgrProvider.GetKubernetesToken(name)
 	if err != nil {
 		return nil, err
 	}
-	token := v1Prefix + base64.RawURLEncoding.EncodeToString([]byte(presignedURLString))
 
 	k8sClientConfig := &rest.Config{
-		Host: aws.StringValue(resp.Cluster.Endpoint),
+		Host: cluster.Endpoint,
 		TLSClientConfig: rest.TLSClientConfig{
 			CAData:   certData,
 			Insecure: false,
 		},
-		BearerToken: token,
+		BearerToken: token.Token,
 	}
 
 	client, err := dynamic.NewForConfig(k8sClientConfig)
@@ -134,6 +122,40 @@ func (eksMgrProvider *eksKubernetesResourceManagerProvider) GetResourceManager(n
 	}, nil
 }
 
+// DescribeKubernetesCluster gets the endpoint and certificate authority of an eks cluster
+func (eksMgrProvider *eksKubernetesResourceManagerProvider) DescribeKubernetesCluster(name string) (*common.KubernetesCluster, error) {
+	resp, err := eksMgrProvider.eksAPI.DescribeCluster(&eks.DescribeClusterInput{
+		Name: aws.String(name),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &common.KubernetesCluster{
+		Name:                     name,
+		Endpoint:                 aws.StringValue(resp.Cluster.Endpoint),
+		CertificateAuthorityData: aws.StringValue(resp.Cluster.CertificateAuthority.Data),
+	}, nil
+}
+
+// GetKubernetesToken creates a token for an eks cluster from a presigned sts GetCallerIdentity request, like aws-iam-authenticator does
+func (eksMgrProvider *eksKubernetesResourceManagerProvider) GetKubernetesToken(name string) (*common.KubernetesToken, error) {
+	request, _ := eksMgrProvider.stsAPI.GetCallerIdentityRequest(&sts.GetCallerIdentityInput{})
+	request.HTTPRequest.Header.Add(clusterIDHeader, name)
+
+	// sign the request
+	presignedURLString, err := request.Presign(60 * time.Second)
+	if err != nil {
+		return nil, err
+	}
+
+	return &common.KubernetesToken{
+		Token: v1Prefix + base64.RawURLEncoding.EncodeToString([]byte(presignedURLString)),
+		// the cluster accepts the token for 15 minutes, expire it early to account for clock skew
+		Expiration: time.Now().Add(tokenExpiration),
+	}, nil
+}
+
 // UpsertResources for create/update of resources in k8s cluster
 func (eksMgr *eksKubernetesResourceManager) UpsertResources(templateName string,
 	templateData interface{}) error {
@@ -151,29 +173,23 @@ func (eksMgr *eksKubernetesResourceManager) UpsertResources(templateName string,
 		return err
 	}
 
-	scanner := bufio.NewScanner(strings.NewReader(templateBody))
-	var b strings.Builder
-	for scanner.Scan() {
-		if scanner.Text() == "---" {
-			// flush current resource
-			if b.Len() > 0 {
-				err = eksMgr.upsertResource(b.String())
-				if err != nil {
-					return err
-				}
-				b.Reset()
-			}
-		} else {
-			// append to current resource
-			_, err = fmt.Fprintln(&b, scanner.Text())
-			if err != nil {
-				return err
-			}
-		}
+	resources, err := common.ParseKubernetesResources(templateBody)
+	if err != nil {
+		return err
 	}
 
-	if b.Len() > 0 {
-		err = eksMgr.upsertResource(b.String())
+	// let extensions patch the rendered resources and add their own
+	resources, err = eksMgr.extensionsManager.DecorateKubernetesResources(eksMgr.name, resources)
+	if err != nil {
+		return err
+	}
+
+	for _, resource := range resources {
+		resourceBody, err := yaml.Marshal(resource)
+		if err != nil {
+			return err
+		}
+		err = eksMgr.upsertResource(string(resourceBody))
 		if err != nil {
 			return err
 		}
@@ -3,9 +3,23 @@ package aws
 import (
 	"testing"
 
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/eks"
+	"github.com/aws/aws-sdk-go/service/eks/eksiface"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
 )
 
+type mockedEKS struct {
+	mock.Mock
+	eksiface.EKSAPI
+}
+
+func (m *mockedEKS) DescribeCluster(input *eks.DescribeClusterInput) (*eks.DescribeClusterOutput, error) {
+	args := m.Called(aws.StringValue(input.Name))
+	return args.Get(0).(*eks.DescribeClusterOutput), args.Error(1)
+}
+
 func TestNewResource(t *testing.T) {
 	deploymentResource :=
 		`
@@ -42,3 +56,26 @@ spec:
 	assert.Equal("hello-go", resourceStub.Metadata.Namespace)
 
 }
+
+func TestEksKubernetesResourceManagerProvider_DescribeKubernetesCluster(t *testing.T) {
+	assert := assert.New(t)
+
+	m := new(mockedEKS)
+	m.On("DescribeCluster", "mu-environment-dev").Return(&eks.DescribeClusterOutput{
+		Cluster: &eks.Cluster{
+			Endpoint: aws.String("https://ABCDEF.yl4.us-east-1.eks.amazonaws.com"),
+			CertificateAuthority: &eks.Certificate{
+				Data: aws.String("Y2VydA=="),
+			},
+		},
+	}, nil)
+
+	provider := &eksKubernetesResourceManagerProvider{eksAPI: m}
+	cluster, err := provider.DescribeKubernetesCluster("mu-environment-dev")
+
+	assert.Nil(err)
+	assert.Equal("mu-environment-dev", cluster.Name)
+	assert.Equal("https://ABCDEF.yl4.us-east-1.eks.amazonaws.com", cluster.Endpoint)
+	assert.Equal("Y2VydA==", cluster.CertificateAuthorityData)
+	m.AssertExpectations(t)
+}
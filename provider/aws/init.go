@@ -123,6 +123,12 @@ func initializeManagers(sess *session.Session, ctx *common.Context, dryrunPath s
 		return err
 	}
 
+	// initialize DeploymentManager
+	ctx.DeploymentManager, err = newDeploymentManager(sess, dryrunPath != "")
+	if err != nil {
+		return err
+	}
+
 	// initialize the RolesetManager
 	ctx.RolesetManager, err = newRolesetManager(ctx)
 
@@ -0,0 +1,30 @@
+package aws
+
+import (
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/cloudformation"
+	"github.com/stelligent/mu/common"
+)
+
+// CancelUpdatesOnInterrupt cancels the updates that are still in progress when the context of the wait on a stack is done,
+// so CloudFormation rolls them back instead of leaving them to finish unattended
+func (cfnMgr *cloudformationStackManager) CancelUpdatesOnInterrupt(enabled bool) {
+	cfnMgr.cancelUpdates = enabled
+}
+
+// interruptStack stops waiting on the stack and returns it in its current status, cancelling its update if requested
+func (cfnMgr *cloudformationStackManager) interruptStack(stackName string, stackDetails *cloudformation.Stack) *common.Stack {
+	status := aws.StringValue(stackDetails.StackStatus)
+	if cfnMgr.cancelUpdates && status == cloudformation.StackStatusUpdateInProgress {
+		log.Warningf("Cancelling update of stack '%s'", stackName)
+		_, err := cfnMgr.cfnAPI.CancelUpdateStack(&cloudformation.CancelUpdateStackInput{
+			StackName: aws.String(stackName),
+		})
+		if err != nil {
+			log.Errorf("Unable to cancel update of stack '%s': %v", stackName, err)
+		}
+	} else {
+		log.Warningf("Stopped waiting on stack '%s' in status %s, CloudFormation will finish it", stackName, status)
+	}
+	return buildStack(stackDetails)
+}
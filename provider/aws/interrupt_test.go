@@ -0,0 +1,75 @@
+package aws
+
+import (
+	"context"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/cloudformation"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStack_AwaitFinalStatus_Interrupted(t *testing.T) {
+	assert := assert.New(t)
+
+	cfn := new(mockedCloudFormation)
+	cfn.On("DescribeStacks").Return(
+		&cloudformation.DescribeStacksOutput{
+			Stacks: []*cloudformation.Stack{
+				{
+					StackName:   aws.String("foo"),
+					StackStatus: aws.String(cloudformation.StackStatusUpdateInProgress),
+				},
+			},
+		}, nil)
+	cfn.On("DescribeStackEvents").Return(
+		&cloudformation.DescribeStackEventsOutput{
+			StackEvents: []*cloudformation.StackEvent{},
+		}, nil)
+
+	stackManager := cloudformationStackManager{
+		cfnAPI: cfn,
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	stack := stackManager.AwaitFinalStatus(ctx, "foo")
+
+	assert.Equal(cloudformation.StackStatusUpdateInProgress, stack.Status)
+	cfn.AssertExpectations(t)
+	cfn.AssertNumberOfCalls(t, "DescribeStacks", 1)
+	cfn.AssertNotCalled(t, "CancelUpdateStack")
+}
+
+func TestStack_AwaitFinalStatus_InterruptedCancelUpdate(t *testing.T) {
+	assert := assert.New(t)
+
+	cfn := new(mockedCloudFormation)
+	cfn.On("DescribeStacks").Return(
+		&cloudformation.DescribeStacksOutput{
+			Stacks: []*cloudformation.Stack{
+				{
+					StackName:   aws.String("foo"),
+					StackStatus: aws.String(cloudformation.StackStatusUpdateInProgress),
+				},
+			},
+		}, nil)
+	cfn.On("DescribeStackEvents").Return(
+		&cloudformation.DescribeStackEventsOutput{
+			StackEvents: []*cloudformation.StackEvent{},
+		}, nil)
+	cfn.On("CancelUpdateStack").Return(&cloudformation.CancelUpdateStackOutput{}, nil)
+
+	stackManager := cloudformationStackManager{
+		cfnAPI: cfn,
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	stackManager.CancelUpdatesOnInterrupt(true)
+
+	stack := stackManager.AwaitFinalStatus(ctx, "foo")
+
+	assert.Equal(cloudformation.StackStatusUpdateInProgress, stack.Status)
+	cfn.AssertExpectations(t)
+	cfn.AssertNumberOfCalls(t, "CancelUpdateStack", 1)
+}
@@ -3,23 +3,30 @@ package aws
 import (
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/secretsmanager"
+	"github.com/aws/aws-sdk-go/service/secretsmanager/secretsmanageriface"
 	"github.com/aws/aws-sdk-go/service/ssm"
 	"github.com/aws/aws-sdk-go/service/ssm/ssmiface"
 	"github.com/stelligent/mu/common"
 )
 
 type paramManager struct {
-	ssmAPI ssmiface.SSMAPI
-	dryrun bool
+	ssmAPI            ssmiface.SSMAPI
+	secretsManagerAPI secretsmanageriface.SecretsManagerAPI
+	dryrun            bool
 }
 
 func newParamManager(sess *session.Session, dryrun bool) (common.ParamManager, error) {
 	log.Debug("Connecting to SSM service")
 	ssmAPI := ssm.New(sess)
 
+	log.Debug("Connecting to Secrets Manager service")
+	secretsManagerAPI := secretsmanager.New(sess)
+
 	return &paramManager{
-		dryrun: dryrun,
-		ssmAPI: ssmAPI,
+		dryrun:            dryrun,
+		ssmAPI:            ssmAPI,
+		secretsManagerAPI: secretsManagerAPI,
 	}, nil
 }
 
@@ -120,3 +127,27 @@ func (paramMgr *paramManager) ParamVersion(name string) (int64, error) {
 
 	return *output.Parameters[0].Version, nil
 }
+
+// GetSecret get the value of a secret in Secrets Manager
+func (paramMgr *paramManager) GetSecret(secretID string, versionStage string, versionID string) (string, error) {
+	secretsManagerAPI := paramMgr.secretsManagerAPI
+
+	log.Debug("Getting secret '%s'", secretID)
+
+	input := &secretsmanager.GetSecretValueInput{
+		SecretId: aws.String(secretID),
+	}
+	if versionStage != "" {
+		input.VersionStage = aws.String(versionStage)
+	}
+	if versionID != "" {
+		input.VersionId = aws.String(versionID)
+	}
+
+	output, err := secretsManagerAPI.GetSecretValue(input)
+	if err != nil {
+		return "", err
+	}
+
+	return aws.StringValue(output.SecretString), nil
+}
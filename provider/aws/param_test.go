@@ -4,6 +4,8 @@ import (
 	"testing"
 
 	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/secretsmanager"
+	"github.com/aws/aws-sdk-go/service/secretsmanager/secretsmanageriface"
 	"github.com/aws/aws-sdk-go/service/ssm"
 	"github.com/aws/aws-sdk-go/service/ssm/ssmiface"
 	"github.com/stretchr/testify/assert"
@@ -111,3 +113,31 @@ func TestParamManager_ParamVersionFalse(t *testing.T) {
 	m.AssertExpectations(t)
 	m.AssertNumberOfCalls(t, "DescribeParameters", 1)
 }
+
+type mockedSecretsManager struct {
+	mock.Mock
+	secretsmanageriface.SecretsManagerAPI
+}
+
+func (m *mockedSecretsManager) GetSecretValue(input *secretsmanager.GetSecretValueInput) (*secretsmanager.GetSecretValueOutput, error) {
+	args := m.Called(aws.StringValue(input.SecretId), aws.StringValue(input.VersionStage))
+	return args.Get(0).(*secretsmanager.GetSecretValueOutput), args.Error(1)
+}
+
+func TestParamManager_GetSecret(t *testing.T) {
+	assert := assert.New(t)
+
+	m := new(mockedSecretsManager)
+	m.On("GetSecretValue", "arn:aws:secretsmanager:us-east-1:123456789012:secret:api-key-AbCdEf", "AWSPREVIOUS").Return(&secretsmanager.GetSecretValueOutput{SecretString: aws.String("bar")}, nil)
+
+	paramMgr := paramManager{
+		secretsManagerAPI: m,
+	}
+
+	val, err := paramMgr.GetSecret("arn:aws:secretsmanager:us-east-1:123456789012:secret:api-key-AbCdEf", "AWSPREVIOUS", "")
+	assert.Nil(err)
+	assert.Equal("bar", val)
+
+	m.AssertExpectations(t)
+	m.AssertNumberOfCalls(t, "GetSecretValue", 1)
+}
@@ -1,6 +1,7 @@
 package aws
 
 import (
+	"context"
 	"fmt"
 	"strconv"
 	"strings"
@@ -19,9 +20,9 @@ func newRolesetManager(ctx *common.Context) (common.RolesetManager, error) {
 	}, nil
 }
 
-func (rolesetMgr *iamRolesetManager) getRolesetFromStack(names ...string) common.Roleset {
+func (rolesetMgr *iamRolesetManager) getRolesetFromStack(ctx context.Context, names ...string) common.Roleset {
 	stackName := common.CreateStackName(rolesetMgr.context.Config.Namespace, common.StackTypeIam, names...)
-	stack := rolesetMgr.context.StackManager.AwaitFinalStatus(stackName)
+	stack := rolesetMgr.context.StackManager.AwaitFinalStatus(ctx, stackName)
 
 	if stack == nil {
 		return make(map[string]string)
@@ -36,16 +37,16 @@ func overrideRole(roleset common.Roleset, roleType string, roleArn string) {
 	}
 }
 
-func (rolesetMgr *iamRolesetManager) GetCommonRoleset() (common.Roleset, error) {
-	roleset := rolesetMgr.getRolesetFromStack("common")
+func (rolesetMgr *iamRolesetManager) GetCommonRoleset(ctx context.Context) (common.Roleset, error) {
+	roleset := rolesetMgr.getRolesetFromStack(ctx, "common")
 
 	overrideRole(roleset, "CloudFormationRoleArn", rolesetMgr.context.Config.Roles.CloudFormation)
 
 	return roleset, nil
 }
 
-func (rolesetMgr *iamRolesetManager) GetEnvironmentRoleset(environmentName string) (common.Roleset, error) {
-	roleset := rolesetMgr.getRolesetFromStack("environment", environmentName)
+func (rolesetMgr *iamRolesetManager) GetEnvironmentRoleset(ctx context.Context, environmentName string) (common.Roleset, error) {
+	roleset := rolesetMgr.getRolesetFromStack(ctx, "environment", environmentName)
 
 	for _, e := range rolesetMgr.context.Config.Environments {
 		if strings.EqualFold(e.Name, environmentName) {
@@ -58,8 +59,8 @@ func (rolesetMgr *iamRolesetManager) GetEnvironmentRoleset(environmentName strin
 	return roleset, nil
 }
 
-func (rolesetMgr *iamRolesetManager) GetServiceRoleset(environmentName string, serviceName string) (common.Roleset, error) {
-	roleset := rolesetMgr.getRolesetFromStack("service", serviceName, environmentName)
+func (rolesetMgr *iamRolesetManager) GetServiceRoleset(ctx context.Context, environmentName string, serviceName string) (common.Roleset, error) {
+	roleset := rolesetMgr.getRolesetFromStack(ctx, "service", serviceName, environmentName)
 
 	overrideRole(roleset, "DatabaseKeyArn", rolesetMgr.context.Config.Service.Database.GetDatabaseConfig(environmentName).KmsKey)
 	overrideRole(roleset, "EC2InstanceProfileArn", rolesetMgr.context.Config.Service.Roles.Ec2Instance)
@@ -71,8 +72,8 @@ func (rolesetMgr *iamRolesetManager) GetServiceRoleset(environmentName string, s
 	return roleset, nil
 }
 
-func (rolesetMgr *iamRolesetManager) GetPipelineRoleset(serviceName string) (common.Roleset, error) {
-	roleset := rolesetMgr.getRolesetFromStack("pipeline", serviceName)
+func (rolesetMgr *iamRolesetManager) GetPipelineRoleset(ctx context.Context, serviceName string) (common.Roleset, error) {
+	roleset := rolesetMgr.getRolesetFromStack(ctx, "pipeline", serviceName)
 
 	overrideRole(roleset, "CodePipelineKeyArn", rolesetMgr.context.Config.Service.Pipeline.KmsKey)
 	overrideRole(roleset, "CodePipelineRoleArn", rolesetMgr.context.Config.Service.Pipeline.Roles.Pipeline)
@@ -85,7 +86,7 @@ func (rolesetMgr *iamRolesetManager) GetPipelineRoleset(serviceName string) (com
 	return roleset, nil
 }
 
-func (rolesetMgr *iamRolesetManager) UpsertCommonRoleset() error {
+func (rolesetMgr *iamRolesetManager) UpsertCommonRoleset(ctx context.Context) error {
 	if rolesetMgr.context.Config.DisableIAM {
 		log.Infof("Skipping upsert of common IAM roles.")
 		return nil
@@ -100,7 +101,7 @@ func (rolesetMgr *iamRolesetManager) UpsertCommonRoleset() error {
 		"Namespace": rolesetMgr.context.Config.Namespace,
 	}
 
-	err := rolesetMgr.context.StackManager.UpsertStack(stackName, common.TemplateCommonIAM, nil, stackParams, stackTags, "", "")
+	err := rolesetMgr.context.StackManager.UpsertStack(ctx, stackName, common.TemplateCommonIAM, nil, stackParams, stackTags, "", "")
 	if err != nil {
 		// ignore error if stack is in progress already
 		if !strings.Contains(err.Error(), "_IN_PROGRESS state and can not be updated") {
@@ -109,7 +110,7 @@ func (rolesetMgr *iamRolesetManager) UpsertCommonRoleset() error {
 	}
 
 	log.Debugf("Waiting for stack '%s' to complete", stackName)
-	stack := rolesetMgr.context.StackManager.AwaitFinalStatus(stackName)
+	stack := rolesetMgr.context.StackManager.AwaitFinalStatus(ctx, stackName)
 	if stack == nil {
 		return fmt.Errorf("Unable to create stack %s", stackName)
 	}
@@ -117,10 +118,10 @@ func (rolesetMgr *iamRolesetManager) UpsertCommonRoleset() error {
 		return fmt.Errorf("Ended in failed status %s %s", stack.Status, stack.StatusReason)
 	}
 
-	return rolesetMgr.context.StackManager.SetTerminationProtection(stackName, true)
+	return rolesetMgr.context.StackManager.SetTerminationProtection(ctx, stackName, true)
 }
 
-func (rolesetMgr *iamRolesetManager) UpsertEnvironmentRoleset(environmentName string) error {
+func (rolesetMgr *iamRolesetManager) UpsertEnvironmentRoleset(ctx context.Context, environmentName string) error {
 	if rolesetMgr.context.Config.DisableIAM {
 		log.Infof("Skipping upsert of environment IAM roles.")
 		return nil
@@ -156,13 +157,13 @@ func (rolesetMgr *iamRolesetManager) UpsertEnvironmentRoleset(environmentName st
 		"Provider":        string(environment.Provider),
 	}
 
-	err := rolesetMgr.context.StackManager.UpsertStack(stackName, common.TemplateEnvIAM, environment, stackParams, stackTags, "", "")
+	err := rolesetMgr.context.StackManager.UpsertStack(ctx, stackName, common.TemplateEnvIAM, environment, stackParams, stackTags, "", "")
 	if err != nil {
 		return err
 	}
 
 	log.Debugf("Waiting for stack '%s' to complete", stackName)
-	stack := rolesetMgr.context.StackManager.AwaitFinalStatus(stackName)
+	stack := rolesetMgr.context.StackManager.AwaitFinalStatus(ctx, stackName)
 	if stack == nil {
 		return fmt.Errorf("Unable to create stack %s", stackName)
 	}
@@ -173,7 +174,7 @@ func (rolesetMgr *iamRolesetManager) UpsertEnvironmentRoleset(environmentName st
 	return nil
 }
 
-func (rolesetMgr *iamRolesetManager) GetEnvironmentProvider(environmentName string) (string, error) {
+func (rolesetMgr *iamRolesetManager) GetEnvironmentProvider(ctx context.Context, environmentName string) (string, error) {
 	envProvider := ""
 	for _, e := range rolesetMgr.context.Config.Environments {
 		if strings.EqualFold(e.Name, environmentName) {
@@ -188,7 +189,7 @@ func (rolesetMgr *iamRolesetManager) GetEnvironmentProvider(environmentName stri
 	if envProvider == "" {
 		log.Debugf("unable to find environment named '%s' in configuration...checking for existing stack", environmentName)
 		envStackName := common.CreateStackName(rolesetMgr.context.Config.Namespace, common.StackTypeEnv, environmentName)
-		envStack := rolesetMgr.context.StackManager.AwaitFinalStatus(envStackName)
+		envStack := rolesetMgr.context.StackManager.AwaitFinalStatus(ctx, envStackName)
 		if envStack == nil {
 			return "", fmt.Errorf("unable to find environment stack named '%s'", envStackName)
 		}
@@ -197,13 +198,13 @@ func (rolesetMgr *iamRolesetManager) GetEnvironmentProvider(environmentName stri
 	return envProvider, nil
 }
 
-func (rolesetMgr *iamRolesetManager) UpsertServiceRoleset(environmentName string, serviceName string, codeDeployBucket string, databaseName string) error {
+func (rolesetMgr *iamRolesetManager) UpsertServiceRoleset(ctx context.Context, environmentName string, serviceName string, codeDeployBucket string, databaseName string) error {
 	if rolesetMgr.context.Config.DisableIAM {
 		log.Infof("Skipping upsert of service IAM roles.")
 		return nil
 	}
 	stackName := common.CreateStackName(rolesetMgr.context.Config.Namespace, common.StackTypeIam, "service", serviceName, environmentName)
-	envProvider, err := rolesetMgr.GetEnvironmentProvider(environmentName)
+	envProvider, err := rolesetMgr.GetEnvironmentProvider(ctx, environmentName)
 	if err != nil {
 		return err
 	}
@@ -234,13 +235,13 @@ func (rolesetMgr *iamRolesetManager) UpsertServiceRoleset(environmentName string
 		return err
 	}
 
-	err = rolesetMgr.context.StackManager.UpsertStack(stackName, common.TemplateServiceIAM, rolesetMgr.context.Config.Service, stackParams, stackTags, policy, "")
+	err = rolesetMgr.context.StackManager.UpsertStack(ctx, stackName, common.TemplateServiceIAM, rolesetMgr.context.Config.Service, stackParams, stackTags, policy, "")
 	if err != nil {
 		return err
 	}
 
 	log.Debugf("Waiting for stack '%s' to complete", stackName)
-	stack := rolesetMgr.context.StackManager.AwaitFinalStatus(stackName)
+	stack := rolesetMgr.context.StackManager.AwaitFinalStatus(ctx, stackName)
 	if stack == nil {
 		return fmt.Errorf("Unable to create stack %s", stackName)
 	}
@@ -251,7 +252,7 @@ func (rolesetMgr *iamRolesetManager) UpsertServiceRoleset(environmentName string
 	return nil
 }
 
-func (rolesetMgr *iamRolesetManager) UpsertPipelineRoleset(serviceName string, pipelineBucket string, codeDeployBucket string) error {
+func (rolesetMgr *iamRolesetManager) UpsertPipelineRoleset(ctx context.Context, serviceName string, pipelineBucket string, codeDeployBucket string) error {
 	if rolesetMgr.context.Config.DisableIAM {
 		log.Infof("Skipping upsert of pipeline IAM roles.")
 		return nil
@@ -293,7 +294,7 @@ func (rolesetMgr *iamRolesetManager) UpsertPipelineRoleset(serviceName string, p
 	stackParams["EnableAcptStage"] = strconv.FormatBool(!pipelineConfig.Acceptance.Disabled)
 	stackParams["EnableProdStage"] = strconv.FormatBool(!pipelineConfig.Production.Disabled)
 
-	commonRoleset, err := rolesetMgr.GetCommonRoleset()
+	commonRoleset, err := rolesetMgr.GetCommonRoleset(ctx)
 	if err != nil {
 		return err
 	}
@@ -305,13 +306,13 @@ func (rolesetMgr *iamRolesetManager) UpsertPipelineRoleset(serviceName string, p
 		return err
 	}
 
-	err = rolesetMgr.context.StackManager.UpsertStack(stackName, common.TemplatePipelineIAM, rolesetMgr.context.Config.Service.Pipeline, stackParams, stackTags, policy, "")
+	err = rolesetMgr.context.StackManager.UpsertStack(ctx, stackName, common.TemplatePipelineIAM, rolesetMgr.context.Config.Service.Pipeline, stackParams, stackTags, policy, "")
 	if err != nil {
 		return err
 	}
 
 	log.Debugf("Waiting for stack '%s' to complete", stackName)
-	stack := rolesetMgr.context.StackManager.AwaitFinalStatus(stackName)
+	stack := rolesetMgr.context.StackManager.AwaitFinalStatus(ctx, stackName)
 	if stack == nil {
 		return fmt.Errorf("Unable to create stack %s", stackName)
 	}
@@ -322,13 +323,13 @@ func (rolesetMgr *iamRolesetManager) UpsertPipelineRoleset(serviceName string, p
 	return nil
 }
 
-func (rolesetMgr *iamRolesetManager) DeleteCommonRoleset() error {
+func (rolesetMgr *iamRolesetManager) DeleteCommonRoleset(ctx context.Context) error {
 	if rolesetMgr.context.Config.DisableIAM {
 		log.Infof("Skipping delete of common IAM roles.")
 		return nil
 	}
 	stackName := common.CreateStackName(rolesetMgr.context.Config.Namespace, common.StackTypeIam, "common")
-	err := rolesetMgr.context.StackManager.SetTerminationProtection(stackName, false)
+	err := rolesetMgr.context.StackManager.SetTerminationProtection(ctx, stackName, false)
 	if err != nil {
 		return err
 	}
@@ -338,14 +339,14 @@ func (rolesetMgr *iamRolesetManager) DeleteCommonRoleset() error {
 	}
 
 	log.Debugf("Waiting for stack '%s' to complete", stackName)
-	stack := rolesetMgr.context.StackManager.AwaitFinalStatus(stackName)
+	stack := rolesetMgr.context.StackManager.AwaitFinalStatus(ctx, stackName)
 	if stack != nil && !strings.HasSuffix(stack.Status, "_COMPLETE") {
 		return fmt.Errorf("Ended in failed status %s %s", stack.Status, stack.StatusReason)
 	}
 	return nil
 }
 
-func (rolesetMgr *iamRolesetManager) DeleteEnvironmentRoleset(environmentName string) error {
+func (rolesetMgr *iamRolesetManager) DeleteEnvironmentRoleset(ctx context.Context, environmentName string) error {
 	if rolesetMgr.context.Config.DisableIAM {
 		log.Infof("Skipping delete of environment IAM roles.")
 		return nil
@@ -357,14 +358,14 @@ func (rolesetMgr *iamRolesetManager) DeleteEnvironmentRoleset(environmentName st
 	}
 
 	log.Debugf("Waiting for stack '%s' to complete", stackName)
-	stack := rolesetMgr.context.StackManager.AwaitFinalStatus(stackName)
+	stack := rolesetMgr.context.StackManager.AwaitFinalStatus(ctx, stackName)
 	if stack != nil && !strings.HasSuffix(stack.Status, "_COMPLETE") {
 		return fmt.Errorf("Ended in failed status %s %s", stack.Status, stack.StatusReason)
 	}
 	return nil
 }
 
-func (rolesetMgr *iamRolesetManager) DeleteServiceRoleset(environmentName string, serviceName string) error {
+func (rolesetMgr *iamRolesetManager) DeleteServiceRoleset(ctx context.Context, environmentName string, serviceName string) error {
 	if rolesetMgr.context.Config.DisableIAM {
 		log.Infof("Skipping delete of service IAM roles.")
 		return nil
@@ -376,14 +377,14 @@ func (rolesetMgr *iamRolesetManager) DeleteServiceRoleset(environmentName string
 	}
 
 	log.Debugf("Waiting for stack '%s' to complete", stackName)
-	stack := rolesetMgr.context.StackManager.AwaitFinalStatus(stackName)
+	stack := rolesetMgr.context.StackManager.AwaitFinalStatus(ctx, stackName)
 	if stack != nil && !strings.HasSuffix(stack.Status, "_COMPLETE") {
 		return fmt.Errorf("Ended in failed status %s %s", stack.Status, stack.StatusReason)
 	}
 	return nil
 }
 
-func (rolesetMgr *iamRolesetManager) DeletePipelineRoleset(serviceName string) error {
+func (rolesetMgr *iamRolesetManager) DeletePipelineRoleset(ctx context.Context, serviceName string) error {
 	if rolesetMgr.context.Config.DisableIAM {
 		log.Infof("Skipping delete of pipeline IAM roles.")
 		return nil
@@ -395,7 +396,7 @@ func (rolesetMgr *iamRolesetManager) DeletePipelineRoleset(serviceName string) e
 	}
 
 	log.Debugf("Waiting for stack '%s' to complete", stackName)
-	stack := rolesetMgr.context.StackManager.AwaitFinalStatus(stackName)
+	stack := rolesetMgr.context.StackManager.AwaitFinalStatus(ctx, stackName)
 	if stack != nil && !strings.HasSuffix(stack.Status, "_COMPLETE") {
 		return fmt.Errorf("Ended in failed status %s %s", stack.Status, stack.StatusReason)
 	}
@@ -1,6 +1,7 @@
 package aws
 
 import (
+	"context"
 	"testing"
 
 	"github.com/stelligent/mu/common"
@@ -13,7 +14,7 @@ type mockedRolesetStackManager struct {
 	common.StackManager
 }
 
-func (m *mockedRolesetStackManager) UpsertStack(stackName string, templateName string, templateData interface{}, parameters map[string]string, tags map[string]string, policy string, roleArn string) error {
+func (m *mockedRolesetStackManager) UpsertStack(ctx context.Context, stackName string, templateName string, templateData interface{}, parameters map[string]string, tags map[string]string, policy string, roleArn string) error {
 	args := m.Called(stackName)
 	return args.Error(0)
 }
@@ -23,7 +24,7 @@ func (m *mockedRolesetStackManager) DeleteStack(stackName string) error {
 	return args.Error(0)
 }
 
-func (m *mockedRolesetStackManager) AwaitFinalStatus(stackName string) *common.Stack {
+func (m *mockedRolesetStackManager) AwaitFinalStatus(ctx context.Context, stackName string) *common.Stack {
 	args := m.Called(stackName)
 	rtn := args.Get(0)
 	if rtn == nil {
@@ -37,7 +38,7 @@ func (m *mockedRolesetStackManager) GetStack(stackName string) (*common.Stack, e
 	return args.Get(0).(*common.Stack), args.Error(1)
 }
 
-func (m *mockedRolesetStackManager) SetTerminationProtection(stackName string, enabled bool) error {
+func (m *mockedRolesetStackManager) SetTerminationProtection(ctx context.Context, stackName string, enabled bool) error {
 	args := m.Called(stackName, enabled)
 	return args.Error(0)
 }
@@ -61,7 +62,7 @@ func TestIamRolesetManager_GetCommonRoleset(t *testing.T) {
 		},
 	}
 
-	roleset, err := i.GetCommonRoleset()
+	roleset, err := i.GetCommonRoleset(context.Background())
 	assert.Nil(err)
 	assert.NotNil(roleset)
 	stackManagerMock.AssertExpectations(t)
@@ -69,7 +70,7 @@ func TestIamRolesetManager_GetCommonRoleset(t *testing.T) {
 	assert.Equal("foo", roleset["CloudFormationRoleArn"])
 
 	i.context.Config.Roles.CloudFormation = "bar"
-	roleset, err = i.GetCommonRoleset()
+	roleset, err = i.GetCommonRoleset(context.Background())
 	assert.Nil(err)
 	assert.NotNil(roleset)
 	stackManagerMock.AssertExpectations(t)
@@ -97,7 +98,7 @@ func TestIamRolesetManager_GetEnvironmentRoleset(t *testing.T) {
 		},
 	}
 
-	roleset, err := i.GetEnvironmentRoleset("env1")
+	roleset, err := i.GetEnvironmentRoleset(context.Background(), "env1")
 	assert.Nil(err)
 	assert.NotNil(roleset)
 	stackManagerMock.AssertExpectations(t)
@@ -115,7 +116,7 @@ func TestIamRolesetManager_GetEnvironmentRoleset(t *testing.T) {
 	i.context.Config.Environments[0].Roles.Instance = "bar1"
 	i.context.Config.Environments[1].Roles.Instance = "bar2"
 
-	roleset, err = i.GetEnvironmentRoleset("env1")
+	roleset, err = i.GetEnvironmentRoleset(context.Background(), "env1")
 	assert.Nil(err)
 	assert.NotNil(roleset)
 	stackManagerMock.AssertExpectations(t)
@@ -144,7 +145,7 @@ func TestIamRolesetManager_GetServiceRoleset(t *testing.T) {
 		},
 	}
 
-	roleset, err := i.GetServiceRoleset("env1", "s1")
+	roleset, err := i.GetServiceRoleset(context.Background(), "env1", "s1")
 	assert.Nil(err)
 	assert.NotNil(roleset)
 	stackManagerMock.AssertExpectations(t)
@@ -155,7 +156,7 @@ func TestIamRolesetManager_GetServiceRoleset(t *testing.T) {
 	i.context.Config.Service.Roles.EcsService = "bar3"
 	i.context.Config.Service.Roles.EcsTask = "bar4"
 
-	roleset, err = i.GetServiceRoleset("env1", "s1")
+	roleset, err = i.GetServiceRoleset(context.Background(), "env1", "s1")
 	assert.Nil(err)
 	assert.NotNil(roleset)
 	stackManagerMock.AssertExpectations(t)
@@ -183,7 +184,7 @@ func TestIamRolesetManager_GetPipelineRoleset(t *testing.T) {
 		},
 	}
 
-	roleset, err := i.GetPipelineRoleset("s1")
+	roleset, err := i.GetPipelineRoleset(context.Background(), "s1")
 	assert.Nil(err)
 	assert.NotNil(roleset)
 	stackManagerMock.AssertExpectations(t)
@@ -194,7 +195,7 @@ func TestIamRolesetManager_GetPipelineRoleset(t *testing.T) {
 	i.context.Config.Service.Pipeline.Roles.Pipeline = "bar5"
 	i.context.Config.Service.Pipeline.Acceptance.Roles.Mu = "bar6"
 
-	roleset, err = i.GetPipelineRoleset("s1")
+	roleset, err = i.GetPipelineRoleset(context.Background(), "s1")
 	assert.Nil(err)
 	assert.NotNil(roleset)
 	stackManagerMock.AssertExpectations(t)
@@ -218,7 +219,7 @@ func TestIamRolesetManager_UpsertCommonRoleset(t *testing.T) {
 		},
 	}
 
-	err := i.UpsertCommonRoleset()
+	err := i.UpsertCommonRoleset(context.Background())
 	assert.Nil(err)
 	stackManagerMock.AssertExpectations(t)
 	stackManagerMock.AssertNumberOfCalls(t, "UpsertStack", 0)
@@ -227,7 +228,7 @@ func TestIamRolesetManager_UpsertCommonRoleset(t *testing.T) {
 	stackManagerMock.On("AwaitFinalStatus", "mu-iam-common").Return(&common.Stack{Status: "CREATE_COMPLETE"})
 	stackManagerMock.On("SetTerminationProtection", "mu-iam-common", true).Return(nil)
 	i.context.Config.DisableIAM = false
-	err = i.UpsertCommonRoleset()
+	err = i.UpsertCommonRoleset(context.Background())
 	assert.Nil(err)
 	stackManagerMock.AssertExpectations(t)
 	stackManagerMock.AssertNumberOfCalls(t, "UpsertStack", 1)
@@ -249,7 +250,7 @@ func TestIamRolesetManager_UpsertEnvironmentRoleset(t *testing.T) {
 		},
 	}
 
-	err := i.UpsertEnvironmentRoleset("env1")
+	err := i.UpsertEnvironmentRoleset(context.Background(), "env1")
 	assert.Nil(err)
 	stackManagerMock.AssertExpectations(t)
 	stackManagerMock.AssertNumberOfCalls(t, "UpsertStack", 0)
@@ -265,7 +266,7 @@ func TestIamRolesetManager_UpsertEnvironmentRoleset(t *testing.T) {
 
 	stackManagerMock.On("UpsertStack", "mu-iam-environment-env1").Return(nil)
 	stackManagerMock.On("AwaitFinalStatus", "mu-iam-environment-env1").Return(&common.Stack{Status: "CREATE_COMPLETE"})
-	err = i.UpsertEnvironmentRoleset("env1")
+	err = i.UpsertEnvironmentRoleset(context.Background(), "env1")
 	assert.Nil(err)
 	stackManagerMock.AssertExpectations(t)
 	stackManagerMock.AssertNumberOfCalls(t, "UpsertStack", 1)
@@ -300,7 +301,7 @@ func TestIamRolesetManager_UpsertServiceRoleset_ManagedEnv(t *testing.T) {
 	stackManagerMock.On("UpsertStack", "mu-iam-service-sv1-env1").Return(nil)
 	stackManagerMock.On("AwaitFinalStatus", "mu-iam-service-sv1-env1").Return(&common.Stack{Status: "CREATE_COMPLETE"})
 
-	err := i.UpsertServiceRoleset("env1", "sv1", "foo-bucket", "")
+	err := i.UpsertServiceRoleset(context.Background(), "env1", "sv1", "foo-bucket", "")
 	assert.Nil(err)
 	stackManagerMock.AssertExpectations(t)
 	stackManagerMock.AssertNumberOfCalls(t, "AwaitFinalStatus", 1)
@@ -331,7 +332,7 @@ func TestIamRolesetManager_UpsertServiceRoleset_SharedEnv(t *testing.T) {
 	}, nil)
 	stackManagerMock.On("UpsertStack", "mu-iam-service-sv1-env1").Return(nil)
 	stackManagerMock.On("AwaitFinalStatus", "mu-iam-service-sv1-env1").Return(&common.Stack{Status: "CREATE_COMPLETE"})
-	err := i.UpsertServiceRoleset("env1", "sv1", "foo-bucket", "")
+	err := i.UpsertServiceRoleset(context.Background(), "env1", "sv1", "foo-bucket", "")
 	assert.Nil(err)
 	stackManagerMock.AssertExpectations(t)
 	stackManagerMock.AssertNumberOfCalls(t, "AwaitFinalStatus", 2)
@@ -361,7 +362,7 @@ func TestIamRolesetManager_UpsertPipelineRoleset(t *testing.T) {
 	}, nil)
 	stackManagerMock.On("UpsertStack", "mu-iam-pipeline-sv1").Return(nil)
 	stackManagerMock.On("AwaitFinalStatus", "mu-iam-pipeline-sv1").Return(&common.Stack{Status: "CREATE_COMPLETE"})
-	err := i.UpsertPipelineRoleset("sv1", "test-bucket", "foo-bucket")
+	err := i.UpsertPipelineRoleset(context.Background(), "sv1", "test-bucket", "foo-bucket")
 	assert.Nil(err)
 	stackManagerMock.AssertExpectations(t)
 	stackManagerMock.AssertNumberOfCalls(t, "AwaitFinalStatus", 2)
@@ -384,7 +385,7 @@ func TestIamRolesetManager_DeleteCommonRoleset(t *testing.T) {
 	stackManagerMock.On("DeleteStack", "mu-iam-common").Return(nil)
 	stackManagerMock.On("AwaitFinalStatus", "mu-iam-common").Return(nil)
 	stackManagerMock.On("SetTerminationProtection", "mu-iam-common", false).Return(nil)
-	err := i.DeleteCommonRoleset()
+	err := i.DeleteCommonRoleset(context.Background())
 	assert.Nil(err)
 	stackManagerMock.AssertExpectations(t)
 	stackManagerMock.AssertNumberOfCalls(t, "AwaitFinalStatus", 1)
@@ -407,7 +408,7 @@ func TestIamRolesetManager_DeleteEnvironmentRoleset(t *testing.T) {
 	}
 	stackManagerMock.On("DeleteStack", "mu-iam-environment-env10").Return(nil)
 	stackManagerMock.On("AwaitFinalStatus", "mu-iam-environment-env10").Return(nil)
-	err := i.DeleteEnvironmentRoleset("env10")
+	err := i.DeleteEnvironmentRoleset(context.Background(), "env10")
 	assert.Nil(err)
 	stackManagerMock.AssertExpectations(t)
 	stackManagerMock.AssertNumberOfCalls(t, "AwaitFinalStatus", 1)
@@ -432,7 +433,7 @@ func TestIamRolesetManager_DeleteServiceRoleset(t *testing.T) {
 	}
 	stackManagerMock.On("DeleteStack", "mu-iam-service-sv1-env10").Return(nil)
 	stackManagerMock.On("AwaitFinalStatus", "mu-iam-service-sv1-env10").Return(nil)
-	err := i.DeleteServiceRoleset("env10", "sv1")
+	err := i.DeleteServiceRoleset(context.Background(), "env10", "sv1")
 	assert.Nil(err)
 	stackManagerMock.AssertExpectations(t)
 	stackManagerMock.AssertNumberOfCalls(t, "AwaitFinalStatus", 1)
@@ -457,7 +458,7 @@ func TestIamRolesetManager_DeletePipelineRoleset(t *testing.T) {
 	}
 	stackManagerMock.On("DeleteStack", "mu-iam-pipeline-sv1").Return(nil)
 	stackManagerMock.On("AwaitFinalStatus", "mu-iam-pipeline-sv1").Return(nil)
-	err := i.DeletePipelineRoleset("sv1")
+	err := i.DeletePipelineRoleset(context.Background(), "sv1")
 	assert.Nil(err)
 	stackManagerMock.AssertExpectations(t)
 	stackManagerMock.AssertNumberOfCalls(t, "AwaitFinalStatus", 1)
@@ -1,6 +1,7 @@
 package aws
 
 import (
+	"context"
 	"fmt"
 	"strconv"
 	"strings"
@@ -51,7 +52,7 @@ func (scManager *serviceCatalogManager) GetStackID(recordID string) (string, err
 	return "", fmt.Errorf("Unable to find stack ARN for record '%s'", recordID)
 }
 
-func (scManager *serviceCatalogManager) createProvisionedProduct(productID string, artifactID string, name string, params map[string]string) error {
+func (scManager *serviceCatalogManager) createProvisionedProduct(ctx context.Context, productID string, artifactID string, name string, params map[string]string) error {
 	// create new
 	if scManager.dryrun {
 		log.Infof("  DRYRUN: Skipping creation of product '%s' from artifact '%s'", productID, artifactID)
@@ -75,18 +76,21 @@ func (scManager *serviceCatalogManager) createProvisionedProduct(productID strin
 		return err
 	}
 
-	time.Sleep(time.Second * 5)
+	// the record needs a moment before it has the stack of the provisioned product
+	if err := common.SleepWithContext(ctx, time.Second*5); err != nil {
+		return err
+	}
 	stackID, err := scManager.GetStackID(aws.StringValue(ppOut.RecordDetail.RecordId))
 	if err != nil {
 		return err
 	}
 
 	log.Infof("  Creating stack '%s'", stackID)
-	scManager.stackManager.AwaitFinalStatus(strings.Split(stackID, "/")[1])
+	scManager.stackManager.AwaitFinalStatus(ctx, strings.Split(stackID, "/")[1])
 	return nil
 }
 
-func (scManager *serviceCatalogManager) updateProvisionedProduct(productID string, artifactID string, name string, params map[string]string) error {
+func (scManager *serviceCatalogManager) updateProvisionedProduct(ctx context.Context, productID string, artifactID string, name string, params map[string]string) error {
 	// update existing
 	if scManager.dryrun {
 		log.Infof("  DRYRUN: Skipping update of product '%s' from artifact '%s'", productID, artifactID)
@@ -116,18 +120,21 @@ func (scManager *serviceCatalogManager) updateProvisionedProduct(productID strin
 		return err
 	}
 
-	time.Sleep(time.Second * 5)
+	// the record needs a moment before it has the stack of the provisioned product
+	if err := common.SleepWithContext(ctx, time.Second*5); err != nil {
+		return err
+	}
 	stackID, err := scManager.GetStackID(aws.StringValue(ppOut.RecordDetail.RecordId))
 	if err != nil {
 		return err
 	}
 
 	log.Infof("  Updating stack '%s'", stackID)
-	scManager.stackManager.AwaitFinalStatus(strings.Split(stackID, "/")[1])
+	scManager.stackManager.AwaitFinalStatus(ctx, strings.Split(stackID, "/")[1])
 	return nil
 }
 
-func (scManager *serviceCatalogManager) UpsertProvisionedProduct(productID string, version string, name string, params map[string]string) error {
+func (scManager *serviceCatalogManager) UpsertProvisionedProduct(ctx context.Context, productID string, version string, name string, params map[string]string) error {
 
 	output, err := scManager.scAPI.ListProvisioningArtifacts(&servicecatalog.ListProvisioningArtifactsInput{
 		ProductId: aws.String(productID),
@@ -159,14 +166,14 @@ func (scManager *serviceCatalogManager) UpsertProvisionedProduct(productID strin
 
 	for _, provisionedProduct := range provisionedProducts.ProvisionedProducts {
 		if name == aws.StringValue(provisionedProduct.Name) {
-			return scManager.updateProvisionedProduct(productID, artifactID, name, params)
+			return scManager.updateProvisionedProduct(ctx, productID, artifactID, name, params)
 		}
 	}
 
-	return scManager.createProvisionedProduct(productID, artifactID, name, params)
+	return scManager.createProvisionedProduct(ctx, productID, artifactID, name, params)
 }
 
-func (scManager *serviceCatalogManager) TerminateProvisionedProducts(productID string) error {
+func (scManager *serviceCatalogManager) TerminateProvisionedProducts(ctx context.Context, productID string) error {
 	var err error
 	err2 := scManager.scAPI.SearchProvisionedProductsPages(&servicecatalog.SearchProvisionedProductsInput{
 		AccessLevelFilter: &servicecatalog.AccessLevelFilter{
@@ -194,10 +201,12 @@ func (scManager *serviceCatalogManager) TerminateProvisionedProducts(productID s
 				return false
 			}
 
-			time.Sleep(time.Second * 5)
+			if err = common.SleepWithContext(ctx, time.Second*5); err != nil {
+				return false
+			}
 
 			log.Infof("  Deleting stack '%s'", stackID)
-			scManager.stackManager.AwaitFinalStatus(strings.Split(stackID, "/")[1])
+			scManager.stackManager.AwaitFinalStatus(ctx, strings.Split(stackID, "/")[1])
 		}
 		return true
 	})
@@ -1,10 +1,13 @@
 package aws
 
 import (
+	"sort"
 	"strings"
 
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/cloudformation"
+	"github.com/aws/aws-sdk-go/service/cloudformation/cloudformationiface"
 	"github.com/aws/aws-sdk-go/service/ecs"
 	"github.com/aws/aws-sdk-go/service/ecs/ecsiface"
 	"github.com/pkg/errors"
@@ -13,6 +16,7 @@ import (
 
 type ecsTaskManager struct {
 	ecsAPI       ecsiface.ECSAPI
+	cfnAPI       cloudformationiface.CloudFormationAPI
 	stackManager common.StackGetter
 }
 
@@ -30,9 +34,11 @@ func newTaskManager(sess *session.Session, stackManager *common.StackManager) (c
 	log.Debug(EcsConnectionLog)
 
 	ecsAPI := ecs.New(sess)
+	cfnAPI := cloudformation.New(sess)
 
 	return &ecsTaskManager{
 		ecsAPI:       ecsAPI,
+		cfnAPI:       cfnAPI,
 		stackManager: *stackManager,
 	}, nil
 }
@@ -80,6 +86,13 @@ func (taskMgr *ecsTaskManager) runTask(runTaskInput *ecs.RunTaskInput) (common.E
 		return nil, err
 	}
 
+	for _, ecsTask := range resp.Tasks {
+		common.EmitEvent(common.Event{
+			Type:   common.EventTypeTask,
+			Task:   aws.StringValue(ecsTask.TaskArn),
+			Status: aws.StringValue(ecsTask.LastStatus),
+		})
+	}
 	return resp, nil
 }
 
@@ -154,6 +167,62 @@ func (taskMgr *ecsTaskManager) StopTask(namespace string, environment string, ta
 	return err
 }
 
+// ListServiceRevisions finds the task definitions that were created by the service stack, most recent first.  Each
+// task definition is described once and at most SvcMaxRevisions are returned.
+func (taskMgr *ecsTaskManager) ListServiceRevisions(namespace string, environment string, serviceName string) ([]common.ServiceRevision, error) {
+	svcStackName := common.CreateStackName(namespace, common.StackTypeService, serviceName, environment)
+	log.Debugf(SvcListRevisionsLog, svcStackName)
+
+	revisions := []common.ServiceRevision{}
+	seen := make(map[string]bool)
+	err := taskMgr.cfnAPI.DescribeStackEventsPages(&cloudformation.DescribeStackEventsInput{
+		StackName: aws.String(svcStackName),
+	}, func(page *cloudformation.DescribeStackEventsOutput, lastPage bool) bool {
+		for _, event := range page.StackEvents {
+			if aws.StringValue(event.LogicalResourceId) != ECSTaskDefinitionResourceName ||
+				aws.StringValue(event.ResourceStatus) != cloudformation.ResourceStatusCreateComplete {
+				continue
+			}
+			taskDefinitionArn := aws.StringValue(event.PhysicalResourceId)
+			if seen[taskDefinitionArn] {
+				continue
+			}
+			seen[taskDefinitionArn] = true
+			if len(seen) > SvcMaxRevisions {
+				return false
+			}
+
+			out, err := taskMgr.ecsAPI.DescribeTaskDefinition(&ecs.DescribeTaskDefinitionInput{
+				TaskDefinition: aws.String(taskDefinitionArn),
+			})
+			if err != nil {
+				log.Warningf(SvcSkipRevisionLog, taskDefinitionArn, err)
+				continue
+			}
+			for _, container := range out.TaskDefinition.ContainerDefinitions {
+				if aws.StringValue(container.Name) != serviceName {
+					continue
+				}
+				imageURL := aws.StringValue(container.Image)
+				revisions = append(revisions, common.ServiceRevision{
+					Revision:   common.ImageTag(imageURL),
+					ImageURL:   imageURL,
+					DeployedAt: aws.TimeValue(event.Timestamp),
+				})
+			}
+		}
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.SliceStable(revisions, func(i, j int) bool {
+		return revisions[i].DeployedAt.After(revisions[j].DeployedAt)
+	})
+	return revisions, nil
+}
+
 func getTaskDetail(ecsTask *ecs.Task, taskMgr *ecsTaskManager, cluster string, environment string, serviceName string) (*common.Task, error) {
 	containers := []common.Container{}
 	if len(ecsTask.Containers) > Zero {
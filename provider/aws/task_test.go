@@ -2,12 +2,15 @@ package aws
 
 import (
 	"errors"
+	"fmt"
 	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/cloudformation"
 	"github.com/aws/aws-sdk-go/service/ecs"
 	"github.com/stelligent/mu/common"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"testing"
+	"time"
 )
 
 type mockedStackManager struct {
@@ -40,6 +43,17 @@ func (m *mockedECS) ListTasks(input *ecs.ListTasksInput) (*ecs.ListTasksOutput,
 	return args.Get(0).(*ecs.ListTasksOutput), args.Error(1)
 }
 
+func (m *mockedECS) DescribeTaskDefinition(input *ecs.DescribeTaskDefinitionInput) (*ecs.DescribeTaskDefinitionOutput, error) {
+	args := m.Called(aws.StringValue(input.TaskDefinition))
+	return args.Get(0).(*ecs.DescribeTaskDefinitionOutput), args.Error(1)
+}
+
+func (m *mockedCloudFormation) DescribeStackEventsPages(input *cloudformation.DescribeStackEventsInput, cb func(*cloudformation.DescribeStackEventsOutput, bool) bool) error {
+	args := m.Called(aws.StringValue(input.StackName))
+	cb(args.Get(0).(*cloudformation.DescribeStackEventsOutput), true)
+	return args.Error(1)
+}
+
 func TestOptionalFlags(t *testing.T) {
 	assertion := assert.New(t)
 	assertion.Equal(TestEnv, getFlagOrValue(Empty, TestEnv))
@@ -180,6 +194,123 @@ func TestRunInputFail(t *testing.T) {
 	stackManagerMock.AssertNumberOfCalls(t, GetStackName, 1)
 }
 
+func TestListServiceRevisions(t *testing.T) {
+	assertion := assert.New(t)
+	ecsMock := new(mockedECS)
+	cfnMock := new(mockedCloudFormation)
+
+	firstDeploy := time.Date(2018, 9, 1, 12, 0, 0, 0, time.UTC)
+	secondDeploy := firstDeploy.Add(time.Hour)
+	cfnMock.On("DescribeStackEventsPages", "mu-service-foosvc-fooenv").Return(&cloudformation.DescribeStackEventsOutput{
+		StackEvents: []*cloudformation.StackEvent{
+			{
+				LogicalResourceId:  aws.String(ECSTaskDefinitionResourceName),
+				PhysicalResourceId: aws.String("foosvc:3"),
+				ResourceStatus:     aws.String(cloudformation.ResourceStatusCreateComplete),
+				Timestamp:          aws.Time(secondDeploy.Add(time.Hour)),
+			},
+			{
+				LogicalResourceId:  aws.String(ECSTaskDefinitionResourceName),
+				PhysicalResourceId: aws.String("foosvc:1"),
+				ResourceStatus:     aws.String(cloudformation.ResourceStatusDeleteComplete),
+				Timestamp:          aws.Time(secondDeploy),
+			},
+			{
+				LogicalResourceId:  aws.String(ECSTaskDefinitionResourceName),
+				PhysicalResourceId: aws.String("foosvc:2"),
+				ResourceStatus:     aws.String(cloudformation.ResourceStatusCreateComplete),
+				Timestamp:          aws.Time(secondDeploy),
+			},
+			{
+				LogicalResourceId:  aws.String(ECSTaskDefinitionResourceName),
+				PhysicalResourceId: aws.String("foosvc:2"),
+				ResourceStatus:     aws.String(cloudformation.ResourceStatusCreateComplete),
+				Timestamp:          aws.Time(secondDeploy),
+			},
+			{
+				LogicalResourceId:  aws.String("EcsService"),
+				PhysicalResourceId: aws.String("foosvc"),
+				ResourceStatus:     aws.String(cloudformation.ResourceStatusCreateComplete),
+				Timestamp:          aws.Time(firstDeploy),
+			},
+			{
+				LogicalResourceId:  aws.String(ECSTaskDefinitionResourceName),
+				PhysicalResourceId: aws.String("foosvc:1"),
+				ResourceStatus:     aws.String(cloudformation.ResourceStatusCreateComplete),
+				Timestamp:          aws.Time(firstDeploy),
+			},
+		},
+	}, nil)
+	ecsMock.On("DescribeTaskDefinition", "foosvc:1").Return(&ecs.DescribeTaskDefinitionOutput{
+		TaskDefinition: &ecs.TaskDefinition{
+			ContainerDefinitions: []*ecs.ContainerDefinition{
+				{Name: aws.String(TestSvc), Image: aws.String("1234.dkr.ecr.us-east-1.amazonaws.com/mu-foosvc:abc123")},
+			},
+		},
+	}, nil)
+	ecsMock.On("DescribeTaskDefinition", "foosvc:3").Return((*ecs.DescribeTaskDefinitionOutput)(nil), errors.New("task definition is inactive"))
+	ecsMock.On("DescribeTaskDefinition", "foosvc:2").Return(&ecs.DescribeTaskDefinitionOutput{
+		TaskDefinition: &ecs.TaskDefinition{
+			ContainerDefinitions: []*ecs.ContainerDefinition{
+				{Name: aws.String("sidecar"), Image: aws.String("envoy:1.7")},
+				{Name: aws.String(TestSvc), Image: aws.String("1234.dkr.ecr.us-east-1.amazonaws.com/mu-foosvc:def456")},
+			},
+		},
+	}, nil)
+
+	taskManager := ecsTaskManager{
+		ecsAPI: ecsMock,
+		cfnAPI: cfnMock,
+	}
+
+	revisions, err := taskManager.ListServiceRevisions("mu", TestEnv, TestSvc)
+	assertion.Nil(err)
+	assertion.Equal([]common.ServiceRevision{
+		{Revision: "def456", ImageURL: "1234.dkr.ecr.us-east-1.amazonaws.com/mu-foosvc:def456", DeployedAt: secondDeploy},
+		{Revision: "abc123", ImageURL: "1234.dkr.ecr.us-east-1.amazonaws.com/mu-foosvc:abc123", DeployedAt: firstDeploy},
+	}, revisions)
+
+	cfnMock.AssertExpectations(t)
+	ecsMock.AssertExpectations(t)
+	ecsMock.AssertNumberOfCalls(t, "DescribeTaskDefinition", 3)
+}
+
+func TestListServiceRevisions_Max(t *testing.T) {
+	assertion := assert.New(t)
+	ecsMock := new(mockedECS)
+	cfnMock := new(mockedCloudFormation)
+
+	events := []*cloudformation.StackEvent{}
+	for i := SvcMaxRevisions + 5; i > 0; i-- {
+		events = append(events, &cloudformation.StackEvent{
+			LogicalResourceId:  aws.String(ECSTaskDefinitionResourceName),
+			PhysicalResourceId: aws.String(fmt.Sprintf("foosvc:%d", i)),
+			ResourceStatus:     aws.String(cloudformation.ResourceStatusCreateComplete),
+			Timestamp:          aws.Time(time.Unix(int64(i), 0)),
+		})
+	}
+	cfnMock.On("DescribeStackEventsPages", "mu-service-foosvc-fooenv").Return(&cloudformation.DescribeStackEventsOutput{
+		StackEvents: events,
+	}, nil)
+	ecsMock.On("DescribeTaskDefinition", mock.Anything).Return(&ecs.DescribeTaskDefinitionOutput{
+		TaskDefinition: &ecs.TaskDefinition{
+			ContainerDefinitions: []*ecs.ContainerDefinition{
+				{Name: aws.String(TestSvc), Image: aws.String("mu-foosvc:abc123")},
+			},
+		},
+	}, nil)
+
+	taskManager := ecsTaskManager{
+		ecsAPI: ecsMock,
+		cfnAPI: cfnMock,
+	}
+
+	revisions, err := taskManager.ListServiceRevisions("mu", TestEnv, TestSvc)
+	assertion.Nil(err)
+	assertion.Len(revisions, SvcMaxRevisions)
+	ecsMock.AssertNumberOfCalls(t, "DescribeTaskDefinition", SvcMaxRevisions)
+}
+
 func getTestTask() common.Task {
 	return common.Task{
 		Environment: TestEnv,
@@ -0,0 +1,71 @@
+package fake
+
+import (
+	"bytes"
+	"crypto/md5"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/url"
+	"strings"
+)
+
+// artifactManager keeps S3 objects in the state, keyed by s3:// URL
+type artifactManager struct {
+	state *State
+}
+
+// CreateArtifact stores the body as an object
+func (artifactMgr *artifactManager) CreateArtifact(body io.ReadSeeker, destURL string, kmsKey string) error {
+	s3URL, err := url.Parse(destURL)
+	if err != nil {
+		return err
+	}
+	if s3URL.Scheme != "s3" {
+		return fmt.Errorf("destURL must have scheme of 's3', received '%s'", s3URL.Scheme)
+	}
+
+	// start from the begining
+	body.Seek(0, 0)
+	data, err := ioutil.ReadAll(body)
+	if err != nil {
+		return err
+	}
+
+	artifactMgr.state.mutex.Lock()
+	defer artifactMgr.state.mutex.Unlock()
+
+	artifactMgr.state.Objects[destURL] = string(data)
+	return nil
+}
+
+// GetArtifact get the artifact conditionally by etag.
+func (artifactMgr *artifactManager) GetArtifact(uri string, etag string) (io.ReadCloser, string, error) {
+	artifactMgr.state.mutex.Lock()
+	defer artifactMgr.state.mutex.Unlock()
+
+	data, ok := artifactMgr.state.Objects[uri]
+	if !ok {
+		return nil, "", fmt.Errorf("NoSuchKey: %s", uri)
+	}
+
+	newEtag := fmt.Sprintf("%x", md5.Sum([]byte(data)))
+	if etag != "" && etag == newEtag {
+		return nil, etag, nil
+	}
+	return ioutil.NopCloser(bytes.NewBufferString(data)), newEtag, nil
+}
+
+// EmptyBucket removes all objects in the bucket
+func (artifactMgr *artifactManager) EmptyBucket(bucketName string) error {
+	artifactMgr.state.mutex.Lock()
+	defer artifactMgr.state.mutex.Unlock()
+
+	prefix := fmt.Sprintf("s3://%s/", bucketName)
+	for key := range artifactMgr.state.Objects {
+		if strings.HasPrefix(key, prefix) {
+			delete(artifactMgr.state.Objects, key)
+		}
+	}
+	return nil
+}
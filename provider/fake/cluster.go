@@ -0,0 +1,110 @@
+package fake
+
+import (
+	"encoding/base64"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/stelligent/mu/common"
+)
+
+type clusterManager struct {
+	state *State
+}
+
+// ListInstances returns no instances, the fake environments have no container instances
+func (clusterMgr *clusterManager) ListInstances(clusterName string) ([]common.ContainerInstance, error) {
+	return []common.ContainerInstance{}, nil
+}
+
+// AuthenticateRepository returns fake credentials in the same form as ECR
+func (clusterMgr *clusterManager) AuthenticateRepository(repoURL string) (string, error) {
+	return base64.StdEncoding.EncodeToString([]byte("AWS:fake")), nil
+}
+
+// DeleteRepository removes the images of a repository
+func (clusterMgr *clusterManager) DeleteRepository(repoName string) error {
+	clusterMgr.state.mutex.Lock()
+	defer clusterMgr.state.mutex.Unlock()
+
+	prefix := fmt.Sprintf("/%s:", repoName)
+	for image := range clusterMgr.state.Images {
+		if strings.Contains(image, prefix) {
+			delete(clusterMgr.state.Images, image)
+		}
+	}
+	return nil
+}
+
+// TagRepositoryImage adds the new tag to a pushed image with the existing tag
+func (clusterMgr *clusterManager) TagRepositoryImage(repoName string, existingTag string, newTag string) (bool, error) {
+	clusterMgr.state.mutex.Lock()
+	defer clusterMgr.state.mutex.Unlock()
+
+	suffix := fmt.Sprintf("/%s:%s", repoName, existingTag)
+	for image, pushed := range clusterMgr.state.Images {
+		if pushed && strings.HasSuffix(image, suffix) {
+			clusterMgr.state.Images[fmt.Sprintf("%s:%s", strings.TrimSuffix(image, ":"+existingTag), newTag)] = true
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+type instanceManager struct{}
+
+// ListInstances returns no instances
+func (instanceMgr *instanceManager) ListInstances(instanceIds ...string) ([]common.Instance, error) {
+	return []common.Instance{}, nil
+}
+
+type rdsManager struct {
+	state *State
+}
+
+// SetIamAuthentication records whether IAM authentication is enabled for the database
+func (rdsMgr *rdsManager) SetIamAuthentication(dbInstanceIdentifier string, enabled bool, dbEngine string) error {
+	rdsMgr.state.mutex.Lock()
+	defer rdsMgr.state.mutex.Unlock()
+
+	rdsMgr.state.IamAuthentication[dbInstanceIdentifier] = enabled
+	return nil
+}
+
+// dockerManager records built images as unpushed, and marks them pushed when they are pushed
+type dockerManager struct {
+	state *State
+}
+
+// ImageBuild records the tags of the image
+func (dockerMgr *dockerManager) ImageBuild(contextDir string, serviceName string, relDockerfile string, tags []string, dockerOut io.Writer) error {
+	dockerMgr.state.mutex.Lock()
+	defer dockerMgr.state.mutex.Unlock()
+
+	for _, tag := range tags {
+		dockerMgr.state.Images[tag] = false
+	}
+	return nil
+}
+
+// ImagePush marks the image as pushed, it must have been built first
+func (dockerMgr *dockerManager) ImagePush(image string, registryAuth string, dockerOut io.Writer) error {
+	dockerMgr.state.mutex.Lock()
+	defer dockerMgr.state.mutex.Unlock()
+
+	if _, ok := dockerMgr.state.Images[image]; !ok {
+		return fmt.Errorf("An image does not exist locally with the tag: %s", image)
+	}
+	dockerMgr.state.Images[image] = true
+	return nil
+}
+
+// ContainerRun writes the output for the image to stdout, an image without an output writes nothing
+func (dockerMgr *dockerManager) ContainerRun(image string, stdin io.Reader, stdout io.Writer, stderr io.Writer) error {
+	dockerMgr.state.mutex.Lock()
+	defer dockerMgr.state.mutex.Unlock()
+
+	_, err := io.WriteString(stdout, dockerMgr.state.ContainerOutputs[image])
+	return err
+}
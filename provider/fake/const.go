@@ -0,0 +1,13 @@
+package fake
+
+import "github.com/op/go-logging"
+
+var log = logging.MustGetLogger("fake")
+
+// Constants for the identity of the fake account
+const (
+	AccountID = "123456789012"
+	Region    = "us-east-1"
+	Partition = "aws"
+	ImageID   = "ami-00000000"
+)
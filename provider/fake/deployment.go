@@ -0,0 +1,53 @@
+package fake
+
+import (
+	"fmt"
+
+	"github.com/stelligent/mu/common"
+)
+
+type deploymentManager struct {
+	state *State
+}
+
+// CreateEcsDeployment records a deployment of the task definition, which completes immediately
+func (deploymentMgr *deploymentManager) CreateEcsDeployment(applicationName string, deploymentGroupName string, taskDefinitionArn string, containerName string, containerPort int) (string, error) {
+	deploymentMgr.state.mutex.Lock()
+	defer deploymentMgr.state.mutex.Unlock()
+
+	status := common.EcsDeploymentSucceeded
+	if _, ok := deploymentMgr.state.DeploymentFailures[deploymentGroupName]; ok {
+		status = common.EcsDeploymentStopped
+	}
+
+	deploymentID := fmt.Sprintf("d-%d", len(deploymentMgr.state.Deployments)+1)
+	deploymentMgr.state.Deployments[deploymentID] = &Deployment{
+		ApplicationName:     applicationName,
+		DeploymentGroupName: deploymentGroupName,
+		TaskDefinitionArn:   taskDefinitionArn,
+		Status:              status,
+	}
+	return deploymentID, nil
+}
+
+// GetEcsDeployment returns a recorded deployment, with all traffic shifted if it succeeded
+func (deploymentMgr *deploymentManager) GetEcsDeployment(deploymentID string) (*common.EcsDeployment, error) {
+	deploymentMgr.state.mutex.Lock()
+	defer deploymentMgr.state.mutex.Unlock()
+
+	deployment, ok := deploymentMgr.state.Deployments[deploymentID]
+	if !ok {
+		return nil, fmt.Errorf("DeploymentDoesNotExistException: The deployment %s could not be found", deploymentID)
+	}
+
+	ecsDeployment := &common.EcsDeployment{
+		ID:     deploymentID,
+		Status: deployment.Status,
+	}
+	if deployment.Status == common.EcsDeploymentSucceeded {
+		ecsDeployment.TrafficWeight = 100
+	} else {
+		ecsDeployment.StatusMessage = deploymentMgr.state.DeploymentFailures[deployment.DeploymentGroupName]
+	}
+	return ecsDeployment, nil
+}
@@ -0,0 +1,21 @@
+package fake
+
+import (
+	"github.com/stelligent/mu/common"
+)
+
+type elbManager struct {
+	state *State
+}
+
+// ListRules lists the rules of a listener
+func (elbMgr *elbManager) ListRules(listenerArn string) ([]common.ElbRule, error) {
+	elbMgr.state.mutex.Lock()
+	defer elbMgr.state.mutex.Unlock()
+
+	rules := []common.ElbRule{}
+	for _, rule := range elbMgr.state.Rules[listenerArn] {
+		rules = append(rules, rule)
+	}
+	return rules, nil
+}
@@ -0,0 +1,36 @@
+package fake
+
+import (
+	"io/ioutil"
+
+	"github.com/stelligent/mu/common"
+)
+
+// InitializeContext loads manager objects that are backed by the in-memory state
+func InitializeContext(ctx *common.Context, state *State) error {
+	ctx.Region = Region
+	ctx.Partition = Partition
+	ctx.AccountID = AccountID
+
+	ctx.StackManager = &stackManager{state: state, extensionsManager: ctx.ExtensionsManager}
+	ctx.ClusterManager = &clusterManager{state: state}
+	ctx.InstanceManager = &instanceManager{}
+	ctx.ElbManager = &elbManager{state: state}
+	ctx.RdsManager = &rdsManager{state: state}
+	ctx.ParamManager = &paramManager{state: state}
+	ctx.PipelineManager = &pipelineManager{state: state}
+	ctx.LocalPipelineManager = ctx.PipelineManager
+	ctx.LogsManager = &logsManager{state: state}
+	ctx.TaskManager = &taskManager{state: state}
+	ctx.ArtifactManager = &artifactManager{state: state}
+	ctx.SubscriptionManager = &subscriptionManager{state: state}
+	ctx.CatalogManager = &catalogManager{state: state}
+	ctx.DeploymentManager = &deploymentManager{state: state}
+	ctx.KubernetesResourceManagerProvider = &kubernetesResourceManagerProvider{state: state}
+	ctx.DockerManager = &dockerManager{state: state}
+	ctx.RolesetManager = &rolesetManager{context: ctx}
+
+	ctx.DockerOut = ioutil.Discard
+
+	return nil
+}
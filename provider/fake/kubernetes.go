@@ -0,0 +1,62 @@
+package fake
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/stelligent/mu/common"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+type kubernetesResourceManagerProvider struct {
+	state *State
+}
+
+// GetResourceManager returns a resource manager that records the templates applied to the cluster
+func (provider *kubernetesResourceManagerProvider) GetResourceManager(name string) (common.KubernetesResourceManager, error) {
+	return &kubernetesResourceManager{
+		state:   provider.state,
+		cluster: name,
+	}, nil
+}
+
+// DescribeKubernetesCluster returns a fake endpoint for the cluster
+func (provider *kubernetesResourceManagerProvider) DescribeKubernetesCluster(name string) (*common.KubernetesCluster, error) {
+	return &common.KubernetesCluster{
+		Name:                     name,
+		Endpoint:                 fmt.Sprintf("https://%s.%s.eks.amazonaws.com", name, Region),
+		CertificateAuthorityData: "ZmFrZQ==",
+	}, nil
+}
+
+// GetKubernetesToken returns a fake token for the cluster
+func (provider *kubernetesResourceManagerProvider) GetKubernetesToken(name string) (*common.KubernetesToken, error) {
+	return &common.KubernetesToken{
+		Token:      fmt.Sprintf("k8s-aws-v1.fake-%s", name),
+		Expiration: time.Now().Add(14 * time.Minute),
+	}, nil
+}
+
+type kubernetesResourceManager struct {
+	state   *State
+	cluster string
+}
+
+// UpsertResources records the template name
+func (kubernetesMgr *kubernetesResourceManager) UpsertResources(templateName string, templateData interface{}) error {
+	kubernetesMgr.state.mutex.Lock()
+	defer kubernetesMgr.state.mutex.Unlock()
+
+	kubernetesMgr.state.KubernetesResources[kubernetesMgr.cluster] = append(kubernetesMgr.state.KubernetesResources[kubernetesMgr.cluster], templateName)
+	return nil
+}
+
+// ListResources returns an empty list, the templates are not rendered into resources
+func (kubernetesMgr *kubernetesResourceManager) ListResources(apiVersion string, kind string, namespace string) (*unstructured.UnstructuredList, error) {
+	return &unstructured.UnstructuredList{}, nil
+}
+
+// DeleteResource does nothing, the templates are not rendered into resources
+func (kubernetesMgr *kubernetesResourceManager) DeleteResource(apiVersion string, kind string, namespace string, name string) error {
+	return nil
+}
@@ -0,0 +1,36 @@
+package fake
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+)
+
+type logsManager struct {
+	state *State
+}
+
+// ViewLogs passes the events of the log group within the search duration to the callback.  Following is not
+// supported, there is nothing that can add events while the logs are being viewed.
+func (logsMgr *logsManager) ViewLogs(ctx context.Context, logGroup string, searchDuration time.Duration, follow bool, filter string, callback func(string, string, int64)) error {
+	logsMgr.state.mutex.Lock()
+	events, ok := logsMgr.state.Logs[logGroup]
+	logsMgr.state.mutex.Unlock()
+
+	if !ok {
+		return fmt.Errorf("ResourceNotFoundException: The specified log group does not exist: %s", logGroup)
+	}
+
+	startTime := time.Now().Add(-searchDuration).Unix() * 1000
+	for _, event := range events {
+		if event.Timestamp != 0 && event.Timestamp <= startTime {
+			continue
+		}
+		if filter != "" && !strings.Contains(event.Message, filter) {
+			continue
+		}
+		callback(event.Stream, event.Message, event.Timestamp)
+	}
+	return nil
+}
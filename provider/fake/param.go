@@ -0,0 +1,64 @@
+package fake
+
+import (
+	"fmt"
+)
+
+type paramManager struct {
+	state *State
+}
+
+// SetParam set the value of a parameter and increment its version
+func (paramMgr *paramManager) SetParam(name string, value string, kmsKey string) error {
+	paramMgr.state.mutex.Lock()
+	defer paramMgr.state.mutex.Unlock()
+
+	paramMgr.state.Params[name] = value
+	paramMgr.state.ParamVersions[name]++
+	return nil
+}
+
+// DeleteParam delete a parameter
+func (paramMgr *paramManager) DeleteParam(name string) error {
+	paramMgr.state.mutex.Lock()
+	defer paramMgr.state.mutex.Unlock()
+
+	if _, ok := paramMgr.state.Params[name]; !ok {
+		return fmt.Errorf("ParameterNotFound: %s", name)
+	}
+	delete(paramMgr.state.Params, name)
+	delete(paramMgr.state.ParamVersions, name)
+	return nil
+}
+
+// GetParam get the value of a parameter
+func (paramMgr *paramManager) GetParam(name string) (string, error) {
+	paramMgr.state.mutex.Lock()
+	defer paramMgr.state.mutex.Unlock()
+
+	value, ok := paramMgr.state.Params[name]
+	if !ok {
+		return "", fmt.Errorf("ParameterNotFound: %s", name)
+	}
+	return value, nil
+}
+
+// ParamVersion get the version of a parameter, or 0 if it doesn't exist
+func (paramMgr *paramManager) ParamVersion(name string) (int64, error) {
+	paramMgr.state.mutex.Lock()
+	defer paramMgr.state.mutex.Unlock()
+
+	return paramMgr.state.ParamVersions[name], nil
+}
+
+// GetSecret get the value of a secret, versions are ignored
+func (paramMgr *paramManager) GetSecret(secretID string, versionStage string, versionID string) (string, error) {
+	paramMgr.state.mutex.Lock()
+	defer paramMgr.state.mutex.Unlock()
+
+	value, ok := paramMgr.state.Secrets[secretID]
+	if !ok {
+		return "", fmt.Errorf("ResourceNotFoundException: %s", secretID)
+	}
+	return value, nil
+}
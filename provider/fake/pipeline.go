@@ -0,0 +1,113 @@
+package fake
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/stelligent/mu/common"
+)
+
+type pipelineManager struct {
+	state *State
+}
+
+// ListState returns no stages, the fake pipelines never run
+func (pipelineMgr *pipelineManager) ListState(pipelineName string) ([]common.PipelineStageState, error) {
+	return []common.PipelineStageState{}, nil
+}
+
+// GetGitInfo returns the git info from the parameters of the pipeline stack
+func (pipelineMgr *pipelineManager) GetGitInfo(pipelineName string) (common.GitInfo, error) {
+	pipelineMgr.state.mutex.Lock()
+	defer pipelineMgr.state.mutex.Unlock()
+
+	for _, stack := range pipelineMgr.state.Stacks {
+		if stack.Tags["type"] == common.StackTypePipeline && stack.Outputs["PipelineName"] == pipelineName {
+			return common.GitInfo{
+				Provider: stack.Parameters["SourceProvider"],
+				Slug:     stack.Parameters["SourceRepo"],
+				RepoName: stack.Parameters["SourceRepo"][strings.LastIndex(stack.Parameters["SourceRepo"], "/")+1:],
+				Revision: stack.Tags["revision"],
+			}, nil
+		}
+	}
+	return common.GitInfo{}, fmt.Errorf("PipelineNotFoundException: %s", pipelineName)
+}
+
+type subscriptionManager struct {
+	state *State
+}
+
+func subscriptionKey(topic string, protocol string, endpoint string) string {
+	return strings.Join([]string{topic, protocol, endpoint}, "|")
+}
+
+// CreateSubscription records the subscription
+func (subscriptionMgr *subscriptionManager) CreateSubscription(topic string, protocol string, endpoint string) error {
+	subscriptionMgr.state.mutex.Lock()
+	defer subscriptionMgr.state.mutex.Unlock()
+
+	subscriptionMgr.state.Subscriptions = append(subscriptionMgr.state.Subscriptions, subscriptionKey(topic, protocol, endpoint))
+	return nil
+}
+
+// GetSubscription returns the subscription if it exists, or nil
+func (subscriptionMgr *subscriptionManager) GetSubscription(topic string, protocol string, endpoint string) (interface{}, error) {
+	subscriptionMgr.state.mutex.Lock()
+	defer subscriptionMgr.state.mutex.Unlock()
+
+	key := subscriptionKey(topic, protocol, endpoint)
+	for _, subscription := range subscriptionMgr.state.Subscriptions {
+		if subscription == key {
+			return subscription, nil
+		}
+	}
+	return nil, nil
+}
+
+type catalogManager struct {
+	state *State
+}
+
+// SetProductVersions records the versions of a product
+func (catalogMgr *catalogManager) SetProductVersions(productID string, productVersions map[string]string) error {
+	catalogMgr.state.mutex.Lock()
+	defer catalogMgr.state.mutex.Unlock()
+
+	versions := make(map[string]string)
+	for version, templateURL := range productVersions {
+		versions[version] = templateURL
+	}
+	catalogMgr.state.ProductVersions[productID] = versions
+	return nil
+}
+
+// UpsertProvisionedProduct records the provisioned product, the version must have been set first
+func (catalogMgr *catalogManager) UpsertProvisionedProduct(ctx context.Context, productID string, version string, name string, params map[string]string) error {
+	catalogMgr.state.mutex.Lock()
+	defer catalogMgr.state.mutex.Unlock()
+
+	if _, ok := catalogMgr.state.ProductVersions[productID][version]; !ok {
+		return fmt.Errorf("Unable to find version '%s' for product '%s'", version, productID)
+	}
+	catalogMgr.state.ProvisionedProducts[name] = ProvisionedProduct{
+		ProductID: productID,
+		Version:   version,
+		Params:    params,
+	}
+	return nil
+}
+
+// TerminateProvisionedProducts removes all the provisioned products of a product
+func (catalogMgr *catalogManager) TerminateProvisionedProducts(ctx context.Context, productID string) error {
+	catalogMgr.state.mutex.Lock()
+	defer catalogMgr.state.mutex.Unlock()
+
+	for name, product := range catalogMgr.state.ProvisionedProducts {
+		if product.ProductID == productID {
+			delete(catalogMgr.state.ProvisionedProducts, name)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,180 @@
+package fake
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/stelligent/mu/common"
+)
+
+// rolesetManager keeps rolesets in IAM stacks of the fake stack manager, so they are visible to ListStacks and purge
+type rolesetManager struct {
+	context *common.Context
+}
+
+func (rolesetMgr *rolesetManager) stackName(names ...string) string {
+	return common.CreateStackName(rolesetMgr.context.Config.Namespace, common.StackTypeIam, names...)
+}
+
+func (rolesetMgr *rolesetManager) getRoleset(ctx context.Context, names ...string) common.Roleset {
+	stack := rolesetMgr.context.StackManager.AwaitFinalStatus(ctx, rolesetMgr.stackName(names...))
+	if stack == nil {
+		return make(common.Roleset)
+	}
+	return stack.Outputs
+}
+
+func (rolesetMgr *rolesetManager) upsertRoleset(ctx context.Context, templateName string, templateData interface{}, tags map[string]string, params map[string]string, names ...string) error {
+	if rolesetMgr.context.Config.DisableIAM {
+		return nil
+	}
+
+	stackName := rolesetMgr.stackName(names...)
+	tags["mu:type"] = common.StackTypeIam
+	params["Namespace"] = rolesetMgr.context.Config.Namespace
+
+	err := rolesetMgr.context.StackManager.UpsertStack(ctx, stackName, templateName, templateData, params, tags, "", "")
+	if err != nil {
+		return err
+	}
+
+	stack := rolesetMgr.context.StackManager.AwaitFinalStatus(ctx, stackName)
+	if stack == nil {
+		return fmt.Errorf("Unable to create stack %s", stackName)
+	}
+	if strings.HasSuffix(stack.Status, "ROLLBACK_COMPLETE") || !strings.HasSuffix(stack.Status, "_COMPLETE") {
+		return fmt.Errorf("Ended in failed status %s %s", stack.Status, stack.StatusReason)
+	}
+	return nil
+}
+
+func (rolesetMgr *rolesetManager) deleteRoleset(names ...string) error {
+	if rolesetMgr.context.Config.DisableIAM {
+		return nil
+	}
+	return rolesetMgr.context.StackManager.DeleteStack(rolesetMgr.stackName(names...))
+}
+
+func (rolesetMgr *rolesetManager) GetCommonRoleset(ctx context.Context) (common.Roleset, error) {
+	return rolesetMgr.getRoleset(ctx, "common"), nil
+}
+
+func (rolesetMgr *rolesetManager) GetEnvironmentRoleset(ctx context.Context, environmentName string) (common.Roleset, error) {
+	return rolesetMgr.getRoleset(ctx, "environment", environmentName), nil
+}
+
+func (rolesetMgr *rolesetManager) GetServiceRoleset(ctx context.Context, environmentName string, serviceName string) (common.Roleset, error) {
+	return rolesetMgr.getRoleset(ctx, "service", serviceName, environmentName), nil
+}
+
+func (rolesetMgr *rolesetManager) GetPipelineRoleset(ctx context.Context, serviceName string) (common.Roleset, error) {
+	return rolesetMgr.getRoleset(ctx, "pipeline", serviceName), nil
+}
+
+func (rolesetMgr *rolesetManager) GetEnvironmentProvider(ctx context.Context, environmentName string) (string, error) {
+	for _, e := range rolesetMgr.context.Config.Environments {
+		if strings.EqualFold(e.Name, environmentName) {
+			if e.Provider == "" {
+				return string(common.EnvProviderEcs), nil
+			}
+			return string(e.Provider), nil
+		}
+	}
+
+	envStackName := common.CreateStackName(rolesetMgr.context.Config.Namespace, common.StackTypeEnv, environmentName)
+	envStack := rolesetMgr.context.StackManager.AwaitFinalStatus(ctx, envStackName)
+	if envStack == nil {
+		return "", fmt.Errorf("unable to find environment stack named '%s'", envStackName)
+	}
+	return envStack.Tags["provider"], nil
+}
+
+func (rolesetMgr *rolesetManager) UpsertCommonRoleset(ctx context.Context) error {
+	err := rolesetMgr.upsertRoleset(ctx, common.TemplateCommonIAM, nil, map[string]string{}, map[string]string{}, "common")
+	if err != nil {
+		return err
+	}
+	if rolesetMgr.context.Config.DisableIAM {
+		return nil
+	}
+	return rolesetMgr.context.StackManager.SetTerminationProtection(ctx, rolesetMgr.stackName("common"), true)
+}
+
+func (rolesetMgr *rolesetManager) UpsertEnvironmentRoleset(ctx context.Context, environmentName string) error {
+	var environment *common.Environment
+	for _, e := range rolesetMgr.context.Config.Environments {
+		if strings.EqualFold(e.Name, environmentName) {
+			environment = &e
+			break
+		}
+	}
+	if environment == nil {
+		return nil
+	}
+
+	provider, _ := rolesetMgr.GetEnvironmentProvider(ctx, environmentName)
+	tags := map[string]string{
+		"mu:environment": environmentName,
+		"mu:provider":    provider,
+	}
+	params := map[string]string{
+		"EnvironmentName": environmentName,
+		"Provider":        provider,
+	}
+	return rolesetMgr.upsertRoleset(ctx, common.TemplateEnvIAM, environment, tags, params, "environment", environmentName)
+}
+
+func (rolesetMgr *rolesetManager) UpsertServiceRoleset(ctx context.Context, environmentName string, serviceName string, codeDeployBucket string, databaseName string) error {
+	provider, err := rolesetMgr.GetEnvironmentProvider(ctx, environmentName)
+	if err != nil {
+		return err
+	}
+	tags := map[string]string{
+		"mu:environment": environmentName,
+		"mu:provider":    provider,
+		"mu:service":     serviceName,
+	}
+	params := map[string]string{
+		"EnvironmentName":  environmentName,
+		"ServiceName":      serviceName,
+		"Provider":         provider,
+		"CodeDeployBucket": codeDeployBucket,
+		"DatabaseName":     databaseName,
+	}
+	return rolesetMgr.upsertRoleset(ctx, common.TemplateServiceIAM, rolesetMgr.context.Config.Service, tags, params, "service", serviceName, environmentName)
+}
+
+func (rolesetMgr *rolesetManager) UpsertPipelineRoleset(ctx context.Context, serviceName string, pipelineBucket string, codeDeployBucket string) error {
+	tags := map[string]string{
+		"mu:service": serviceName,
+	}
+	params := map[string]string{
+		"ServiceName":      serviceName,
+		"PipelineBucket":   pipelineBucket,
+		"CodeDeployBucket": codeDeployBucket,
+	}
+	return rolesetMgr.upsertRoleset(ctx, common.TemplatePipelineIAM, rolesetMgr.context.Config.Service.Pipeline, tags, params, "pipeline", serviceName)
+}
+
+func (rolesetMgr *rolesetManager) DeleteCommonRoleset(ctx context.Context) error {
+	if !rolesetMgr.context.Config.DisableIAM {
+		err := rolesetMgr.context.StackManager.SetTerminationProtection(ctx, rolesetMgr.stackName("common"), false)
+		if err != nil {
+			return err
+		}
+	}
+	return rolesetMgr.deleteRoleset("common")
+}
+
+func (rolesetMgr *rolesetManager) DeleteEnvironmentRoleset(ctx context.Context, environmentName string) error {
+	return rolesetMgr.deleteRoleset("environment", environmentName)
+}
+
+func (rolesetMgr *rolesetManager) DeleteServiceRoleset(ctx context.Context, environmentName string, serviceName string) error {
+	return rolesetMgr.deleteRoleset("service", serviceName, environmentName)
+}
+
+func (rolesetMgr *rolesetManager) DeletePipelineRoleset(ctx context.Context, serviceName string) error {
+	return rolesetMgr.deleteRoleset("pipeline", serviceName)
+}
@@ -0,0 +1,374 @@
+package fake
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/stelligent/mu/common"
+	"github.com/stelligent/mu/templates"
+)
+
+type stackManager struct {
+	state             *State
+	extensionsManager common.ExtensionsManager
+	planMode          bool
+	allowDataLoss     bool
+}
+
+func stackID(stackName string) string {
+	return fmt.Sprintf("arn:aws:cloudformation:%s:%s:stack/%s/fake", Region, AccountID, stackName)
+}
+
+// copyStack returns a copy so that callers can't modify the state without going through the manager
+func copyStack(stack *common.Stack) *common.Stack {
+	c := *stack
+	c.Tags = make(map[string]string)
+	c.Outputs = make(map[string]string)
+	c.Parameters = make(map[string]string)
+	for k, v := range stack.Tags {
+		c.Tags[k] = v
+	}
+	for k, v := range stack.Outputs {
+		c.Outputs[k] = v
+	}
+	for k, v := range stack.Parameters {
+		c.Parameters[k] = v
+	}
+	return &c
+}
+
+// AllowDataLoss records whether data loss is allowed
+func (stackMgr *stackManager) AllowDataLoss(allow bool) {
+	stackMgr.allowDataLoss = allow
+}
+
+// PlanChanges will cause upserts and deletes to be skipped
+func (stackMgr *stackManager) PlanChanges(enabled bool) {
+	stackMgr.planMode = enabled
+}
+
+// CancelUpdatesOnInterrupt has no effect, fake stacks reach their final status as soon as they are upserted
+func (stackMgr *stackManager) CancelUpdatesOnInterrupt(enabled bool) {
+}
+
+// SetTerminationProtection sets the flag on the stack
+func (stackMgr *stackManager) SetTerminationProtection(ctx context.Context, stackName string, enabled bool) error {
+	if stackMgr.planMode {
+		return nil
+	}
+
+	stackMgr.state.mutex.Lock()
+	defer stackMgr.state.mutex.Unlock()
+
+	stack, ok := stackMgr.state.Stacks[stackName]
+	if !ok {
+		return fmt.Errorf("Stack with id %s does not exist", stackName)
+	}
+	stack.EnableTerminationProtection = enabled
+	return nil
+}
+
+// UpsertStack renders the template and records the stack in the state, running the stack hooks of the extensions around it
+func (stackMgr *stackManager) UpsertStack(ctx context.Context, stackName string, templateName string, templateData interface{}, parameters map[string]string, tags map[string]string, policy string, roleArn string) error {
+	templateBody, err := templates.GetAsset(templateName, templates.ExecuteTemplate(templateData))
+	if err != nil {
+		return err
+	}
+
+	if stackMgr.planMode {
+		log.Debugf("PLAN: Skipping upsert of stack '%s'", stackName)
+		return nil
+	}
+
+	if stackMgr.extensionsManager != nil {
+		err = stackMgr.extensionsManager.BeforeStackUpsert(stackName)
+		if err != nil {
+			return err
+		}
+	}
+
+	stack := stackMgr.recordStack(stackName, templateName, templateBody, parameters, tags)
+	stackMgr.afterStackUpsert(stack)
+	return nil
+}
+
+// afterStackUpsert runs the AfterStackUpsert hooks for a successful upsert, a failing hook fails the stack
+func (stackMgr *stackManager) afterStackUpsert(stack *common.Stack) {
+	if stackMgr.extensionsManager == nil || strings.HasSuffix(stack.Status, "ROLLBACK_COMPLETE") {
+		return
+	}
+
+	err := stackMgr.extensionsManager.AfterStackUpsert(stack)
+	if err != nil {
+		log.Errorf("%v", err)
+		stackMgr.state.mutex.Lock()
+		defer stackMgr.state.mutex.Unlock()
+		stackMgr.state.Stacks[stack.Name].Status = common.StackStatusHookFailed
+		stackMgr.state.Stacks[stack.Name].StatusReason = err.Error()
+	}
+}
+
+// recordStack records the upserted stack in the state, and returns a copy of it
+func (stackMgr *stackManager) recordStack(stackName string, templateName string, templateBody string, parameters map[string]string, tags map[string]string) *common.Stack {
+	stackMgr.state.mutex.Lock()
+	defer stackMgr.state.mutex.Unlock()
+
+	stack, exists := stackMgr.state.Stacks[stackName]
+	if !exists {
+		stack = &common.Stack{
+			ID:   stackID(stackName),
+			Name: stackName,
+		}
+		initStackMaps(stack)
+	} else if stack.Status == common.StackStatusRollbackComplete {
+		// same as cloudformation, stacks that failed to create are replaced
+		stack.Parameters = make(map[string]string)
+		exists = false
+	}
+
+	log.Debugf("Upserting stack '%s' from template '%s'", stackName, templateName)
+
+	for key, value := range parameters {
+		// an empty value means use the previous value
+		if value != "" || !exists {
+			stack.Parameters[key] = value
+		}
+	}
+	stack.Tags = map[string]string{"version": common.GetVersion()}
+	for key, value := range tags {
+		if strings.HasPrefix(key, "mu:") && value != "" {
+			stack.Tags[key[3:]] = value
+		}
+	}
+	stack.Outputs = defaultOutputs(stack, len(stackMgr.state.Revisions[stackName])+1)
+	for key, value := range stackMgr.state.StackOutputs[stackName] {
+		stack.Outputs[key] = value
+	}
+	stack.LastUpdateTime = time.Now()
+
+	if reason, ok := stackMgr.state.StackFailures[stackName]; ok {
+		stack.StatusReason = reason
+		if exists {
+			stack.Status = common.StackStatusUpdateRollbackComplete
+		} else {
+			stack.Status = common.StackStatusRollbackComplete
+		}
+	} else {
+		stack.StatusReason = ""
+		if exists {
+			stack.Status = common.StackStatusUpdateComplete
+		} else {
+			stack.Status = common.StackStatusCreateComplete
+		}
+		if imageURL := stack.Parameters["ImageUrl"]; stack.Tags["type"] == string(common.StackTypeService) && imageURL != "" {
+			stackMgr.state.Revisions[stackName] = append([]common.ServiceRevision{
+				{
+					Revision:   common.ImageTag(imageURL),
+					ImageURL:   imageURL,
+					DeployedAt: stack.LastUpdateTime,
+				},
+			}, stackMgr.state.Revisions[stackName]...)
+		}
+		if provider := common.EnvProvider(stack.Tags["provider"]); stack.Tags["type"] == string(common.StackTypeService) &&
+			(provider == common.EnvProviderEcs || provider == common.EnvProviderEcsFargate) {
+			// the service replaces its tasks with one running task of the new task definition
+			stackMgr.state.removeServiceTasks(stack.Tags["environment"], stack.Tags["service"])
+			stackMgr.state.Tasks = append(stackMgr.state.Tasks, common.Task{
+				Name:           fmt.Sprintf("%s-%d", stackName, len(stackMgr.state.Revisions[stackName])),
+				Environment:    stack.Tags["environment"],
+				Service:        stack.Tags["service"],
+				Status:         "RUNNING",
+				TaskDefinition: stack.Outputs["MicroserviceTaskDefinitionArn"],
+			})
+		}
+	}
+
+	stackMgr.state.Stacks[stackName] = stack
+	stackMgr.state.Templates[stackName] = templateBody
+	return copyStack(stack)
+}
+
+// defaultOutputs are the outputs that the workflows depend on from the real templates, revision is the
+// revision of the task definition for services
+func defaultOutputs(stack *common.Stack, revision int) map[string]string {
+	outputs := make(map[string]string)
+	switch stack.Tags["type"] {
+	case common.StackTypeEnv:
+		outputs["provider"] = stack.Tags["provider"]
+		outputs["EcsCluster"] = stack.Name
+		if count := stack.Parameters["EfsMountTargetCount"]; count != "" && count != "0" {
+			outputs["EfsFileSystemId"] = fmt.Sprintf("fs-%s", stack.Name)
+		}
+		if stack.Parameters["CapacityProviderEnabled"] == "true" {
+			outputs["EcsCapacityProvider"] = fmt.Sprintf("%s-EcsCapacityProvider", stack.Name)
+		}
+	case string(common.StackTypeVpc):
+		outputs["InstanceSubnetIds"] = "subnet-1,subnet-2,subnet-3"
+	case common.StackTypeTarget:
+		outputs["InstanceSubnetIds"] = stack.Parameters["InstanceSubnetIds"]
+	case common.StackTypeLoadBalancer:
+		outputs["ServiceDiscoveryName"] = stack.Parameters["ServiceDiscoveryName"]
+		if stack.Parameters["ElbType"] == common.LoadbalancerTypeNetwork {
+			outputs["ElbType"] = common.LoadbalancerTypeNetwork
+			outputs["ElbArn"] = fmt.Sprintf("arn:aws:elasticloadbalancing:%s:%s:loadbalancer/net/%s/fake", Region, AccountID, stack.Name)
+			scheme := "http"
+			if stack.Parameters["ElbCert"] != "" {
+				scheme = "https"
+			}
+			outputs["BaseUrl"] = fmt.Sprintf("%s://%s.elb.amazonaws.com", scheme, stack.Name)
+			break
+		}
+		outputs["ElbType"] = string(common.LoadbalancerTypeApplication)
+		outputs["ElbFullName"] = fmt.Sprintf("app/%s/fake", stack.Name)
+		outputs["ElbHttpListenerArn"] = fmt.Sprintf("arn:aws:elasticloadbalancing:%s:%s:listener/app/%s/fake/http", Region, AccountID, stack.Name)
+		outputs["BaseUrl"] = fmt.Sprintf("http://%s.elb.amazonaws.com", stack.Name)
+	case common.StackTypeRepo:
+		outputs["RepoUrl"] = fmt.Sprintf("%s.dkr.ecr.%s.amazonaws.com/%s", AccountID, Region, stack.Parameters["RepoName"])
+	case common.StackTypeApp:
+		outputs["ApplicationName"] = stack.Name
+	case common.StackTypeBucket:
+		outputs["Bucket"] = fmt.Sprintf("%s-%s", stack.Name, AccountID)
+	case common.StackTypeService:
+		outputs["MicroserviceTaskDefinitionArn"] = fmt.Sprintf("arn:aws:ecs:%s:%s:task-definition/%s:%d", Region, AccountID, stack.Name, revision)
+		outputs["CodeDeployApplication"] = stack.Name
+		outputs["CodeDeployDeploymentGroup"] = stack.Name
+		if provider := common.EnvProvider(stack.Tags["provider"]); provider == common.EnvProviderEcs || provider == common.EnvProviderEcsFargate {
+			outputs["ServiceDiscoveryArn"] = fmt.Sprintf("arn:aws:servicediscovery:%s:%s:service/srv-%s", Region, AccountID, stack.Name)
+		}
+	case common.StackTypePipeline:
+		outputs["PipelineName"] = stack.Name
+	case common.StackTypeIam:
+		for _, role := range iamRoles(stack.Tags) {
+			outputs[role] = fmt.Sprintf("arn:aws:iam::%s:role/%s-%s", AccountID, stack.Name, role)
+		}
+	case common.StackTypeDatabase:
+		outputs["DatabaseName"] = stack.Parameters["DatabaseName"]
+		outputs["DatabaseEndpointAddress"] = fmt.Sprintf("%s.rds.amazonaws.com", stack.Name)
+		outputs["DatabaseEndpointPort"] = "3306"
+		outputs["DatabaseMasterUsername"] = stack.Parameters["DatabaseMasterUsername"]
+	}
+	return outputs
+}
+
+// iamRoles are the outputs of the IAM templates, which are identified by the service and environment tags
+func iamRoles(tags map[string]string) []string {
+	switch {
+	case tags["service"] != "" && tags["environment"] != "":
+		return []string{"EC2InstanceProfileArn", "CodeDeployRoleArn", "EcsEventsRoleArn", "EcsServiceRoleArn", "EcsTaskRoleArn", "ApplicationAutoScalingRoleArn"}
+	case tags["environment"] != "":
+		return []string{"EC2InstanceProfileArn", "EC2RoleArn", "EksServiceRoleArn"}
+	case tags["service"] != "":
+		return []string{"CodePipelineKeyArn", "CodePipelineRoleArn", "CodeBuildCIRoleArn", "CodeBuildCDAcptRoleArn", "CodeBuildCDProdRoleArn", "MuAcptRoleArn", "MuProdRoleArn"}
+	}
+	return []string{"CloudFormationRoleArn"}
+}
+
+// AwaitFinalStatus returns a copy of the stack, or nil if it doesn't exist
+func (stackMgr *stackManager) AwaitFinalStatus(ctx context.Context, stackName string) *common.Stack {
+	stack, err := stackMgr.GetStack(stackName)
+	if err != nil {
+		return nil
+	}
+	return stack
+}
+
+// DeleteStack removes the stack from the state, deleting a stack that doesn't exist is not an error
+func (stackMgr *stackManager) DeleteStack(stackName string) error {
+	if stackMgr.planMode {
+		log.Debugf("PLAN: Skipping delete of stack '%s'", stackName)
+		return nil
+	}
+
+	stackMgr.state.mutex.Lock()
+	defer stackMgr.state.mutex.Unlock()
+
+	stack, ok := stackMgr.state.Stacks[stackName]
+	if !ok {
+		return nil
+	}
+	if stack.EnableTerminationProtection {
+		return fmt.Errorf("Stack [%s] cannot be deleted while TerminationProtection is enabled", stackName)
+	}
+
+	log.Debugf("Deleting stack '%s'", stackName)
+	if stack.Tags["type"] == string(common.StackTypeService) {
+		stackMgr.state.removeServiceTasks(stack.Tags["environment"], stack.Tags["service"])
+	}
+	delete(stackMgr.state.Stacks, stackName)
+	delete(stackMgr.state.Templates, stackName)
+	return nil
+}
+
+// ListStacks will find mu stacks
+func (stackMgr *stackManager) ListStacks(stackType common.StackType, namespace string) ([]*common.Stack, error) {
+	stackMgr.state.mutex.Lock()
+	defer stackMgr.state.mutex.Unlock()
+
+	var stacks []*common.Stack
+	expectedStackPrefix := fmt.Sprintf("%s-%s", namespace, stackType)
+	for _, stack := range stackMgr.state.Stacks {
+		if stack.Tags["type"] == string(stackType) &&
+			(strings.HasPrefix(stack.Name, expectedStackPrefix) || strings.HasPrefix(stack.Tags["name"], expectedStackPrefix)) {
+			stacks = append(stacks, copyStack(stack))
+		}
+	}
+
+	sort.Slice(stacks, func(i, j int) bool {
+		return stacks[i].Name < stacks[j].Name
+	})
+	return stacks, nil
+}
+
+// GetStack get a specific stack
+func (stackMgr *stackManager) GetStack(stackName string) (*common.Stack, error) {
+	stackMgr.state.mutex.Lock()
+	defer stackMgr.state.mutex.Unlock()
+
+	stack, ok := stackMgr.state.Stacks[stackName]
+	if !ok {
+		return nil, fmt.Errorf("Stack with id %s does not exist", stackName)
+	}
+	return copyStack(stack), nil
+}
+
+// DetectStackDrift returns the name of the stack as the id of the detection
+func (stackMgr *stackManager) DetectStackDrift(stackName string) (string, error) {
+	stackMgr.state.mutex.Lock()
+	defer stackMgr.state.mutex.Unlock()
+
+	if _, ok := stackMgr.state.Stacks[stackName]; !ok {
+		return "", fmt.Errorf("Stack with id %s does not exist", stackName)
+	}
+	return stackName, nil
+}
+
+// AwaitStackDrift reports the stack as drifted if the state has drifted resources for it
+func (stackMgr *stackManager) AwaitStackDrift(ctx context.Context, stackName string, detectionID string) (*common.StackDrift, error) {
+	stackMgr.state.mutex.Lock()
+	defer stackMgr.state.mutex.Unlock()
+
+	drift := &common.StackDrift{
+		StackName:     stackName,
+		Status:        common.StackDriftStatusInSync,
+		DetectionTime: time.Now(),
+		Resources:     make([]common.StackResourceDrift, 0),
+	}
+	if resources := stackMgr.state.StackDrifts[stackName]; len(resources) > 0 {
+		drift.Status = common.StackDriftStatusDrifted
+		drift.Resources = append(drift.Resources, resources...)
+	}
+	return drift, nil
+}
+
+// FindLatestImageID returns the same fake image for every pattern
+func (stackMgr *stackManager) FindLatestImageID(owner string, namePattern string) (string, error) {
+	return ImageID, nil
+}
+
+// CountAZs returns the AZCount of the state
+func (stackMgr *stackManager) CountAZs() (int, error) {
+	return stackMgr.state.AZCount, nil
+}
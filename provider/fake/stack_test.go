@@ -0,0 +1,148 @@
+package fake
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stelligent/mu/common"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStack_UpsertStack(t *testing.T) {
+	assert := assert.New(t)
+
+	state := NewState()
+	state.StackOutputs["mu-repo-api"] = map[string]string{"Extra": "value"}
+	stackMgr := &stackManager{state: state}
+
+	err := stackMgr.UpsertStack(context.Background(), "mu-repo-api", common.TemplateRepo, nil, map[string]string{"RepoName": "mu-api"}, map[string]string{"mu:type": common.StackTypeRepo}, "", "")
+	assert.Nil(err)
+
+	stack := stackMgr.AwaitFinalStatus(context.Background(), "mu-repo-api")
+	assert.NotNil(stack)
+	assert.Equal(common.StackStatusCreateComplete, stack.Status)
+	assert.Equal("123456789012.dkr.ecr.us-east-1.amazonaws.com/mu-api", stack.Outputs["RepoUrl"])
+	assert.Equal("value", stack.Outputs["Extra"])
+	assert.Equal(common.StackTypeRepo, stack.Tags["type"])
+	assert.Contains(state.Templates["mu-repo-api"], "AWS::ECR::Repository")
+
+	// empty parameters keep their previous value
+	err = stackMgr.UpsertStack(context.Background(), "mu-repo-api", common.TemplateRepo, nil, map[string]string{"RepoName": ""}, map[string]string{"mu:type": common.StackTypeRepo}, "", "")
+	assert.Nil(err)
+	stack = stackMgr.AwaitFinalStatus(context.Background(), "mu-repo-api")
+	assert.Equal(common.StackStatusUpdateComplete, stack.Status)
+	assert.Equal("mu-api", stack.Parameters["RepoName"])
+
+	// callers get copies of the stack
+	stack.Outputs["RepoUrl"] = "changed"
+	assert.Equal("123456789012.dkr.ecr.us-east-1.amazonaws.com/mu-api", state.Stacks["mu-repo-api"].Outputs["RepoUrl"])
+}
+
+func TestStack_UpsertStack_Failure(t *testing.T) {
+	assert := assert.New(t)
+
+	state := NewState()
+	state.StackFailures["mu-repo-api"] = "Resource creation cancelled"
+	stackMgr := &stackManager{state: state}
+
+	err := stackMgr.UpsertStack(context.Background(), "mu-repo-api", common.TemplateRepo, nil, map[string]string{}, map[string]string{"mu:type": common.StackTypeRepo}, "", "")
+	assert.Nil(err)
+
+	stack := stackMgr.AwaitFinalStatus(context.Background(), "mu-repo-api")
+	assert.Equal(common.StackStatusRollbackComplete, stack.Status)
+	assert.Equal("Resource creation cancelled", stack.StatusReason)
+}
+
+func TestStack_UpsertStack_InvalidTemplate(t *testing.T) {
+	assert := assert.New(t)
+
+	stackMgr := &stackManager{state: NewState()}
+
+	err := stackMgr.UpsertStack(context.Background(), "mu-repo-api", "does-not-exist.yml", nil, map[string]string{}, map[string]string{}, "", "")
+	assert.NotNil(err)
+	assert.Nil(stackMgr.AwaitFinalStatus(context.Background(), "mu-repo-api"))
+}
+
+func TestStack_DeleteStack(t *testing.T) {
+	assert := assert.New(t)
+
+	stackMgr := &stackManager{state: NewState()}
+
+	err := stackMgr.UpsertStack(context.Background(), "mu-iam-common", common.TemplateCommonIAM, nil, map[string]string{}, map[string]string{"mu:type": common.StackTypeIam}, "", "")
+	assert.Nil(err)
+	assert.Nil(stackMgr.SetTerminationProtection(context.Background(), "mu-iam-common", true))
+
+	assert.NotNil(stackMgr.DeleteStack("mu-iam-common"))
+	assert.NotNil(stackMgr.AwaitFinalStatus(context.Background(), "mu-iam-common"))
+
+	assert.Nil(stackMgr.SetTerminationProtection(context.Background(), "mu-iam-common", false))
+	assert.Nil(stackMgr.DeleteStack("mu-iam-common"))
+	assert.Nil(stackMgr.AwaitFinalStatus(context.Background(), "mu-iam-common"))
+
+	// deleting a stack that doesn't exist is not an error
+	assert.Nil(stackMgr.DeleteStack("mu-iam-common"))
+}
+
+func TestStack_ListStacks(t *testing.T) {
+	assert := assert.New(t)
+
+	stackMgr := &stackManager{state: NewState()}
+	for _, name := range []string{"mu-repo-b", "mu-repo-a", "other-repo-c"} {
+		err := stackMgr.UpsertStack(context.Background(), name, common.TemplateRepo, nil, map[string]string{}, map[string]string{"mu:type": common.StackTypeRepo}, "", "")
+		assert.Nil(err)
+	}
+
+	stacks, err := stackMgr.ListStacks(common.StackTypeRepo, "mu")
+	assert.Nil(err)
+	assert.Equal(2, len(stacks))
+	assert.Equal("mu-repo-a", stacks[0].Name)
+	assert.Equal("mu-repo-b", stacks[1].Name)
+}
+
+// hookRecorder records the stack hooks that ran, the other extension methods aren't used by the stack manager
+type hookRecorder struct {
+	common.ExtensionsManager
+	hooks     []string
+	beforeErr error
+	afterErr  error
+}
+
+func (r *hookRecorder) BeforeStackUpsert(stackName string) error {
+	r.hooks = append(r.hooks, "before:"+stackName)
+	return r.beforeErr
+}
+
+func (r *hookRecorder) AfterStackUpsert(stack *common.Stack) error {
+	r.hooks = append(r.hooks, "after:"+stack.Name+":"+stack.Status)
+	return r.afterErr
+}
+
+func TestStack_UpsertStack_Hooks(t *testing.T) {
+	assert := assert.New(t)
+
+	state := NewState()
+	state.StackFailures["mu-repo-failed"] = "Resource creation cancelled"
+	hooks := &hookRecorder{}
+	stackMgr := &stackManager{state: state, extensionsManager: hooks}
+
+	err := stackMgr.UpsertStack(context.Background(), "mu-repo-api", common.TemplateRepo, nil, map[string]string{}, map[string]string{"mu:type": common.StackTypeRepo}, "", "")
+	assert.Nil(err)
+	err = stackMgr.UpsertStack(context.Background(), "mu-repo-failed", common.TemplateRepo, nil, map[string]string{}, map[string]string{"mu:type": common.StackTypeRepo}, "", "")
+	assert.Nil(err)
+	assert.Equal([]string{"before:mu-repo-api", "after:mu-repo-api:CREATE_COMPLETE", "before:mu-repo-failed"}, hooks.hooks)
+
+	// a failing after hook fails the stack
+	hooks.afterErr = errors.New("smoke test failed")
+	err = stackMgr.UpsertStack(context.Background(), "mu-repo-api", common.TemplateRepo, nil, map[string]string{}, map[string]string{"mu:type": common.StackTypeRepo}, "", "")
+	assert.Nil(err)
+	stack := stackMgr.AwaitFinalStatus(context.Background(), "mu-repo-api")
+	assert.Equal(common.StackStatusHookFailed, stack.Status)
+	assert.Equal("smoke test failed", stack.StatusReason)
+
+	// a failing before hook aborts the upsert
+	hooks.beforeErr = errors.New("policy violation")
+	err = stackMgr.UpsertStack(context.Background(), "mu-repo-web", common.TemplateRepo, nil, map[string]string{}, map[string]string{"mu:type": common.StackTypeRepo}, "", "")
+	assert.NotNil(err)
+	assert.Nil(stackMgr.AwaitFinalStatus(context.Background(), "mu-repo-web"))
+}
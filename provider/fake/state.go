@@ -0,0 +1,243 @@
+package fake
+
+import (
+	"io"
+	"io/ioutil"
+	"os"
+	"sort"
+	"sync"
+
+	"github.com/aws/aws-sdk-go/service/elbv2"
+	"github.com/stelligent/mu/common"
+	"gopkg.in/yaml.v2"
+)
+
+// State is the in-memory state shared by all the fake managers.  It can be seeded from a YAML fixture and inspected
+// by tests after running workflows against it.
+type State struct {
+	// Stacks keyed by stack name
+	Stacks map[string]*common.Stack `yaml:"stacks"`
+	// StackOutputs are added to a stack when it is upserted, keyed by stack name
+	StackOutputs map[string]map[string]string `yaml:"stackOutputs"`
+	// StackFailures causes the upsert of a stack to end in a rollback with the reason, keyed by stack name
+	StackFailures map[string]string `yaml:"stackFailures"`
+	// StackDrifts are the resources that drift detection reports as changed outside of CloudFormation, keyed by stack name
+	StackDrifts map[string][]common.StackResourceDrift `yaml:"stackDrifts"`
+	// Templates are the rendered templates of the upserted stacks, keyed by stack name
+	Templates map[string]string `yaml:"-"`
+	// AZCount is the number of availability zones in the region, defaults to 3
+	AZCount int `yaml:"azCount"`
+
+	// Params are the SSM parameters, keyed by name
+	Params        map[string]string `yaml:"params"`
+	ParamVersions map[string]int64  `yaml:"-"`
+	// Secrets are the Secrets Manager secrets, keyed by ARN
+	Secrets map[string]string `yaml:"secrets"`
+
+	// Objects are the S3 objects, keyed by s3:// URL
+	Objects map[string]string `yaml:"objects"`
+
+	// Tasks are the ECS tasks running in all environments
+	Tasks []common.Task `yaml:"tasks"`
+	// Revisions are the images each service has been deployed with, most recent first, keyed by stack name
+	Revisions map[string][]common.ServiceRevision `yaml:"revisions"`
+
+	// Deployments are the CodeDeploy deployments, keyed by deployment ID
+	Deployments map[string]*Deployment `yaml:"deployments"`
+	// DeploymentFailures causes the deployments to a deployment group to be stopped with the reason, keyed by deployment group name
+	DeploymentFailures map[string]string `yaml:"deploymentFailures"`
+
+	// Rules are the ELB listener rules, keyed by listener ARN
+	Rules map[string][]*elbv2.Rule `yaml:"rules"`
+
+	// Logs are the CloudWatch log events, keyed by log group
+	Logs map[string][]LogEvent `yaml:"logs"`
+
+	// Images are the docker images that have been built, and whether they have been pushed
+	Images map[string]bool `yaml:"images"`
+	// ContainerOutputs are written to stdout when a container is run, keyed by image
+	ContainerOutputs map[string]string `yaml:"containerOutputs"`
+
+	// Subscriptions are the SNS subscriptions, as 'topic|protocol|endpoint'
+	Subscriptions []string `yaml:"subscriptions"`
+
+	// IamAuthentication is whether IAM authentication is enabled, keyed by database instance
+	IamAuthentication map[string]bool `yaml:"iamAuthentication"`
+
+	// ProductVersions are the service catalog product versions, keyed by product ID
+	ProductVersions map[string]map[string]string `yaml:"productVersions"`
+	// ProvisionedProducts are the provisioned service catalog products, keyed by name
+	ProvisionedProducts map[string]ProvisionedProduct `yaml:"provisionedProducts"`
+
+	// KubernetesResources are the templates that have been applied, keyed by cluster name
+	KubernetesResources map[string][]string `yaml:"kubernetesResources"`
+
+	mutex sync.Mutex
+}
+
+// LogEvent is a CloudWatch log event
+type LogEvent struct {
+	Stream    string `yaml:"stream"`
+	Message   string `yaml:"message"`
+	Timestamp int64  `yaml:"timestamp"`
+}
+
+// Deployment is a CodeDeploy deployment of a task definition
+type Deployment struct {
+	ApplicationName     string `yaml:"applicationName"`
+	DeploymentGroupName string `yaml:"deploymentGroupName"`
+	TaskDefinitionArn   string `yaml:"taskDefinitionArn"`
+	Status              string `yaml:"status"`
+}
+
+// ProvisionedProduct is a provisioned service catalog product
+type ProvisionedProduct struct {
+	ProductID string            `yaml:"productId"`
+	Version   string            `yaml:"version"`
+	Params    map[string]string `yaml:"params"`
+}
+
+// NewState creates an empty state
+func NewState() *State {
+	state := new(State)
+	state.init()
+	return state
+}
+
+// LoadState creates a state seeded from a YAML fixture
+func LoadState(reader io.Reader) (*State, error) {
+	data, err := ioutil.ReadAll(reader)
+	if err != nil {
+		return nil, err
+	}
+
+	state := new(State)
+	if err = yaml.Unmarshal(data, state); err != nil {
+		return nil, err
+	}
+	state.init()
+
+	for name, stack := range state.Stacks {
+		if stack.Name == "" {
+			stack.Name = name
+		}
+		if stack.ID == "" {
+			stack.ID = stackID(name)
+		}
+		if stack.Status == "" {
+			stack.Status = common.StackStatusCreateComplete
+		}
+		initStackMaps(stack)
+	}
+	for name := range state.Params {
+		state.ParamVersions[name] = 1
+	}
+	return state, nil
+}
+
+// LoadStateFile creates a state seeded from a YAML fixture file
+func LoadStateFile(path string) (*State, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return LoadState(f)
+}
+
+func (state *State) init() {
+	if state.Stacks == nil {
+		state.Stacks = make(map[string]*common.Stack)
+	}
+	if state.StackOutputs == nil {
+		state.StackOutputs = make(map[string]map[string]string)
+	}
+	if state.StackFailures == nil {
+		state.StackFailures = make(map[string]string)
+	}
+	if state.StackDrifts == nil {
+		state.StackDrifts = make(map[string][]common.StackResourceDrift)
+	}
+	if state.Templates == nil {
+		state.Templates = make(map[string]string)
+	}
+	if state.AZCount == 0 {
+		state.AZCount = 3
+	}
+	if state.Params == nil {
+		state.Params = make(map[string]string)
+	}
+	if state.ParamVersions == nil {
+		state.ParamVersions = make(map[string]int64)
+	}
+	if state.Secrets == nil {
+		state.Secrets = make(map[string]string)
+	}
+	if state.Objects == nil {
+		state.Objects = make(map[string]string)
+	}
+	if state.Tasks == nil {
+		state.Tasks = make([]common.Task, 0)
+	}
+	if state.Revisions == nil {
+		state.Revisions = make(map[string][]common.ServiceRevision)
+	}
+	if state.Deployments == nil {
+		state.Deployments = make(map[string]*Deployment)
+	}
+	if state.DeploymentFailures == nil {
+		state.DeploymentFailures = make(map[string]string)
+	}
+	if state.Rules == nil {
+		state.Rules = make(map[string][]*elbv2.Rule)
+	}
+	if state.Logs == nil {
+		state.Logs = make(map[string][]LogEvent)
+	}
+	if state.Images == nil {
+		state.Images = make(map[string]bool)
+	}
+	if state.ContainerOutputs == nil {
+		state.ContainerOutputs = make(map[string]string)
+	}
+	if state.Subscriptions == nil {
+		state.Subscriptions = make([]string, 0)
+	}
+	if state.IamAuthentication == nil {
+		state.IamAuthentication = make(map[string]bool)
+	}
+	if state.ProductVersions == nil {
+		state.ProductVersions = make(map[string]map[string]string)
+	}
+	if state.ProvisionedProducts == nil {
+		state.ProvisionedProducts = make(map[string]ProvisionedProduct)
+	}
+	if state.KubernetesResources == nil {
+		state.KubernetesResources = make(map[string][]string)
+	}
+}
+
+func initStackMaps(stack *common.Stack) {
+	if stack.Tags == nil {
+		stack.Tags = make(map[string]string)
+	}
+	if stack.Outputs == nil {
+		stack.Outputs = make(map[string]string)
+	}
+	if stack.Parameters == nil {
+		stack.Parameters = make(map[string]string)
+	}
+}
+
+// StackNames returns the names of all stacks in the state
+func (state *State) StackNames() []string {
+	state.mutex.Lock()
+	defer state.mutex.Unlock()
+
+	names := make([]string, 0, len(state.Stacks))
+	for name := range state.Stacks {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,74 @@
+package fake
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/stelligent/mu/common"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoadState(t *testing.T) {
+	assert := assert.New(t)
+
+	yamlConfig :=
+		`
+---
+stacks:
+  mu-environment-dev:
+    tags:
+      type: environment
+      provider: ecs
+    outputs:
+      EcsCluster: mu-environment-dev
+  mu-service-api-dev:
+    status: UPDATE_ROLLBACK_COMPLETE
+    tags:
+      type: service
+stackFailures:
+  mu-service-api-dev: boom
+params:
+  mu-database-api-dev-DatabaseMasterPassword: secret
+objects:
+  s3://bucket/key: hello
+rules:
+  arn:listener:
+  - priority: "5"
+tasks:
+- name: task-1
+  environment: dev
+  service: api
+azCount: 2
+`
+
+	state, err := LoadState(bytes.NewBufferString(yamlConfig))
+	assert.Nil(err)
+
+	assert.Equal([]string{"mu-environment-dev", "mu-service-api-dev"}, state.StackNames())
+
+	envStack := state.Stacks["mu-environment-dev"]
+	assert.Equal("mu-environment-dev", envStack.Name)
+	assert.Equal(common.StackStatusCreateComplete, envStack.Status)
+	assert.Equal("ecs", envStack.Tags["provider"])
+	assert.Equal("mu-environment-dev", envStack.Outputs["EcsCluster"])
+	assert.NotNil(envStack.Parameters)
+	assert.Equal(common.StackStatusUpdateRollbackComplete, state.Stacks["mu-service-api-dev"].Status)
+
+	assert.Equal("boom", state.StackFailures["mu-service-api-dev"])
+	assert.Equal("secret", state.Params["mu-database-api-dev-DatabaseMasterPassword"])
+	assert.Equal(int64(1), state.ParamVersions["mu-database-api-dev-DatabaseMasterPassword"])
+	assert.Equal("hello", state.Objects["s3://bucket/key"])
+	assert.Equal("5", aws.StringValue(state.Rules["arn:listener"][0].Priority))
+	assert.Equal("task-1", state.Tasks[0].Name)
+	assert.Equal(2, state.AZCount)
+}
+
+func TestNewState(t *testing.T) {
+	assert := assert.New(t)
+
+	state := NewState()
+	assert.Equal(3, state.AZCount)
+	assert.Empty(state.StackNames())
+	assert.NotNil(state.Templates)
+}
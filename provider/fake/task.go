@@ -0,0 +1,99 @@
+package fake
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ecs"
+	"github.com/stelligent/mu/common"
+)
+
+type taskManager struct {
+	state *State
+}
+
+// ListTasks lists the tasks of a service in an environment
+func (taskMgr *taskManager) ListTasks(namespace string, environment string, serviceName string) ([]common.Task, error) {
+	taskMgr.state.mutex.Lock()
+	defer taskMgr.state.mutex.Unlock()
+
+	tasks := []common.Task{}
+	for _, task := range taskMgr.state.Tasks {
+		if task.Environment != environment || (serviceName != "" && task.Service != serviceName) {
+			continue
+		}
+		tasks = append(tasks, task)
+	}
+	return tasks, nil
+}
+
+// StopTask removes a task
+func (taskMgr *taskManager) StopTask(namespace string, environment string, task string) error {
+	taskMgr.state.mutex.Lock()
+	defer taskMgr.state.mutex.Unlock()
+
+	for i, t := range taskMgr.state.Tasks {
+		if t.Environment == environment && t.Name == task {
+			taskMgr.state.Tasks = append(taskMgr.state.Tasks[:i], taskMgr.state.Tasks[i+1:]...)
+			return nil
+		}
+	}
+	return fmt.Errorf("InvalidParameterException: The referenced task was not found")
+}
+
+// ExecuteCommand records a stopped task for the command, using the task definition of the service stack
+func (taskMgr *taskManager) ExecuteCommand(namespace string, task common.Task) (common.ECSRunTaskResult, error) {
+	svcStackName := common.CreateStackName(namespace, common.StackTypeService, task.Service, task.Environment)
+
+	taskMgr.state.mutex.Lock()
+	defer taskMgr.state.mutex.Unlock()
+
+	svcStack, ok := taskMgr.state.Stacks[svcStackName]
+	if !ok {
+		return nil, fmt.Errorf("Stack with id %s does not exist", svcStackName)
+	}
+
+	command := make([]string, len(task.Command))
+	for i, commandPart := range task.Command {
+		command[i] = strings.TrimSpace(commandPart)
+	}
+
+	task.Name = fmt.Sprintf("%s-%d", svcStackName, len(taskMgr.state.Tasks)+1)
+	task.Status = "STOPPED"
+	task.Command = command
+	task.Cluster = common.NewStringIfNotEmpty(svcStack.Outputs["EcsCluster"], task.Cluster)
+	task.TaskDefinition = common.NewStringIfNotEmpty(svcStack.Outputs["MicroserviceTaskDefinitionArn"], task.TaskDefinition)
+	taskMgr.state.Tasks = append(taskMgr.state.Tasks, task)
+
+	return &ecs.RunTaskOutput{
+		Tasks: []*ecs.Task{
+			{
+				TaskArn:           aws.String(fmt.Sprintf("arn:aws:ecs:%s:%s:task/%s", Region, AccountID, task.Name)),
+				TaskDefinitionArn: aws.String(task.TaskDefinition),
+				LastStatus:        aws.String(task.Status),
+			},
+		},
+	}, nil
+}
+
+// ListServiceRevisions lists the images the service stack has been upserted with
+func (taskMgr *taskManager) ListServiceRevisions(namespace string, environment string, serviceName string) ([]common.ServiceRevision, error) {
+	svcStackName := common.CreateStackName(namespace, common.StackTypeService, serviceName, environment)
+
+	taskMgr.state.mutex.Lock()
+	defer taskMgr.state.mutex.Unlock()
+
+	return append([]common.ServiceRevision{}, taskMgr.state.Revisions[svcStackName]...), nil
+}
+
+// removeServiceTasks removes the tasks of a service in an environment, the mutex of the state must be held
+func (state *State) removeServiceTasks(environment string, serviceName string) {
+	tasks := make([]common.Task, 0, len(state.Tasks))
+	for _, task := range state.Tasks {
+		if task.Environment != environment || task.Service != serviceName {
+			tasks = append(tasks, task)
+		}
+	}
+	state.Tasks = tasks
+}
@@ -0,0 +1,128 @@
+package local
+
+import (
+	"crypto/md5"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+
+	"github.com/stelligent/mu/common"
+)
+
+// localArtifactManager stores artifacts for s3 URLs in a local directory, named by bucket and key
+type localArtifactManager struct {
+	artifactsDir string
+}
+
+func newArtifactManager(artifactsDir string) (common.ArtifactManager, error) {
+	return &localArtifactManager{
+		artifactsDir: artifactsDir,
+	}, nil
+}
+
+func (artifactMgr *localArtifactManager) artifactPath(artifactURL *url.URL) string {
+	return filepath.Join(artifactMgr.artifactsDir, artifactURL.Host, filepath.FromSlash(artifactURL.Path))
+}
+
+// CreateArtifact write the artifact to the local artifacts directory
+func (artifactMgr *localArtifactManager) CreateArtifact(body io.ReadSeeker, destURL string, kmsKey string) error {
+	s3URL, err := url.Parse(destURL)
+	if err != nil {
+		return err
+	}
+	if s3URL.Scheme != "s3" {
+		return fmt.Errorf("destURL must have scheme of 's3', received '%s'", s3URL.Scheme)
+	}
+
+	// start from the begining
+	body.Seek(0, 0)
+
+	artifactFile := artifactMgr.artifactPath(s3URL)
+	if err = os.MkdirAll(filepath.Dir(artifactFile), 0700); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(artifactFile, os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0600)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	log.Debugf("Creating artifact at '%s' in '%s'", destURL, artifactFile)
+	_, err = io.Copy(f, body)
+	return err
+}
+
+// GetArtifact get the artifact conditionally by etag.
+func (artifactMgr *localArtifactManager) GetArtifact(uri string, etag string) (io.ReadCloser, string, error) {
+	artifactURL, err := url.Parse(uri)
+	if err != nil {
+		return nil, "", err
+	}
+
+	switch artifactURL.Scheme {
+	case "s3":
+		return getArtifactFile(artifactMgr.artifactPath(artifactURL), etag)
+	case "file":
+		return getArtifactFile(artifactURL.Path, etag)
+	case "http", "https":
+		return getArtifactHTTP(artifactURL, etag)
+	}
+	return nil, "", fmt.Errorf("unknown scheme on URL '%s'", artifactURL)
+}
+
+func getArtifactHTTP(artifactURL *url.URL, etag string) (io.ReadCloser, string, error) {
+	req, err := http.NewRequest("GET", artifactURL.String(), nil)
+	if err != nil {
+		return nil, "", err
+	}
+
+	client := &http.Client{}
+	req.Header.Add("If-None-Match", etag)
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, "", err
+	}
+
+	if resp.StatusCode == 304 {
+		return nil, etag, nil
+	}
+
+	return resp.Body, resp.Header.Get(http.CanonicalHeaderKey("etag")), nil
+}
+
+func getArtifactFile(path string, etag string) (io.ReadCloser, string, error) {
+	newEtag, err := md5File(path)
+	if err != nil {
+		return nil, "", err
+	}
+
+	if etag == "" || etag != newEtag {
+		body, err := os.Open(path)
+		return body, newEtag, err
+	}
+	return nil, newEtag, nil
+}
+
+func md5File(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	h := md5.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("%x", h.Sum(nil)), nil
+}
+
+// EmptyBucket removes the local directory for a bucket
+func (artifactMgr *localArtifactManager) EmptyBucket(bucketName string) error {
+	log.Infof("  Emptying bucket '%s'", bucketName)
+	return os.RemoveAll(filepath.Join(artifactMgr.artifactsDir, bucketName))
+}
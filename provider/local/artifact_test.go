@@ -0,0 +1,43 @@
+package local
+
+import (
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestArtifactManager(t *testing.T) {
+	assert := assert.New(t)
+
+	dir, err := ioutil.TempDir("", "mu-local")
+	assert.Nil(err)
+	defer os.RemoveAll(dir)
+
+	artifactMgr, err := newArtifactManager(dir)
+	assert.Nil(err)
+
+	err = artifactMgr.CreateArtifact(strings.NewReader("hello"), "s3://bucket/path/to/file.txt", "")
+	assert.Nil(err)
+
+	body, etag, err := artifactMgr.GetArtifact("s3://bucket/path/to/file.txt", "")
+	assert.Nil(err)
+	assert.NotEmpty(etag)
+	data, _ := ioutil.ReadAll(body)
+	body.Close()
+	assert.Equal("hello", string(data))
+
+	body, sameEtag, err := artifactMgr.GetArtifact("s3://bucket/path/to/file.txt", etag)
+	assert.Nil(err)
+	assert.Nil(body)
+	assert.Equal(etag, sameEtag)
+
+	err = artifactMgr.CreateArtifact(strings.NewReader("hello"), "file:///tmp/foo", "")
+	assert.NotNil(err)
+
+	assert.Nil(artifactMgr.EmptyBucket("bucket"))
+	_, _, err = artifactMgr.GetArtifact("s3://bucket/path/to/file.txt", "")
+	assert.NotNil(err)
+}
@@ -0,0 +1,20 @@
+package local
+
+import "github.com/op/go-logging"
+
+var log = logging.MustGetLogger("local")
+
+// Constants for the labels and defaults used on the local docker daemon
+const (
+	LabelStack         = "mu.stack"
+	LabelNamespace     = "mu.namespace"
+	LabelEnvironment   = "mu.environment"
+	LabelService       = "mu.service"
+	LabelTask          = "mu.task"
+	ProxyImage         = "traefik:1.7"
+	ProxyBasePort      = 8080
+	ProxyPortOutputKey = "ProxyPort"
+	DefaultServicePort = 8080
+	StateFileName      = "state.json"
+	ArtifactsDirName   = "artifacts"
+)
@@ -0,0 +1,238 @@
+package local
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/filters"
+	"github.com/docker/docker/api/types/network"
+	"github.com/docker/docker/client"
+	"github.com/docker/go-connections/nat"
+	"github.com/stelligent/mu/common"
+)
+
+// containerSpec describes a container to run on the local docker daemon
+type containerSpec struct {
+	Name    string
+	Image   string
+	Network string
+	Command []string
+	Env     []string
+	Labels  map[string]string
+	Binds   []string
+	// Ports maps a container port to a host port
+	Ports map[int]int
+}
+
+// containerRuntime is the subset of the docker daemon that the local provider depends on
+type containerRuntime interface {
+	EnsureNetwork(name string, labels map[string]string) error
+	RemoveNetwork(name string) error
+	RunContainer(spec *containerSpec) (string, error)
+	ListContainers(labels map[string]string) ([]types.Container, error)
+	RemoveContainer(id string) error
+	WaitContainer(id string) (int64, error)
+	ContainerLogs(id string, since time.Time, callback func(string, time.Time)) error
+}
+
+type dockerRuntime struct {
+	dockerClient *client.Client
+}
+
+func newDockerRuntime() (containerRuntime, error) {
+	log.Debug("Connecting to Docker daemon")
+	cli, err := client.NewEnvClient()
+	if err != nil {
+		return nil, err
+	}
+
+	return &dockerRuntime{
+		dockerClient: cli,
+	}, nil
+}
+
+func labelFilters(labels map[string]string) filters.Args {
+	args := filters.NewArgs()
+	for key, value := range labels {
+		args.Add("label", fmt.Sprintf("%s=%s", key, value))
+	}
+	return args
+}
+
+// EnsureNetwork creates a bridge network if it doesn't already exist
+func (d *dockerRuntime) EnsureNetwork(name string, labels map[string]string) error {
+	args := filters.NewArgs()
+	args.Add("name", name)
+	networks, err := d.dockerClient.NetworkList(context.Background(), types.NetworkListOptions{Filters: args})
+	if err != nil {
+		return err
+	}
+	for _, n := range networks {
+		if n.Name == name {
+			return nil
+		}
+	}
+
+	log.Debugf("Creating network '%s'", name)
+	_, err = d.dockerClient.NetworkCreate(context.Background(), name, types.NetworkCreate{
+		CheckDuplicate: true,
+		Driver:         "bridge",
+		Labels:         labels,
+	})
+	return err
+}
+
+// RemoveNetwork removes a network, ignoring networks that are already gone
+func (d *dockerRuntime) RemoveNetwork(name string) error {
+	log.Debugf("Removing network '%s'", name)
+	err := d.dockerClient.NetworkRemove(context.Background(), name)
+	if err != nil && client.IsErrNotFound(err) {
+		return nil
+	}
+	return err
+}
+
+// RunContainer creates and starts a container, pulling the image if it isn't available locally
+func (d *dockerRuntime) RunContainer(spec *containerSpec) (string, error) {
+	config := &container.Config{
+		Image:        spec.Image,
+		Cmd:          spec.Command,
+		Env:          spec.Env,
+		Labels:       spec.Labels,
+		ExposedPorts: nat.PortSet{},
+	}
+	hostConfig := &container.HostConfig{
+		Binds:        spec.Binds,
+		PortBindings: nat.PortMap{},
+		NetworkMode:  container.NetworkMode(spec.Network),
+	}
+	for containerPort, hostPort := range spec.Ports {
+		port := nat.Port(fmt.Sprintf("%d/tcp", containerPort))
+		config.ExposedPorts[port] = struct{}{}
+		if hostPort > 0 {
+			hostConfig.PortBindings[port] = []nat.PortBinding{{HostPort: strconv.Itoa(hostPort)}}
+		}
+	}
+	networkingConfig := &network.NetworkingConfig{}
+
+	log.Debugf("Creating container '%s' from image '%s'", spec.Name, spec.Image)
+	resp, err := d.dockerClient.ContainerCreate(context.Background(), config, hostConfig, networkingConfig, spec.Name)
+	if err != nil && client.IsErrImageNotFound(err) {
+		if err = d.pullImage(spec.Image); err != nil {
+			return "", err
+		}
+		resp, err = d.dockerClient.ContainerCreate(context.Background(), config, hostConfig, networkingConfig, spec.Name)
+	}
+	if err != nil {
+		return "", err
+	}
+
+	err = d.dockerClient.ContainerStart(context.Background(), resp.ID, types.ContainerStartOptions{})
+	if err != nil {
+		return "", err
+	}
+	return resp.ID, nil
+}
+
+func (d *dockerRuntime) pullImage(image string) error {
+	log.Noticef("  Pulling image '%s'", image)
+	out, err := d.dockerClient.ImagePull(context.Background(), image, types.ImagePullOptions{})
+	if err != nil {
+		return err
+	}
+	defer out.Close()
+	_, err = io.Copy(ioutil.Discard, out)
+	return err
+}
+
+// ListContainers finds all containers, running or not, with the given labels
+func (d *dockerRuntime) ListContainers(labels map[string]string) ([]types.Container, error) {
+	return d.dockerClient.ContainerList(context.Background(), types.ContainerListOptions{
+		All:     true,
+		Filters: labelFilters(labels),
+	})
+}
+
+// RemoveContainer stops and removes a container
+func (d *dockerRuntime) RemoveContainer(id string) error {
+	log.Debugf("Removing container '%s'", id)
+	return d.dockerClient.ContainerRemove(context.Background(), id, types.ContainerRemoveOptions{Force: true})
+}
+
+// WaitContainer blocks until the container exits and returns its exit code
+func (d *dockerRuntime) WaitContainer(id string) (int64, error) {
+	return d.dockerClient.ContainerWait(context.Background(), id)
+}
+
+// ContainerLogs passes each line of the container logs after since to the callback
+func (d *dockerRuntime) ContainerLogs(id string, since time.Time, callback func(string, time.Time)) error {
+	options := types.ContainerLogsOptions{
+		ShowStdout: true,
+		ShowStderr: true,
+		Timestamps: true,
+	}
+	if !since.IsZero() {
+		options.Since = strconv.FormatInt(since.Unix(), 10)
+	}
+	out, err := d.dockerClient.ContainerLogs(context.Background(), id, options)
+	if err != nil {
+		return err
+	}
+	defer out.Close()
+
+	return readLogStream(out, callback)
+}
+
+// readLogStream demultiplexes the docker log stream, where each frame has an 8 byte header
+// with the stream type in the first byte and the frame size in the last 4 bytes
+func readLogStream(stream io.Reader, callback func(string, time.Time)) error {
+	reader := bufio.NewReader(stream)
+	header := make([]byte, 8)
+	for {
+		if _, err := io.ReadFull(reader, header); err == io.EOF {
+			return nil
+		} else if err != nil {
+			return err
+		}
+
+		frame := make([]byte, binary.BigEndian.Uint32(header[4:]))
+		if _, err := io.ReadFull(reader, frame); err != nil {
+			return err
+		}
+
+		for _, line := range strings.Split(strings.TrimRight(string(frame), "\n"), "\n") {
+			message, ts := splitLogTimestamp(line)
+			callback(message, ts)
+		}
+	}
+}
+
+func splitLogTimestamp(line string) (string, time.Time) {
+	parts := strings.SplitN(line, " ", 2)
+	if len(parts) == 2 {
+		if ts, err := time.Parse(time.RFC3339Nano, parts[0]); err == nil {
+			return parts[1], ts
+		}
+	}
+	return line, time.Now()
+}
+
+// localDockerManager builds and runs images with the docker daemon, but never pushes since the daemon is also the registry
+type localDockerManager struct {
+	common.DockerManager
+}
+
+// ImagePush is a no-op since images built locally are already available to the daemon
+func (d *localDockerManager) ImagePush(image string, registryAuth string, dockerOut io.Writer) error {
+	log.Infof("  Skipping push of image '%s' to local docker daemon", image)
+	return nil
+}
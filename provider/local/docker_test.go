@@ -0,0 +1,95 @@
+package local
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+	"time"
+
+	"github.com/docker/docker/api/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type mockedRuntime struct {
+	mock.Mock
+}
+
+func (m *mockedRuntime) EnsureNetwork(name string, labels map[string]string) error {
+	args := m.Called(name)
+	return args.Error(0)
+}
+func (m *mockedRuntime) RemoveNetwork(name string) error {
+	args := m.Called(name)
+	return args.Error(0)
+}
+func (m *mockedRuntime) RunContainer(spec *containerSpec) (string, error) {
+	args := m.Called(spec)
+	return args.String(0), args.Error(1)
+}
+func (m *mockedRuntime) ListContainers(labels map[string]string) ([]types.Container, error) {
+	args := m.Called(labels)
+	return args.Get(0).([]types.Container), args.Error(1)
+}
+func (m *mockedRuntime) RemoveContainer(id string) error {
+	args := m.Called(id)
+	return args.Error(0)
+}
+func (m *mockedRuntime) WaitContainer(id string) (int64, error) {
+	args := m.Called(id)
+	return args.Get(0).(int64), args.Error(1)
+}
+func (m *mockedRuntime) ContainerLogs(id string, since time.Time, callback func(string, time.Time)) error {
+	args := m.Called(id)
+	for _, line := range args.Get(0).([]string) {
+		message, ts := splitLogTimestamp(line)
+		callback(message, ts)
+	}
+	return args.Error(1)
+}
+
+func logFrame(stream byte, payload string) []byte {
+	header := make([]byte, 8)
+	header[0] = stream
+	binary.BigEndian.PutUint32(header[4:], uint32(len(payload)))
+	return append(header, []byte(payload)...)
+}
+
+func TestReadLogStream(t *testing.T) {
+	assert := assert.New(t)
+
+	stream := new(bytes.Buffer)
+	stream.Write(logFrame(1, "2019-01-02T03:04:05.000000006Z hello\n"))
+	stream.Write(logFrame(2, "2019-01-02T03:04:06Z first\n2019-01-02T03:04:07Z second\n"))
+
+	messages := []string{}
+	timestamps := []time.Time{}
+	err := readLogStream(stream, func(message string, ts time.Time) {
+		messages = append(messages, message)
+		timestamps = append(timestamps, ts)
+	})
+
+	assert.Nil(err)
+	assert.Equal([]string{"hello", "first", "second"}, messages)
+	assert.Equal(time.Date(2019, 1, 2, 3, 4, 5, 6, time.UTC), timestamps[0])
+	assert.Equal(time.Date(2019, 1, 2, 3, 4, 7, 0, time.UTC), timestamps[2])
+}
+
+func TestReadLogStream_Truncated(t *testing.T) {
+	assert := assert.New(t)
+
+	frame := logFrame(1, "hello world\n")
+	err := readLogStream(bytes.NewReader(frame[:len(frame)-3]), func(message string, ts time.Time) {})
+	assert.NotNil(err)
+}
+
+func TestSplitLogTimestamp(t *testing.T) {
+	assert := assert.New(t)
+
+	message, ts := splitLogTimestamp("2019-01-02T03:04:05Z hello world")
+	assert.Equal("hello world", message)
+	assert.Equal(time.Date(2019, 1, 2, 3, 4, 5, 0, time.UTC), ts)
+
+	message, _ = splitLogTimestamp("no timestamp here")
+	assert.Equal("no timestamp here", message)
+}
@@ -0,0 +1,90 @@
+package local
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/mitchellh/go-homedir"
+	"github.com/stelligent/mu/common"
+)
+
+// InitializeContext loads manager objects that run everything against the local docker daemon.  State is kept in
+// stateDir, which defaults to ~/.mu/local
+func InitializeContext(ctx *common.Context, stateDir string) error {
+	if stateDir == "" {
+		userdir, err := homedir.Dir()
+		if err != nil {
+			return err
+		}
+		stateDir = filepath.Join(userdir, ".mu", "local")
+	}
+	log.Debugf("Using local provider with state in '%s'", stateDir)
+
+	runtime, err := newDockerRuntime()
+	if err != nil {
+		return err
+	}
+
+	return initializeManagers(ctx, stateDir, runtime)
+}
+
+func initializeManagers(ctx *common.Context, stateDir string, runtime containerRuntime) error {
+	var err error
+
+	ctx.Region = "local"
+	ctx.Partition = "local"
+	ctx.AccountID = "local"
+
+	state := newStateStore(filepath.Join(stateDir, StateFileName))
+
+	// initialize StackManager
+	ctx.StackManager, err = newStackManager(state, runtime)
+	if err != nil {
+		return err
+	}
+
+	// initialize TaskManager
+	ctx.TaskManager, err = newTaskManager(runtime, ctx.StackManager)
+	if err != nil {
+		return err
+	}
+
+	// initialize LogsManager
+	ctx.LogsManager, err = newLogsManager(runtime)
+	if err != nil {
+		return err
+	}
+
+	// initialize ParamManager
+	ctx.ParamManager, err = newParamManager(state)
+	if err != nil {
+		return err
+	}
+
+	// initialize ArtifactManager
+	ctx.ArtifactManager, err = newArtifactManager(filepath.Join(stateDir, ArtifactsDirName))
+	if err != nil {
+		return err
+	}
+
+	// images are built by the local docker daemon and never pushed
+	if ctx.DockerManager != nil {
+		ctx.DockerManager = &localDockerManager{ctx.DockerManager}
+	}
+
+	ctx.ClusterManager = &localClusterManager{}
+	ctx.InstanceManager = &localInstanceManager{}
+	ctx.ElbManager = &localElbManager{}
+	ctx.RdsManager = &localRdsManager{}
+	ctx.PipelineManager = &localPipelineManager{}
+	ctx.LocalPipelineManager = ctx.PipelineManager
+	ctx.SubscriptionManager = &localSubscriptionManager{}
+	ctx.CatalogManager = &localCatalogManager{}
+	ctx.DeploymentManager = &localDeploymentManager{}
+	ctx.KubernetesResourceManagerProvider = &localKubernetesResourceManagerProvider{}
+	ctx.RolesetManager = &localRolesetManager{context: ctx}
+
+	ctx.DockerOut = os.Stdout
+
+	return nil
+}
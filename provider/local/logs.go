@@ -0,0 +1,70 @@
+package local
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/stelligent/mu/common"
+)
+
+type localLogsManager struct {
+	runtime containerRuntime
+}
+
+func newLogsManager(runtime containerRuntime) (common.LogsManager, error) {
+	return &localLogsManager{
+		runtime: runtime,
+	}, nil
+}
+
+// ViewLogs view the logs of the containers for a stack.  The log group is the stack name, as it is for CloudWatch Logs
+func (logsMgr *localLogsManager) ViewLogs(ctx context.Context, logGroup string, searchDuration time.Duration, follow bool, filter string, callback func(string, string, int64)) error {
+	startTime := time.Now().Add(-searchDuration)
+	lastSeen := make(map[string]time.Time)
+
+	for {
+		log.Debugf("Searching for logs of containers in stack '%s' after time '%s' and filter '%s'", logGroup, startTime, filter)
+
+		containers, err := logsMgr.runtime.ListContainers(map[string]string{LabelStack: logGroup})
+		if err != nil {
+			return err
+		}
+
+		for _, c := range containers {
+			since, ok := lastSeen[c.ID]
+			if !ok {
+				since = startTime
+			}
+			streamName := c.ID
+			if len(c.Names) > 0 {
+				streamName = strings.TrimPrefix(c.Names[0], "/")
+			}
+
+			err := logsMgr.runtime.ContainerLogs(c.ID, since, func(message string, ts time.Time) {
+				if !ts.After(since) {
+					return
+				}
+				lastSeen[c.ID] = ts
+				if filter != "" && !strings.Contains(message, filter) {
+					return
+				}
+				callback(streamName, message, ts.UnixNano()/int64(time.Millisecond))
+			})
+			if err != nil {
+				return err
+			}
+		}
+
+		if !follow {
+			break
+		}
+
+		// following stops once the workflow is interrupted
+		if common.SleepWithContext(ctx, 5*time.Second) != nil {
+			break
+		}
+	}
+
+	return nil
+}
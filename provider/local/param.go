@@ -0,0 +1,72 @@
+package local
+
+import (
+	"fmt"
+
+	"github.com/stelligent/mu/common"
+)
+
+type localParamManager struct {
+	state *stateStore
+}
+
+func newParamManager(state *stateStore) (common.ParamManager, error) {
+	return &localParamManager{
+		state: state,
+	}, nil
+}
+
+// SetParam set the value of a parameter.  The kmsKey is ignored, the local state is only readable by the current user
+func (paramMgr *localParamManager) SetParam(name string, value string, kmsKey string) error {
+	log.Debugf("Setting param '%s'", name)
+	return paramMgr.state.update(func(state *localState) error {
+		param, ok := state.Params[name]
+		if !ok {
+			param = &localParam{}
+			state.Params[name] = param
+		}
+		param.Value = value
+		param.Version++
+		return nil
+	})
+}
+
+// DeleteParam delete a parameter
+func (paramMgr *localParamManager) DeleteParam(name string) error {
+	log.Debugf("Deleting param '%s'", name)
+	return paramMgr.state.update(func(state *localState) error {
+		delete(state.Params, name)
+		return nil
+	})
+}
+
+// GetParam get the value of a parameter
+func (paramMgr *localParamManager) GetParam(name string) (string, error) {
+	var value string
+	err := paramMgr.state.read(func(state *localState) error {
+		param, ok := state.Params[name]
+		if !ok {
+			return fmt.Errorf("Unable to find param '%s'", name)
+		}
+		value = param.Value
+		return nil
+	})
+	return value, err
+}
+
+// ParamVersion get the version of a parameter, or 0 if it doesn't exist
+func (paramMgr *localParamManager) ParamVersion(name string) (int64, error) {
+	var version int64
+	err := paramMgr.state.read(func(state *localState) error {
+		if param, ok := state.Params[name]; ok {
+			version = param.Version
+		}
+		return nil
+	})
+	return version, err
+}
+
+// GetSecret fails since there is no local Secrets Manager, use params for local secrets instead
+func (paramMgr *localParamManager) GetSecret(secretID string, versionStage string, versionID string) (string, error) {
+	return "", errUnsupported("Secrets Manager")
+}
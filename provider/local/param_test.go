@@ -0,0 +1,44 @@
+package local
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParamManager(t *testing.T) {
+	assert := assert.New(t)
+
+	dir, err := ioutil.TempDir("", "mu-local")
+	assert.Nil(err)
+	defer os.RemoveAll(dir)
+
+	paramMgr, err := newParamManager(newStateStore(filepath.Join(dir, StateFileName)))
+	assert.Nil(err)
+
+	version, err := paramMgr.ParamVersion("foo")
+	assert.Nil(err)
+	assert.Equal(int64(0), version)
+
+	_, err = paramMgr.GetParam("foo")
+	assert.NotNil(err)
+
+	assert.Nil(paramMgr.SetParam("foo", "bar", ""))
+	assert.Nil(paramMgr.SetParam("foo", "baz", ""))
+
+	val, err := paramMgr.GetParam("foo")
+	assert.Nil(err)
+	assert.Equal("baz", val)
+
+	version, err = paramMgr.ParamVersion("foo")
+	assert.Nil(err)
+	assert.Equal(int64(2), version)
+
+	assert.Nil(paramMgr.DeleteParam("foo"))
+	version, err = paramMgr.ParamVersion("foo")
+	assert.Nil(err)
+	assert.Equal(int64(0), version)
+}
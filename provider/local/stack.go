@@ -0,0 +1,463 @@
+package local
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/stelligent/mu/common"
+)
+
+type localStackManager struct {
+	state    *stateStore
+	runtime  containerRuntime
+	planMode bool
+}
+
+func newStackManager(state *stateStore, runtime containerRuntime) (common.StackManager, error) {
+	return &localStackManager{
+		state:   state,
+		runtime: runtime,
+	}, nil
+}
+
+// AllowDataLoss has no effect locally, there are no stack policies to override
+func (stackMgr *localStackManager) AllowDataLoss(allow bool) {
+}
+
+// PlanChanges will cause upserts to only be logged, rather than applied to the docker daemon
+func (stackMgr *localStackManager) PlanChanges(enabled bool) {
+	stackMgr.planMode = enabled
+}
+
+// CancelUpdatesOnInterrupt has no effect locally, stacks are applied synchronously so there is nothing to wait for
+func (stackMgr *localStackManager) CancelUpdatesOnInterrupt(enabled bool) {
+}
+
+// SetTerminationProtection records the flag on the local stack
+func (stackMgr *localStackManager) SetTerminationProtection(ctx context.Context, stackName string, enabled bool) error {
+	return stackMgr.state.update(func(state *localState) error {
+		if stack, ok := state.Stacks[stackName]; ok {
+			stack.EnableTerminationProtection = enabled
+		}
+		return nil
+	})
+}
+
+// UpsertStack records the stack in the local state and applies it to the docker daemon based on the stack type.
+// The stack is recorded as in progress first, so the state isn't locked while docker pulls and runs images.
+func (stackMgr *localStackManager) UpsertStack(ctx context.Context, stackName string, templateName string, templateData interface{}, parameters map[string]string, tags map[string]string, policy string, roleArn string) error {
+	var stack *common.Stack
+	var snapshot *localState
+	var exists bool
+	err := stackMgr.state.update(func(state *localState) error {
+		stack, exists = state.Stacks[stackName]
+		if stackMgr.planMode {
+			action := "created"
+			if exists {
+				action = "updated"
+			}
+			log.Noticef("  PLAN: Stack '%s' would be %s from template '%s'", stackName, action, templateName)
+			stack = nil
+			return nil
+		}
+
+		if !exists {
+			stack = &common.Stack{
+				ID:         fmt.Sprintf("local:%s", stackName),
+				Name:       stackName,
+				Parameters: make(map[string]string),
+			}
+			log.Infof("  Creating stack '%s'", stackName)
+		} else {
+			log.Infof("  Updating stack '%s'", stackName)
+		}
+
+		for key, value := range parameters {
+			// an empty value means use the previous value
+			if value != "" || stack.Parameters[key] == "" {
+				stack.Parameters[key] = value
+			}
+		}
+
+		stack.Tags = map[string]string{"version": common.GetVersion()}
+		for key, value := range tags {
+			if strings.HasPrefix(key, "mu:") && value != "" {
+				stack.Tags[key[3:]] = value
+			}
+		}
+		stack.Outputs = make(map[string]string)
+		stack.LastUpdateTime = time.Now()
+
+		// reserve the port of a load balancer before releasing the state, so concurrent upserts pick another one
+		if stack.Tags["type"] == common.StackTypeLoadBalancer && stack.Parameters[ProxyPortOutputKey] == "" {
+			stack.Parameters[ProxyPortOutputKey] = strconv.Itoa(nextProxyPort(state))
+		}
+
+		if exists {
+			stack.Status = common.StackStatusUpdateInProgress
+		} else {
+			stack.Status = common.StackStatusCreateInProgress
+		}
+		state.Stacks[stackName] = stack
+		snapshot = state
+		return nil
+	})
+	if err != nil || stack == nil {
+		return err
+	}
+
+	applyErr := stackMgr.applyStack(snapshot, stack, templateData)
+
+	return stackMgr.state.update(func(state *localState) error {
+		if applyErr != nil {
+			stack.StatusReason = applyErr.Error()
+			if exists {
+				stack.Status = common.StackStatusUpdateRollbackComplete
+			} else {
+				stack.Status = common.StackStatusRollbackComplete
+			}
+		} else {
+			stack.StatusReason = ""
+			if exists {
+				stack.Status = common.StackStatusUpdateComplete
+			} else {
+				stack.Status = common.StackStatusCreateComplete
+			}
+		}
+
+		state.Stacks[stackName] = stack
+		return nil
+	})
+}
+
+// applyStack applies the stack to the docker daemon, the state is a snapshot that is only read
+func (stackMgr *localStackManager) applyStack(state *localState, stack *common.Stack, templateData interface{}) error {
+	switch stack.Tags["type"] {
+	case common.StackTypeEnv:
+		return stackMgr.applyEnvironment(stack)
+	case common.StackTypeLoadBalancer:
+		return stackMgr.applyLoadBalancer(stack)
+	case common.StackTypeRepo:
+		stack.Outputs["RepoUrl"] = stack.Parameters["RepoName"]
+	case common.StackTypeService:
+		service, _ := templateData.(*common.Service)
+		return stackMgr.applyService(state, stack, service)
+	case common.StackTypeDatabase, common.StackTypePipeline:
+		log.Warningf("Stacks of type '%s' are not supported by the local provider, recording '%s' only", stack.Tags["type"], stack.Name)
+	}
+	return nil
+}
+
+// applyEnvironment maps an environment to a docker network
+func (stackMgr *localStackManager) applyEnvironment(stack *common.Stack) error {
+	provider := common.EnvProvider(stack.Tags["provider"])
+	if provider != "" && provider != common.EnvProviderEcs && provider != common.EnvProviderEcsFargate {
+		return fmt.Errorf("Provider '%s' is not supported by the local provider, use '%s' instead", provider, common.EnvProviderEcs)
+	}
+
+	stack.Outputs["provider"] = stack.Tags["provider"]
+	stack.Outputs["EcsCluster"] = stack.Name
+	return stackMgr.runtime.EnsureNetwork(stack.Name, map[string]string{
+		LabelNamespace:   stack.Parameters["Namespace"],
+		LabelEnvironment: stack.Parameters["EnvironmentName"],
+	})
+}
+
+// applyLoadBalancer maps a load balancer to a reverse proxy container on the environment network
+func (stackMgr *localStackManager) applyLoadBalancer(stack *common.Stack) error {
+	namespace := stack.Parameters["Namespace"]
+	environmentName := stack.Parameters["EnvironmentName"]
+	networkName := common.CreateStackName(namespace, common.StackTypeEnv, environmentName)
+
+	port := stack.Parameters[ProxyPortOutputKey]
+	stack.Outputs[ProxyPortOutputKey] = port
+	stack.Outputs["BaseUrl"] = fmt.Sprintf("http://localhost:%s", port)
+
+	// the network is normally created by the environment stack, which is upserted after the load balancer
+	labels := map[string]string{
+		LabelNamespace:   namespace,
+		LabelEnvironment: environmentName,
+	}
+	err := stackMgr.runtime.EnsureNetwork(networkName, labels)
+	if err != nil {
+		return err
+	}
+
+	err = stackMgr.removeContainers(stack.Name)
+	if err != nil {
+		return err
+	}
+
+	hostPort, _ := strconv.Atoi(port)
+	labels[LabelStack] = stack.Name
+	_, err = stackMgr.runtime.RunContainer(&containerSpec{
+		Name:    fmt.Sprintf("%s-proxy", stack.Name),
+		Image:   ProxyImage,
+		Network: networkName,
+		Command: []string{
+			"--docker",
+			"--docker.exposedbydefault=false",
+			fmt.Sprintf("--docker.network=%s", networkName),
+			fmt.Sprintf("--docker.constraints=tag==%s", networkName),
+		},
+		Labels: labels,
+		Binds:  []string{"/var/run/docker.sock:/var/run/docker.sock"},
+		Ports:  map[int]int{80: hostPort},
+	})
+	return err
+}
+
+// nextProxyPort finds the first port after the ports used by the other load balancers
+func nextProxyPort(state *localState) int {
+	port := ProxyBasePort
+	for _, stack := range state.Stacks {
+		if stack.Tags["type"] != common.StackTypeLoadBalancer {
+			continue
+		}
+		if used, err := strconv.Atoi(stack.Parameters[ProxyPortOutputKey]); err == nil && used >= port {
+			port = used + 1
+		}
+	}
+	return port
+}
+
+// applyService replaces the containers for the service with new containers behind the reverse proxy
+func (stackMgr *localStackManager) applyService(state *localState, stack *common.Stack, service *common.Service) error {
+	namespace := stack.Parameters["Namespace"]
+	environmentName := stack.Parameters["EnvironmentName"]
+	serviceName := stack.Parameters["ServiceName"]
+	networkName := common.CreateStackName(namespace, common.StackTypeEnv, environmentName)
+
+	if _, ok := state.Stacks[networkName]; !ok {
+		return fmt.Errorf("Unable to find environment '%s'", networkName)
+	}
+
+	image := stack.Parameters["ImageUrl"]
+	if image == "" {
+		return fmt.Errorf("No image defined for service '%s'", serviceName)
+	}
+
+	port := stack.Parameters["ServicePort"]
+	if port == "" {
+		port = strconv.Itoa(DefaultServicePort)
+	}
+	desiredCount := 1
+	if count, err := strconv.Atoi(stack.Parameters["ServiceDesiredCount"]); err == nil {
+		desiredCount = count
+	}
+
+	labels := map[string]string{
+		LabelStack:                        stack.Name,
+		LabelNamespace:                    namespace,
+		LabelEnvironment:                  environmentName,
+		LabelService:                      serviceName,
+		"traefik.enable":                  "true",
+		"traefik.tags":                    networkName,
+		"traefik.port":                    port,
+		"traefik.docker.network":          networkName,
+		"traefik.backend":                 stack.Name,
+		"traefik.frontend.rule":           frontendRule(stack.Parameters["PathPattern"], stack.Parameters["HostPattern"]),
+		"traefik.frontend.passHostHeader": "true",
+	}
+
+	env, err := serviceEnv(service, stack.Parameters, state.Params)
+	if err != nil {
+		return err
+	}
+
+	err = stackMgr.removeContainers(stack.Name)
+	if err != nil {
+		return err
+	}
+
+	for i := 0; i < desiredCount; i++ {
+		_, err := stackMgr.runtime.RunContainer(&containerSpec{
+			Name:    fmt.Sprintf("%s-%d", stack.Name, i+1),
+			Image:   image,
+			Network: networkName,
+			Env:     env,
+			Labels:  labels,
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	stack.Outputs["MicroserviceTaskDefinitionArn"] = image
+	return nil
+}
+
+// frontendRule converts the path and host patterns of a service to a traefik frontend rule
+func frontendRule(pathPattern string, hostPattern string) string {
+	rules := []string{}
+	if pathPattern != "" {
+		paths := strings.Split(pathPattern, ",")
+		for i, path := range paths {
+			paths[i] = strings.TrimSuffix(strings.TrimSuffix(path, "*"), "/")
+			if paths[i] == "" {
+				paths[i] = "/"
+			}
+		}
+		rules = append(rules, fmt.Sprintf("PathPrefix:%s", strings.Join(paths, ",")))
+	}
+	if hostPattern != "" {
+		rules = append(rules, fmt.Sprintf("Host:%s", hostPattern))
+	}
+	if len(rules) == 0 {
+		return "PathPrefix:/"
+	}
+	return strings.Join(rules, ";")
+}
+
+// serviceEnv builds the container environment from the service config, secrets in the local params and database parameters
+func serviceEnv(service *common.Service, parameters map[string]string, params map[string]*localParam) ([]string, error) {
+	env := []string{}
+	if service != nil {
+		for key, value := range service.Environment {
+			if s, ok := value.(string); ok {
+				env = append(env, fmt.Sprintf("%s=%s", key, s))
+			}
+		}
+		for key, ref := range service.SecretReferences() {
+			if ref.IsSecretsManager() {
+				return nil, errUnsupported("Secrets Manager")
+			}
+			param, ok := params[ref.ParameterName]
+			if !ok {
+				return nil, fmt.Errorf("Unable to find param '%s' for secret '%s'", ref.ParameterName, key)
+			}
+			env = append(env, fmt.Sprintf("%s=%s", key, param.Value))
+		}
+	}
+	for _, key := range []string{"DatabaseName", "DatabaseEndpointAddress", "DatabaseEndpointPort", "DatabaseMasterUsername", "DatabaseMasterPassword"} {
+		if parameters[key] != "" {
+			env = append(env, fmt.Sprintf("%s=%s", key, parameters[key]))
+		}
+	}
+	sort.Strings(env)
+	return env, nil
+}
+
+func (stackMgr *localStackManager) removeContainers(stackName string) error {
+	containers, err := stackMgr.runtime.ListContainers(map[string]string{LabelStack: stackName})
+	if err != nil {
+		return err
+	}
+	for _, c := range containers {
+		if err := stackMgr.runtime.RemoveContainer(c.ID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// AwaitFinalStatus returns the stack, local stacks are applied synchronously so there is nothing to wait for
+func (stackMgr *localStackManager) AwaitFinalStatus(ctx context.Context, stackName string) *common.Stack {
+	stack, err := stackMgr.GetStack(stackName)
+	if err != nil {
+		return nil
+	}
+	return stack
+}
+
+// DeleteStack removes the containers and network for a stack, stacks that don't exist are already deleted
+func (stackMgr *localStackManager) DeleteStack(stackName string) error {
+	var stack *common.Stack
+	err := stackMgr.state.read(func(state *localState) error {
+		stack = state.Stacks[stackName]
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	if stack == nil {
+		log.Debugf("  Stack '%s' is already deleted", stackName)
+		return nil
+	}
+	if stackMgr.planMode {
+		log.Noticef("  PLAN: Stack '%s' would be deleted", stackName)
+		return nil
+	}
+
+	err = stackMgr.removeContainers(stackName)
+	if err != nil {
+		return err
+	}
+	if stack.Tags["type"] == common.StackTypeEnv {
+		err = stackMgr.runtime.RemoveNetwork(stackName)
+		if err != nil {
+			return err
+		}
+	}
+
+	return stackMgr.state.update(func(state *localState) error {
+		delete(state.Stacks, stackName)
+		return nil
+	})
+}
+
+// ListStacks will find mu stacks
+func (stackMgr *localStackManager) ListStacks(stackType common.StackType, namespace string) ([]*common.Stack, error) {
+	var stacks []*common.Stack
+	expectedStackPrefix := fmt.Sprintf("%s-%s", namespace, stackType)
+
+	err := stackMgr.state.read(func(state *localState) error {
+		for _, stack := range state.Stacks {
+			if stack.Tags["type"] == string(stackType) && strings.HasPrefix(stack.Name, expectedStackPrefix) {
+				stacks = append(stacks, stack)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(stacks, func(i, j int) bool {
+		return stacks[i].Name < stacks[j].Name
+	})
+	return stacks, nil
+}
+
+// GetStack get a specific stack
+func (stackMgr *localStackManager) GetStack(stackName string) (*common.Stack, error) {
+	var stack *common.Stack
+	err := stackMgr.state.read(func(state *localState) error {
+		s, ok := state.Stacks[stackName]
+		if !ok {
+			return fmt.Errorf("Unable to find stack '%s'", stackName)
+		}
+		stack = s
+		return nil
+	})
+	return stack, err
+}
+
+// DetectStackDrift has no effect locally, the docker daemon has no templates to compare resources to
+func (stackMgr *localStackManager) DetectStackDrift(stackName string) (string, error) {
+	return stackName, nil
+}
+
+// AwaitStackDrift reports local stacks as not checked
+func (stackMgr *localStackManager) AwaitStackDrift(ctx context.Context, stackName string, detectionID string) (*common.StackDrift, error) {
+	return &common.StackDrift{
+		StackName:    stackName,
+		Status:       common.StackDriftStatusNotChecked,
+		StatusReason: "Drift detection is not supported for local stacks",
+		Resources:    make([]common.StackResourceDrift, 0),
+	}, nil
+}
+
+// FindLatestImageID returns a placeholder, there are no machine images locally
+func (stackMgr *localStackManager) FindLatestImageID(owner string, namePattern string) (string, error) {
+	return "local", nil
+}
+
+// CountAZs returns the minimum number of availability zones that mu requires
+func (stackMgr *localStackManager) CountAZs() (int, error) {
+	return 2, nil
+}
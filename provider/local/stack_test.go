@@ -0,0 +1,233 @@
+package local
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/docker/docker/api/types"
+	"github.com/stelligent/mu/common"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func newTestStackManager(t *testing.T, runtime containerRuntime) (*localStackManager, func()) {
+	dir, err := ioutil.TempDir("", "mu-local")
+	assert.Nil(t, err)
+
+	stackMgr := &localStackManager{
+		state:   newStateStore(filepath.Join(dir, StateFileName)),
+		runtime: runtime,
+	}
+	return stackMgr, func() { os.RemoveAll(dir) }
+}
+
+func TestStack_UpsertEnvironment(t *testing.T) {
+	assert := assert.New(t)
+
+	m := new(mockedRuntime)
+	m.On("EnsureNetwork", "mu-environment-dev").Return(nil)
+	m.On("ListContainers", mock.Anything).Return([]types.Container{{ID: "old-proxy"}}, nil)
+	m.On("RemoveContainer", "old-proxy").Return(nil)
+	m.On("RunContainer", mock.AnythingOfType("*local.containerSpec")).Return("proxy", nil)
+
+	stackMgr, cleanup := newTestStackManager(t, m)
+	defer cleanup()
+
+	params := map[string]string{"Namespace": "mu", "EnvironmentName": "dev"}
+
+	err := stackMgr.UpsertStack(context.Background(), "mu-loadbalancer-dev", common.TemplateELB, nil, params, map[string]string{"mu:type": common.StackTypeLoadBalancer}, "", "")
+	assert.Nil(err)
+	err = stackMgr.UpsertStack(context.Background(), "mu-environment-dev", common.TemplateEnvECS, nil, params, map[string]string{"mu:type": common.StackTypeEnv, "mu:provider": "ecs"}, "", "")
+	assert.Nil(err)
+
+	lbStack := stackMgr.AwaitFinalStatus(context.Background(), "mu-loadbalancer-dev")
+	assert.NotNil(lbStack)
+	assert.Equal(common.StackStatusCreateComplete, lbStack.Status)
+	assert.Equal("http://localhost:8080", lbStack.Outputs["BaseUrl"])
+	assert.Equal(common.StackTypeLoadBalancer, lbStack.Tags["type"])
+
+	envStack := stackMgr.AwaitFinalStatus(context.Background(), "mu-environment-dev")
+	assert.NotNil(envStack)
+	assert.Equal("ecs", envStack.Tags["provider"])
+	assert.Equal(common.GetVersion(), envStack.Tags["version"])
+
+	proxy := m.Calls[3].Arguments.Get(0).(*containerSpec)
+	assert.Equal(ProxyImage, proxy.Image)
+	assert.Equal("mu-environment-dev", proxy.Network)
+	assert.Equal(8080, proxy.Ports[80])
+
+	stacks, err := stackMgr.ListStacks(common.StackTypeEnv, "mu")
+	assert.Nil(err)
+	assert.Equal(1, len(stacks))
+
+	m.AssertExpectations(t)
+	m.AssertNumberOfCalls(t, "EnsureNetwork", 2)
+}
+
+func TestStack_UpsertEnvironment_Unsupported(t *testing.T) {
+	assert := assert.New(t)
+
+	m := new(mockedRuntime)
+	stackMgr, cleanup := newTestStackManager(t, m)
+	defer cleanup()
+
+	err := stackMgr.UpsertStack(context.Background(), "mu-environment-dev", common.TemplateEnvEKS, nil, map[string]string{}, map[string]string{"mu:type": common.StackTypeEnv, "mu:provider": "eks"}, "", "")
+	assert.Nil(err)
+
+	stack := stackMgr.AwaitFinalStatus(context.Background(), "mu-environment-dev")
+	assert.Equal(common.StackStatusRollbackComplete, stack.Status)
+	assert.Contains(stack.StatusReason, "not supported")
+
+	m.AssertNumberOfCalls(t, "EnsureNetwork", 0)
+}
+
+func TestStack_UpsertService(t *testing.T) {
+	assert := assert.New(t)
+
+	m := new(mockedRuntime)
+	m.On("EnsureNetwork", "mu-environment-dev").Return(nil)
+	m.On("ListContainers", mock.Anything).Return([]types.Container{}, nil)
+	m.On("RunContainer", mock.AnythingOfType("*local.containerSpec")).Return("id", nil)
+
+	stackMgr, cleanup := newTestStackManager(t, m)
+	defer cleanup()
+
+	err := stackMgr.UpsertStack(context.Background(), "mu-environment-dev", common.TemplateEnvECS, nil, map[string]string{"Namespace": "mu", "EnvironmentName": "dev"}, map[string]string{"mu:type": common.StackTypeEnv}, "", "")
+	assert.Nil(err)
+
+	service := &common.Service{
+		Environment: map[string]interface{}{"FOO": "bar"},
+	}
+	params := map[string]string{
+		"Namespace":           "mu",
+		"EnvironmentName":     "dev",
+		"ServiceName":         "api",
+		"ImageUrl":            "mu-api:1234",
+		"ServicePort":         "3000",
+		"ServiceDesiredCount": "2",
+		"PathPattern":         "/api/*,/v2",
+		"HostPattern":         "api.example.com",
+	}
+	err = stackMgr.UpsertStack(context.Background(), "mu-service-api-dev", common.TemplateServiceECS, service, params, map[string]string{"mu:type": common.StackTypeService}, "", "")
+	assert.Nil(err)
+
+	stack := stackMgr.AwaitFinalStatus(context.Background(), "mu-service-api-dev")
+	assert.Equal(common.StackStatusCreateComplete, stack.Status)
+
+	m.AssertNumberOfCalls(t, "RunContainer", 2)
+	spec := m.Calls[len(m.Calls)-1].Arguments.Get(0).(*containerSpec)
+	assert.Equal("mu-service-api-dev-2", spec.Name)
+	assert.Equal("mu-api:1234", spec.Image)
+	assert.Equal([]string{"FOO=bar"}, spec.Env)
+	assert.Equal("3000", spec.Labels["traefik.port"])
+	assert.Equal("PathPrefix:/api,/v2;Host:api.example.com", spec.Labels["traefik.frontend.rule"])
+	assert.Equal("api", spec.Labels[LabelService])
+
+	// an update keeps previous values for empty parameters
+	params["ImageUrl"] = ""
+	params["ServiceDesiredCount"] = "1"
+	err = stackMgr.UpsertStack(context.Background(), "mu-service-api-dev", common.TemplateServiceECS, service, params, map[string]string{"mu:type": common.StackTypeService}, "", "")
+	assert.Nil(err)
+
+	stack = stackMgr.AwaitFinalStatus(context.Background(), "mu-service-api-dev")
+	assert.Equal(common.StackStatusUpdateComplete, stack.Status)
+	assert.Equal("mu-api:1234", stack.Parameters["ImageUrl"])
+	m.AssertNumberOfCalls(t, "RunContainer", 3)
+}
+
+func TestStack_UpsertService_Unlocked(t *testing.T) {
+	assert := assert.New(t)
+
+	var stackMgr *localStackManager
+	var runningStatus string
+	m := new(mockedRuntime)
+	m.On("EnsureNetwork", "mu-environment-dev").Return(nil)
+	m.On("ListContainers", mock.Anything).Return([]types.Container{}, nil)
+	m.On("RunContainer", mock.AnythingOfType("*local.containerSpec")).Return("id", nil).Run(func(args mock.Arguments) {
+		// the state must not be locked while containers are run
+		stack, err := stackMgr.GetStack("mu-service-api-dev")
+		assert.Nil(err)
+		runningStatus = stack.Status
+	})
+
+	stackMgr, cleanup := newTestStackManager(t, m)
+	defer cleanup()
+
+	err := stackMgr.UpsertStack(context.Background(), "mu-environment-dev", common.TemplateEnvECS, nil, map[string]string{"Namespace": "mu", "EnvironmentName": "dev"}, map[string]string{"mu:type": common.StackTypeEnv}, "", "")
+	assert.Nil(err)
+
+	params := map[string]string{"Namespace": "mu", "EnvironmentName": "dev", "ServiceName": "api", "ImageUrl": "mu-api:1234"}
+	err = stackMgr.UpsertStack(context.Background(), "mu-service-api-dev", common.TemplateServiceECS, nil, params, map[string]string{"mu:type": common.StackTypeService}, "", "")
+	assert.Nil(err)
+
+	assert.Equal(common.StackStatusCreateInProgress, runningStatus)
+	assert.Equal(common.StackStatusCreateComplete, stackMgr.AwaitFinalStatus(context.Background(), "mu-service-api-dev").Status)
+}
+
+func TestStack_UpsertService_NoEnvironment(t *testing.T) {
+	assert := assert.New(t)
+
+	m := new(mockedRuntime)
+	stackMgr, cleanup := newTestStackManager(t, m)
+	defer cleanup()
+
+	params := map[string]string{"Namespace": "mu", "EnvironmentName": "dev", "ServiceName": "api", "ImageUrl": "mu-api:1234"}
+	err := stackMgr.UpsertStack(context.Background(), "mu-service-api-dev", common.TemplateServiceECS, nil, params, map[string]string{"mu:type": common.StackTypeService}, "", "")
+	assert.Nil(err)
+
+	stack := stackMgr.AwaitFinalStatus(context.Background(), "mu-service-api-dev")
+	assert.Equal(common.StackStatusRollbackComplete, stack.Status)
+	m.AssertNumberOfCalls(t, "RunContainer", 0)
+}
+
+func TestStack_UpsertStack_Plan(t *testing.T) {
+	assert := assert.New(t)
+
+	m := new(mockedRuntime)
+	stackMgr, cleanup := newTestStackManager(t, m)
+	defer cleanup()
+	stackMgr.PlanChanges(true)
+
+	err := stackMgr.UpsertStack(context.Background(), "mu-environment-dev", common.TemplateEnvECS, nil, map[string]string{}, map[string]string{"mu:type": common.StackTypeEnv}, "", "")
+	assert.Nil(err)
+	assert.Nil(stackMgr.AwaitFinalStatus(context.Background(), "mu-environment-dev"))
+	m.AssertNumberOfCalls(t, "EnsureNetwork", 0)
+}
+
+func TestStack_DeleteStack(t *testing.T) {
+	assert := assert.New(t)
+
+	m := new(mockedRuntime)
+	m.On("EnsureNetwork", "mu-environment-dev").Return(nil)
+	m.On("RemoveNetwork", "mu-environment-dev").Return(nil)
+	m.On("ListContainers", mock.Anything).Return([]types.Container{}, nil)
+
+	stackMgr, cleanup := newTestStackManager(t, m)
+	defer cleanup()
+
+	err := stackMgr.UpsertStack(context.Background(), "mu-environment-dev", common.TemplateEnvECS, nil, map[string]string{}, map[string]string{"mu:type": common.StackTypeEnv}, "", "")
+	assert.Nil(err)
+
+	err = stackMgr.DeleteStack("mu-environment-dev")
+	assert.Nil(err)
+	assert.Nil(stackMgr.AwaitFinalStatus(context.Background(), "mu-environment-dev"))
+
+	// deleting a stack that doesn't exist is a no-op
+	err = stackMgr.DeleteStack("mu-environment-dev")
+	assert.Nil(err)
+	m.AssertNumberOfCalls(t, "RemoveNetwork", 1)
+
+	m.AssertExpectations(t)
+}
+
+func TestFrontendRule(t *testing.T) {
+	assert := assert.New(t)
+
+	assert.Equal("PathPrefix:/", frontendRule("", ""))
+	assert.Equal("PathPrefix:/", frontendRule("/*", ""))
+	assert.Equal("PathPrefix:/foo,/bar", frontendRule("/foo/*,/bar", ""))
+	assert.Equal("Host:foo.example.com", frontendRule("", "foo.example.com"))
+}
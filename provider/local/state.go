@@ -0,0 +1,101 @@
+package local
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/stelligent/mu/common"
+)
+
+// localState is everything the local provider needs to remember between invocations of mu
+type localState struct {
+	Stacks map[string]*common.Stack `json:"stacks"`
+	Params map[string]*localParam   `json:"params"`
+}
+
+type localParam struct {
+	Value   string `json:"value"`
+	Version int64  `json:"version"`
+}
+
+// stateStore persists the localState as a JSON file
+type stateStore struct {
+	path  string
+	mutex sync.Mutex
+}
+
+func newStateStore(path string) *stateStore {
+	return &stateStore{path: path}
+}
+
+func (store *stateStore) load() (*localState, error) {
+	state := &localState{
+		Stacks: make(map[string]*common.Stack),
+		Params: make(map[string]*localParam),
+	}
+
+	data, err := ioutil.ReadFile(store.path)
+	if os.IsNotExist(err) {
+		return state, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	if err = json.Unmarshal(data, state); err != nil {
+		return nil, err
+	}
+	if state.Stacks == nil {
+		state.Stacks = make(map[string]*common.Stack)
+	}
+	if state.Params == nil {
+		state.Params = make(map[string]*localParam)
+	}
+	return state, nil
+}
+
+func (store *stateStore) save(state *localState) error {
+	if err := os.MkdirAll(filepath.Dir(store.path), 0700); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	// write to a temp file first so an interrupted save never corrupts the state
+	tmpPath := store.path + ".tmp"
+	if err = ioutil.WriteFile(tmpPath, data, 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmpPath, store.path)
+}
+
+// read loads the state and passes it to fn, changes are discarded
+func (store *stateStore) read(fn func(state *localState) error) error {
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	state, err := store.load()
+	if err != nil {
+		return err
+	}
+	return fn(state)
+}
+
+// update loads the state, passes it to fn and saves it if fn succeeds
+func (store *stateStore) update(fn func(state *localState) error) error {
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	state, err := store.load()
+	if err != nil {
+		return err
+	}
+	if err = fn(state); err != nil {
+		return err
+	}
+	return store.save(state)
+}
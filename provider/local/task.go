@@ -0,0 +1,121 @@
+package local
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ecs"
+	"github.com/stelligent/mu/common"
+)
+
+type localTaskManager struct {
+	runtime      containerRuntime
+	stackManager common.StackGetter
+}
+
+func newTaskManager(runtime containerRuntime, stackManager common.StackGetter) (common.TaskManager, error) {
+	return &localTaskManager{
+		runtime:      runtime,
+		stackManager: stackManager,
+	}, nil
+}
+
+// ListTasks lists the containers running for a service in an environment
+func (taskMgr *localTaskManager) ListTasks(namespace string, environment string, serviceName string) ([]common.Task, error) {
+	labels := map[string]string{
+		LabelNamespace:   namespace,
+		LabelEnvironment: environment,
+	}
+	if serviceName != "" {
+		labels[LabelService] = serviceName
+	}
+
+	containers, err := taskMgr.runtime.ListContainers(labels)
+	if err != nil {
+		return nil, err
+	}
+
+	tasks := []common.Task{}
+	for _, c := range containers {
+		if c.Labels[LabelService] == "" {
+			// the reverse proxy for the environment
+			continue
+		}
+		name := c.ID
+		if len(c.Names) > 0 {
+			name = strings.TrimPrefix(c.Names[0], "/")
+		}
+		tasks = append(tasks, common.Task{
+			Name:        name,
+			Environment: environment,
+			Service:     c.Labels[LabelService],
+			Status:      strings.ToUpper(c.State),
+			Containers: []common.Container{
+				{Name: name, Instance: "local"},
+			},
+		})
+	}
+	return tasks, nil
+}
+
+// StopTask removes a container
+func (taskMgr *localTaskManager) StopTask(namespace string, environment string, task string) error {
+	return taskMgr.runtime.RemoveContainer(task)
+}
+
+// ExecuteCommand runs a command in a new container from the image of the service
+func (taskMgr *localTaskManager) ExecuteCommand(namespace string, task common.Task) (common.ECSRunTaskResult, error) {
+	log.Infof("Executing command '%s' on environment '%s' for service '%s'", task.Command, task.Environment, task.Service)
+
+	svcStackName := common.CreateStackName(namespace, common.StackTypeService, task.Service, task.Environment)
+	svcStack, err := taskMgr.stackManager.GetStack(svcStackName)
+	if err != nil {
+		return nil, err
+	}
+
+	command := make([]string, len(task.Command))
+	for i, commandPart := range task.Command {
+		command[i] = strings.TrimSpace(commandPart)
+	}
+
+	taskName := fmt.Sprintf("%s-task-%d", svcStackName, time.Now().Unix())
+	id, err := taskMgr.runtime.RunContainer(&containerSpec{
+		Name:    taskName,
+		Image:   svcStack.Parameters["ImageUrl"],
+		Network: common.CreateStackName(namespace, common.StackTypeEnv, task.Environment),
+		Command: command,
+		Labels: map[string]string{
+			LabelNamespace:   namespace,
+			LabelEnvironment: task.Environment,
+			LabelTask:        task.Service,
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	exitCode, err := taskMgr.runtime.WaitContainer(id)
+	log.Info("Command execution complete")
+	if err != nil {
+		return nil, err
+	}
+
+	ecsTask := &ecs.Task{
+		TaskArn:    aws.String(taskName),
+		LastStatus: aws.String("STOPPED"),
+		Containers: []*ecs.Container{
+			{
+				Name:     aws.String(task.Service),
+				ExitCode: aws.Int64(exitCode),
+			},
+		},
+	}
+	return &ecs.RunTaskOutput{Tasks: []*ecs.Task{ecsTask}}, nil
+}
+
+// ListServiceRevisions is unsupported, the local docker daemon keeps no history of the deployed images
+func (taskMgr *localTaskManager) ListServiceRevisions(namespace string, environment string, serviceName string) ([]common.ServiceRevision, error) {
+	return nil, errUnsupported("Service revision history")
+}
@@ -0,0 +1,69 @@
+package local
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/docker/docker/api/types"
+	"github.com/stelligent/mu/common"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type mockedStackManager struct {
+	mock.Mock
+	common.StackManager
+}
+
+func (m *mockedStackManager) GetStack(stackName string) (*common.Stack, error) {
+	args := m.Called(stackName)
+	return args.Get(0).(*common.Stack), args.Error(1)
+}
+
+func TestTaskManager_ListTasks(t *testing.T) {
+	assert := assert.New(t)
+
+	m := new(mockedRuntime)
+	m.On("ListContainers", map[string]string{LabelNamespace: "mu", LabelEnvironment: "dev", LabelService: "api"}).Return([]types.Container{
+		{ID: "1", Names: []string{"/mu-service-api-dev-1"}, State: "running", Labels: map[string]string{LabelService: "api"}},
+		{ID: "2", Names: []string{"/mu-loadbalancer-dev-proxy"}, State: "running", Labels: map[string]string{}},
+	}, nil)
+
+	taskMgr, err := newTaskManager(m, nil)
+	assert.Nil(err)
+
+	tasks, err := taskMgr.ListTasks("mu", "dev", "api")
+	assert.Nil(err)
+	assert.Equal(1, len(tasks))
+	assert.Equal("mu-service-api-dev-1", tasks[0].Name)
+	assert.Equal("RUNNING", tasks[0].Status)
+	assert.Equal("api", tasks[0].Service)
+
+	m.AssertExpectations(t)
+}
+
+func TestTaskManager_ExecuteCommand(t *testing.T) {
+	assert := assert.New(t)
+
+	stackManager := new(mockedStackManager)
+	stackManager.On("GetStack", "mu-service-api-dev").Return(&common.Stack{Parameters: map[string]string{"ImageUrl": "mu-api:1234"}}, nil)
+
+	m := new(mockedRuntime)
+	m.On("RunContainer", mock.AnythingOfType("*local.containerSpec")).Return("task-id", nil)
+	m.On("WaitContainer", "task-id").Return(int64(3), nil)
+
+	taskMgr, err := newTaskManager(m, stackManager)
+	assert.Nil(err)
+
+	result, err := taskMgr.ExecuteCommand("mu", common.Task{Environment: "dev", Service: "api", Command: []string{"echo", " hello "}})
+	assert.Nil(err)
+	assert.Equal(int64(3), aws.Int64Value(result.Tasks[0].Containers[0].ExitCode))
+
+	spec := m.Calls[0].Arguments.Get(0).(*containerSpec)
+	assert.Equal("mu-api:1234", spec.Image)
+	assert.Equal([]string{"echo", "hello"}, spec.Command)
+	assert.Equal("mu-environment-dev", spec.Network)
+
+	m.AssertExpectations(t)
+	stackManager.AssertExpectations(t)
+}
@@ -0,0 +1,183 @@
+package local
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"strings"
+
+	"github.com/stelligent/mu/common"
+)
+
+// errUnsupported is returned by the capabilities that only exist in AWS
+func errUnsupported(feature string) error {
+	return fmt.Errorf("%s is not supported by the local provider", feature)
+}
+
+// localClusterManager has no instances, and the local docker daemon needs no registry credentials
+type localClusterManager struct{}
+
+func (clusterMgr *localClusterManager) ListInstances(clusterName string) ([]common.ContainerInstance, error) {
+	return []common.ContainerInstance{}, nil
+}
+
+func (clusterMgr *localClusterManager) AuthenticateRepository(repoURL string) (string, error) {
+	return base64.StdEncoding.EncodeToString([]byte("local:local")), nil
+}
+
+func (clusterMgr *localClusterManager) DeleteRepository(repoName string) error {
+	return nil
+}
+
+// TagRepositoryImage never finds the image, so images are always rebuilt by the local docker daemon
+func (clusterMgr *localClusterManager) TagRepositoryImage(repoName string, existingTag string, newTag string) (bool, error) {
+	return false, nil
+}
+
+type localInstanceManager struct{}
+
+func (instanceMgr *localInstanceManager) ListInstances(instanceIds ...string) ([]common.Instance, error) {
+	return []common.Instance{}, nil
+}
+
+// localElbManager has no listener rules, the reverse proxy is configured from container labels
+type localElbManager struct{}
+
+func (elbMgr *localElbManager) ListRules(listenerArn string) ([]common.ElbRule, error) {
+	return []common.ElbRule{}, nil
+}
+
+type localRdsManager struct{}
+
+func (rdsMgr *localRdsManager) SetIamAuthentication(dbInstanceIdentifier string, enabled bool, dbEngine string) error {
+	return errUnsupported("IAM authentication")
+}
+
+type localPipelineManager struct{}
+
+func (pipelineMgr *localPipelineManager) ListState(pipelineName string) ([]common.PipelineStageState, error) {
+	return nil, errUnsupported("Pipelines")
+}
+
+func (pipelineMgr *localPipelineManager) GetGitInfo(pipelineName string) (common.GitInfo, error) {
+	return common.GitInfo{}, errUnsupported("Pipelines")
+}
+
+type localSubscriptionManager struct{}
+
+func (subscriptionMgr *localSubscriptionManager) CreateSubscription(topic string, protocol string, endpoint string) error {
+	return errUnsupported("Subscriptions")
+}
+
+func (subscriptionMgr *localSubscriptionManager) GetSubscription(topic string, protocol string, endpoint string) (interface{}, error) {
+	return nil, errUnsupported("Subscriptions")
+}
+
+type localCatalogManager struct{}
+
+func (catalogMgr *localCatalogManager) SetProductVersions(productID string, productVersions map[string]string) error {
+	return errUnsupported("Catalogs")
+}
+
+func (catalogMgr *localCatalogManager) UpsertProvisionedProduct(ctx context.Context, productID string, version string, name string, params map[string]string) error {
+	return errUnsupported("Catalogs")
+}
+
+func (catalogMgr *localCatalogManager) TerminateProvisionedProducts(ctx context.Context, productID string) error {
+	return errUnsupported("Catalogs")
+}
+
+type localDeploymentManager struct{}
+
+func (deploymentMgr *localDeploymentManager) CreateEcsDeployment(applicationName string, deploymentGroupName string, taskDefinitionArn string, containerName string, containerPort int) (string, error) {
+	return "", errUnsupported("Traffic shifting deployments")
+}
+
+func (deploymentMgr *localDeploymentManager) GetEcsDeployment(deploymentID string) (*common.EcsDeployment, error) {
+	return nil, errUnsupported("Traffic shifting deployments")
+}
+
+type localKubernetesResourceManagerProvider struct{}
+
+func (provider *localKubernetesResourceManagerProvider) GetResourceManager(name string) (common.KubernetesResourceManager, error) {
+	return nil, errUnsupported("Kubernetes")
+}
+
+func (provider *localKubernetesResourceManagerProvider) DescribeKubernetesCluster(name string) (*common.KubernetesCluster, error) {
+	return nil, errUnsupported("Kubernetes")
+}
+
+func (provider *localKubernetesResourceManagerProvider) GetKubernetesToken(name string) (*common.KubernetesToken, error) {
+	return nil, errUnsupported("Kubernetes")
+}
+
+// localRolesetManager has no IAM roles to manage, containers run with the permissions of the docker daemon
+type localRolesetManager struct {
+	context *common.Context
+}
+
+func (rolesetMgr *localRolesetManager) UpsertCommonRoleset(ctx context.Context) error {
+	return nil
+}
+
+func (rolesetMgr *localRolesetManager) UpsertEnvironmentRoleset(ctx context.Context, environmentName string) error {
+	return nil
+}
+
+func (rolesetMgr *localRolesetManager) UpsertServiceRoleset(ctx context.Context, environmentName string, serviceName string, codeDeployBucket string, databaseName string) error {
+	return nil
+}
+
+func (rolesetMgr *localRolesetManager) UpsertPipelineRoleset(ctx context.Context, serviceName string, pipelineBucket string, codeDeployBucket string) error {
+	return nil
+}
+
+func (rolesetMgr *localRolesetManager) GetCommonRoleset(ctx context.Context) (common.Roleset, error) {
+	return make(common.Roleset), nil
+}
+
+func (rolesetMgr *localRolesetManager) GetEnvironmentRoleset(ctx context.Context, environmentName string) (common.Roleset, error) {
+	return make(common.Roleset), nil
+}
+
+func (rolesetMgr *localRolesetManager) GetServiceRoleset(ctx context.Context, environmentName string, serviceName string) (common.Roleset, error) {
+	return make(common.Roleset), nil
+}
+
+func (rolesetMgr *localRolesetManager) GetPipelineRoleset(ctx context.Context, serviceName string) (common.Roleset, error) {
+	return make(common.Roleset), nil
+}
+
+func (rolesetMgr *localRolesetManager) GetEnvironmentProvider(ctx context.Context, environmentName string) (string, error) {
+	for _, e := range rolesetMgr.context.Config.Environments {
+		if strings.EqualFold(e.Name, environmentName) {
+			if e.Provider == "" {
+				return string(common.EnvProviderEcs), nil
+			}
+			return string(e.Provider), nil
+		}
+	}
+
+	envStackName := common.CreateStackName(rolesetMgr.context.Config.Namespace, common.StackTypeEnv, environmentName)
+	envStack := rolesetMgr.context.StackManager.AwaitFinalStatus(ctx, envStackName)
+	if envStack == nil {
+		return "", fmt.Errorf("unable to find environment stack named '%s'", envStackName)
+	}
+	return envStack.Tags["provider"], nil
+}
+
+func (rolesetMgr *localRolesetManager) DeleteCommonRoleset(ctx context.Context) error {
+	return nil
+}
+
+func (rolesetMgr *localRolesetManager) DeleteEnvironmentRoleset(ctx context.Context, environmentName string) error {
+	return nil
+}
+
+func (rolesetMgr *localRolesetManager) DeleteServiceRoleset(ctx context.Context, environmentName string, serviceName string) error {
+	return nil
+}
+
+func (rolesetMgr *localRolesetManager) DeletePipelineRoleset(ctx context.Context, serviceName string) error {
+	return nil
+}
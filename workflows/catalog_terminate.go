@@ -1,6 +1,7 @@
 package workflows
 
 import (
+	"context"
 	"github.com/stelligent/mu/common"
 )
 
@@ -18,7 +19,7 @@ func NewCatalogTerminator(ctx *common.Context) Executor {
 }
 
 func (workflow *purgeWorkflow) terminateProduct(stack *common.Stack) Executor {
-	return func() error {
-		return workflow.context.CatalogManager.TerminateProvisionedProducts(stack.Outputs["ProductId"])
+	return func(ctx context.Context) error {
+		return workflow.context.CatalogManager.TerminateProvisionedProducts(ctx, stack.Outputs["ProductId"])
 	}
 }
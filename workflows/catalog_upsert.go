@@ -1,6 +1,7 @@
 package workflows
 
 import (
+	"context"
 	"fmt"
 	"path"
 	"strings"
@@ -52,13 +53,13 @@ func NewCatalogUpserter(ctx *common.Context) Executor {
 
 }
 func (workflow *catalogWorkflow) catalogCommonRoleset(pipelineParams map[string]string, rolesetUpserter common.RolesetUpserter, rolesetGetter common.RolesetGetter) Executor {
-	return func() error {
-		err := rolesetUpserter.UpsertCommonRoleset()
+	return func(ctx context.Context) error {
+		err := rolesetUpserter.UpsertCommonRoleset(ctx)
 		if err != nil {
 			return err
 		}
 
-		roleset, err := rolesetGetter.GetCommonRoleset()
+		roleset, err := rolesetGetter.GetCommonRoleset(ctx)
 		if err != nil {
 			return err
 		}
@@ -71,7 +72,7 @@ func (workflow *catalogWorkflow) catalogCommonRoleset(pipelineParams map[string]
 // Setup the catalog bucket
 func (workflow *catalogWorkflow) catalogBucket(namespace string, stackUpserter common.StackUpserter, stackWaiter common.StackWaiter) Executor {
 
-	return func() error {
+	return func(ctx context.Context) error {
 		bucketStackName := common.CreateStackName(namespace, common.StackTypeBucket, "servicecatalog")
 		log.Noticef("Upserting Bucket for Service Catalog")
 		bucketParams := make(map[string]string)
@@ -82,7 +83,7 @@ func (workflow *catalogWorkflow) catalogBucket(namespace string, stackUpserter c
 			Type: common.StackTypeBucket,
 		})
 
-		err := stackUpserter.UpsertStack(bucketStackName, common.TemplateBucket, nil, bucketParams, tags, "", "")
+		err := stackUpserter.UpsertStack(ctx, bucketStackName, common.TemplateBucket, nil, bucketParams, tags, "", "")
 		if err != nil {
 			// ignore error if stack is in progress already
 			if !strings.Contains(err.Error(), "_IN_PROGRESS state and can not be updated") {
@@ -91,7 +92,7 @@ func (workflow *catalogWorkflow) catalogBucket(namespace string, stackUpserter c
 		}
 
 		log.Debugf("Waiting for stack '%s' to complete", bucketStackName)
-		stack := stackWaiter.AwaitFinalStatus(bucketStackName)
+		stack := stackWaiter.AwaitFinalStatus(ctx, bucketStackName)
 		if stack == nil {
 			return fmt.Errorf("Unable to create stack %s", bucketStackName)
 		}
@@ -109,7 +110,7 @@ func (workflow *catalogWorkflow) catalogBucket(namespace string, stackUpserter c
 // Setup the catalog IAM
 func (workflow *catalogWorkflow) catalogIAM(namespace string, productParams map[string]string, catalog *common.Catalog, stackUpserter common.StackUpserter, stackWaiter common.StackWaiter) Executor {
 
-	return func() error {
+	return func(ctx context.Context) error {
 		log.Noticef("Upserting Catalog IAM")
 		stackParams := make(map[string]string)
 		stackParams["Namespace"] = namespace
@@ -120,7 +121,7 @@ func (workflow *catalogWorkflow) catalogIAM(namespace string, productParams map[
 		})
 
 		stackName := common.CreateStackName(namespace, common.StackTypeIam, "portfolio", "common")
-		err := stackUpserter.UpsertStack(stackName, common.TemplatePortfolioIAM, nil, stackParams, tags, "", "")
+		err := stackUpserter.UpsertStack(ctx, stackName, common.TemplatePortfolioIAM, nil, stackParams, tags, "", "")
 		if err != nil {
 			// ignore error if stack is in progress already
 			if !strings.Contains(err.Error(), "_IN_PROGRESS state and can not be updated") {
@@ -129,7 +130,7 @@ func (workflow *catalogWorkflow) catalogIAM(namespace string, productParams map[
 		}
 
 		log.Debugf("Waiting for stack '%s' to complete", stackName)
-		stack := stackWaiter.AwaitFinalStatus(stackName)
+		stack := stackWaiter.AwaitFinalStatus(ctx, stackName)
 		if stack == nil {
 			return fmt.Errorf("Unable to create stack %s", stackName)
 		}
@@ -148,7 +149,7 @@ func (workflow *catalogWorkflow) catalogIAM(namespace string, productParams map[
 // Setup the catalog portfolio
 func (workflow *catalogWorkflow) catalogPortfolio(namespace string, params map[string]string, stackUpserter common.StackUpserter, stackWaiter common.StackWaiter) Executor {
 
-	return func() error {
+	return func(ctx context.Context) error {
 		log.Noticef("Upserting Catalog Portfolio")
 		stackParams := make(map[string]string)
 		stackParams["Namespace"] = namespace
@@ -159,7 +160,7 @@ func (workflow *catalogWorkflow) catalogPortfolio(namespace string, params map[s
 		})
 
 		stackName := common.CreateStackName(namespace, common.StackTypePortfolio, "common")
-		err := stackUpserter.UpsertStack(stackName, common.TemplatePortfolio, nil, stackParams, tags, "", "")
+		err := stackUpserter.UpsertStack(ctx, stackName, common.TemplatePortfolio, nil, stackParams, tags, "", "")
 		if err != nil {
 			// ignore error if stack is in progress already
 			if !strings.Contains(err.Error(), "_IN_PROGRESS state and can not be updated") {
@@ -168,7 +169,7 @@ func (workflow *catalogWorkflow) catalogPortfolio(namespace string, params map[s
 		}
 
 		log.Debugf("Waiting for stack '%s' to complete", stackName)
-		stack := stackWaiter.AwaitFinalStatus(stackName)
+		stack := stackWaiter.AwaitFinalStatus(ctx, stackName)
 		if stack == nil {
 			return fmt.Errorf("Unable to create stack %s", stackName)
 		}
@@ -183,7 +184,7 @@ func (workflow *catalogWorkflow) catalogPortfolio(namespace string, params map[s
 }
 
 func (workflow *catalogWorkflow) catalogParams(config *common.Config, pipelineParams map[string]string, stackLister common.StackLister) Executor {
-	return func() error {
+	return func(ctx context.Context) error {
 		pipelineParams["RepoVersion"] = config.Repo.Revision
 		pipelineParams["RepoName"] = config.Repo.Name
 		pipelineParams["MuVersion"] = workflow.muVersion
@@ -204,7 +205,7 @@ func (workflow *catalogWorkflow) catalogProducts(namespace string, catalog *comm
 
 	for i, p := range catalog.Pipelines {
 		pipeline := p
-		catExecutors[i] = func() error {
+		catExecutors[i] = func(ctx context.Context) error {
 			log.Noticef("Upserting Catalog Product '%s'", pipeline.Name)
 
 			stackParams := common.MapClone(productParams)
@@ -235,7 +236,7 @@ func (workflow *catalogWorkflow) catalogProducts(namespace string, catalog *comm
 				}
 			}
 
-			err := stackUpserter.UpsertStack(stackName, common.TemplateProduct, nil, stackParams, tags, "", "")
+			err := stackUpserter.UpsertStack(ctx, stackName, common.TemplateProduct, nil, stackParams, tags, "", "")
 			if err != nil {
 				// ignore error if stack is in progress already
 				if !strings.Contains(err.Error(), "_IN_PROGRESS state and can not be updated") {
@@ -244,7 +245,7 @@ func (workflow *catalogWorkflow) catalogProducts(namespace string, catalog *comm
 			}
 
 			log.Debugf("Waiting for stack '%s' to complete", stackName)
-			stack := stackWaiter.AwaitFinalStatus(stackName)
+			stack := stackWaiter.AwaitFinalStatus(ctx, stackName)
 			if stack == nil {
 				return fmt.Errorf("Unable to create stack %s", stackName)
 			}
@@ -260,7 +261,7 @@ func (workflow *catalogWorkflow) catalogProducts(namespace string, catalog *comm
 }
 
 func (workflow *catalogWorkflow) catalogProductVersions(namespace string, catalog *common.Catalog, pipelineParams map[string]string, artifactCreator common.ArtifactCreator, extensionsManager common.ExtensionsManager, rolesetGetter common.RolesetGetter, stackWaiter common.StackWaiter, catalogUpserter common.CatalogUpserter) Executor {
-	return func() error {
+	return func(ctx context.Context) error {
 		for _, pipeline := range catalog.Pipelines {
 			templateName := fmt.Sprintf("artifact-pipeline-%s.yml", pipeline.Name)
 			productVersions := make(map[string]string)
@@ -280,7 +281,7 @@ func (workflow *catalogWorkflow) catalogProductVersions(namespace string, catalo
 					if templateData["AcptEnv"] == "" {
 						templateData["AcptEnv"] = "acceptance"
 					}
-					templateData["AcptEnvProvider"], err = rolesetGetter.GetEnvironmentProvider(templateData["AcptEnv"])
+					templateData["AcptEnvProvider"], err = rolesetGetter.GetEnvironmentProvider(ctx, templateData["AcptEnv"])
 					if err != nil {
 						return err
 					}
@@ -290,7 +291,7 @@ func (workflow *catalogWorkflow) catalogProductVersions(namespace string, catalo
 					if templateData["ProdEnv"] == "" {
 						templateData["ProdEnv"] = "production"
 					}
-					templateData["ProdEnvProvider"], err = rolesetGetter.GetEnvironmentProvider(templateData["ProdEnv"])
+					templateData["ProdEnvProvider"], err = rolesetGetter.GetEnvironmentProvider(ctx, templateData["ProdEnv"])
 					if err != nil {
 						return err
 					}
@@ -315,7 +316,7 @@ func (workflow *catalogWorkflow) catalogProductVersions(namespace string, catalo
 			}
 
 			stackName := common.CreateStackName(namespace, common.StackTypeProduct, pipeline.Name)
-			stack := stackWaiter.AwaitFinalStatus(stackName)
+			stack := stackWaiter.AwaitFinalStatus(ctx, stackName)
 			if stack == nil {
 				return fmt.Errorf("Unable to find product id for stack '%s'", stackName)
 			}
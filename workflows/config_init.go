@@ -1,6 +1,7 @@
 package workflows
 
 import (
+	"context"
 	"fmt"
 	"io/ioutil"
 	"os"
@@ -24,7 +25,7 @@ type configWorkflow struct {
 }
 
 func (workflow *configWorkflow) configInitialize(config *common.Config, createEnvironment bool, listenPort int, forceOverwrite bool) Executor {
-	return func() error {
+	return func(ctx context.Context) error {
 		basedir := "."
 		if config.Basedir != "" {
 			basedir = config.Basedir
@@ -3,6 +3,7 @@ package workflows
 import (
 	"bufio"
 	"bytes"
+	"context"
 	"fmt"
 	"io/ioutil"
 	"os"
@@ -30,7 +31,7 @@ func TestNewConfigInitializer_FileExists(t *testing.T) {
 	defer os.RemoveAll(config.Basedir)
 
 	workflow := new(configWorkflow)
-	err = workflow.configInitialize(config, false, 80, false)()
+	err = workflow.configInitialize(config, false, 80, false)(context.Background())
 	assert.Nil(err)
 
 	if newConfig, err := loadConfig(config.Basedir); err == nil {
@@ -39,10 +40,10 @@ func TestNewConfigInitializer_FileExists(t *testing.T) {
 		assert.Fail(err.Error())
 	}
 
-	err = workflow.configInitialize(config, false, 80, false)()
+	err = workflow.configInitialize(config, false, 80, false)(context.Background())
 	assert.Nil(err)
 
-	err = workflow.configInitialize(config, false, 3000, true)()
+	err = workflow.configInitialize(config, false, 3000, true)(context.Background())
 	assert.Nil(err)
 
 	if newConfig, err := loadConfig(config.Basedir); err == nil {
@@ -24,6 +24,9 @@ var SvcEnvironmentTableHeader = []string{EnvironmentHeader, SvcRevisionHeader, S
 // SvcTaskContainerHeader is the header for container task detail
 var SvcTaskContainerHeader = []string{"Environment", "Container", "Task", "Instance"}
 
+// SvcRevisionTableHeader is the header for the service revisions table
+var SvcRevisionTableHeader = []string{SvcRevisionHeader, SvcImageHeader, SvcDeployedHeader}
+
 // PipeLineServiceHeader is the header for the pipeline service table
 var PipeLineServiceHeader = []string{SvcServiceHeader, SvcStackHeader, SvcStatusHeader, SvcLastUpdateHeader}
 
@@ -36,6 +39,24 @@ var ServiceTableHeader = []string{SvcServiceHeader, SvcRevisionHeader, SvcStatus
 // EnvironmentShowHeader is the header for the environment table
 var EnvironmentShowHeader = []string{EnvironmentHeader, SvcStackHeader, SvcStatusHeader, SvcLastUpdateHeader}
 
+// DriftTableHeader is the header for the table of stack drifts
+var DriftTableHeader = []string{SvcStackHeader, TypeHeader, DriftStatusHeader, DriftResourcesHeader, DriftDetectedHeader}
+
+// DriftResourceTableHeader is the header for the table of the drifted resources of a stack
+var DriftResourceTableHeader = []string{DriftResourceHeader, TypeHeader, SvcStatusHeader, DriftPropertyHeader, DriftExpectedHeader, DriftActualHeader}
+
+// DriftScopes are the scopes of stacks to detect drift of
+var DriftScopes = []string{DriftScopeEnv, DriftScopeSvc, DriftScopeDb, DriftScopeAll}
+
+// ViewFormats are the output formats of the list and show commands
+var ViewFormats = []string{TABLE, JSON, YAML, CSV}
+
+// HistoryRunTableHeader is the header for the table of past runs
+var HistoryRunTableHeader = []string{HistoryRunHeader, HistoryCommandHeader, SvcStatusHeader, HistoryStartedHeader, HistoryDurationHeader, HistoryStacksHeader}
+
+// HistoryStepTableHeader is the header for the table of the stacks of a run
+var HistoryStepTableHeader = []string{SvcStackHeader, SvcActionHeader, SvcStatusHeader, HistoryCompletedHeader}
+
 // Constants to prevent multiple updates when making changes.
 const (
 	Zero                   = 0
@@ -46,7 +67,12 @@ const (
 	NA                     = "N/A"
 	UnknownValue           = "???"
 	JSON                   = "json"
+	JSONVIEW               = "json-view"
 	SHELL                  = "shell"
+	YAML                   = "yaml"
+	CSV                    = "csv"
+	TABLE                  = "table"
+	CLI                    = "cli"
 	LastUpdateTime         = "2006-01-02 15:04:05"
 	CPU                    = "CPU"
 	MEMORY                 = "MEMORY"
@@ -94,9 +120,27 @@ const (
 	SvcStatusHeader        = "Status"
 	SvcRevisionHeader      = "Revision"
 	SvcImageHeader         = "Image"
+	SvcDeployedHeader      = "Deployed"
 	EnvironmentHeader      = "Environment"
 	SvcStackHeader         = "Stack"
 	SvcLastUpdateHeader    = "Last Update"
+	HistoryRunHeader       = "Run"
+	HistoryCommandHeader   = "Command"
+	HistoryStartedHeader   = "Started"
+	HistoryDurationHeader  = "Duration"
+	HistoryStacksHeader    = "Stacks"
+	HistoryCompletedHeader = "Completed"
+	DriftStatusHeader      = "Drift Status"
+	DriftResourcesHeader   = "Drifted Resources"
+	DriftDetectedHeader    = "Detected"
+	DriftResourceHeader    = "Resource"
+	DriftPropertyHeader    = "Property"
+	DriftExpectedHeader    = "Expected"
+	DriftActualHeader      = "Actual"
+	DriftScopeEnv          = "env"
+	DriftScopeSvc          = "svc"
+	DriftScopeDb           = "db"
+	DriftScopeAll          = "all"
 	SvcCmdTaskExecutingLog = "Creating service executor...\n"
 	SvcCmdTaskResultLog    = "Service executor complete with result:\n%s\n"
 	SvcCmdTaskErrorLog     = "The following error has occurred executing the command:  '%v'"
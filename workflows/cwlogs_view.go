@@ -1,6 +1,7 @@
 package workflows
 
 import (
+	"context"
 	"fmt"
 	"io"
 	"strings"
@@ -64,7 +65,7 @@ func getServiceName(ctx *common.Context, serviceName string) string {
 
 func (workflow *logsWorkflow) logsViewer(logsViewer common.LogsViewer, writer io.Writer, filter string, searchDuration time.Duration, follow bool, logGroups ...string) Executor {
 
-	return func() error {
+	return func(ctx context.Context) error {
 		cb := func(logStream string, message string, timestamp int64) {
 			// TODO: unchecked return
 			fmt.Fprintf(writer, "[%s] %s\n", Bold(logStream), strings.TrimSpace(message))
@@ -79,7 +80,7 @@ func (workflow *logsWorkflow) logsViewer(logsViewer common.LogsViewer, writer io
 			lg := logGroup
 			go func() {
 				defer wg.Done()
-				e := logsViewer.ViewLogs(lg, searchDuration, follow, filter, cb)
+				e := logsViewer.ViewLogs(ctx, lg, searchDuration, follow, filter, cb)
 				if err == nil {
 					err = e
 				}
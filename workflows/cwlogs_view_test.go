@@ -1,6 +1,7 @@
 package workflows
 
 import (
+	"context"
 	"io/ioutil"
 	"testing"
 	"time"
@@ -14,7 +15,7 @@ type mockedLogsManager struct {
 	mock.Mock
 }
 
-func (m *mockedLogsManager) ViewLogs(logGroup string, searchDuration time.Duration, follow bool, filter string, callback func(string, string, int64)) error {
+func (m *mockedLogsManager) ViewLogs(ctx context.Context, logGroup string, searchDuration time.Duration, follow bool, filter string, callback func(string, string, int64)) error {
 	args := m.Called(logGroup)
 	return args.Error(0)
 }
@@ -35,7 +36,7 @@ func TestNewEnvironmentLogViewer(t *testing.T) {
 
 	assert.NotNil(workflow)
 
-	err := workflow()
+	err := workflow(context.Background())
 	assert.Nil(err)
 
 	logsManager.AssertExpectations(t)
@@ -57,7 +58,7 @@ func TestNewServiceLogViewer(t *testing.T) {
 
 	assert.NotNil(workflow)
 
-	err := workflow()
+	err := workflow(context.Background())
 	assert.Nil(err)
 
 	logsManager.AssertExpectations(t)
@@ -84,7 +85,7 @@ func TestNewPipelineLogViewer(t *testing.T) {
 
 	assert.NotNil(workflow)
 
-	err := workflow()
+	err := workflow(context.Background())
 	assert.Nil(err)
 
 	logsManager.AssertExpectations(t)
@@ -1,6 +1,7 @@
 package workflows
 
 import (
+	"context"
 	"errors"
 	"fmt"
 
@@ -17,10 +18,11 @@ type databaseWorkflow struct {
 	databaseKeyArn        string
 	ssmParamName          string
 	ssmParamIsManaged     bool
+	plan                  bool
 }
 
 func (workflow *databaseWorkflow) databaseInput(ctx *common.Context, serviceName string, environmentName string) Executor {
-	return func() error {
+	return func(context.Context) error {
 		// Repo Name
 		if serviceName != "" {
 			workflow.serviceName = serviceName
@@ -32,6 +34,7 @@ func (workflow *databaseWorkflow) databaseInput(ctx *common.Context, serviceName
 			return errors.New("Service name must be provided")
 		}
 
+		workflow.plan = ctx.Config.Plan
 		workflow.appRevisionBucket = ctx.Config.Service.Pipeline.Build.Bucket
 		workflow.databaseName = ctx.Config.Service.Database.Name
 		workflow.ssmParamName = ctx.Config.Service.Database.MasterPasswordSSMParam
@@ -1,45 +1,35 @@
 package workflows
 
 import (
-	"fmt"
+	"context"
 	"io"
 
 	"github.com/stelligent/mu/common"
 )
 
 // NewDatabaseLister create a new workflow for listing databases
-func NewDatabaseLister(ctx *common.Context, writer io.Writer) Executor {
+func NewDatabaseLister(ctx *common.Context, format string, writer io.Writer) Executor {
 
 	workflow := new(databaseWorkflow)
 
 	return newPipelineExecutor(
-		workflow.databaseLister(ctx.Config.Namespace, ctx.StackManager, writer),
+		workflow.databaseLister(ctx.Config.Namespace, ctx.StackManager, format, writer),
 	)
 }
 
-func (workflow *databaseWorkflow) databaseLister(namespace string, stackLister common.StackLister, writer io.Writer) Executor {
+func (workflow *databaseWorkflow) databaseLister(namespace string, stackLister common.StackLister, format string, writer io.Writer) Executor {
 
-	return func() error {
+	return func(ctx context.Context) error {
 		stacks, err := stackLister.ListStacks(common.StackTypeDatabase, namespace)
 		if err != nil {
 			return err
 		}
 
-		table := CreateTableSection(writer, PipeLineServiceHeader)
-
+		views := make([]*stackView, 0, len(stacks))
 		for _, stack := range stacks {
-
-			table.Append([]string{
-				Bold(stack.Tags[SvcTagKey]),
-				stack.Name,
-				fmt.Sprintf(KeyValueFormat, colorizeStackStatus(stack.Status), stack.StatusReason),
-				stack.LastUpdateTime.Local().Format(LastUpdateTime),
-			})
-
+			views = append(views, newStackView(stack))
 		}
 
-		table.Render()
-
-		return nil
+		return writeStackList(writer, format, PipeLineServiceHeader, views, func(view *stackView) string { return view.Service })
 	}
 }
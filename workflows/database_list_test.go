@@ -9,6 +9,6 @@ import (
 func TestNewDatabaseLister(t *testing.T) {
 	assert := assert.New(t)
 	ctx := common.NewContext()
-	lister := NewDatabaseLister(ctx, nil)
+	lister := NewDatabaseLister(ctx, TABLE, nil)
 	assert.NotNil(lister)
 }
@@ -1,6 +1,7 @@
 package workflows
 
 import (
+	"context"
 	"fmt"
 
 	"github.com/stelligent/mu/common"
@@ -19,7 +20,7 @@ func DatabaseSetPassword(ctx *common.Context, environmentName string, serviceNam
 
 // DatabaseSetPassword sets a database password for an environment
 func (workflow *databaseWorkflow) databaseSetPassword(ctx *common.Context, environmentName string, newPassword string) Executor {
-	return func() error {
+	return func(context.Context) error {
 		dbStackName := common.CreateStackName(ctx.Config.Namespace, common.StackTypeDatabase, workflow.serviceName, environmentName)
 		return ctx.ParamManager.SetParam(fmt.Sprintf("%s-%s", dbStackName, "DatabaseMasterPassword"), newPassword, workflow.databaseKeyArn)
 	}
@@ -36,7 +37,7 @@ func DatabaseGetPassword(ctx *common.Context, environmentName string, serviceNam
 }
 
 func (workflow *databaseWorkflow) databaseGetPassword(ctx *common.Context, environmentName string) Executor {
-	return func() error {
+	return func(context.Context) error {
 		dbStackName := common.CreateStackName(ctx.Config.Namespace, common.StackTypeDatabase, workflow.serviceName, environmentName)
 		log.Debugf("Getting password for dbStackName:%s", dbStackName)
 		dbPass, err := ctx.ParamManager.GetParam(fmt.Sprintf("%s-%s", dbStackName, "DatabaseMasterPassword"))
@@ -1,6 +1,7 @@
 package workflows
 
 import (
+	"context"
 	"fmt"
 	"strings"
 
@@ -19,16 +20,16 @@ func NewDatabaseTerminator(ctx *common.Context, serviceName string, environmentN
 }
 
 func (workflow *databaseWorkflow) databaseTerminator(namespace string, environmentName string, stackDeleter common.StackDeleter, stackWaiter common.StackWaiter, paramDeleter common.ParamDeleter) Executor {
-	return func() error {
+	return func(ctx context.Context) error {
 		log.Noticef("Deleting database '%s' from '%s'", workflow.serviceName, environmentName)
 		dbStackName := common.CreateStackName(namespace, common.StackTypeDatabase, workflow.serviceName, environmentName)
-		dbStack := stackWaiter.AwaitFinalStatus(dbStackName)
+		dbStack := stackWaiter.AwaitFinalStatus(ctx, dbStackName)
 		if dbStack != nil {
 			err := stackDeleter.DeleteStack(dbStackName)
 			if err != nil {
 				return err
 			}
-			dbStack = stackWaiter.AwaitFinalStatus(dbStackName)
+			dbStack = stackWaiter.AwaitFinalStatus(ctx, dbStackName)
 			if dbStack != nil && !strings.HasSuffix(dbStack.Status, "_COMPLETE") {
 				return fmt.Errorf("Ended in failed status %s %s", dbStack.Status, dbStack.StatusReason)
 			}
@@ -1,6 +1,7 @@
 package workflows
 
 import (
+	"context"
 	"testing"
 
 	"github.com/stelligent/mu/common"
@@ -27,7 +28,7 @@ func TestDatabaseTerminate(t *testing.T) {
 	stackManager.On("AwaitFinalStatus", "mu-database-foo-dev").Return(&common.Stack{Status: common.StackStatusDeleteComplete})
 	stackManager.On("DeleteStack", "mu-database-foo-dev").Return(nil)
 
-	err := workflow.databaseTerminator("mu", "dev", stackManager, stackManager, paramManager)()
+	err := workflow.databaseTerminator("mu", "dev", stackManager, stackManager, paramManager)(context.Background())
 	assert.Nil(err)
 
 	stackManager.AssertExpectations(t)
@@ -1,6 +1,7 @@
 package workflows
 
 import (
+	"context"
 	"crypto/rand"
 	"fmt"
 	"os"
@@ -35,9 +36,9 @@ func NewDatabaseUpserter(ctx *common.Context, environmentName string) Executor {
 }
 
 func (workflow *databaseWorkflow) databaseEnvironmentLoader(namespace string, environmentName string, stackWaiter common.StackWaiter, ecsImportParams map[string]string, elbRuleLister common.ElbRuleLister) Executor {
-	return func() error {
+	return func(ctx context.Context) error {
 		ecsStackName := common.CreateStackName(namespace, common.StackTypeEnv, environmentName)
-		ecsStack := stackWaiter.AwaitFinalStatus(ecsStackName)
+		ecsStack := stackWaiter.AwaitFinalStatus(ctx, ecsStackName)
 
 		if ecsStack == nil {
 			return fmt.Errorf("Unable to find stack '%s' for environment '%s'", ecsStackName, environmentName)
@@ -52,26 +53,26 @@ func (workflow *databaseWorkflow) databaseEnvironmentLoader(namespace string, en
 }
 
 func (workflow *databaseWorkflow) databaseRolesetUpserter(rolesetUpserter common.RolesetUpserter, rolesetGetter common.RolesetGetter, environmentName string) Executor {
-	return func() error {
+	return func(ctx context.Context) error {
 
-		err := rolesetUpserter.UpsertCommonRoleset()
+		err := rolesetUpserter.UpsertCommonRoleset(ctx)
 		if err != nil {
 			return err
 		}
 
-		commonRoleset, err := rolesetGetter.GetCommonRoleset()
+		commonRoleset, err := rolesetGetter.GetCommonRoleset(ctx)
 		if err != nil {
 			return err
 		}
 
 		workflow.cloudFormationRoleArn = commonRoleset["CloudFormationRoleArn"]
 
-		err = rolesetUpserter.UpsertServiceRoleset(environmentName, workflow.serviceName, workflow.appRevisionBucket, workflow.databaseName)
+		err = rolesetUpserter.UpsertServiceRoleset(ctx, environmentName, workflow.serviceName, workflow.appRevisionBucket, workflow.databaseName)
 		if err != nil {
 			return err
 		}
 
-		serviceRoleset, err := rolesetGetter.GetServiceRoleset(environmentName, workflow.serviceName)
+		serviceRoleset, err := rolesetGetter.GetServiceRoleset(ctx, environmentName, workflow.serviceName)
 		if err != nil {
 			return err
 		}
@@ -88,7 +89,7 @@ func (workflow *databaseWorkflow) databaseRolesetUpserter(rolesetUpserter common
 func (workflow *databaseWorkflow) databaseMasterPassword(namespace string,
 	service *common.Service, params *map[string]string, environmentName string,
 	paramManager common.ParamManager, cliExtension common.CliExtension) Executor {
-	return func() error {
+	return func(ctx context.Context) error {
 
 		//DatabaseMasterPassword:
 		if workflow.ssmParamIsManaged {
@@ -104,7 +105,10 @@ func (workflow *databaseWorkflow) databaseMasterPassword(namespace string,
 					os.Exit(126)
 				}
 			}
-			if dbPassVersion == 0 {
+			if dbPassVersion == 0 && workflow.plan {
+				log.Noticef("PLAN: Skipping creation of parameter '%s'", workflow.ssmParamName)
+				dbPassVersion = 1
+			} else if dbPassVersion == 0 {
 				dbPass := randomPassword(32)
 				err = paramManager.SetParam(workflow.ssmParamName, dbPass, workflow.databaseKeyArn)
 				if err != nil {
@@ -121,7 +125,7 @@ func (workflow *databaseWorkflow) databaseMasterPassword(namespace string,
 }
 
 func (workflow *databaseWorkflow) databaseDeployer(namespace string, service *common.Service, stackParams map[string]string, environmentName string, stackUpserter common.StackUpserter, stackWaiter common.StackWaiter, rdsSetter common.RdsIamAuthenticationSetter) Executor {
-	return func() error {
+	return func(ctx context.Context) error {
 
 		if service.Database.Name == "" {
 			log.Noticef("Skipping database since database.name is unset")
@@ -164,13 +168,13 @@ func (workflow *databaseWorkflow) databaseDeployer(namespace string, service *co
 			return err
 		}
 
-		err = stackUpserter.UpsertStack(dbStackName, common.TemplateDatabase, service, stackParams, tags, policy, workflow.cloudFormationRoleArn)
+		err = stackUpserter.UpsertStack(ctx, dbStackName, common.TemplateDatabase, service, stackParams, tags, policy, workflow.cloudFormationRoleArn)
 
 		if err != nil {
 			return err
 		}
 		log.Debugf("Waiting for stack '%s' to complete", dbStackName)
-		stack := stackWaiter.AwaitFinalStatus(dbStackName)
+		stack := stackWaiter.AwaitFinalStatus(ctx, dbStackName)
 		if stack == nil {
 			return fmt.Errorf("Unable to create stack %s", dbStackName)
 		}
@@ -179,7 +183,7 @@ func (workflow *databaseWorkflow) databaseDeployer(namespace string, service *co
 		}
 
 		// update IAM Authentication
-		if stack.Outputs["DatabaseIdentifier"] != "" && dbConfig.EngineMode != "serverless" {
+		if stack.Outputs["DatabaseIdentifier"] != "" && dbConfig.EngineMode != "serverless" && !workflow.plan {
 			enableIamAuthentication, _ := strconv.ParseBool(dbConfig.IamAuthentication)
 			return rdsSetter.SetIamAuthentication(stack.Outputs["DatabaseIdentifier"], enableIamAuthentication, dbConfig.Engine)
 		}
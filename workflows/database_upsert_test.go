@@ -1,6 +1,7 @@
 package workflows
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"os/exec"
@@ -47,6 +48,10 @@ func (m *mockedParamManager) ParamVersion(name string) (int64, error) {
 	args := m.Called(name)
 	return args.Get(0).(int64), args.Error(1)
 }
+func (m *mockedParamManager) GetSecret(secretID string, versionStage string, versionID string) (string, error) {
+	args := m.Called(secretID)
+	return args.String(0), args.Error(1)
+}
 
 type mockedCliExtension struct {
 	mock.Mock
@@ -70,7 +75,7 @@ func TestDatabaseUpserter_NoName(t *testing.T) {
 
 	workflow := new(databaseWorkflow)
 	workflow.serviceName = "foo"
-	err := workflow.databaseDeployer("mu", &config.Service, params, "dev", stackManager, stackManager, rdsManager)()
+	err := workflow.databaseDeployer("mu", &config.Service, params, "dev", stackManager, stackManager, rdsManager)(context.Background())
 	assert.Nil(err)
 
 	stackManager.AssertExpectations(t)
@@ -100,7 +105,7 @@ func TestDatabaseUpserter(t *testing.T) {
 
 	workflow := new(databaseWorkflow)
 	workflow.serviceName = "foo"
-	err := workflow.databaseDeployer("mu", &config.Service, params, "dev", stackManager, stackManager, rdsManager)()
+	err := workflow.databaseDeployer("mu", &config.Service, params, "dev", stackManager, stackManager, rdsManager)(context.Background())
 	assert.Nil(err)
 
 	stackManager.AssertExpectations(t)
@@ -133,7 +138,7 @@ func TestDatabaseMasterPassword_NoPassAccept(t *testing.T) {
 	mockPrompt.On("Prompt", mock.Anything, mock.Anything).Return(true, nil)
 
 	workflow.serviceName = "foo"
-	err := workflow.databaseMasterPassword("mu", &config.Service, &params, "dev", paramManager, mockPrompt)()
+	err := workflow.databaseMasterPassword("mu", &config.Service, &params, "dev", paramManager, mockPrompt)(context.Background())
 	assert.Nil(err)
 
 	paramManager.AssertExpectations(t)
@@ -165,7 +170,7 @@ func TestDatabaseMasterPassword_NoPassDeny(t *testing.T) {
 		mockPrompt.On("Prompt", mock.Anything, mock.Anything).Return(false, nil)
 
 		workflow.serviceName = "foo"
-		err := workflow.databaseMasterPassword("mu", &config.Service, &params, "dev", paramManager, mockPrompt)()
+		err := workflow.databaseMasterPassword("mu", &config.Service, &params, "dev", paramManager, mockPrompt)(context.Background())
 		assert.Nil(err)
 
 		paramManager.AssertExpectations(t)
@@ -200,7 +205,7 @@ func TestDatabaseMasterPassword_ExistingPass(t *testing.T) {
 		ssmParamIsManaged: true,
 	}
 	workflow.serviceName = "foo"
-	err := workflow.databaseMasterPassword("mu", &config.Service, &params, "dev", paramManager, mockPrompt)()
+	err := workflow.databaseMasterPassword("mu", &config.Service, &params, "dev", paramManager, mockPrompt)(context.Background())
 	assert.Nil(err)
 
 	paramManager.AssertExpectations(t)
@@ -222,7 +227,7 @@ func TestNewDatabaseUpserter_databaseRolesetUpserter(t *testing.T) {
 	}, nil)
 
 	workflow := new(databaseWorkflow)
-	err := workflow.databaseRolesetUpserter(rolesetManager, rolesetManager, "")()
+	err := workflow.databaseRolesetUpserter(rolesetManager, rolesetManager, "")(context.Background())
 	assert.Nil(err)
 	assert.Equal("bar", workflow.cloudFormationRoleArn)
 
@@ -241,7 +246,7 @@ func TestNewDatabaseUpserter_databaseRolesetUpserterMissingKmsArn(t *testing.T)
 	rolesetManager.On("GetServiceRoleset").Return(common.Roleset{}, nil)
 
 	workflow := new(databaseWorkflow)
-	err := workflow.databaseRolesetUpserter(rolesetManager, rolesetManager, "")()
+	err := workflow.databaseRolesetUpserter(rolesetManager, rolesetManager, "")(context.Background())
 	assert.NotNil(err)
 }
 
@@ -267,7 +272,7 @@ func TestDatabaseUpserter_UserDefinedSSMParam(t *testing.T) {
 	workflow := new(databaseWorkflow)
 	workflow.ssmParamName = config.Service.Database.MasterPasswordSSMParam
 	workflow.serviceName = "foo"
-	err := workflow.databaseDeployer("mu", &config.Service, params, "dev", stackManager, stackManager, rdsManager)()
+	err := workflow.databaseDeployer("mu", &config.Service, params, "dev", stackManager, stackManager, rdsManager)(context.Background())
 	assert.Nil(err)
 
 	stackManager.AssertExpectations(t)
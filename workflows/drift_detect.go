@@ -0,0 +1,252 @@
+package workflows
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/stelligent/mu/common"
+)
+
+type driftWorkflow struct {
+	stacks []*common.Stack
+	drifts []*common.StackDrift
+}
+
+// driftScopeStackTypes are the types of stacks that are checked for drift in each scope
+var driftScopeStackTypes = map[string][]common.StackType{
+	DriftScopeEnv: {common.StackTypeVpc, common.StackTypeTarget, common.StackTypeLoadBalancer, common.StackTypeEnv},
+	DriftScopeSvc: {common.StackTypeRepo, common.StackTypeService, common.StackTypeSchedule, common.StackTypePipeline},
+	DriftScopeDb:  {common.StackTypeDatabase},
+	DriftScopeAll: {
+		common.StackTypeVpc, common.StackTypeTarget, common.StackTypeIam, common.StackTypeLoadBalancer,
+		common.StackTypeEnv, common.StackTypeRepo, common.StackTypeApp, common.StackTypeService,
+		common.StackTypeSchedule, common.StackTypePipeline, common.StackTypeDatabase, common.StackTypeBucket,
+		common.StackTypePortfolio, common.StackTypeProduct,
+	},
+}
+
+// NewDriftDetector create a new workflow for detecting drift of the stacks in the scope and reporting the resources that drifted.
+// The workflow fails if any stack has drifted, which IsDriftedError tells apart from the drift that could not be detected.
+func NewDriftDetector(ctx *common.Context, scope string, format string, writer io.Writer) Executor {
+
+	workflow := new(driftWorkflow)
+
+	return newPipelineExecutor(
+		workflow.driftStackLister(ctx.Config.Namespace, scope, ctx.StackManager),
+		workflow.driftDetector(ctx.StackManager),
+		workflow.driftViewer(format, writer),
+		workflow.driftChecker(),
+	)
+}
+
+func (workflow *driftWorkflow) driftStackLister(namespace string, scope string, stackLister common.StackLister) Executor {
+	return func(ctx context.Context) error {
+		stackTypes, ok := driftScopeStackTypes[scope]
+		if !ok {
+			return fmt.Errorf("Unknown scope '%s', must be one of: %s", scope, strings.Join(DriftScopes, ", "))
+		}
+
+		workflow.stacks = make([]*common.Stack, 0)
+		for _, stackType := range stackTypes {
+			stacks, err := stackLister.ListStacks(stackType, namespace)
+			if err != nil {
+				return err
+			}
+			workflow.stacks = append(workflow.stacks, stacks...)
+		}
+		sort.Slice(workflow.stacks, func(i, j int) bool {
+			return workflow.stacks[i].Name < workflow.stacks[j].Name
+		})
+
+		log.Noticef("Detecting drift of %d stacks in namespace '%s'", len(workflow.stacks), namespace)
+		return nil
+	}
+}
+
+func (workflow *driftWorkflow) driftDetector(driftDetector common.StackDriftDetector) Executor {
+	return func(ctx context.Context) error {
+		// start all detections before waiting on any, since each one takes a while
+		workflow.drifts = make([]*common.StackDrift, len(workflow.stacks))
+		detectionIDs := make([]string, len(workflow.stacks))
+		for i, stack := range workflow.stacks {
+			detectionID, err := driftDetector.DetectStackDrift(stack.Name)
+			if err != nil {
+				workflow.drifts[i] = unknownStackDrift(stack, err)
+				continue
+			}
+			detectionIDs[i] = detectionID
+		}
+
+		for i, stack := range workflow.stacks {
+			if workflow.drifts[i] == nil {
+				drift, err := driftDetector.AwaitStackDrift(ctx, stack.Name, detectionIDs[i])
+				if err != nil {
+					if ctx.Err() != nil {
+						return ctx.Err()
+					}
+					drift = unknownStackDrift(stack, err)
+				}
+				drift.StackType = stack.Tags["type"]
+				workflow.drifts[i] = drift
+			}
+
+			drift := workflow.drifts[i]
+			if drift.Status == common.StackDriftStatusUnknown {
+				log.Warningf("Unable to detect drift of stack '%s': %s", stack.Name, drift.StatusReason)
+			}
+			common.EmitEvent(common.Event{
+				Type:      common.EventTypeStackDrift,
+				StackName: stack.Name,
+				Status:    drift.Status,
+				Reason:    drift.StatusReason,
+			})
+		}
+		return nil
+	}
+}
+
+func unknownStackDrift(stack *common.Stack, err error) *common.StackDrift {
+	return &common.StackDrift{
+		StackName:    stack.Name,
+		StackType:    stack.Tags["type"],
+		Status:       common.StackDriftStatusUnknown,
+		StatusReason: err.Error(),
+		Resources:    make([]common.StackResourceDrift, 0),
+	}
+}
+
+func (workflow *driftWorkflow) driftViewer(format string, writer io.Writer) Executor {
+	return func(ctx context.Context) error {
+		records := func() [][]string {
+			rows := [][]string{{"stackName", "stackType", "status", "statusReason", "logicalId", "physicalId",
+				"resourceType", "resourceStatus", "propertyPath", "differenceType", "expectedValue", "actualValue"}}
+			for _, drift := range workflow.drifts {
+				if len(drift.Resources) == 0 {
+					rows = append(rows, driftRecord(drift, common.StackResourceDrift{}, common.StackPropertyDifference{}))
+				}
+				for _, resource := range drift.Resources {
+					if len(resource.Differences) == 0 {
+						rows = append(rows, driftRecord(drift, resource, common.StackPropertyDifference{}))
+					}
+					for _, difference := range resource.Differences {
+						rows = append(rows, driftRecord(drift, resource, difference))
+					}
+				}
+			}
+			return rows
+		}
+		return writeView(writer, format, workflow.drifts, records, func() { printDrifts(workflow.drifts, writer) })
+	}
+}
+
+// driftRecord is a csv record of a property difference, stacks and resources without differences leave the columns empty
+func driftRecord(drift *common.StackDrift, resource common.StackResourceDrift, difference common.StackPropertyDifference) []string {
+	return []string{
+		drift.StackName, drift.StackType, drift.Status, drift.StatusReason,
+		resource.LogicalID, resource.PhysicalID, resource.ResourceType, resource.Status,
+		difference.PropertyPath, difference.DifferenceType, difference.ExpectedValue, difference.ActualValue,
+	}
+}
+
+func printDrifts(drifts []*common.StackDrift, writer io.Writer) {
+	table := CreateTableSection(writer, DriftTableHeader)
+	for _, drift := range drifts {
+		table.Append([]string{
+			Bold(drift.StackName),
+			drift.StackType,
+			fmt.Sprintf(KeyValueFormat, colorizeDriftStatus(drift.Status), drift.StatusReason),
+			fmt.Sprintf(IntStringFormat, len(drift.Resources)),
+			formatLastUpdate(drift.DetectionTime),
+		})
+	}
+	table.Render()
+
+	for _, drift := range drifts {
+		if len(drift.Resources) == 0 {
+			continue
+		}
+
+		fmt.Fprint(writer, NewLine)
+		fmt.Fprintf(writer, HeadNewlineHeader, Bold(drift.StackName))
+		resourceTable := CreateTableSection(writer, DriftResourceTableHeader)
+		for _, resource := range drift.Resources {
+			if len(resource.Differences) == 0 {
+				resourceTable.Append([]string{
+					Bold(resource.LogicalID),
+					resource.ResourceType,
+					colorizeDriftStatus(resource.Status),
+					LineChar,
+					LineChar,
+					LineChar,
+				})
+			}
+			for _, difference := range resource.Differences {
+				resourceTable.Append([]string{
+					Bold(resource.LogicalID),
+					resource.ResourceType,
+					colorizeDriftStatus(resource.Status),
+					difference.PropertyPath,
+					difference.ExpectedValue,
+					difference.ActualValue,
+				})
+			}
+		}
+		resourceTable.Render()
+	}
+}
+
+func (workflow *driftWorkflow) driftChecker() Executor {
+	return func(ctx context.Context) error {
+		drifted := 0
+		unknown := 0
+		for _, drift := range workflow.drifts {
+			switch drift.Status {
+			case common.StackDriftStatusDrifted:
+				drifted++
+			case common.StackDriftStatusUnknown:
+				unknown++
+			}
+		}
+
+		if drifted > 0 {
+			return driftedError{drifted: drifted, total: len(workflow.drifts)}
+		} else if unknown > 0 {
+			return fmt.Errorf("Unable to detect drift of %d of %d stacks", unknown, len(workflow.drifts))
+		}
+		return nil
+	}
+}
+
+// driftedError reports the stacks that have drifted, as opposed to the errors of detecting drift
+type driftedError struct {
+	drifted int
+	total   int
+}
+
+func (e driftedError) Error() string {
+	return fmt.Sprintf("%d of %d stacks have drifted", e.drifted, e.total)
+}
+
+// IsDriftedError returns true if the error of the drift detector is that stacks have drifted
+func IsDriftedError(err error) bool {
+	if logged, ok := err.(loggedError); ok {
+		err = logged.err
+	}
+	_, ok := err.(driftedError)
+	return ok
+}
+
+func colorizeDriftStatus(driftStatus string) string {
+	switch driftStatus {
+	case common.StackDriftStatusInSync:
+		return color.New(color.FgGreen).Sprint(driftStatus)
+	case common.StackDriftStatusDrifted, common.StackResourceDriftStatusModified, common.StackResourceDriftStatusDeleted:
+		return color.New(color.FgRed).Sprint(driftStatus)
+	default:
+		return color.New(color.FgBlue).Sprint(driftStatus)
+	}
+}
@@ -0,0 +1,66 @@
+package workflows
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/stelligent/mu/common"
+	"github.com/stelligent/mu/provider/fake"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewDriftDetector(t *testing.T) {
+	assert := assert.New(t)
+
+	state := fake.NewState()
+	ctx, err := newFakeContext(state)
+	assert.Nil(err)
+
+	err = NewEnvironmentsUpserter(ctx, []string{"dev"})(context.Background())
+	assert.Nil(err)
+
+	// nothing has drifted yet
+	out := new(bytes.Buffer)
+	err = NewDriftDetector(ctx, DriftScopeEnv, TABLE, out)(context.Background())
+	assert.Nil(err)
+	assert.Contains(out.String(), "mu-vpc-dev")
+	assert.Contains(out.String(), common.StackDriftStatusInSync)
+
+	state.StackDrifts["mu-vpc-dev"] = []common.StackResourceDrift{
+		{
+			LogicalID:    "InstanceSecurityGroup",
+			ResourceType: "AWS::EC2::SecurityGroup",
+			Status:       common.StackResourceDriftStatusModified,
+			Differences: []common.StackPropertyDifference{
+				{PropertyPath: "/SecurityGroupIngress/0/CidrIp", DifferenceType: "NOT_EQUAL", ExpectedValue: "10.0.0.0/16", ActualValue: "0.0.0.0/0"},
+			},
+		},
+	}
+
+	out = new(bytes.Buffer)
+	err = NewDriftDetector(ctx, DriftScopeEnv, JSON, out)(context.Background())
+	assert.NotNil(err)
+	assert.True(IsDriftedError(err))
+
+	drifts := make([]*common.StackDrift, 0)
+	assert.Nil(json.Unmarshal(out.Bytes(), &drifts))
+	driftsByName := make(map[string]*common.StackDrift)
+	for _, drift := range drifts {
+		driftsByName[drift.StackName] = drift
+	}
+	assert.Equal(common.StackDriftStatusDrifted, driftsByName["mu-vpc-dev"].Status)
+	assert.Equal(string(common.StackTypeVpc), driftsByName["mu-vpc-dev"].StackType)
+	assert.Equal("0.0.0.0/0", driftsByName["mu-vpc-dev"].Resources[0].Differences[0].ActualValue)
+	assert.Equal(common.StackDriftStatusInSync, driftsByName["mu-environment-dev"].Status)
+	assert.NotContains(driftsByName, "mu-iam-common")
+
+	// the database scope has no stacks that drifted
+	err = NewDriftDetector(ctx, DriftScopeDb, TABLE, new(bytes.Buffer))(context.Background())
+	assert.Nil(err)
+
+	err = NewDriftDetector(ctx, "everything", TABLE, new(bytes.Buffer))(context.Background())
+	assert.NotNil(err)
+	assert.False(IsDriftedError(err))
+}
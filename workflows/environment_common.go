@@ -1,6 +1,7 @@
 package workflows
 
 import (
+	"context"
 	"strings"
 
 	"github.com/fatih/color"
@@ -13,6 +14,7 @@ type environmentWorkflow struct {
 	repoName                  string
 	cloudFormationRoleArn     string
 	ec2RoleArn                string
+	vpcStackName              string
 	kubernetesResourceManager common.KubernetesResourceManager
 	rbacUsers                 []*subjectRoleBinding
 	rbacServices              []*subjectRoleBinding
@@ -58,7 +60,7 @@ func (workflow *environmentWorkflow) isEc2Provider() Conditional {
 }
 
 func (workflow *environmentWorkflow) connectKubernetes(muNamespace string, provider common.KubernetesResourceManagerProvider) Executor {
-	return func() error {
+	return func(ctx context.Context) error {
 		clusterName := common.CreateStackName(muNamespace, common.StackTypeEnv, workflow.environment.Name)
 		kubernetesResourceManager, err := provider.GetResourceManager(clusterName)
 		workflow.kubernetesResourceManager = kubernetesResourceManager
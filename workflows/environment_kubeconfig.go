@@ -0,0 +1,256 @@
+package workflows
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/mitchellh/go-homedir"
+	"github.com/stelligent/mu/common"
+	"gopkg.in/yaml.v2"
+)
+
+// kubeconfigExecAPIVersion is the version of the exec credential api used by `mu env token`
+const kubeconfigExecAPIVersion = "client.authentication.k8s.io/v1beta1"
+
+// NewEnvironmentKubeconfigWriter create a new workflow for writing a kubeconfig that lets kubectl connect to the cluster of an environment,
+// the token command authenticates with the same profile and assumed role as the current invocation
+func NewEnvironmentKubeconfigWriter(ctx *common.Context, environmentName string, merge bool, kubeconfigPath string, profile string, assumeRole string, writer io.Writer) Executor {
+
+	workflow := new(environmentWorkflow)
+	var cluster *common.KubernetesCluster
+	kubeconfig := make(map[string]interface{})
+
+	return newPipelineExecutor(
+		workflow.environmentKubernetesClusterLoader(ctx.Config.Namespace, environmentName, ctx.StackManager, ctx.KubernetesResourceManagerProvider, &cluster),
+		newConditionalExecutor(func() bool { return merge },
+			workflow.environmentKubeconfigLoader(kubeconfigPath, kubeconfig), nil),
+		workflow.environmentKubeconfigMerger(kubeconfigContextName(ctx.Config.Namespace, environmentName),
+			kubeconfigTokenCommand(ctx.Config.Namespace, environmentName, ctx.Region, profile, assumeRole), &cluster, kubeconfig),
+		newConditionalExecutor(func() bool { return merge },
+			workflow.environmentKubeconfigSaver(kubeconfigPath, kubeconfig),
+			workflow.environmentKubeconfigPrinter(kubeconfig, writer)),
+	)
+}
+
+// NewEnvironmentTokenPrinter create a new workflow for printing a token that lets kubectl authenticate to the cluster of an environment
+func NewEnvironmentTokenPrinter(ctx *common.Context, environmentName string, writer io.Writer) Executor {
+
+	workflow := new(environmentWorkflow)
+	var cluster *common.KubernetesCluster
+
+	return newPipelineExecutor(
+		workflow.environmentKubernetesClusterLoader(ctx.Config.Namespace, environmentName, ctx.StackManager, nil, &cluster),
+		workflow.environmentTokenPrinter(&cluster, ctx.KubernetesResourceManagerProvider, writer),
+	)
+}
+
+// environmentKubernetesClusterLoader finds the cluster of the environment, the connection info is only described when a describer is given
+func (workflow *environmentWorkflow) environmentKubernetesClusterLoader(namespace string, environmentName string, stackGetter common.StackGetter, clusterDescriber common.KubernetesClusterDescriber, cluster **common.KubernetesCluster) Executor {
+	return func(ctx context.Context) error {
+		envStackName := common.CreateStackName(namespace, common.StackTypeEnv, environmentName)
+		envStack, err := stackGetter.GetStack(envStackName)
+		if err != nil {
+			return fmt.Errorf("Unable to find environment '%s': %v", environmentName, err)
+		}
+
+		provider := common.EnvProvider(envStack.Tags["provider"])
+		if provider != common.EnvProviderEks && provider != common.EnvProviderEksFargate {
+			return fmt.Errorf("Environment '%s' has provider '%s', a kubeconfig is only available for '%s' environments", environmentName, provider, common.EnvProviderEks)
+		}
+
+		if clusterDescriber == nil {
+			*cluster = &common.KubernetesCluster{Name: envStack.Name}
+			return nil
+		}
+
+		*cluster, err = clusterDescriber.DescribeKubernetesCluster(envStack.Name)
+		return err
+	}
+}
+
+// environmentKubeconfigLoader loads the existing kubeconfig to merge with, a missing file is an empty kubeconfig
+func (workflow *environmentWorkflow) environmentKubeconfigLoader(kubeconfigPath string, kubeconfig map[string]interface{}) Executor {
+	return func(ctx context.Context) error {
+		path, err := resolveKubeconfigPath(kubeconfigPath)
+		if err != nil {
+			return err
+		}
+
+		body, err := ioutil.ReadFile(path)
+		if os.IsNotExist(err) {
+			log.Debugf("Kubeconfig '%s' does not exist yet", path)
+			return nil
+		} else if err != nil {
+			return err
+		}
+
+		existing := make(map[interface{}]interface{})
+		err = yaml.Unmarshal(body, existing)
+		if err != nil {
+			return fmt.Errorf("Unable to parse kubeconfig '%s': %v", path, err)
+		}
+		for key, value := range common.ConvertMapI2MapS(existing).(map[string]interface{}) {
+			kubeconfig[key] = value
+		}
+		return nil
+	}
+}
+
+func (workflow *environmentWorkflow) environmentKubeconfigMerger(name string, tokenCommand map[string]interface{}, cluster **common.KubernetesCluster, kubeconfig map[string]interface{}) Executor {
+	return func(ctx context.Context) error {
+		mergeKubeconfig(kubeconfig, name, *cluster, tokenCommand)
+		return nil
+	}
+}
+
+func (workflow *environmentWorkflow) environmentKubeconfigSaver(kubeconfigPath string, kubeconfig map[string]interface{}) Executor {
+	return func(ctx context.Context) error {
+		path, err := resolveKubeconfigPath(kubeconfigPath)
+		if err != nil {
+			return err
+		}
+		body, err := yaml.Marshal(kubeconfig)
+		if err != nil {
+			return err
+		}
+
+		err = os.MkdirAll(filepath.Dir(path), 0700)
+		if err != nil {
+			return err
+		}
+		err = ioutil.WriteFile(path, body, 0600)
+		if err != nil {
+			return err
+		}
+		log.Noticef("Merged context '%s' into kubeconfig '%s'", kubeconfig["current-context"], path)
+		return nil
+	}
+}
+
+func (workflow *environmentWorkflow) environmentKubeconfigPrinter(kubeconfig map[string]interface{}, writer io.Writer) Executor {
+	return func(ctx context.Context) error {
+		body, err := yaml.Marshal(kubeconfig)
+		if err != nil {
+			return err
+		}
+		_, err = writer.Write(body)
+		return err
+	}
+}
+
+func (workflow *environmentWorkflow) environmentTokenPrinter(cluster **common.KubernetesCluster, tokenGenerator common.KubernetesTokenGenerator, writer io.Writer) Executor {
+	return func(ctx context.Context) error {
+		token, err := tokenGenerator.GetKubernetesToken((*cluster).Name)
+		if err != nil {
+			return err
+		}
+
+		// see https://kubernetes.io/docs/reference/access-authn-authz/authentication/#client-go-credential-plugins
+		return json.NewEncoder(writer).Encode(map[string]interface{}{
+			"apiVersion": kubeconfigExecAPIVersion,
+			"kind":       "ExecCredential",
+			"spec":       map[string]interface{}{},
+			"status": map[string]interface{}{
+				"token":               token.Token,
+				"expirationTimestamp": token.Expiration.UTC().Format(time.RFC3339),
+			},
+		})
+	}
+}
+
+// kubeconfigContextName is the name of the context, cluster and user of an environment, prefixed with the namespace like the stacks
+func kubeconfigContextName(namespace string, environmentName string) string {
+	return fmt.Sprintf("%s-%s", namespace, environmentName)
+}
+
+// kubeconfigTokenCommand runs `mu env token` with the namespace, region, profile and assumed role of the current invocation,
+// so kubectl can run it from any directory
+func kubeconfigTokenCommand(namespace string, environmentName string, region string, profile string, assumeRole string) map[string]interface{} {
+	args := []interface{}{"--silent", "--namespace", namespace}
+	if region != "" {
+		args = append(args, "--region", region)
+	}
+	if profile != "" {
+		args = append(args, "--profile", profile)
+	}
+	if assumeRole != "" {
+		args = append(args, "--assume-role", assumeRole)
+	}
+	args = append(args, "environment", "token", environmentName)
+
+	command := map[string]interface{}{
+		"apiVersion": kubeconfigExecAPIVersion,
+		"command":    "mu",
+		"args":       args,
+	}
+	if envProfile := os.Getenv("AWS_PROFILE"); profile == "" && envProfile != "" {
+		command["env"] = []interface{}{
+			map[string]interface{}{"name": "AWS_PROFILE", "value": envProfile},
+		}
+	}
+	return command
+}
+
+// mergeKubeconfig replaces or adds the cluster, user and context of the environment and makes it the current context
+func mergeKubeconfig(kubeconfig map[string]interface{}, name string, cluster *common.KubernetesCluster, tokenCommand map[string]interface{}) {
+	if kubeconfig["apiVersion"] == nil {
+		kubeconfig["apiVersion"] = "v1"
+	}
+	if kubeconfig["kind"] == nil {
+		kubeconfig["kind"] = "Config"
+	}
+	if kubeconfig["preferences"] == nil {
+		kubeconfig["preferences"] = map[string]interface{}{}
+	}
+
+	kubeconfigUpsertNamed(kubeconfig, "clusters", name, "cluster", map[string]interface{}{
+		"server":                     cluster.Endpoint,
+		"certificate-authority-data": cluster.CertificateAuthorityData,
+	})
+	kubeconfigUpsertNamed(kubeconfig, "users", name, "user", map[string]interface{}{
+		"exec": tokenCommand,
+	})
+	kubeconfigUpsertNamed(kubeconfig, "contexts", name, "context", map[string]interface{}{
+		"cluster": name,
+		"user":    name,
+	})
+	kubeconfig["current-context"] = name
+}
+
+func kubeconfigUpsertNamed(kubeconfig map[string]interface{}, listName string, name string, key string, value map[string]interface{}) {
+	entry := map[string]interface{}{
+		"name": name,
+		key:    value,
+	}
+
+	list := common.MapGetSlice(kubeconfig, listName)
+	for i, existing := range list {
+		if common.MapGetString(existing, "name") == name {
+			list[i] = entry
+			return
+		}
+	}
+	kubeconfig[listName] = append(list, entry)
+}
+
+// resolveKubeconfigPath defaults to the first file of KUBECONFIG or ~/.kube/config, like kubectl
+func resolveKubeconfigPath(kubeconfigPath string) (string, error) {
+	if kubeconfigPath != "" {
+		return kubeconfigPath, nil
+	}
+	if paths := filepath.SplitList(os.Getenv("KUBECONFIG")); len(paths) > 0 && strings.TrimSpace(paths[0]) != "" {
+		return paths[0], nil
+	}
+	homeDir, err := homedir.Dir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(homeDir, ".kube", "config"), nil
+}
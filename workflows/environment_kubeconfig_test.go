@@ -0,0 +1,92 @@
+package workflows
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stelligent/mu/common"
+	"github.com/stelligent/mu/provider/fake"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMergeKubeconfig(t *testing.T) {
+	assert := assert.New(t)
+
+	kubeconfig := map[string]interface{}{
+		"apiVersion":      "v1",
+		"kind":            "Config",
+		"current-context": "minikube",
+		"clusters": []interface{}{
+			map[string]interface{}{"name": "minikube", "cluster": map[string]interface{}{"server": "https://192.168.99.100:8443"}},
+			map[string]interface{}{"name": "mu-dev", "cluster": map[string]interface{}{"server": "https://old.eks.amazonaws.com"}},
+		},
+	}
+	cluster := &common.KubernetesCluster{
+		Name:                     "mu-environment-dev",
+		Endpoint:                 "https://new.eks.amazonaws.com",
+		CertificateAuthorityData: "Y2VydA==",
+	}
+
+	mergeKubeconfig(kubeconfig, kubeconfigContextName("mu", "dev"), cluster, kubeconfigTokenCommand("mu", "dev", "us-west-2", "ops", "arn:aws:iam::123456789012:role/deployer"))
+
+	assert.Equal("mu-dev", kubeconfig["current-context"])
+	assert.Equal(2, len(common.MapGetSlice(kubeconfig, "clusters")))
+	assert.Equal("https://192.168.99.100:8443", common.MapGetString(kubeconfig, "clusters", 0, "cluster", "server"))
+	assert.Equal("https://new.eks.amazonaws.com", common.MapGetString(kubeconfig, "clusters", 1, "cluster", "server"))
+	assert.Equal("Y2VydA==", common.MapGetString(kubeconfig, "clusters", 1, "cluster", "certificate-authority-data"))
+	assert.Equal("mu-dev", common.MapGetString(kubeconfig, "contexts", 0, "context", "user"))
+	assert.Equal("mu", common.MapGetString(kubeconfig, "users", 0, "user", "exec", "command"))
+	assert.Equal([]interface{}{"--silent", "--namespace", "mu", "--region", "us-west-2", "--profile", "ops",
+		"--assume-role", "arn:aws:iam::123456789012:role/deployer", "environment", "token", "dev"},
+		common.MapGetSlice(kubeconfig, "users", 0, "user", "exec", "args"))
+}
+
+func TestEnvironmentKubeconfig(t *testing.T) {
+	assert := assert.New(t)
+
+	state := fake.NewState()
+	ctx, err := newFakeContext(state)
+	assert.Nil(err)
+	ctx.Config.Environments = []common.Environment{{Name: "dev", Provider: common.EnvProviderEks}, {Name: "ecs"}}
+
+	err = NewEnvironmentsUpserter(ctx, []string{"dev", "ecs"})(context.Background())
+	assert.Nil(err)
+
+	out := new(bytes.Buffer)
+	err = NewEnvironmentKubeconfigWriter(ctx, "dev", false, "", "", "", out)(context.Background())
+	assert.Nil(err)
+	assert.Contains(out.String(), "current-context: mu-dev")
+	assert.Contains(out.String(), "server: https://mu-environment-dev.us-east-1.eks.amazonaws.com")
+
+	dir, err := ioutil.TempDir("", "mu-kubeconfig")
+	assert.Nil(err)
+	defer os.RemoveAll(dir)
+	kubeconfigPath := filepath.Join(dir, "config")
+	err = ioutil.WriteFile(kubeconfigPath, []byte("apiVersion: v1\nkind: Config\ncurrent-context: minikube\ncontexts:\n- name: minikube\n  context:\n    cluster: minikube\n    user: minikube\n"), 0600)
+	assert.Nil(err)
+
+	err = NewEnvironmentKubeconfigWriter(ctx, "dev", true, kubeconfigPath, "", "", new(bytes.Buffer))(context.Background())
+	assert.Nil(err)
+	merged, err := ioutil.ReadFile(kubeconfigPath)
+	assert.Nil(err)
+	assert.Contains(string(merged), "current-context: mu-dev")
+	assert.Contains(string(merged), "name: minikube")
+
+	out = new(bytes.Buffer)
+	err = NewEnvironmentTokenPrinter(ctx, "dev", out)(context.Background())
+	assert.Nil(err)
+	credential := make(map[string]interface{})
+	assert.Nil(json.Unmarshal(out.Bytes(), &credential))
+	assert.Equal("ExecCredential", credential["kind"])
+	assert.Equal("k8s-aws-v1.fake-mu-environment-dev", common.MapGetString(credential, "status", "token"))
+
+	err = NewEnvironmentKubeconfigWriter(ctx, "ecs", false, "", "", "", new(bytes.Buffer))(context.Background())
+	assert.NotNil(err)
+	err = NewEnvironmentTokenPrinter(ctx, "missing", new(bytes.Buffer))(context.Background())
+	assert.NotNil(err)
+}
@@ -1,43 +1,35 @@
 package workflows
 
 import (
-	"fmt"
+	"context"
 	"io"
 
 	"github.com/stelligent/mu/common"
 )
 
 // NewEnvironmentLister create a new workflow for listing environments
-func NewEnvironmentLister(ctx *common.Context, writer io.Writer) Executor {
+func NewEnvironmentLister(ctx *common.Context, format string, writer io.Writer) Executor {
 
 	workflow := new(environmentWorkflow)
 
 	return newPipelineExecutor(
-		workflow.environmentLister(ctx.Config.Namespace, ctx.StackManager, writer),
+		workflow.environmentLister(ctx.Config.Namespace, ctx.StackManager, format, writer),
 	)
 }
 
-func (workflow *environmentWorkflow) environmentLister(namespace string, stackLister common.StackLister, writer io.Writer) Executor {
+func (workflow *environmentWorkflow) environmentLister(namespace string, stackLister common.StackLister, format string, writer io.Writer) Executor {
 
-	return func() error {
+	return func(ctx context.Context) error {
 		stacks, err := stackLister.ListStacks(common.StackTypeEnv, namespace)
 		if err != nil {
 			return err
 		}
 
-		table := CreateTableSection(writer, EnvironmentShowHeader)
-
+		views := make([]*stackView, 0, len(stacks))
 		for _, stack := range stacks {
-			table.Append([]string{
-				Bold(stack.Tags[EnvTagKey]),
-				stack.Name,
-				fmt.Sprintf(KeyValueFormat, colorizeStackStatus(stack.Status), stack.StatusReason),
-				stack.LastUpdateTime.Local().Format(LastUpdateTime),
-			})
+			views = append(views, newStackView(stack))
 		}
 
-		table.Render()
-
-		return nil
+		return writeStackList(writer, format, EnvironmentShowHeader, views, func(view *stackView) string { return view.Environment })
 	}
 }
@@ -9,6 +9,6 @@ import (
 func TestNewEnvironmentLister(t *testing.T) {
 	assert := assert.New(t)
 	ctx := common.NewContext()
-	lister := NewEnvironmentLister(ctx, nil)
+	lister := NewEnvironmentLister(ctx, TABLE, nil)
 	assert.NotNil(lister)
 }
@@ -1,6 +1,7 @@
 package workflows
 
 import (
+	"context"
 	"fmt"
 
 	"github.com/stelligent/mu/common"
@@ -8,7 +9,7 @@ import (
 
 // Function to normalize a workflow's environment
 func (workflow *environmentWorkflow) environmentNormalizer() Executor {
-	return func() error {
+	return func(ctx context.Context) error {
 		if workflow.environment.Provider == "" {
 			workflow.environment.Provider = common.EnvProviderEcs
 		}
@@ -1,6 +1,7 @@
 package workflows
 
 import (
+	"context"
 	"fmt"
 	"strings"
 
@@ -42,7 +43,7 @@ func newEnvironmentTerminator(ctx *common.Context, environmentName string) Execu
 }
 
 func (workflow *environmentWorkflow) environmentServiceTerminator(namespace string, environmentName string, stackLister common.StackLister, stackDeleter common.StackDeleter, stackWaiter common.StackWaiter, rolesetDeleter common.RolesetDeleter) Executor {
-	return func() error {
+	return func(ctx context.Context) error {
 		log.Noticef("Terminating Services for environment '%s' ...", environmentName)
 		stacks, err := stackLister.ListStacks(common.StackTypeService, namespace)
 		if err != nil {
@@ -61,18 +62,18 @@ func (workflow *environmentWorkflow) environmentServiceTerminator(namespace stri
 
 			serviceName := stack.Tags["service"]
 			stackName := stack.Name
-			executors = append(executors, func() error {
+			executors = append(executors, func(ctx context.Context) error {
 				log.Infof("   Undeploying service '%s' from environment '%s'", serviceName, environmentName)
-				stackWaiter.AwaitFinalStatus(stackName)
-				return rolesetDeleter.DeleteServiceRoleset(environmentName, serviceName)
+				stackWaiter.AwaitFinalStatus(ctx, stackName)
+				return rolesetDeleter.DeleteServiceRoleset(ctx, environmentName, serviceName)
 			})
 		}
 
-		return newParallelExecutor(executors...)()
+		return newParallelExecutor(executors...)(ctx)
 	}
 }
 func (workflow *environmentWorkflow) environmentDbTerminator(namespace string, environmentName string, stackLister common.StackLister, stackDeleter common.StackDeleter, stackWaiter common.StackWaiter) Executor {
-	return func() error {
+	return func(ctx context.Context) error {
 		log.Noticef("Terminating Databases for environment '%s' ...", environmentName)
 		stacks, err := stackLister.ListStacks(common.StackTypeDatabase, namespace)
 		if err != nil {
@@ -92,14 +93,14 @@ func (workflow *environmentWorkflow) environmentDbTerminator(namespace string, e
 				continue
 			}
 			log.Infof("   Terminating database for service '%s' from environment '%s'", stack.Tags["service"], environmentName)
-			stackWaiter.AwaitFinalStatus(stack.Name)
+			stackWaiter.AwaitFinalStatus(ctx, stack.Name)
 		}
 
 		return nil
 	}
 }
 func (workflow *environmentWorkflow) environmentEcsTerminator(namespace string, environmentName string, stackDeleter common.StackDeleter, stackWaiter common.StackWaiter) Executor {
-	return func() error {
+	return func(ctx context.Context) error {
 		log.Noticef("Terminating environment '%s' ...", environmentName)
 		envStackName := common.CreateStackName(namespace, common.StackTypeEnv, environmentName)
 		err := stackDeleter.DeleteStack(envStackName)
@@ -107,7 +108,7 @@ func (workflow *environmentWorkflow) environmentEcsTerminator(namespace string,
 			return err
 		}
 
-		stack := stackWaiter.AwaitFinalStatus(envStackName)
+		stack := stackWaiter.AwaitFinalStatus(ctx, envStackName)
 		if stack != nil && !strings.HasSuffix(stack.Status, "_COMPLETE") {
 			return fmt.Errorf("Ended in failed status %s %s", stack.Status, stack.StatusReason)
 		}
@@ -117,8 +118,8 @@ func (workflow *environmentWorkflow) environmentEcsTerminator(namespace string,
 }
 
 func (workflow *environmentWorkflow) environmentRolesetTerminator(rolesetDeleter common.RolesetDeleter, environmentName string) Executor {
-	return func() error {
-		err := rolesetDeleter.DeleteEnvironmentRoleset(environmentName)
+	return func(ctx context.Context) error {
+		err := rolesetDeleter.DeleteEnvironmentRoleset(ctx, environmentName)
 		if err != nil {
 			return err
 		}
@@ -127,7 +128,7 @@ func (workflow *environmentWorkflow) environmentRolesetTerminator(rolesetDeleter
 }
 
 func (workflow *environmentWorkflow) environmentKubernetesIngressTerminator(environmentName string) Executor {
-	return func() error {
+	return func(ctx context.Context) error {
 		log.Noticef("Terminating ingress in environment '%s'", environmentName)
 
 		err := workflow.kubernetesResourceManager.DeleteResource("v1", "Namespace", "", "mu-ingress")
@@ -139,7 +140,7 @@ func (workflow *environmentWorkflow) environmentKubernetesIngressTerminator(envi
 }
 
 func (workflow *environmentWorkflow) environmentElbTerminator(namespace string, environmentName string, stackDeleter common.StackDeleter, stackWaiter common.StackWaiter) Executor {
-	return func() error {
+	return func(ctx context.Context) error {
 		log.Noticef("Terminating ELB environment '%s' ...", environmentName)
 		envStackName := common.CreateStackName(namespace, common.StackTypeLoadBalancer, environmentName)
 		err := stackDeleter.DeleteStack(envStackName)
@@ -147,7 +148,7 @@ func (workflow *environmentWorkflow) environmentElbTerminator(namespace string,
 			return err
 		}
 
-		stack := stackWaiter.AwaitFinalStatus(envStackName)
+		stack := stackWaiter.AwaitFinalStatus(ctx, envStackName)
 		if stack != nil && !strings.HasSuffix(stack.Status, "_COMPLETE") {
 			return fmt.Errorf("Ended in failed status %s %s", stack.Status, stack.StatusReason)
 		}
@@ -156,7 +157,7 @@ func (workflow *environmentWorkflow) environmentElbTerminator(namespace string,
 	}
 }
 func (workflow *environmentWorkflow) environmentVpcTerminator(namespace string, environmentName string, stackDeleter common.StackDeleter, stackWaiter common.StackWaiter) Executor {
-	return func() error {
+	return func(ctx context.Context) error {
 		log.Noticef("Terminating VPC environment '%s' ...", environmentName)
 		vpcStackName := common.CreateStackName(namespace, common.StackTypeVpc, environmentName)
 		err := stackDeleter.DeleteStack(vpcStackName)
@@ -164,7 +165,7 @@ func (workflow *environmentWorkflow) environmentVpcTerminator(namespace string,
 			log.Debugf("Unable to delete VPC, but ignoring error: %v", err)
 		}
 
-		stack := stackWaiter.AwaitFinalStatus(vpcStackName)
+		stack := stackWaiter.AwaitFinalStatus(ctx, vpcStackName)
 		if stack != nil && !strings.HasSuffix(stack.Status, "_COMPLETE") {
 			return fmt.Errorf("Ended in failed status %s %s", stack.Status, stack.StatusReason)
 		}
@@ -175,7 +176,7 @@ func (workflow *environmentWorkflow) environmentVpcTerminator(namespace string,
 			log.Debugf("Unable to delete VPC target, but ignoring error: %v", err)
 		}
 
-		stack = stackWaiter.AwaitFinalStatus(targetStackName)
+		stack = stackWaiter.AwaitFinalStatus(ctx, targetStackName)
 		if stack != nil && !strings.HasSuffix(stack.Status, "_COMPLETE") {
 			return fmt.Errorf("Ended in failed status %s %s", stack.Status, stack.StatusReason)
 		}
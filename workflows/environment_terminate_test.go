@@ -1,6 +1,7 @@
 package workflows
 
 import (
+	"context"
 	"testing"
 
 	"github.com/stelligent/mu/common"
@@ -20,7 +21,7 @@ type mockedStackManagerForTerminate struct {
 	mock.Mock
 }
 
-func (m *mockedStackManagerForTerminate) AwaitFinalStatus(stackName string) *common.Stack {
+func (m *mockedStackManagerForTerminate) AwaitFinalStatus(ctx context.Context, stackName string) *common.Stack {
 	args := m.Called(stackName)
 	return args.Get(0).(*common.Stack)
 }
@@ -45,7 +46,7 @@ func TestNewEnvironmentEcsTerminator(t *testing.T) {
 	stackManager.On("AwaitFinalStatus", "mu-environment-foo").Return(&common.Stack{Status: common.StackStatusDeleteComplete})
 	stackManager.On("DeleteStack", "mu-environment-foo").Return(nil)
 
-	err := workflow.environmentEcsTerminator("mu", "foo", stackManager, stackManager)()
+	err := workflow.environmentEcsTerminator("mu", "foo", stackManager, stackManager)(context.Background())
 	assert.Nil(err)
 
 	stackManager.AssertExpectations(t)
@@ -67,7 +68,7 @@ func TestNewEnvironmentVpcTerminator(t *testing.T) {
 	stackManager.On("DeleteStack", "mu-target-foo").Return(nil)
 	stackManager.On("DeleteStack", "mu-vpc-foo").Return(nil)
 
-	err := workflow.environmentVpcTerminator("mu", "foo", stackManager, stackManager)()
+	err := workflow.environmentVpcTerminator("mu", "foo", stackManager, stackManager)(context.Background())
 	assert.Nil(err)
 
 	stackManager.AssertExpectations(t)
@@ -1,6 +1,7 @@
 package workflows
 
 import (
+	"context"
 	"fmt"
 	"strconv"
 	"strings"
@@ -47,12 +48,16 @@ func newEnvironmentUpserter(ctx *common.Context, environmentName string) Executo
 			newPipelineExecutor(
 				workflow.environmentKubernetesBootstrapper(ctx.Config.Namespace, envStackParams, ctx.StackManager, ctx.StackManager),
 				workflow.environmentUpserter(ctx.Config.Namespace, envStackParams, ctx.StackManager, ctx.StackManager, ctx.StackManager),
-				workflow.connectKubernetes(ctx.Config.Namespace, ctx.KubernetesResourceManagerProvider),
-				workflow.environmentKubernetesClusterUpserter(ctx.Config.Namespace, serviceName, ctx.Region, ctx.AccountID, ctx.Partition),
-				workflow.environmentKubernetesIngressUpserter(ctx.Config.Namespace, ctx.Region, ctx.AccountID, ctx.Partition),
+				newPlanSkippingExecutor(&ctx.Config, "kubernetes resources",
+					newPipelineExecutor(
+						workflow.connectKubernetes(ctx.Config.Namespace, ctx.KubernetesResourceManagerProvider),
+						workflow.environmentKubernetesClusterUpserter(ctx.Config.Namespace, serviceName, ctx.Region, ctx.AccountID, ctx.Partition),
+						workflow.environmentKubernetesIngressUpserter(ctx.Config.Namespace, ctx.Region, ctx.AccountID, ctx.Partition),
+					)),
 			),
 			newPipelineExecutor(
 				workflow.environmentElbUpserter(ctx.Config.Namespace, envStackParams, elbStackParams, ctx.StackManager, ctx.StackManager, ctx.StackManager),
+				workflow.environmentEfsParams(ctx.Config.Namespace, &ctx.Config, envStackParams, ctx.StackManager),
 				workflow.environmentUpserter(ctx.Config.Namespace, envStackParams, ctx.StackManager, ctx.StackManager, ctx.StackManager),
 			),
 		),
@@ -62,7 +67,7 @@ func newEnvironmentUpserter(ctx *common.Context, environmentName string) Executo
 // Find an environment in config, by name and set the reference
 func (workflow *environmentWorkflow) environmentFinder(config *common.Config, environmentName string) Executor {
 
-	return func() error {
+	return func(ctx context.Context) error {
 		for _, e := range config.Environments {
 			if strings.EqualFold(e.Name, environmentName) {
 				workflow.environment = &e
@@ -110,7 +115,7 @@ func (workflow *environmentWorkflow) environmentFinder(config *common.Config, en
 func (workflow *environmentWorkflow) environmentVpcUpserter(namespace string,
 	envStackParams map[string]string, elbStackParams map[string]string, imageFinder common.ImageFinder,
 	stackUpserter common.StackUpserter, stackWaiter common.StackWaiter, azCounter common.AZCounter) Executor {
-	return func() error {
+	return func(ctx context.Context) error {
 		environment := workflow.environment
 		vpcStackParams := make(map[string]string)
 		var err error
@@ -179,13 +184,13 @@ func (workflow *environmentWorkflow) environmentVpcUpserter(namespace string,
 				Repo:        workflow.repoName,
 			})
 
-			err = stackUpserter.UpsertStack(vpcStackName, vpcTemplateName, environment, vpcStackParams, tags, "", workflow.cloudFormationRoleArn)
+			err = stackUpserter.UpsertStack(ctx, vpcStackName, vpcTemplateName, environment, vpcStackParams, tags, "", workflow.cloudFormationRoleArn)
 			if err != nil {
 				return err
 			}
 
 			log.Debugf("Waiting for stack '%s' to complete", vpcStackName)
-			stack := stackWaiter.AwaitFinalStatus(vpcStackName)
+			stack := stackWaiter.AwaitFinalStatus(ctx, vpcStackName)
 
 			if stack == nil {
 				return fmt.Errorf("Unable to create stack %s", vpcStackName)
@@ -195,6 +200,7 @@ func (workflow *environmentWorkflow) environmentVpcUpserter(namespace string,
 			}
 		}
 
+		workflow.vpcStackName = vpcStackName
 		envStackParams["VpcId"] = fmt.Sprintf("%s-VpcId", vpcStackName)
 		envStackParams["InstanceSubnetIds"] = fmt.Sprintf("%s-InstanceSubnetIds", vpcStackName)
 
@@ -206,25 +212,25 @@ func (workflow *environmentWorkflow) environmentVpcUpserter(namespace string,
 }
 
 func (workflow *environmentWorkflow) environmentRolesetUpserter(rolesetUpserter common.RolesetUpserter, rolesetGetter common.RolesetGetter, envStackParams map[string]string) Executor {
-	return func() error {
-		err := rolesetUpserter.UpsertCommonRoleset()
+	return func(ctx context.Context) error {
+		err := rolesetUpserter.UpsertCommonRoleset(ctx)
 		if err != nil {
 			return err
 		}
 
-		commonRoleset, err := rolesetGetter.GetCommonRoleset()
+		commonRoleset, err := rolesetGetter.GetCommonRoleset(ctx)
 		if err != nil {
 			return err
 		}
 
 		workflow.cloudFormationRoleArn = commonRoleset["CloudFormationRoleArn"]
 
-		err = rolesetUpserter.UpsertEnvironmentRoleset(workflow.environment.Name)
+		err = rolesetUpserter.UpsertEnvironmentRoleset(ctx, workflow.environment.Name)
 		if err != nil {
 			return err
 		}
 
-		environmentRoleset, err := rolesetGetter.GetEnvironmentRoleset(workflow.environment.Name)
+		environmentRoleset, err := rolesetGetter.GetEnvironmentRoleset(ctx, workflow.environment.Name)
 		if err != nil {
 			return err
 		}
@@ -240,7 +246,7 @@ func (workflow *environmentWorkflow) environmentRolesetUpserter(rolesetUpserter
 }
 
 func (workflow *environmentWorkflow) environmentElbUpserter(namespace string, envStackParams map[string]string, elbStackParams map[string]string, imageFinder common.ImageFinder, stackUpserter common.StackUpserter, stackWaiter common.StackWaiter) Executor {
-	return func() error {
+	return func(ctx context.Context) error {
 		environment := workflow.environment
 		envStackName := common.CreateStackName(namespace, common.StackTypeLoadBalancer, environment.Name)
 
@@ -271,6 +277,10 @@ func (workflow *environmentWorkflow) environmentElbUpserter(namespace string, en
 			stackParams["ServiceDiscoveryName"] = environment.Discovery.Name
 		}
 
+		if environment.Loadbalancer.Type != "" {
+			stackParams["ElbType"] = string(environment.Loadbalancer.Type)
+		}
+
 		stackParams["ElbInternal"] = strconv.FormatBool(environment.Loadbalancer.Internal)
 
 		if environment.Loadbalancer.AccessLogs.S3BucketName != "" {
@@ -289,12 +299,12 @@ func (workflow *environmentWorkflow) environmentElbUpserter(namespace string, en
 			Repo:        workflow.repoName,
 		})
 
-		err := stackUpserter.UpsertStack(envStackName, common.TemplateELB, environment, stackParams, tags, "", workflow.cloudFormationRoleArn)
+		err := stackUpserter.UpsertStack(ctx, envStackName, common.TemplateELB, environment, stackParams, tags, "", workflow.cloudFormationRoleArn)
 		if err != nil {
 			return err
 		}
 		log.Debugf("Waiting for stack '%s' to complete", envStackName)
-		stack := stackWaiter.AwaitFinalStatus(envStackName)
+		stack := stackWaiter.AwaitFinalStatus(ctx, envStackName)
 
 		if stack == nil {
 			return fmt.Errorf("Unable to create stack %s", envStackName)
@@ -309,10 +319,72 @@ func (workflow *environmentWorkflow) environmentElbUpserter(namespace string, en
 	}
 }
 
+// environmentEfsParams adds mount targets for an EFS filesystem in the instance subnets when a service of the config has EFS volumes.
+// An existing filesystem is kept, so an upsert from a repo without volumes doesn't remove the data of other services.
+func (workflow *environmentWorkflow) environmentEfsParams(namespace string, config *common.Config, envStackParams map[string]string, stackWaiter common.StackWaiter) Executor {
+	return func(ctx context.Context) error {
+		environment := workflow.environment
+		if environment.Provider != common.EnvProviderEcs && environment.Provider != common.EnvProviderEcsFargate {
+			return nil
+		}
+
+		hasEfsVolumes, err := configHasEfsVolumes(config, environment.Name)
+		if err != nil {
+			return err
+		}
+		if !hasEfsVolumes {
+			envStack := stackWaiter.AwaitFinalStatus(ctx, common.CreateStackName(namespace, common.StackTypeEnv, environment.Name))
+			if envStack == nil || envStack.Outputs["EfsFileSystemId"] == "" {
+				return nil
+			}
+		}
+
+		// EFS allows one mount target per availability zone, the instance subnets of a VPC managed by mu are one per
+		// availability zone but the subnets of a target VPC have to be listed
+		var subnetIds []string
+		if environment.VpcTarget.VpcID != "" {
+			if len(environment.VpcTarget.EfsSubnetIds) == 0 {
+				return fmt.Errorf("Environment '%s' needs vpcTarget.efsSubnetIds for the EFS mount targets, list one subnet per availability zone", environment.Name)
+			}
+			subnetIds = environment.VpcTarget.EfsSubnetIds
+		} else {
+			vpcStack := stackWaiter.AwaitFinalStatus(ctx, workflow.vpcStackName)
+			if vpcStack == nil || vpcStack.Outputs["InstanceSubnetIds"] == "" {
+				return fmt.Errorf("Unable to find the instance subnets of stack '%s' for the EFS mount targets", workflow.vpcStackName)
+			}
+			subnetIds = strings.Split(vpcStack.Outputs["InstanceSubnetIds"], ",")
+		}
+
+		// the template has at most 3 mount targets
+		if len(subnetIds) > 3 {
+			subnetIds = subnetIds[:3]
+		}
+		envStackParams["EfsSubnetIds"] = strings.Join(subnetIds, ",")
+		envStackParams["EfsMountTargetCount"] = strconv.Itoa(len(subnetIds))
+		return nil
+	}
+}
+
+// configHasEfsVolumes returns true if any service of the config has EFS volumes in the environment
+func configHasEfsVolumes(config *common.Config, environmentName string) (bool, error) {
+	for _, service := range append([]common.Service{config.Service}, config.Services...) {
+		serviceConfig, err := service.GetServiceConfig(environmentName)
+		if err != nil {
+			return false, err
+		}
+		for _, volume := range serviceConfig.Volumes {
+			if volume.IsEFS() {
+				return true, nil
+			}
+		}
+	}
+	return false, nil
+}
+
 func (workflow *environmentWorkflow) environmentUpserter(namespace string, envStackParams map[string]string,
 	imageFinder common.ImageFinder, stackUpserter common.StackUpserter,
 	stackWaiter common.StackWaiter) Executor {
-	return func() error {
+	return func(ctx context.Context) error {
 		log.Debugf("Using provider '%s' for environment", workflow.environment.Provider)
 
 		environment := workflow.environment
@@ -386,6 +458,16 @@ func (workflow *environmentWorkflow) environmentUpserter(namespace string, envSt
 
 		common.NewMapElementIfNotEmpty(stackParams, "HttpProxy", environment.Cluster.HTTPProxy)
 
+		if environment.Provider == common.EnvProviderEcs {
+			stackParams["OnDemandBaseCapacity"] = strconv.Itoa(environment.Cluster.Spot.OnDemandBaseCapacity)
+			stackParams["OnDemandPercentageAboveBaseCapacity"] = strconv.Itoa(100 - environment.Cluster.Spot.Percentage)
+			common.NewMapElementIfNotEmpty(stackParams, "SpotAllocationStrategy", string(environment.Cluster.Spot.AllocationStrategy))
+			stackParams["CapacityProviderEnabled"] = strconv.FormatBool(environment.Cluster.CapacityProvider.Enabled)
+			common.NewMapElementIfNotZero(stackParams, "CapacityProviderTargetCapacity", environment.Cluster.CapacityProvider.TargetCapacity)
+		} else if environment.Cluster.UsesLaunchTemplate() {
+			log.Warningf("Environment '%s' ignores the instanceTypes, spot and capacityProvider of the cluster, they are only supported for provider '%s'", environment.Name, common.EnvProviderEcs)
+		}
+
 		tags := createTagMap(&EnvironmentTags{
 			Environment: environment.Name,
 			Type:        string(common.StackTypeEnv),
@@ -394,12 +476,12 @@ func (workflow *environmentWorkflow) environmentUpserter(namespace string, envSt
 			Repo:        workflow.repoName,
 		})
 
-		err := stackUpserter.UpsertStack(envStackName, templateName, environment, stackParams, tags, "", workflow.cloudFormationRoleArn)
+		err := stackUpserter.UpsertStack(ctx, envStackName, templateName, environment, stackParams, tags, "", workflow.cloudFormationRoleArn)
 		if err != nil {
 			return err
 		}
 		log.Debugf("Waiting for stack '%s' to complete", envStackName)
-		stack := stackWaiter.AwaitFinalStatus(envStackName)
+		stack := stackWaiter.AwaitFinalStatus(ctx, envStackName)
 
 		if stack == nil {
 			return fmt.Errorf("Unable to create stack %s", envStackName)
@@ -413,9 +495,9 @@ func (workflow *environmentWorkflow) environmentUpserter(namespace string, envSt
 }
 
 func (workflow *environmentWorkflow) environmentKubernetesBootstrapper(namespace string, envStackParams map[string]string, stackWaiter common.StackWaiter, stackUpserter common.StackUpserter) Executor {
-	return func() error {
+	return func(ctx context.Context) error {
 		envStackName := common.CreateStackName(namespace, common.StackTypeEnv, workflow.environment.Name)
-		envStack := stackWaiter.AwaitFinalStatus(envStackName)
+		envStack := stackWaiter.AwaitFinalStatus(ctx, envStackName)
 
 		if envStack == nil || envStack.Status == cloudformation.StackStatusRollbackComplete {
 			log.Debugf("Attempting to bootstrap stack '%s'", envStackName)
@@ -435,12 +517,12 @@ func (workflow *environmentWorkflow) environmentKubernetesBootstrapper(namespace
 				Repo:        workflow.repoName,
 			})
 
-			err := stackUpserter.UpsertStack(envStackName, common.TemplateEnvEKSBootstrap, workflow.environment, stackParams, tags, "", "")
+			err := stackUpserter.UpsertStack(ctx, envStackName, common.TemplateEnvEKSBootstrap, workflow.environment, stackParams, tags, "", "")
 			if err != nil {
 				return err
 			}
 			log.Debugf("Waiting for stack '%s' to complete", envStackName)
-			stack := stackWaiter.AwaitFinalStatus(envStackName)
+			stack := stackWaiter.AwaitFinalStatus(ctx, envStackName)
 
 			if stack == nil {
 				return fmt.Errorf("Unable to create stack %s", envStackName)
@@ -456,7 +538,7 @@ func (workflow *environmentWorkflow) environmentKubernetesBootstrapper(namespace
 }
 
 func (workflow *environmentWorkflow) environmentKubernetesClusterUpserter(namespace string, serviceName string, region string, accountID string, partition string) Executor {
-	return func() error {
+	return func(ctx context.Context) error {
 
 		templateData := map[string]interface{}{
 			"EC2RoleArn":   workflow.ec2RoleArn,
@@ -478,7 +560,7 @@ func (workflow *environmentWorkflow) environmentKubernetesClusterUpserter(namesp
 }
 
 func (workflow *environmentWorkflow) environmentKubernetesIngressUpserter(namespace string, region string, accountID string, partition string) Executor {
-	return func() error {
+	return func(ctx context.Context) error {
 
 		var elbCertArn string
 		if workflow.environment.Loadbalancer.Certificate != "" {
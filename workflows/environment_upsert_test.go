@@ -2,6 +2,7 @@ package workflows
 
 import (
 	"bytes"
+	"context"
 	"testing"
 
 	"github.com/stelligent/mu/common"
@@ -25,19 +26,19 @@ func TestEnvironmentFinder(t *testing.T) {
 	workflow := new(environmentWorkflow)
 
 	workflow.environment = nil
-	fooErr := workflow.environmentFinder(config, "foo")()
+	fooErr := workflow.environmentFinder(config, "foo")(context.Background())
 	assert.NotNil(workflow.environment)
 	assert.Equal("foo", workflow.environment.Name)
 	assert.Nil(fooErr)
 
 	workflow.environment = nil
-	barErr := workflow.environmentFinder(config, "bar")()
+	barErr := workflow.environmentFinder(config, "bar")(context.Background())
 	assert.NotNil(workflow.environment)
 	assert.Equal("bar", workflow.environment.Name)
 	assert.Nil(barErr)
 
 	workflow.environment = nil
-	bazErr := workflow.environmentFinder(config, "baz")()
+	bazErr := workflow.environmentFinder(config, "baz")(context.Background())
 	assert.Nil(workflow.environment)
 	assert.NotNil(bazErr)
 }
@@ -55,7 +56,7 @@ type mockedStackManagerForUpsert struct {
 	common.StackManager
 }
 
-func (m *mockedStackManagerForUpsert) AwaitFinalStatus(stackName string) *common.Stack {
+func (m *mockedStackManagerForUpsert) AwaitFinalStatus(ctx context.Context, stackName string) *common.Stack {
 	args := m.Called(stackName)
 	rtn := args.Get(0)
 	if rtn == nil {
@@ -63,7 +64,7 @@ func (m *mockedStackManagerForUpsert) AwaitFinalStatus(stackName string) *common
 	}
 	return rtn.(*common.Stack)
 }
-func (m *mockedStackManagerForUpsert) UpsertStack(stackName string, templateName string, templateData interface{}, stackParameters map[string]string, stackTags map[string]string, policy string, roleArn string) error {
+func (m *mockedStackManagerForUpsert) UpsertStack(ctx context.Context, stackName string, templateName string, templateData interface{}, stackParameters map[string]string, stackTags map[string]string, policy string, roleArn string) error {
 	args := m.Called(stackName, stackParameters)
 	return args.Error(0)
 }
@@ -92,7 +93,7 @@ func TestEnvironmentEcsUpserter(t *testing.T) {
 	stackManager.On("UpsertStack", "mu-environment-foo", mock.AnythingOfType("map[string]string")).Return(nil)
 	stackManager.On("FindLatestImageID").Return("ami-00000", nil)
 
-	err := workflow.environmentUpserter("mu", vpcInputParams, stackManager, stackManager, stackManager)()
+	err := workflow.environmentUpserter("mu", vpcInputParams, stackManager, stackManager, stackManager)(context.Background())
 	assert.Nil(err)
 
 	stackManager.AssertExpectations(t)
@@ -121,7 +122,7 @@ func TestEnvironmentEc2Upserter(t *testing.T) {
 	stackManager.On("UpsertStack", "mu-environment-foo", mock.AnythingOfType("map[string]string")).Return(nil)
 	stackManager.On("FindLatestImageID").Return("ami-00000", nil)
 
-	err := workflow.environmentUpserter("mu", vpcInputParams, stackManager, stackManager, stackManager)()
+	err := workflow.environmentUpserter("mu", vpcInputParams, stackManager, stackManager, stackManager)(context.Background())
 	assert.Nil(err)
 
 	stackManager.AssertExpectations(t)
@@ -164,7 +165,7 @@ func TestEnvironmentElbUpserter(t *testing.T) {
 	stackManager.On("AwaitFinalStatus", "mu-loadbalancer-foo").Return(&common.Stack{Status: common.StackStatusCreateComplete})
 	stackManager.On("UpsertStack", "mu-loadbalancer-foo", mock.AnythingOfType("map[string]string")).Return(nil)
 
-	err := workflow.environmentElbUpserter("mu", vpcInputParams, vpcInputParams, stackManager, stackManager, stackManager)()
+	err := workflow.environmentElbUpserter("mu", vpcInputParams, vpcInputParams, stackManager, stackManager, stackManager)(context.Background())
 	assert.Nil(err)
 
 	stackManager.AssertExpectations(t)
@@ -189,7 +190,7 @@ func TestEnvironmentVpcUpserter(t *testing.T) {
 	stackManager.On("FindLatestImageID").Return("ami-00000", nil)
 	stackManager.On("CountAZs").Return(3)
 
-	err := workflow.environmentVpcUpserter("mu", vpcInputParams, vpcInputParams, stackManager, stackManager, stackManager, stackManager)()
+	err := workflow.environmentVpcUpserter("mu", vpcInputParams, vpcInputParams, stackManager, stackManager, stackManager, stackManager)(context.Background())
 	assert.Nil(err)
 	assert.Equal("mu-vpc-foo-VpcId", vpcInputParams["VpcId"])
 	assert.Equal("mu-vpc-foo-InstanceSubnetIds", vpcInputParams["InstanceSubnetIds"])
@@ -215,7 +216,7 @@ func TestEnvironmentVpcUpserter_NoBastion(t *testing.T) {
 	stackManager.On("UpsertStack", "mu-vpc-foo", mock.AnythingOfType("map[string]string")).Return(nil)
 	stackManager.On("CountAZs").Return(3)
 
-	err := workflow.environmentVpcUpserter("mu", vpcInputParams, vpcInputParams, stackManager, stackManager, stackManager, stackManager)()
+	err := workflow.environmentVpcUpserter("mu", vpcInputParams, vpcInputParams, stackManager, stackManager, stackManager, stackManager)(context.Background())
 	assert.Nil(err)
 	assert.Equal("mu-vpc-foo-VpcId", vpcInputParams["VpcId"])
 	assert.Equal("mu-vpc-foo-InstanceSubnetIds", vpcInputParams["InstanceSubnetIds"])
@@ -252,7 +253,7 @@ environments:
 	workflow := new(environmentWorkflow)
 	workflow.environment = &config.Environments[0]
 
-	err = workflow.environmentVpcUpserter("mu", vpcInputParams, vpcInputParams, stackManager, stackManager, stackManager, stackManager)()
+	err = workflow.environmentVpcUpserter("mu", vpcInputParams, vpcInputParams, stackManager, stackManager, stackManager, stackManager)(context.Background())
 	assert.Nil(err)
 	assert.Equal("mu-target-dev-VpcId", vpcInputParams["VpcId"])
 	assert.Equal("mu-target-dev-InstanceSubnetIds", vpcInputParams["InstanceSubnetIds"])
@@ -1,48 +1,50 @@
 package workflows
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
+	"time"
 
 	"github.com/stelligent/mu/common"
 )
 
 // InstanceView representation of instance
 type instanceView struct {
-	instanceID   string
-	instanceType string
-	amiID        string
-	instanceIP   string
-	availZone    string
-	ready        bool
-	status       string
-	taskCount    int64
-	cpuAvail     int64
-	memAvail     int64
+	InstanceID       string `json:"instanceId" yaml:"instanceId"`
+	InstanceType     string `json:"instanceType,omitempty" yaml:"instanceType,omitempty"`
+	AmiID            string `json:"amiId,omitempty" yaml:"amiId,omitempty"`
+	PrivateIP        string `json:"privateIp" yaml:"privateIp"`
+	AvailabilityZone string `json:"availabilityZone" yaml:"availabilityZone"`
+	Connected        bool   `json:"connected" yaml:"connected"`
+	Status           string `json:"status,omitempty" yaml:"status,omitempty"`
+	TaskCount        int64  `json:"taskCount" yaml:"taskCount"`
+	CPUAvailable     int64  `json:"cpuAvailable" yaml:"cpuAvailable"`
+	MemoryAvailable  int64  `json:"memoryAvailable" yaml:"memoryAvailable"`
 }
 
 // ServiceView representation of service
 type serviceView struct {
-	name         string
-	revision     string
-	status       string
-	statusReason string
-	lastUpdate   string
+	Name         string    `json:"service" yaml:"service"`
+	Revision     string    `json:"revision" yaml:"revision"`
+	Status       string    `json:"status,omitempty" yaml:"status,omitempty"`
+	StatusReason string    `json:"statusReason,omitempty" yaml:"statusReason,omitempty"`
+	LastUpdate   time.Time `json:"lastUpdate" yaml:"lastUpdate"`
 }
 
 // EnvironmentView representation of environment
 type environmentView struct {
-	name          string
-	provider      common.EnvProvider
-	clusterName   string
-	clusterStatus string
-	vpcName       string
-	vpcStatus     string
-	bastionHost   string
-	baseURL       string
-	instances     []*instanceView
-	services      []*serviceView
+	Name          string             `json:"environment" yaml:"environment"`
+	Provider      common.EnvProvider `json:"provider" yaml:"provider"`
+	ClusterName   string             `json:"clusterStack" yaml:"clusterStack"`
+	ClusterStatus string             `json:"clusterStatus" yaml:"clusterStatus"`
+	VpcName       string             `json:"vpcStack" yaml:"vpcStack"`
+	VpcStatus     string             `json:"vpcStatus,omitempty" yaml:"vpcStatus,omitempty"`
+	BastionHost   string             `json:"bastionHost,omitempty" yaml:"bastionHost,omitempty"`
+	BaseURL       string             `json:"baseUrl" yaml:"baseUrl"`
+	Instances     []*instanceView    `json:"instances" yaml:"instances"`
+	Services      []*serviceView     `json:"services" yaml:"services"`
 }
 
 // NewEnvironmentViewer create a new workflow for showing an environment
@@ -50,16 +52,20 @@ func NewEnvironmentViewer(ctx *common.Context, format string, environmentName st
 
 	workflow := new(environmentWorkflow)
 	view := new(environmentView)
-	view.instances = make([]*instanceView, 0)
-	view.services = make([]*serviceView, 0)
+	view.Instances = make([]*instanceView, 0)
+	view.Services = make([]*serviceView, 0)
 
-	var environmentViewer func() error
-	if format == JSON {
+	// json and shell stay the BASE_URL of the environment for the newman and shell steps of pipelines
+	var environmentViewer Executor
+	switch format {
+	case JSON:
 		environmentViewer = workflow.environmentViewerJSON(view, writer)
-	} else if format == SHELL {
+	case SHELL:
 		environmentViewer = workflow.environmentViewerSHELL(view, writer)
-	} else {
-		environmentViewer = workflow.environmentViewerCLI(view, writer)
+	case JSONVIEW:
+		environmentViewer = workflow.environmentViewer(view, JSON, writer)
+	default:
+		environmentViewer = workflow.environmentViewer(view, format, writer)
 	}
 
 	return newPipelineExecutor(
@@ -69,15 +75,15 @@ func NewEnvironmentViewer(ctx *common.Context, format string, environmentName st
 			newPipelineExecutor(
 				workflow.connectKubernetes(ctx.Config.Namespace, ctx.KubernetesResourceManagerProvider),
 				workflow.environmentEksIngressLoader(view),
-				workflow.environmentEksNodeLoader(&view.instances),
-				workflow.environmentEksServiceLoader(&view.services),
+				workflow.environmentEksNodeLoader(&view.Instances),
+				workflow.environmentEksServiceLoader(&view.Services),
 			),
-			workflow.environmentCFNServiceLoader(ctx.Config.Namespace, environmentName, "", ctx.StackManager, &view.services),
+			workflow.environmentCFNServiceLoader(ctx.Config.Namespace, environmentName, "", ctx.StackManager, &view.Services),
 		),
 		newConditionalExecutor(
 			workflow.isEcsProvider(),
 			newPipelineExecutor(
-				workflow.environmentEcsInstanceLoader(ctx.Config.Namespace, environmentName, ctx.ClusterManager, ctx.InstanceManager, &view.instances),
+				workflow.environmentEcsInstanceLoader(ctx.Config.Namespace, environmentName, ctx.ClusterManager, ctx.InstanceManager, &view.Instances),
 			),
 			nil,
 		),
@@ -86,7 +92,7 @@ func NewEnvironmentViewer(ctx *common.Context, format string, environmentName st
 }
 
 func (workflow *environmentWorkflow) environmentLoader(namespace string, environmentName string, stackGetter common.StackGetter, view *environmentView) Executor {
-	return func() error {
+	return func(ctx context.Context) error {
 		lbStackName := common.CreateStackName(namespace, common.StackTypeLoadBalancer, environmentName)
 		lbStack, _ := stackGetter.GetStack(lbStackName)
 
@@ -103,20 +109,20 @@ func (workflow *environmentWorkflow) environmentLoader(namespace string, environ
 			Provider: common.EnvProvider(clusterStack.Tags["provider"]),
 		}
 
-		view.name = environmentName
-		view.provider = common.EnvProvider(clusterStack.Tags["provider"])
-		view.clusterName = clusterStackName
-		view.clusterStatus = clusterStack.Status
-		view.vpcName = vpcStackName
+		view.Name = environmentName
+		view.Provider = common.EnvProvider(clusterStack.Tags["provider"])
+		view.ClusterName = clusterStackName
+		view.ClusterStatus = clusterStack.Status
+		view.VpcName = vpcStackName
 		if vpcStack != nil {
-			view.vpcStatus = vpcStack.Status
-			view.bastionHost = vpcStack.Outputs[BastionHostKey]
+			view.VpcStatus = vpcStack.Status
+			view.BastionHost = vpcStack.Outputs[BastionHostKey]
 		}
 
 		if lbStack != nil {
-			view.baseURL = lbStack.Outputs[BaseURLValueKey]
+			view.BaseURL = lbStack.Outputs[BaseURLValueKey]
 		} else {
-			view.baseURL = clusterStack.Outputs[BaseURLValueKey]
+			view.BaseURL = clusterStack.Outputs[BaseURLValueKey]
 		}
 
 		return nil
@@ -124,7 +130,7 @@ func (workflow *environmentWorkflow) environmentLoader(namespace string, environ
 }
 
 func (workflow *environmentWorkflow) environmentEksIngressLoader(view *environmentView) Executor {
-	return func() error {
+	return func(ctx context.Context) error {
 		ingressList, err := workflow.kubernetesResourceManager.ListResources("v1", "Service", "mu-ingress")
 		if err != nil {
 			return err
@@ -133,14 +139,14 @@ func (workflow *environmentWorkflow) environmentEksIngressLoader(view *environme
 			if common.MapGetString(ingress.Object, "metadata", "name") == "nginx-ingress-service" {
 				host := common.MapGetString(ingress.Object, "status", "loadBalancer", "ingress", 0, "hostname")
 				proto := common.MapGetString(ingress.Object, "spec", "ports", 0, "name")
-				view.baseURL = fmt.Sprintf("%s://%s", proto, host)
+				view.BaseURL = fmt.Sprintf("%s://%s", proto, host)
 			}
 		}
 		return nil
 	}
 }
 func (workflow *environmentWorkflow) environmentEksNodeLoader(instances *[]*instanceView) Executor {
-	return func() error {
+	return func(ctx context.Context) error {
 		nodes, err := workflow.kubernetesResourceManager.ListResources("v1", "Node", "")
 		if err != nil {
 			log.Warningf("Unable to list nodes: %v", err)
@@ -173,7 +179,7 @@ func (workflow *environmentWorkflow) environmentEksNodeLoader(instances *[]*inst
 	}
 }
 func (workflow *environmentWorkflow) environmentEksServiceLoader(services *[]*serviceView) Executor {
-	return func() error {
+	return func(ctx context.Context) error {
 		namespaces, err := workflow.kubernetesResourceManager.ListResources("v1", "Namespace", "")
 		if err != nil {
 			return err
@@ -187,7 +193,7 @@ func (workflow *environmentWorkflow) environmentEksServiceLoader(services *[]*se
 					common.MapGetString(ns.Object, "metadata", "annotations", "mu/revision"),
 					"",
 					"",
-					"",
+					time.Time{},
 				})
 			}
 		}
@@ -196,7 +202,7 @@ func (workflow *environmentWorkflow) environmentEksServiceLoader(services *[]*se
 }
 
 func (workflow *environmentWorkflow) environmentEcsInstanceLoader(namespace string, environmentName string, clusterInstanceLister common.ClusterInstanceLister, instanceLister common.InstanceLister, instanceViews *[]*instanceView) Executor {
-	return func() error {
+	return func(ctx context.Context) error {
 		clusterName := common.CreateStackName(namespace, common.StackTypeEnv, environmentName)
 		containerInstances, err := clusterInstanceLister.ListInstances(clusterName)
 		if err == nil {
@@ -258,7 +264,7 @@ func (workflow *environmentWorkflow) environmentEcsInstanceLoader(namespace stri
 	}
 }
 func (workflow *environmentWorkflow) environmentCFNServiceLoader(namespace string, environmentName string, serviceName string, stackLister common.StackLister, serviceViews *[]*serviceView) Executor {
-	return func() error {
+	return func(ctx context.Context) error {
 		stacks, err := stackLister.ListStacks(common.StackTypeService, namespace)
 		if err != nil {
 			return err
@@ -277,7 +283,7 @@ func (workflow *environmentWorkflow) environmentCFNServiceLoader(namespace strin
 				stackValues.Tags["revision"],
 				stackValues.Status,
 				stackValues.StatusReason,
-				stackValues.LastUpdateTime.Local().Format(LastUpdateTime),
+				stackValues.LastUpdateTime,
 			})
 		}
 		return nil
@@ -285,10 +291,10 @@ func (workflow *environmentWorkflow) environmentCFNServiceLoader(namespace strin
 }
 
 func (workflow *environmentWorkflow) environmentViewerJSON(view *environmentView, writer io.Writer) Executor {
-	return func() error {
+	return func(ctx context.Context) error {
 		output := common.JSONOutput{}
 		output.Values[0].Key = BaseURLKey
-		output.Values[0].Value = view.baseURL
+		output.Values[0].Value = view.BaseURL
 
 		enc := json.NewEncoder(writer)
 		return enc.Encode(&output)
@@ -296,10 +302,10 @@ func (workflow *environmentWorkflow) environmentViewerJSON(view *environmentView
 }
 
 func (workflow *environmentWorkflow) environmentViewerSHELL(view *environmentView, writer io.Writer) Executor {
-	return func() error {
+	return func(ctx context.Context) error {
 		output := common.JSONOutput{}
 		output.Values[0].Key = BaseURLKey
-		output.Values[0].Value = view.baseURL
+		output.Values[0].Value = view.BaseURL
 
 		for _, val := range output.Values {
 			fmt.Fprintf(writer, "%s=%s\n", val.Key, val.Value)
@@ -309,36 +315,44 @@ func (workflow *environmentWorkflow) environmentViewerSHELL(view *environmentVie
 	}
 }
 
-func (workflow *environmentWorkflow) environmentViewerCLI(view *environmentView, writer io.Writer) Executor {
-	return func() error {
-
-		fmt.Fprintf(writer, HeaderValueFormat, Bold(EnvironmentHeader), view.name)
-		fmt.Fprintf(writer, HeaderValueFormat, Bold("Provider"), view.provider)
-		if view.clusterName != "" {
-			fmt.Fprintf(writer, StackFormat, Bold(ClusterStack), view.clusterName, colorizeStackStatus(view.clusterStatus))
+func (workflow *environmentWorkflow) environmentViewer(view *environmentView, format string, writer io.Writer) Executor {
+	return func(ctx context.Context) error {
+		records := func() [][]string {
+			rows := [][]string{{"environment", "service", "revision", "status", "statusReason", "lastUpdate"}}
+			for _, service := range view.Services {
+				rows = append(rows, []string{view.Name, service.Name, service.Revision, service.Status, service.StatusReason, formatViewTime(service.LastUpdate)})
+			}
+			return rows
 		}
+		return writeView(writer, format, view, records, func() { printEnvironment(view, writer) })
+	}
+}
 
-		if view.vpcStatus == "" {
-			fmt.Fprintf(writer, UnmanagedStackFormat, Bold(VPCStack))
-		} else {
-			fmt.Fprintf(writer, StackFormat, Bold(VPCStack), view.vpcName, colorizeStackStatus(view.vpcStatus))
-			fmt.Fprintf(writer, HeaderValueFormat, Bold(BastionHost), view.bastionHost)
-		}
-		fmt.Fprintf(writer, HeaderValueFormat, Bold(BaseURLHeader), view.baseURL)
+func printEnvironment(view *environmentView, writer io.Writer) {
+	fmt.Fprintf(writer, HeaderValueFormat, Bold(EnvironmentHeader), view.Name)
+	fmt.Fprintf(writer, HeaderValueFormat, Bold("Provider"), view.Provider)
+	if view.ClusterName != "" {
+		fmt.Fprintf(writer, StackFormat, Bold(ClusterStack), view.ClusterName, colorizeStackStatus(view.ClusterStatus))
+	}
 
-		if len(view.instances) > 0 {
-			fmt.Fprintf(writer, HeadNewlineHeader, Bold(ContainerInstances))
-			printInstanceTable(view.instances, writer)
-		}
+	if view.VpcStatus == "" {
+		fmt.Fprintf(writer, UnmanagedStackFormat, Bold(VPCStack))
+	} else {
+		fmt.Fprintf(writer, StackFormat, Bold(VPCStack), view.VpcName, colorizeStackStatus(view.VpcStatus))
+		fmt.Fprintf(writer, HeaderValueFormat, Bold(BastionHost), view.BastionHost)
+	}
+	fmt.Fprintf(writer, HeaderValueFormat, Bold(BaseURLHeader), view.BaseURL)
 
-		fmt.Fprint(writer, NewLine)
-		fmt.Fprintf(writer, HeadNewlineHeader, Bold(ServicesHeader))
-		printServiceTable(view.services, writer)
+	if len(view.Instances) > 0 {
+		fmt.Fprintf(writer, HeadNewlineHeader, Bold(ContainerInstances))
+		printInstanceTable(view.Instances, writer)
+	}
 
-		fmt.Fprint(writer, NewLine)
+	fmt.Fprint(writer, NewLine)
+	fmt.Fprintf(writer, HeadNewlineHeader, Bold(ServicesHeader))
+	printServiceTable(view.Services, writer)
 
-		return nil
-	}
+	fmt.Fprint(writer, NewLine)
 }
 
 func printServiceTable(services []*serviceView, writer io.Writer) {
@@ -346,10 +360,10 @@ func printServiceTable(services []*serviceView, writer io.Writer) {
 
 	for _, service := range services {
 		table.Append([]string{
-			Bold(service.name),
-			service.revision,
-			fmt.Sprintf(KeyValueFormat, colorizeStackStatus(service.status), service.statusReason),
-			service.lastUpdate,
+			Bold(service.Name),
+			service.Revision,
+			fmt.Sprintf(KeyValueFormat, colorizeStackStatus(service.Status), service.StatusReason),
+			formatLastUpdate(service.LastUpdate),
 		})
 	}
 
@@ -361,16 +375,16 @@ func printInstanceTable(instances []*instanceView, writer io.Writer) {
 
 	for _, instance := range instances {
 		table.Append([]string{
-			instance.instanceID,
-			instance.instanceType,
-			instance.amiID,
-			instance.instanceIP,
-			instance.availZone,
-			fmt.Sprintf(BoolStringFormat, instance.ready),
-			instance.status,
-			fmt.Sprintf(IntStringFormat, instance.taskCount),
-			fmt.Sprintf(IntStringFormat, instance.cpuAvail),
-			fmt.Sprintf(IntStringFormat, instance.memAvail),
+			instance.InstanceID,
+			instance.InstanceType,
+			instance.AmiID,
+			instance.PrivateIP,
+			instance.AvailabilityZone,
+			fmt.Sprintf(BoolStringFormat, instance.Connected),
+			instance.Status,
+			fmt.Sprintf(IntStringFormat, instance.TaskCount),
+			fmt.Sprintf(IntStringFormat, instance.CPUAvailable),
+			fmt.Sprintf(IntStringFormat, instance.MemoryAvailable),
 		})
 	}
 
@@ -1,6 +1,9 @@
 package workflows
 
 import (
+	"bytes"
+	"context"
+	"encoding/json"
 	"testing"
 
 	"github.com/stelligent/mu/common"
@@ -13,3 +16,33 @@ func TestNewEnvironmentViewer(t *testing.T) {
 	viewer := NewEnvironmentViewer(ctx, "json", "foo", nil)
 	assert.NotNil(viewer)
 }
+
+func TestEnvironmentViewer_Formats(t *testing.T) {
+	assert := assert.New(t)
+
+	workflow := new(environmentWorkflow)
+	view := &environmentView{
+		Name:      "dev",
+		BaseURL:   "https://dev.example.com",
+		Instances: make([]*instanceView, 0),
+		Services:  make([]*serviceView, 0),
+	}
+
+	var out bytes.Buffer
+	err := workflow.environmentViewerJSON(view, &out)(context.Background())
+	assert.Nil(err)
+	assert.JSONEq(`{"values":[{"key":"BASE_URL","value":"https://dev.example.com"}]}`, out.String())
+
+	out.Reset()
+	err = workflow.environmentViewerSHELL(view, &out)(context.Background())
+	assert.Nil(err)
+	assert.Equal("BASE_URL=https://dev.example.com\n", out.String())
+
+	out.Reset()
+	err = workflow.environmentViewer(view, JSON, &out)(context.Background())
+	assert.Nil(err)
+	var fullView map[string]interface{}
+	assert.Nil(json.Unmarshal(out.Bytes(), &fullView))
+	assert.Equal("dev", fullView["environment"])
+	assert.Equal("https://dev.example.com", fullView["baseUrl"])
+}
@@ -1,30 +1,44 @@
 package workflows
 
 import (
-	"errors"
+	"context"
+	"reflect"
+	"runtime"
+	"strings"
+	"time"
 
 	"github.com/stelligent/mu/common"
 )
 
-// Executor define contract for the steps of a workflow
-type Executor func() error
+// Executor define contract for the steps of a workflow, the context is done once the workflow is interrupted or times out
+type Executor func(ctx context.Context) error
 
 // Conditional define contract for the conditional predicate
 type Conditional func() bool
 
 func newPipelineExecutor(executors ...Executor) Executor {
-	return func() error {
+	return func(ctx context.Context) error {
 		for _, executor := range executors {
-			err := executor()
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			err := runStep(ctx, executor)
 			if err != nil {
+				// the step most likely failed because it was stopped, report why it was stopped instead
+				if ctxErr := ctx.Err(); ctxErr != nil {
+					log.Debugf("%+v", err)
+					return ctxErr
+				}
 				switch err.(type) {
 				case common.Warning:
 					log.Warning(err.Error())
 					return nil
+				case loggedError:
+					return err
 				default:
 					log.Errorf("%v", err)
 					log.Debugf("%+v", err)
-					return errors.New("")
+					return loggedError{err}
 				}
 			}
 		}
@@ -32,45 +46,128 @@ func newPipelineExecutor(executors ...Executor) Executor {
 	}
 }
 
+// loggedError is an error that was already logged by a pipeline, so pipelines that contain it don't log it again
+type loggedError struct {
+	err error
+}
+
+func (e loggedError) Error() string {
+	return e.err.Error()
+}
+
+// IsLoggedError returns true if the error was already logged by the pipeline that returned it
+func IsLoggedError(err error) bool {
+	_, ok := err.(loggedError)
+	return ok
+}
+
 func newConditionalExecutor(conditional Conditional, trueExecutor Executor, falseExecutor Executor) Executor {
-	return func() error {
+	return func(ctx context.Context) error {
 		if conditional() == true {
 			if trueExecutor != nil {
-				return trueExecutor()
+				return runStep(ctx, trueExecutor)
 			}
 		} else {
 			if falseExecutor != nil {
-				return falseExecutor()
+				return runStep(ctx, falseExecutor)
 			}
 		}
 		return nil
 	}
 }
 
-func executeWithChan(executor Executor, errChan chan error) {
-	errChan <- executor()
+// newPlanSkippingExecutor skips steps that can't be previewed with change sets when only planning changes
+func newPlanSkippingExecutor(config *common.Config, description string, executor Executor) Executor {
+	return func(ctx context.Context) error {
+		if config.Plan {
+			log.Noticef("PLAN: Skipping %s", description)
+			return nil
+		}
+		return runStep(ctx, executor)
+	}
+}
+
+func executeWithChan(ctx context.Context, executor Executor, errChan chan error) {
+	errChan <- runStep(ctx, executor)
 }
 
 func newErrorExecutor(err error) Executor {
-	return func() error {
+	return func(ctx context.Context) error {
 		return err
 	}
 }
 
+// newParallelExecutor runs the executors concurrently. The first error stops the other executors at their next step,
+// and is returned once all of them have stopped so none are left running in the background
 func newParallelExecutor(executors ...Executor) Executor {
-	return func() error {
-		errChan := make(chan error)
+	return func(ctx context.Context) error {
+		ctx, cancel := context.WithCancel(ctx)
+		defer cancel()
 
+		errChan := make(chan error, len(executors))
 		for _, executor := range executors {
-			go executeWithChan(executor, errChan)
+			go executeWithChan(ctx, executor, errChan)
 		}
 
+		var firstErr error
 		for i := 0; i < len(executors); i++ {
 			err := <-errChan
-			if err != nil {
-				return err
+			if err != nil && firstErr == nil {
+				firstErr = err
+				cancel()
 			}
 		}
-		return nil
+		return firstErr
+	}
+}
+
+// combinedSteps are the executors that only run other steps, they don't get events of their own
+var combinedSteps = map[string]bool{
+	"newPipelineExecutor":     true,
+	"newConditionalExecutor":  true,
+	"newParallelExecutor":     true,
+	"newPlanSkippingExecutor": true,
+	"newErrorExecutor":        true,
+}
+
+// runStep runs the executor, emitting events for the start and the result of the step when events are enabled
+func runStep(ctx context.Context, executor Executor) error {
+	if !common.EventsEnabled() {
+		return executor(ctx)
+	}
+	step := stepName(executor)
+	if combinedSteps[step] {
+		return executor(ctx)
+	}
+
+	common.EmitEvent(common.Event{Type: common.EventTypeStep, Step: step, Status: common.EventStatusStarted})
+	start := time.Now()
+	err := executor(ctx)
+	event := common.Event{
+		Type:            common.EventTypeStep,
+		Step:            step,
+		Status:          common.EventStatusSucceeded,
+		DurationSeconds: time.Since(start).Seconds(),
+	}
+	if _, ok := err.(common.Warning); ok {
+		event.Reason = err.Error()
+	} else if err != nil {
+		event.Status = common.EventStatusFailed
+		event.Reason = err.Error()
+	}
+	common.EmitEvent(event)
+	return err
+}
+
+// stepName returns the name of the function that created the executor, e.g. environmentUpserter
+func stepName(executor Executor) string {
+	name := runtime.FuncForPC(reflect.ValueOf(executor).Pointer()).Name()
+	parts := strings.Split(name[strings.LastIndex(name, "/")+1:], ".")
+	for i := len(parts) - 1; i > 0; i-- {
+		part := strings.TrimSuffix(parts[i], "-fm")
+		if strings.Trim(strings.TrimPrefix(part, "func"), "0123456789") != "" {
+			return part
+		}
 	}
+	return name
 }
@@ -1,9 +1,15 @@
 package workflows
 
 import (
+	"bytes"
+	"context"
+	"encoding/json"
 	"errors"
+	"github.com/stelligent/mu/common"
 	"github.com/stretchr/testify/assert"
+	"strings"
 	"testing"
+	"time"
 )
 
 func TestNewWorkflow(t *testing.T) {
@@ -11,29 +17,47 @@ func TestNewWorkflow(t *testing.T) {
 
 	// empty
 	emptyWorkflow := newPipelineExecutor()
-	assert.Nil(emptyWorkflow())
+	assert.Nil(emptyWorkflow(context.Background()))
 
 	// error case
-	errorWorkflow := newPipelineExecutor(func() error {
+	errorWorkflow := newPipelineExecutor(func(ctx context.Context) error {
 		return errors.New("error occurred")
 	})
-	assert.NotNil(errorWorkflow())
+	assert.NotNil(errorWorkflow(context.Background()))
 
 	// multiple success case
 	runcount := 0
 	successWorkflow := newPipelineExecutor(
-		func() error {
+		func(ctx context.Context) error {
 			runcount = runcount + 1
 			return nil
 		},
-		func() error {
+		func(ctx context.Context) error {
 			runcount = runcount + 1
 			return nil
 		})
-	assert.Nil(successWorkflow())
+	assert.Nil(successWorkflow(context.Background()))
 	assert.Equal(2, runcount)
 }
 
+func TestNewWorkflow_LoggedError(t *testing.T) {
+	assert := assert.New(t)
+
+	// the pipeline that logs an error returns it as logged, and pipelines that contain it return it unchanged
+	failure := errors.New("error occurred")
+	inner := newPipelineExecutor(func(ctx context.Context) error {
+		return failure
+	})
+	err := newPipelineExecutor(inner)(context.Background())
+	assert.True(IsLoggedError(err))
+	assert.Equal(loggedError{failure}, err)
+	assert.Equal("error occurred", err.Error())
+
+	// errors that weren't returned by a pipeline weren't logged
+	assert.False(IsLoggedError(failure))
+	assert.False(IsLoggedError(nil))
+}
+
 func TestNewConditionalExecutor(t *testing.T) {
 	assert := assert.New(t)
 
@@ -42,13 +66,13 @@ func TestNewConditionalExecutor(t *testing.T) {
 
 	err := newConditionalExecutor(func() bool {
 		return false
-	}, func() error {
+	}, func(ctx context.Context) error {
 		trueCount++
 		return nil
-	}, func() error {
+	}, func(ctx context.Context) error {
 		falseCount++
 		return nil
-	})()
+	})(context.Background())
 
 	assert.Nil(err)
 	assert.Equal(0, trueCount)
@@ -56,15 +80,105 @@ func TestNewConditionalExecutor(t *testing.T) {
 
 	err = newConditionalExecutor(func() bool {
 		return true
-	}, func() error {
+	}, func(ctx context.Context) error {
 		trueCount++
 		return nil
-	}, func() error {
+	}, func(ctx context.Context) error {
 		falseCount++
 		return nil
-	})()
+	})(context.Background())
 
 	assert.Nil(err)
 	assert.Equal(1, trueCount)
 	assert.Equal(1, falseCount)
 }
+
+func TestNewWorkflow_Cancelled(t *testing.T) {
+	assert := assert.New(t)
+
+	runcount := 0
+	ctx, cancel := context.WithCancel(context.Background())
+	workflow := newPipelineExecutor(
+		func(ctx context.Context) error {
+			runcount = runcount + 1
+			cancel()
+			return nil
+		},
+		func(ctx context.Context) error {
+			runcount = runcount + 1
+			return nil
+		})
+	assert.Equal(context.Canceled, workflow(ctx))
+	assert.Equal(1, runcount)
+}
+
+func TestNewParallelExecutor(t *testing.T) {
+	assert := assert.New(t)
+
+	// the failing executor stops the others, which have all returned once the parallel executor returns
+	stopped := make(chan bool, 2)
+	blocking := func(ctx context.Context) error {
+		err := common.SleepWithContext(ctx, time.Minute)
+		stopped <- true
+		return err
+	}
+	err := newParallelExecutor(blocking, func(ctx context.Context) error {
+		return errors.New("failed")
+	}, blocking)(context.Background())
+
+	assert.NotNil(err)
+	assert.Equal("failed", err.Error())
+	assert.Equal(2, len(stopped))
+}
+
+func TestNewParallelExecutor_Timeout(t *testing.T) {
+	assert := assert.New(t)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	err := newParallelExecutor(func(ctx context.Context) error {
+		return common.SleepWithContext(ctx, time.Minute)
+	})(ctx)
+
+	assert.Equal(context.DeadlineExceeded, err)
+}
+
+func newNamedStep(err error) Executor {
+	return func(ctx context.Context) error {
+		return err
+	}
+}
+
+func TestStepName(t *testing.T) {
+	assert := assert.New(t)
+
+	assert.Equal("newNamedStep", stepName(newNamedStep(nil)))
+	assert.Equal("newPipelineExecutor", stepName(newPipelineExecutor()))
+	assert.Equal("environmentLister", stepName(new(environmentWorkflow).environmentLister("mu", nil, TABLE, nil)))
+}
+
+func TestRunStep_Events(t *testing.T) {
+	assert := assert.New(t)
+
+	out := new(bytes.Buffer)
+	common.SetupEvents(out)
+	defer common.SetupEvents(nil)
+
+	err := newPipelineExecutor(newNamedStep(nil), newConditionalExecutor(func() bool { return true }, newNamedStep(errors.New("failed")), nil))(context.Background())
+	assert.NotNil(err)
+
+	events := make([]common.Event, 0)
+	for _, line := range strings.Split(strings.TrimSpace(out.String()), "\n") {
+		event := common.Event{}
+		assert.Nil(json.Unmarshal([]byte(line), &event))
+		events = append(events, event)
+	}
+	assert.Equal(4, len(events))
+	assert.Equal(common.EventTypeStep, events[0].Type)
+	assert.Equal("newNamedStep", events[0].Step)
+	assert.Equal(common.EventStatusStarted, events[0].Status)
+	assert.Equal(common.EventStatusSucceeded, events[1].Status)
+	assert.Equal(common.EventStatusStarted, events[2].Status)
+	assert.Equal(common.EventStatusFailed, events[3].Status)
+	assert.Equal("failed", events[3].Reason)
+}
@@ -0,0 +1,161 @@
+package workflows
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/stelligent/mu/common"
+)
+
+type historyWorkflow struct {
+}
+
+// NewHistoryViewer create a new workflow for showing the past runs in the journal, or the stacks of a single run
+func NewHistoryViewer(ctx *common.Context, format string, runID string, writer io.Writer) Executor {
+
+	workflow := new(historyWorkflow)
+
+	return newPipelineExecutor(
+		newConditionalExecutor(func() bool { return runID == "" },
+			workflow.historyLister(ctx.Config.Namespace, ctx.JournalManager, format, writer),
+			workflow.historyRunViewer(ctx.Config.Namespace, runID, ctx.JournalManager, format, writer)),
+	)
+}
+
+func (workflow *historyWorkflow) historyLister(namespace string, journalLister common.JournalLister, format string, writer io.Writer) Executor {
+	return func(ctx context.Context) error {
+		if journalLister == nil {
+			return errors.New("Journal is not available")
+		}
+		runs, err := journalLister.ListRuns()
+		if err != nil {
+			return err
+		}
+
+		// newest runs first
+		views := make([]*common.JournalRun, 0, len(runs))
+		for i := len(runs) - 1; i >= 0; i-- {
+			if runs[i].Namespace == namespace {
+				views = append(views, runs[i])
+			}
+		}
+
+		records := func() [][]string {
+			rows := [][]string{{"id", "command", "status", "resumeOf", "startTime", "endTime", "stacks"}}
+			for _, run := range views {
+				rows = append(rows, []string{
+					run.ID,
+					run.Command,
+					run.Status,
+					run.ResumeOf,
+					formatViewTime(run.StartTime),
+					formatViewTime(run.EndTime),
+					fmt.Sprintf(IntStringFormat, len(run.Steps)),
+				})
+			}
+			return rows
+		}
+
+		return writeView(writer, format, views, records, func() {
+			table := CreateTableSection(writer, HistoryRunTableHeader)
+			for _, run := range views {
+				table.Append([]string{
+					Bold(run.ID),
+					run.Command,
+					colorizeRunStatus(run.Status),
+					run.StartTime.Local().Format(LastUpdateTime),
+					runDuration(run),
+					fmt.Sprintf(IntStringFormat, len(run.Steps)),
+				})
+			}
+			table.Render()
+		})
+	}
+}
+
+func (workflow *historyWorkflow) historyRunViewer(namespace string, runID string, journalLister common.JournalLister, format string, writer io.Writer) Executor {
+	return func(ctx context.Context) error {
+		if journalLister == nil {
+			return errors.New("Journal is not available")
+		}
+		runs, err := journalLister.ListRuns()
+		if err != nil {
+			return err
+		}
+
+		var run *common.JournalRun
+		for _, r := range runs {
+			if r.Namespace == namespace && r.ID == runID {
+				run = r
+			}
+		}
+		if run == nil {
+			return fmt.Errorf("Run '%s' not found in the journal", runID)
+		}
+
+		records := func() [][]string {
+			rows := [][]string{{"run", "stackName", "action", "status", "skipped", "time"}}
+			for _, step := range run.Steps {
+				rows = append(rows, []string{
+					run.ID,
+					step.StackName,
+					step.Action,
+					step.Status,
+					fmt.Sprintf(BoolStringFormat, step.Skipped),
+					formatViewTime(step.Time),
+				})
+			}
+			return rows
+		}
+
+		return writeView(writer, format, run, records, func() { printHistoryRun(run, writer) })
+	}
+}
+
+func printHistoryRun(run *common.JournalRun, writer io.Writer) {
+	fmt.Fprintf(writer, HeaderValueFormat, Bold(HistoryRunHeader), run.ID)
+	fmt.Fprintf(writer, HeaderValueFormat, Bold(HistoryCommandHeader), run.Command)
+	fmt.Fprintf(writer, HeaderValueFormat, Bold(SvcStatusHeader), colorizeRunStatus(run.Status))
+	if run.ResumeOf != "" {
+		fmt.Fprintf(writer, HeaderValueFormat, Bold("Resumed"), run.ResumeOf)
+	}
+	fmt.Fprintf(writer, HeaderValueFormat, Bold(HistoryDurationHeader), runDuration(run))
+
+	table := CreateTableSection(writer, HistoryStepTableHeader)
+	for _, step := range run.Steps {
+		action := step.Action
+		if step.Skipped {
+			action = fmt.Sprintf("%s (skipped)", action)
+		}
+		table.Append([]string{
+			Bold(step.StackName),
+			action,
+			colorizeStackStatus(step.Status),
+			step.Time.Local().Format(LastUpdateTime),
+		})
+	}
+	table.Render()
+}
+
+func colorizeRunStatus(status string) string {
+	switch status {
+	case common.JournalRunSucceeded:
+		return color.New(color.FgGreen).Sprint(status)
+	case common.JournalRunFailed:
+		return color.New(color.FgRed).Sprint(status)
+	default:
+		return color.New(color.FgBlue).Sprint(status)
+	}
+}
+
+// runDuration returns how long the run took, runs that never finished have no duration
+func runDuration(run *common.JournalRun) string {
+	if run.EndTime.IsZero() {
+		return NA
+	}
+	return run.EndTime.Sub(run.StartTime).Round(time.Second).String()
+}
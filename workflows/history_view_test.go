@@ -0,0 +1,58 @@
+package workflows
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stelligent/mu/common"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewHistoryViewer(t *testing.T) {
+	assert := assert.New(t)
+
+	dir, err := ioutil.TempDir("", "mu-history")
+	assert.Nil(err)
+	defer os.RemoveAll(dir)
+	store, err := common.NewJournalStore(filepath.Join(dir, "journal.json"), nil)
+	assert.Nil(err)
+
+	journal := common.NewJournalManager(store, "mu", "purge", false)
+	assert.Nil(journal.CompleteStep(common.JournalStep{StackName: "mu-service-api-dev", Action: common.JournalActionDelete, Status: common.StackStatusDeleteComplete}))
+	assert.Nil(journal.FinishRun(errors.New("failed")))
+	runs, err := journal.ListRuns()
+	assert.Nil(err)
+	assert.Equal(1, len(runs))
+
+	ctx := common.NewContext()
+	ctx.Config.Namespace = "mu"
+	ctx.JournalManager = journal
+
+	out := new(bytes.Buffer)
+	err = NewHistoryViewer(ctx, TABLE, "", out)(context.Background())
+	assert.Nil(err)
+	assert.Contains(out.String(), runs[0].ID)
+	assert.Contains(out.String(), "purge")
+	assert.Contains(out.String(), common.JournalRunFailed)
+
+	out = new(bytes.Buffer)
+	err = NewHistoryViewer(ctx, TABLE, runs[0].ID, out)(context.Background())
+	assert.Nil(err)
+	assert.Contains(out.String(), "mu-service-api-dev")
+	assert.Contains(out.String(), common.JournalActionDelete)
+
+	err = NewHistoryViewer(ctx, TABLE, "missing", new(bytes.Buffer))(context.Background())
+	assert.NotNil(err)
+
+	// runs of other namespaces aren't shown
+	ctx.Config.Namespace = "other"
+	out = new(bytes.Buffer)
+	err = NewHistoryViewer(ctx, TABLE, "", out)(context.Background())
+	assert.Nil(err)
+	assert.NotContains(out.String(), runs[0].ID)
+}
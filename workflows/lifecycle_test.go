@@ -0,0 +1,484 @@
+package workflows
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/elbv2"
+	"github.com/stelligent/mu/common"
+	"github.com/stelligent/mu/provider/fake"
+	"github.com/stretchr/testify/assert"
+)
+
+func newFakeContext(state *fake.State) (*common.Context, error) {
+	ctx := common.NewContext()
+	if err := ctx.InitializeContext(); err != nil {
+		return nil, err
+	}
+	if err := fake.InitializeContext(ctx, state); err != nil {
+		return nil, err
+	}
+	ctx.Config.Namespace = "mu"
+	ctx.Config.Repo.Name = "api"
+	ctx.Config.Repo.Revision = "abc123"
+	ctx.Config.Environments = []common.Environment{{Name: "dev"}}
+	ctx.Config.Service.Name = "api"
+	ctx.Config.Service.Port = 8080
+	ctx.Config.Service.PathPatterns = []string{"/api/*"}
+	return ctx, nil
+}
+
+func TestLifecycle(t *testing.T) {
+	assert := assert.New(t)
+
+	state := fake.NewState()
+	state.Rules["arn:aws:elasticloadbalancing:us-east-1:123456789012:listener/app/mu-loadbalancer-dev/fake/http"] = []*elbv2.Rule{
+		{Priority: aws.String("5")},
+	}
+	ctx, err := newFakeContext(state)
+	assert.Nil(err)
+
+	err = NewEnvironmentsUpserter(ctx, []string{"dev"})(context.Background())
+	assert.Nil(err)
+	assert.Contains(state.StackNames(), "mu-iam-common")
+	assert.Contains(state.StackNames(), "mu-vpc-dev")
+	assert.Contains(state.StackNames(), "mu-loadbalancer-dev")
+	assert.Contains(state.StackNames(), "mu-environment-dev")
+	assert.Equal(common.StackStatusCreateComplete, state.Stacks["mu-environment-dev"].Status)
+	assert.Equal("ecs", state.Stacks["mu-environment-dev"].Tags["provider"])
+
+	ctx.Config.Service.DesiredCount = 1
+	ctx.Config.Service.EnvironmentConfig = common.EnvironmentOverrides{
+		"dev": {"desiredCount": 3},
+	}
+	ctx.Config.Service.Secrets = map[string]string{"API_KEY": "/mu/api/key"}
+	err = NewServiceDeployer(ctx, "dev", "abc123")(context.Background())
+	assert.Nil(err)
+	svcStack := state.Stacks["mu-service-api-dev"]
+	assert.NotNil(svcStack)
+	assert.Equal("123456789012.dkr.ecr.us-east-1.amazonaws.com/mu-api:abc123", svcStack.Parameters["ImageUrl"])
+	assert.Equal("3", svcStack.Parameters["ServiceDesiredCount"])
+	assert.Equal(1, ctx.Config.Service.DesiredCount)
+	assert.Equal("6", svcStack.Parameters["PathListenerRulePriority"])
+	assert.Equal("7", svcStack.Parameters["HostListenerRulePriority"])
+	assert.Contains(state.Templates["mu-service-api-dev"], "AWS::ECS::Service")
+	assert.Contains(state.Templates["mu-service-api-dev"], `ValueFrom: "/mu/api/key"`)
+	assert.Contains(state.Templates["mu-iam-service-api-dev"], "arn:${AWS::Partition}:ssm:${AWS::Region}:${AWS::AccountId}:parameter/mu/api/key")
+
+	err = NewServiceDeployer(ctx, "dev", "def456")(context.Background())
+	assert.Nil(err)
+	assert.Equal("123456789012.dkr.ecr.us-east-1.amazonaws.com/mu-api:def456", state.Stacks["mu-service-api-dev"].Parameters["ImageUrl"])
+
+	revisionsOut := new(bytes.Buffer)
+	err = NewServiceRollbacker(ctx, "dev", "", true, TABLE, revisionsOut)(context.Background())
+	assert.Nil(err)
+	assert.Contains(revisionsOut.String(), "def456")
+	assert.Contains(revisionsOut.String(), "abc123")
+
+	revisionsOut.Reset()
+	err = NewServiceRollbacker(ctx, "dev", "", true, JSON, revisionsOut)(context.Background())
+	assert.Nil(err)
+	var revisionViews []map[string]interface{}
+	assert.Nil(json.Unmarshal(revisionsOut.Bytes(), &revisionViews))
+	assert.Equal(2, len(revisionViews))
+	assert.Equal("api", revisionViews[0]["service"])
+	assert.Equal(true, revisionViews[0]["current"])
+	assert.Equal("123456789012.dkr.ecr.us-east-1.amazonaws.com/mu-api:def456", revisionViews[0]["imageUrl"])
+	assert.Equal(false, revisionViews[1]["current"])
+	assert.Equal("123456789012.dkr.ecr.us-east-1.amazonaws.com/mu-api:def456", state.Stacks["mu-service-api-dev"].Parameters["ImageUrl"])
+
+	err = NewServiceRollbacker(ctx, "dev", "", false, TABLE, new(bytes.Buffer))(context.Background())
+	assert.Nil(err)
+	assert.Equal("123456789012.dkr.ecr.us-east-1.amazonaws.com/mu-api:abc123", state.Stacks["mu-service-api-dev"].Parameters["ImageUrl"])
+
+	err = NewServiceRollbacker(ctx, "dev", "def456", false, TABLE, new(bytes.Buffer))(context.Background())
+	assert.Nil(err)
+	assert.Equal("123456789012.dkr.ecr.us-east-1.amazonaws.com/mu-api:def456", state.Stacks["mu-service-api-dev"].Parameters["ImageUrl"])
+
+	err = NewServiceRollbacker(ctx, "dev", "missing", false, TABLE, new(bytes.Buffer))(context.Background())
+	assert.NotNil(err)
+
+	err = NewServiceUndeployer(ctx, "api", "dev")(context.Background())
+	assert.Nil(err)
+	assert.NotContains(state.StackNames(), "mu-service-api-dev")
+
+	err = NewPurge(ctx)(context.Background())
+	assert.Nil(err)
+	assert.Empty(state.StackNames())
+}
+
+func TestLifecycle_FailedEnvironment(t *testing.T) {
+	assert := assert.New(t)
+
+	state := fake.NewState()
+	state.StackFailures["mu-loadbalancer-dev"] = "Listener limit exceeded"
+	ctx, err := newFakeContext(state)
+	assert.Nil(err)
+
+	err = NewEnvironmentsUpserter(ctx, []string{"dev"})(context.Background())
+	assert.NotNil(err)
+	assert.Contains(err.Error(), "Listener limit exceeded")
+	assert.NotContains(state.StackNames(), "mu-environment-dev")
+}
+
+func TestLifecycle_TrafficShifting(t *testing.T) {
+	assert := assert.New(t)
+
+	state := fake.NewState()
+	ctx, err := newFakeContext(state)
+	assert.Nil(err)
+
+	err = NewEnvironmentsUpserter(ctx, []string{"dev"})(context.Background())
+	assert.Nil(err)
+
+	ctx.Config.Service.DeploymentStrategy = common.Canary10Percent5MinutesDeploymentStrategy
+	err = NewServiceDeployer(ctx, "dev", "abc123")(context.Background())
+	assert.Nil(err)
+	assert.Contains(state.Templates["mu-service-api-dev"], "CODE_DEPLOY")
+	assert.Contains(state.Templates["mu-service-api-dev"], "CodeDeployDefault.ECSCanary10Percent5Minutes")
+	assert.Contains(state.Templates["mu-iam-service-api-dev"], "AWSCodeDeployRoleForECS")
+	assert.Empty(state.Deployments)
+
+	err = NewServiceDeployer(ctx, "dev", "def456")(context.Background())
+	assert.Nil(err)
+	assert.Equal("arn:aws:ecs:us-east-1:123456789012:task-definition/mu-service-api-dev:1", state.Stacks["mu-service-api-dev"].Parameters["DeployedTaskDefinitionArn"])
+	assert.Len(state.Deployments, 1)
+	assert.Equal("mu-service-api-dev", state.Deployments["d-1"].DeploymentGroupName)
+	assert.Equal("arn:aws:ecs:us-east-1:123456789012:task-definition/mu-service-api-dev:2", state.Deployments["d-1"].TaskDefinitionArn)
+
+	state.DeploymentFailures["mu-service-api-dev"] = "Alarm 'api-5xx' was activated"
+	err = NewServiceDeployer(ctx, "dev", "ghi789")(context.Background())
+	assert.NotNil(err)
+	assert.Len(state.Deployments, 2)
+	assert.Equal("arn:aws:ecs:us-east-1:123456789012:task-definition/mu-service-api-dev:1", state.Stacks["mu-service-api-dev"].Parameters["DeployedTaskDefinitionArn"])
+}
+
+func TestLifecycle_Sidecars(t *testing.T) {
+	assert := assert.New(t)
+
+	state := fake.NewState()
+	ctx, err := newFakeContext(state)
+	assert.Nil(err)
+
+	err = NewEnvironmentsUpserter(ctx, []string{"dev"})(context.Background())
+	assert.Nil(err)
+
+	ctx.Config.Service.Sidecars = []common.Sidecar{
+		{
+			Name:        "envoy",
+			Image:       "envoyproxy/envoy:v1.9.0",
+			Port:        9901,
+			Essential:   true,
+			DependsOn:   []string{"api"},
+			Environment: map[string]string{"ENVOY_LOG_LEVEL": "info"},
+			Memory:      128,
+		},
+	}
+	err = NewServiceDeployer(ctx, "dev", "abc123")(context.Background())
+	assert.Nil(err)
+	template := state.Templates["mu-service-api-dev"]
+	assert.Contains(template, "- Name: envoy")
+	assert.Contains(template, `Image: "envoyproxy/envoy:v1.9.0"`)
+	assert.Contains(template, "ContainerName: api")
+	assert.Contains(template, "ContainerPort: 9901")
+	assert.Contains(template, "Name: ENVOY_LOG_LEVEL")
+
+	ctx.Config.Service.Sidecars[0].DependsOn = []string{"missing"}
+	err = NewServiceDeployer(ctx, "dev", "abc123")(context.Background())
+	assert.NotNil(err)
+}
+
+func TestLifecycle_NetworkLoadbalancer(t *testing.T) {
+	assert := assert.New(t)
+
+	state := fake.NewState()
+	ctx, err := newFakeContext(state)
+	assert.Nil(err)
+	ctx.Config.Environments[0].Loadbalancer.Type = common.LoadbalancerTypeNetwork
+
+	err = NewEnvironmentsUpserter(ctx, []string{"dev"})(context.Background())
+	assert.Nil(err)
+	assert.Equal("network", state.Stacks["mu-loadbalancer-dev"].Parameters["ElbType"])
+
+	// path patterns need the listener rules of an application load balancer
+	err = NewServiceDeployer(ctx, "dev", "abc123")(context.Background())
+	assert.NotNil(err)
+
+	ctx.Config.Service.PathPatterns = nil
+	ctx.Config.Service.Protocol = common.ServiceProtocolTCP
+	ctx.Config.Service.Port = 1883
+	err = NewServiceDeployer(ctx, "dev", "abc123")(context.Background())
+	assert.Nil(err)
+	svcStack := state.Stacks["mu-service-api-dev"]
+	assert.Equal("mu-loadbalancer-dev-ElbArn", svcStack.Parameters["ElbArn"])
+	assert.Equal("1883", svcStack.Parameters["ListenerPort"])
+	assert.Equal("TCP", svcStack.Parameters["ServiceProtocol"])
+	assert.Contains(state.Templates["mu-service-api-dev"], "ElbNetworkListener")
+	assert.Contains(state.Templates["mu-service-api-dev"], "HealthCheckProtocol: TCP")
+
+	// TLS listeners need the certificate of the environment
+	ctx.Config.Service.Protocol = common.ServiceProtocolTLS
+	err = NewServiceDeployer(ctx, "dev", "abc123")(context.Background())
+	assert.NotNil(err)
+}
+
+func TestLifecycle_GrpcService(t *testing.T) {
+	assert := assert.New(t)
+
+	state := fake.NewState()
+	state.StackOutputs["mu-loadbalancer-dev"] = map[string]string{
+		"ElbHttpsListenerArn": "arn:aws:elasticloadbalancing:us-east-1:123456789012:listener/app/mu-loadbalancer-dev/fake/https",
+	}
+	ctx, err := newFakeContext(state)
+	assert.Nil(err)
+
+	err = NewEnvironmentsUpserter(ctx, []string{"dev"})(context.Background())
+	assert.Nil(err)
+
+	ctx.Config.Service.Protocol = common.ServiceProtocolGRPC
+	ctx.Config.Service.PathPatterns = []string{"/helloworld.Greeter/*"}
+	err = NewServiceDeployer(ctx, "dev", "abc123")(context.Background())
+	assert.Nil(err)
+	svcStack := state.Stacks["mu-service-api-dev"]
+	assert.Equal("HTTP", svcStack.Parameters["ServiceProtocol"])
+	assert.Equal("/AWS.ALB/healthcheck", svcStack.Parameters["ServiceHealthEndpoint"])
+	assert.Contains(state.Templates["mu-service-api-dev"], "ProtocolVersion: GRPC")
+}
+
+func TestLifecycle_Autoscaling(t *testing.T) {
+	assert := assert.New(t)
+
+	state := fake.NewState()
+	ctx, err := newFakeContext(state)
+	assert.Nil(err)
+
+	err = NewEnvironmentsUpserter(ctx, []string{"dev"})(context.Background())
+	assert.Nil(err)
+
+	zero := 0
+	ctx.Config.Service.Autoscaling = common.Autoscaling{
+		TargetTracking: []common.TargetTrackingPolicy{
+			{Name: "requests", Metric: common.AutoscalingMetricRequestCount, TargetValue: 1000},
+			{Name: "queue", Metric: common.AutoscalingMetricSqsQueueDepth, TargetValue: 100, QueueName: "jobs"},
+		},
+		Scheduled: []common.ScheduledAction{
+			{Name: "night", Schedule: "cron(0 20 * * ? *)", MinSize: &zero, MaxSize: &zero},
+		},
+	}
+	err = NewServiceDeployer(ctx, "dev", "abc123")(context.Background())
+	assert.Nil(err)
+
+	assert.Equal("mu-loadbalancer-dev-ElbFullName", state.Stacks["mu-service-api-dev"].Parameters["ElbFullName"])
+	template := state.Templates["mu-service-api-dev"]
+	assert.NotContains(template, "CPUUtilizationPolicy:")
+	assert.Contains(template, "PredefinedMetricType: ALBRequestCountPerTarget")
+	assert.Contains(template, `Value: "jobs"`)
+	assert.Contains(template, "ScheduledActionName: night")
+	assert.Contains(template, "MinCapacity: 0")
+}
+
+func TestLifecycle_Export(t *testing.T) {
+	assert := assert.New(t)
+
+	state := fake.NewState()
+	ctx, err := newFakeContext(state)
+	assert.Nil(err)
+	ctx.Config.Environments = []common.Environment{{Name: "dev", Provider: common.EnvProviderEks}}
+
+	err = NewEnvironmentsUpserter(ctx, []string{"dev"})(context.Background())
+	assert.Nil(err)
+
+	dir, err := ioutil.TempDir("", "mu-export")
+	assert.Nil(err)
+	defer os.RemoveAll(dir)
+
+	// the repo of the service only exists after the first deploy
+	err = NewServiceExporter(ctx, "dev", "abc123", ServiceExportFormatRaw, dir, false)(context.Background())
+	assert.NotNil(err)
+
+	err = NewServiceDeployer(ctx, "dev", "abc123")(context.Background())
+	assert.Nil(err)
+	applied := len(state.KubernetesResources["mu-environment-dev"])
+
+	err = NewServiceExporter(ctx, "dev", "abc123", ServiceExportFormatKustomize, filepath.Join(dir, "kustomize"), false)(context.Background())
+	assert.Nil(err)
+	assert.Equal(applied, len(state.KubernetesResources["mu-environment-dev"]))
+	kustomization, err := ioutil.ReadFile(filepath.Join(dir, "kustomize", "kustomization.yaml"))
+	assert.Nil(err)
+	assert.Contains(string(kustomization), "- deployment-api-deployment.yaml")
+	assert.Contains(string(kustomization), "- service-api.yaml")
+	deployment, err := ioutil.ReadFile(filepath.Join(dir, "kustomize", "deployment-api-deployment.yaml"))
+	assert.Nil(err)
+	assert.Contains(string(deployment), "image: 123456789012.dkr.ecr.us-east-1.amazonaws.com/mu-api:abc123")
+
+	err = NewServiceExporter(ctx, "dev", "abc123", ServiceExportFormatHelm, filepath.Join(dir, "helm"), false)(context.Background())
+	assert.Nil(err)
+	chart, err := ioutil.ReadFile(filepath.Join(dir, "helm", "Chart.yaml"))
+	assert.Nil(err)
+	assert.Contains(string(chart), "name: api")
+	values, err := ioutil.ReadFile(filepath.Join(dir, "helm", "values.yaml"))
+	assert.Nil(err)
+	assert.Contains(string(values), "image: 123456789012.dkr.ecr.us-east-1.amazonaws.com/mu-api:abc123")
+	assert.Contains(string(values), "replicaCount: 2")
+	deployment, err = ioutil.ReadFile(filepath.Join(dir, "helm", "templates", "deployment-api-deployment.yaml"))
+	assert.Nil(err)
+	assert.Contains(string(deployment), "image: {{ .Values.image }}")
+	assert.Contains(string(deployment), "replicas: {{ .Values.replicaCount }}")
+
+	err = NewServiceExporter(ctx, "dev", "abc123", "jsonnet", dir, false)(context.Background())
+	assert.NotNil(err)
+}
+
+func TestLifecycle_Volumes(t *testing.T) {
+	assert := assert.New(t)
+
+	state := fake.NewState()
+	ctx, err := newFakeContext(state)
+	assert.Nil(err)
+
+	err = NewEnvironmentsUpserter(ctx, []string{"dev"})(context.Background())
+	assert.Nil(err)
+	assert.Equal("", state.Stacks["mu-environment-dev"].Outputs["EfsFileSystemId"])
+
+	// the filesystem is only created by the environment once a service has volumes
+	ctx.Config.Service.Volumes = []common.Volume{{Name: "app-data", ContainerPath: "/var/lib/app"}}
+	err = NewServiceDeployer(ctx, "dev", "abc123")(context.Background())
+	assert.NotNil(err)
+
+	err = NewEnvironmentsUpserter(ctx, []string{"dev"})(context.Background())
+	assert.Nil(err)
+	assert.Equal("3", state.Stacks["mu-environment-dev"].Parameters["EfsMountTargetCount"])
+	assert.Equal("subnet-1,subnet-2,subnet-3", state.Stacks["mu-environment-dev"].Parameters["EfsSubnetIds"])
+
+	err = NewServiceDeployer(ctx, "dev", "abc123")(context.Background())
+	assert.Nil(err)
+	assert.Equal("mu-environment-dev-EfsFileSystemId", state.Stacks["mu-service-api-dev"].Parameters["EfsFileSystemId"])
+	template := state.Templates["mu-service-api-dev"]
+	assert.Contains(template, "AppDataAccessPoint:")
+	assert.Contains(template, "EFSVolumeConfiguration:")
+	assert.Contains(template, "AccessPointId: !Ref AppDataAccessPoint")
+	assert.Contains(template, "SourceVolume: app-data")
+	assert.Contains(template, "ContainerPath: /var/lib/app")
+
+	// the filesystem is kept by an upsert without volumes
+	ctx.Config.Service.Volumes = nil
+	err = NewEnvironmentsUpserter(ctx, []string{"dev"})(context.Background())
+	assert.Nil(err)
+	assert.Equal("3", state.Stacks["mu-environment-dev"].Parameters["EfsMountTargetCount"])
+
+	ctx.Config.Service.Volumes = []common.Volume{{Name: "cache", Type: common.VolumeTypeEBS, ContainerPath: "/cache"}}
+	err = NewServiceDeployer(ctx, "dev", "abc123")(context.Background())
+	assert.NotNil(err)
+}
+
+func TestLifecycle_Dependencies(t *testing.T) {
+	assert := assert.New(t)
+
+	state := fake.NewState()
+	ctx, err := newFakeContext(state)
+	assert.Nil(err)
+	ctx.Config.Service = common.Service{}
+	ctx.Config.Services = []common.Service{
+		{Name: "web", Port: 8080, PathPatterns: []string{"/*"}, DependsOn: []common.ServiceDependency{{Service: "api"}}},
+		{Name: "api", Port: 8080, PathPatterns: []string{"/api/*"}},
+	}
+
+	err = NewEnvironmentsUpserter(ctx, []string{"dev"})(context.Background())
+	assert.Nil(err)
+
+	// web can't be deployed before api
+	err = NewOrderedServicesExecutor(ctx, "web", func(serviceCtx *common.Context) Executor {
+		return NewServiceDeployer(serviceCtx, "dev", "abc123")
+	})(context.Background())
+	assert.NotNil(err)
+	assert.Nil(state.Stacks["mu-service-web-dev"])
+
+	err = NewOrderedServicesExecutor(ctx, "", func(serviceCtx *common.Context) Executor {
+		return NewServiceDeployer(serviceCtx, "dev", "abc123")
+	})(context.Background())
+	assert.Nil(err)
+	assert.NotNil(state.Stacks["mu-service-api-dev"])
+	assert.Contains(state.Templates["mu-service-web-dev"], "- Name: API_SERVICE_DISCOVERY_NAME")
+	assert.Contains(state.Templates["mu-service-web-dev"], "Value: !Sub api.dev.mu.local")
+
+	// api must have running tasks
+	for i := range state.Tasks {
+		state.Tasks[i].Status = "STOPPED"
+	}
+	err = NewOrderedServicesExecutor(ctx, "web", func(serviceCtx *common.Context) Executor {
+		return NewServiceDeployer(serviceCtx, "dev", "abc123")
+	})(context.Background())
+	assert.NotNil(err)
+
+	// the database of a service must exist
+	ctx.Config.Services[0].DependsOn = append(ctx.Config.Services[0].DependsOn, common.ServiceDependency{Database: "api"})
+	err = NewOrderedServicesExecutor(ctx, "web", func(serviceCtx *common.Context) Executor {
+		return NewServiceDeployer(serviceCtx, "dev", "abc123")
+	})(context.Background())
+	assert.NotNil(err)
+}
+
+func TestLifecycle_CapacityProviders(t *testing.T) {
+	assert := assert.New(t)
+
+	state := fake.NewState()
+	ctx, err := newFakeContext(state)
+	assert.Nil(err)
+	ctx.Config.Environments[0].Cluster.InstanceTypes = []string{"m5a.large", "m4.large"}
+	ctx.Config.Environments[0].Cluster.Spot.Percentage = 75
+	ctx.Config.Environments = append(ctx.Config.Environments, common.Environment{Name: "fargate", Provider: common.EnvProviderEcsFargate})
+
+	err = NewEnvironmentsUpserter(ctx, []string{"dev", "fargate"})(context.Background())
+	assert.Nil(err)
+	assert.Equal("25", state.Stacks["mu-environment-dev"].Parameters["OnDemandPercentageAboveBaseCapacity"])
+	assert.Equal("false", state.Stacks["mu-environment-dev"].Parameters["CapacityProviderEnabled"])
+	assert.Contains(state.Templates["mu-environment-dev"], "MixedInstancesPolicy:")
+	assert.Contains(state.Templates["mu-environment-dev"], "- InstanceType: m5a.large")
+	assert.Equal("", state.Stacks["mu-environment-fargate"].Parameters["OnDemandPercentageAboveBaseCapacity"])
+
+	// clusters without spot instances, instance types or capacity provider keep their launch configuration
+	ctx.Config.Environments = append(ctx.Config.Environments, common.Environment{Name: "ondemand", Provider: common.EnvProviderEcs})
+	err = NewEnvironmentsUpserter(ctx, []string{"ondemand"})(context.Background())
+	assert.Nil(err)
+	assert.Contains(state.Templates["mu-environment-ondemand"], "Type: AWS::AutoScaling::LaunchConfiguration")
+	assert.NotContains(state.Templates["mu-environment-ondemand"], "LaunchTemplateData:")
+	assert.Contains(state.Templates["mu-environment-dev"], "Type: AWS::EC2::LaunchTemplate")
+
+	// EC2 tasks need the capacity provider of the environment
+	ctx.Config.Service.CapacityProviders = []common.CapacityProviderItem{{Provider: common.CapacityProviderEC2, Weight: 1}}
+	err = NewServiceDeployer(ctx, "dev", "abc123")(context.Background())
+	assert.NotNil(err)
+
+	ctx.Config.Environments[0].Cluster.CapacityProvider.Enabled = true
+	err = NewEnvironmentsUpserter(ctx, []string{"dev"})(context.Background())
+	assert.Nil(err)
+	assert.Equal("true", state.Stacks["mu-environment-dev"].Parameters["CapacityProviderEnabled"])
+
+	err = NewServiceDeployer(ctx, "dev", "abc123")(context.Background())
+	assert.Nil(err)
+	assert.Equal("mu-environment-dev-EcsCapacityProvider", state.Stacks["mu-service-api-dev"].Parameters["EcsCapacityProvider"])
+	assert.Contains(state.Templates["mu-service-api-dev"], "CapacityProviderStrategy:")
+	assert.Equal([]common.CapacityProviderItem{{Provider: common.CapacityProviderEC2, Weight: 1}}, ctx.Config.Service.CapacityProviders)
+
+	// services without capacity providers keep their launch type
+	ctx.Config.Service.CapacityProviders = nil
+	err = NewServiceDeployer(ctx, "dev", "abc123")(context.Background())
+	assert.Nil(err)
+	assert.NotContains(state.Templates["mu-service-api-dev"], "CapacityProviderStrategy:")
+	assert.Nil(ctx.Config.Service.CapacityProviders)
+
+	// Fargate Spot is only available in Fargate environments
+	ctx.Config.Service.CapacityProviders = []common.CapacityProviderItem{{Provider: common.CapacityProviderFargate, Base: 1}, {Provider: common.CapacityProviderFargateSpot, Weight: 1}}
+	err = NewServiceDeployer(ctx, "dev", "abc123")(context.Background())
+	assert.NotNil(err)
+
+	err = NewServiceDeployer(ctx, "fargate", "abc123")(context.Background())
+	assert.Nil(err)
+	assert.Contains(state.Templates["mu-service-api-fargate"], "- CapacityProvider: FARGATE_SPOT")
+}
@@ -1,6 +1,7 @@
 package workflows
 
 import (
+	"context"
 	"github.com/fatih/color"
 	"github.com/stelligent/mu/common"
 )
@@ -9,6 +10,8 @@ type pipelineWorkflow struct {
 	serviceName      string
 	databaseName     string
 	muFile           string
+	servicePath      string
+	serviceSelector  string
 	pipelineConfig   *common.Pipeline
 	codeRevision     string
 	codeBranch       string
@@ -35,7 +38,7 @@ func colorizeActionStatus(actionStatus string) string {
 // Find the service in config
 func (workflow *pipelineWorkflow) serviceFinder(serviceName string, ctx *common.Context) Executor {
 
-	return func() error {
+	return func(context.Context) error {
 		// Repo Name
 		if serviceName != "" {
 			workflow.serviceName = serviceName
@@ -50,6 +53,12 @@ func (workflow *pipelineWorkflow) serviceFinder(serviceName string, ctx *common.
 		workflow.codeRevision = ctx.Config.Repo.Revision
 		workflow.codeBranch = ctx.Config.Repo.Branch
 		workflow.muFile = ctx.Config.RelMuFile
+		workflow.servicePath = ctx.Config.Service.Path
+
+		// only pipelines of a mu.yml with several services select their service, a single service is the whole mu.yml
+		if len(ctx.Config.Services) > 0 {
+			workflow.serviceSelector = workflow.serviceName
+		}
 
 		repoName := ctx.Config.Repo.Slug
 		if workflow.pipelineConfig.Source.Repo == "" {
@@ -1,6 +1,7 @@
 package workflows
 
 import (
+	"context"
 	"github.com/stelligent/mu/common"
 	"github.com/stretchr/testify/assert"
 	"testing"
@@ -15,20 +16,27 @@ func TestServiceFinder(t *testing.T) {
 	ctx.Config.Repo.Name = "my-repo"
 	ctx.Config.Repo.Slug = "foo/my-repo"
 
-	err := workflow.serviceFinder("", ctx)()
+	err := workflow.serviceFinder("", ctx)(context.Background())
 	assert.Nil(err)
 	assert.NotNil(workflow.pipelineConfig)
 	assert.Equal("my-repo", workflow.serviceName)
 	assert.Equal("foo/my-repo", workflow.pipelineConfig.Source.Repo)
 	assert.Equal("GitHub", workflow.pipelineConfig.Source.Provider)
+	assert.Equal("", workflow.serviceSelector)
 
 	ctx.Config.Service.Name = "my-service"
 	ctx.Config.Service.Pipeline.Source.Provider = "CodeCommit"
 	ctx.Config.Service.Pipeline.Source.Repo = "bar/my-repo"
-	err = workflow.serviceFinder("", ctx)()
+	err = workflow.serviceFinder("", ctx)(context.Background())
 	assert.Nil(err)
 	assert.NotNil(workflow.pipelineConfig)
 	assert.Equal("my-service", workflow.serviceName)
 	assert.Equal("bar/my-repo", workflow.pipelineConfig.Source.Repo)
 	assert.Equal("CodeCommit", workflow.pipelineConfig.Source.Provider)
+	assert.Equal("", workflow.serviceSelector)
+
+	ctx.Config.Services = []common.Service{ctx.Config.Service}
+	err = workflow.serviceFinder("", ctx)(context.Background())
+	assert.Nil(err)
+	assert.Equal("my-service", workflow.serviceSelector)
 }
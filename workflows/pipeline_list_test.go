@@ -9,6 +9,6 @@ import (
 func TestNewPipelineLister(t *testing.T) {
 	assert := assert.New(t)
 	ctx := common.NewContext()
-	lister := NewPipelineLister(ctx, nil)
+	lister := NewPipelineLister(ctx, TABLE, nil)
 	assert.NotNil(lister)
 }
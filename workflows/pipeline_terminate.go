@@ -1,6 +1,7 @@
 package workflows
 
 import (
+	"context"
 	"fmt"
 	"github.com/stelligent/mu/common"
 	"strings"
@@ -19,8 +20,8 @@ func NewPipelineTerminator(ctx *common.Context, serviceName string) Executor {
 }
 
 func (workflow *pipelineWorkflow) pipelineRolesetTerminator(rolesetDeleter common.RolesetDeleter) Executor {
-	return func() error {
-		err := rolesetDeleter.DeletePipelineRoleset(workflow.serviceName)
+	return func(ctx context.Context) error {
+		err := rolesetDeleter.DeletePipelineRoleset(ctx, workflow.serviceName)
 		if err != nil {
 			return err
 		}
@@ -29,7 +30,7 @@ func (workflow *pipelineWorkflow) pipelineRolesetTerminator(rolesetDeleter commo
 }
 
 func (workflow *pipelineWorkflow) pipelineTerminator(namespace string, stackDeleter common.StackDeleter, stackWaiter common.StackWaiter) Executor {
-	return func() error {
+	return func(ctx context.Context) error {
 		log.Noticef("Terminating Pipeline '%s' ...", workflow.serviceName)
 		pipelineStackName := common.CreateStackName(namespace, common.StackTypePipeline, workflow.serviceName)
 		err := stackDeleter.DeleteStack(pipelineStackName)
@@ -37,7 +38,7 @@ func (workflow *pipelineWorkflow) pipelineTerminator(namespace string, stackDele
 			return err
 		}
 
-		stack := stackWaiter.AwaitFinalStatus(pipelineStackName)
+		stack := stackWaiter.AwaitFinalStatus(ctx, pipelineStackName)
 		if stack != nil && !strings.HasSuffix(stack.Status, "_COMPLETE") {
 			return fmt.Errorf("Ended in failed status %s %s", stack.Status, stack.StatusReason)
 		}
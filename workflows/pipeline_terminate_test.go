@@ -1,6 +1,7 @@
 package workflows
 
 import (
+	"context"
 	"github.com/stelligent/mu/common"
 	"github.com/stretchr/testify/assert"
 	"testing"
@@ -24,7 +25,7 @@ func TestPipelineTerminator(t *testing.T) {
 	stackManager.On("AwaitFinalStatus", "mu-pipeline-foo").Return(&common.Stack{Status: common.StackStatusDeleteComplete})
 	stackManager.On("DeleteStack", "mu-pipeline-foo").Return(nil)
 
-	err := workflow.pipelineTerminator("mu", stackManager, stackManager)()
+	err := workflow.pipelineTerminator("mu", stackManager, stackManager)(context.Background())
 	assert.Nil(err)
 
 	stackManager.AssertExpectations(t)
@@ -1,6 +1,7 @@
 package workflows
 
 import (
+	"context"
 	"fmt"
 	"path"
 	"strconv"
@@ -29,7 +30,8 @@ func NewPipelineUpserter(ctx *common.Context, tokenProvider func(bool) string) E
 		workflow.pipelineToken(ctx.Config.Namespace, tokenProvider, ctx.StackManager, stackParams),
 		newConditionalExecutor(
 			workflow.isFromCatalog(&ctx.Config.Service.Pipeline),
-			workflow.pipelineCatalogUpserter(ctx.Config.Namespace, &ctx.Config.Service.Pipeline, stackParams, ctx.CatalogManager, ctx.StackManager),
+			newPlanSkippingExecutor(&ctx.Config, "provisioning of catalog product",
+				workflow.pipelineCatalogUpserter(ctx.Config.Namespace, &ctx.Config.Service.Pipeline, stackParams, ctx.CatalogManager, ctx.StackManager)),
 			newPipelineExecutor(
 				newParallelExecutor(
 					workflow.pipelineBucket(ctx.Config.Namespace, stackParams, ctx.StackManager, ctx.StackManager),
@@ -39,7 +41,8 @@ func NewPipelineUpserter(ctx *common.Context, tokenProvider func(bool) string) E
 				workflow.pipelineUpserter(ctx.Config.Namespace, ctx.StackManager, ctx.StackManager, stackParams),
 			),
 		),
-		workflow.pipelineNotifyUpserter(ctx.Config.Namespace, &ctx.Config.Service.Pipeline, ctx.SubscriptionManager))
+		newPlanSkippingExecutor(&ctx.Config, "pipeline notifications",
+			workflow.pipelineNotifyUpserter(ctx.Config.Namespace, &ctx.Config.Service.Pipeline, ctx.SubscriptionManager)))
 
 }
 
@@ -50,7 +53,7 @@ func (workflow *pipelineWorkflow) isFromCatalog(pipeline *common.Pipeline) Condi
 }
 
 func (workflow *pipelineWorkflow) codedeployBucket(namespace string, service *common.Service, stackUpserter common.StackUpserter, stackWaiter common.StackWaiter) Executor {
-	return func() error {
+	return func(ctx context.Context) error {
 
 		if service.Pipeline.Build.Bucket != "" {
 			workflow.codeDeployBucket = service.Pipeline.Build.Bucket
@@ -65,13 +68,13 @@ func (workflow *pipelineWorkflow) codedeployBucket(namespace string, service *co
 				Type: common.StackTypeBucket,
 			})
 
-			err := stackUpserter.UpsertStack(bucketStackName, common.TemplateBucket, nil, bucketParams, tags, "", "")
+			err := stackUpserter.UpsertStack(ctx, bucketStackName, common.TemplateBucket, nil, bucketParams, tags, "", "")
 			if err != nil {
 				return err
 			}
 
 			log.Debugf("Waiting for stack '%s' to complete", bucketStackName)
-			stack := stackWaiter.AwaitFinalStatus(bucketStackName)
+			stack := stackWaiter.AwaitFinalStatus(ctx, bucketStackName)
 			if stack == nil {
 				return fmt.Errorf("Unable to create stack %s", bucketStackName)
 			}
@@ -89,7 +92,7 @@ func (workflow *pipelineWorkflow) codedeployBucket(namespace string, service *co
 // Setup the artifact bucket
 func (workflow *pipelineWorkflow) pipelineBucket(namespace string, params map[string]string, stackUpserter common.StackUpserter, stackWaiter common.StackWaiter) Executor {
 
-	return func() error {
+	return func(ctx context.Context) error {
 		if workflow.pipelineConfig.Bucket != "" {
 			params["PipelineBucket"] = workflow.pipelineConfig.Bucket
 		} else {
@@ -103,7 +106,7 @@ func (workflow *pipelineWorkflow) pipelineBucket(namespace string, params map[st
 				Type: common.StackTypeBucket,
 			})
 
-			err := stackUpserter.UpsertStack(bucketStackName, common.TemplateBucket, nil, bucketParams, tags, "", "")
+			err := stackUpserter.UpsertStack(ctx, bucketStackName, common.TemplateBucket, nil, bucketParams, tags, "", "")
 			if err != nil {
 				// ignore error if stack is in progress already
 				if !strings.Contains(err.Error(), "_IN_PROGRESS state and can not be updated") {
@@ -112,7 +115,7 @@ func (workflow *pipelineWorkflow) pipelineBucket(namespace string, params map[st
 			}
 
 			log.Debugf("Waiting for stack '%s' to complete", bucketStackName)
-			stack := stackWaiter.AwaitFinalStatus(bucketStackName)
+			stack := stackWaiter.AwaitFinalStatus(ctx, bucketStackName)
 			if stack == nil {
 				return fmt.Errorf("Unable to create stack %s", bucketStackName)
 			}
@@ -129,9 +132,9 @@ func (workflow *pipelineWorkflow) pipelineBucket(namespace string, params map[st
 
 // Fetch token if needed
 func (workflow *pipelineWorkflow) pipelineToken(namespace string, tokenProvider func(bool) string, stackWaiter common.StackWaiter, params map[string]string) Executor {
-	return func() error {
+	return func(ctx context.Context) error {
 		pipelineStackName := common.CreateStackName(namespace, common.StackTypePipeline, workflow.serviceName)
-		pipelineStack := stackWaiter.AwaitFinalStatus(pipelineStackName)
+		pipelineStack := stackWaiter.AwaitFinalStatus(ctx, pipelineStackName)
 		if workflow.pipelineConfig.Source.Provider == "GitHub" {
 			params["GitHubToken"] = tokenProvider(pipelineStack == nil)
 		}
@@ -140,7 +143,7 @@ func (workflow *pipelineWorkflow) pipelineToken(namespace string, tokenProvider
 }
 
 func (workflow *pipelineWorkflow) pipelineRolesetUpserter(rolesetUpserter common.RolesetUpserter, rolesetGetter common.RolesetGetter, params map[string]string) Executor {
-	return func() error {
+	return func(ctx context.Context) error {
 		environments := make([]string, 0)
 
 		if !workflow.pipelineConfig.Acceptance.Disabled {
@@ -166,22 +169,22 @@ func (workflow *pipelineWorkflow) pipelineRolesetUpserter(rolesetUpserter common
 		// add executors for environment and service rolesets
 		for i := range environments {
 			envName := environments[i]
-			rolesetExecutors = append(rolesetExecutors, func() error {
-				return rolesetUpserter.UpsertEnvironmentRoleset(envName)
+			rolesetExecutors = append(rolesetExecutors, func(ctx context.Context) error {
+				return rolesetUpserter.UpsertEnvironmentRoleset(ctx, envName)
 			})
 
-			rolesetExecutors = append(rolesetExecutors, func() error {
-				return rolesetUpserter.UpsertServiceRoleset(envName, workflow.serviceName, workflow.codeDeployBucket, workflow.databaseName)
+			rolesetExecutors = append(rolesetExecutors, func(ctx context.Context) error {
+				return rolesetUpserter.UpsertServiceRoleset(ctx, envName, workflow.serviceName, workflow.codeDeployBucket, workflow.databaseName)
 			})
 		}
 
-		rolesetExecutors = append(rolesetExecutors, func() error {
-			err := rolesetUpserter.UpsertPipelineRoleset(workflow.serviceName, params["PipelineBucket"], workflow.codeDeployBucket)
+		rolesetExecutors = append(rolesetExecutors, func(ctx context.Context) error {
+			err := rolesetUpserter.UpsertPipelineRoleset(ctx, workflow.serviceName, params["PipelineBucket"], workflow.codeDeployBucket)
 			if err != nil {
 				return err
 			}
 
-			pipelineRoleset, err := rolesetGetter.GetPipelineRoleset(workflow.serviceName)
+			pipelineRoleset, err := rolesetGetter.GetPipelineRoleset(ctx, workflow.serviceName)
 			if err != nil {
 				return err
 			}
@@ -196,16 +199,18 @@ func (workflow *pipelineWorkflow) pipelineRolesetUpserter(rolesetUpserter common
 		})
 
 		executor := newPipelineExecutor(
-			rolesetUpserter.UpsertCommonRoleset,
+			func(context.Context) error {
+				return rolesetUpserter.UpsertCommonRoleset(ctx)
+			},
 			newParallelExecutor(rolesetExecutors...),
 		)
 
-		return executor()
+		return executor(ctx)
 	}
 }
 
 func (workflow *pipelineWorkflow) pipelineUpserter(namespace string, stackUpserter common.StackUpserter, stackWaiter common.StackWaiter, params map[string]string) Executor {
-	return func() error {
+	return func(ctx context.Context) error {
 		pipelineStackName := common.CreateStackName(namespace, common.StackTypePipeline, workflow.serviceName)
 
 		log.Noticef("Upserting Pipeline for service '%s' ...", workflow.serviceName)
@@ -214,6 +219,8 @@ func (workflow *pipelineWorkflow) pipelineUpserter(namespace string, stackUpsert
 		if err != nil {
 			return err
 		}
+		common.NewMapElementIfNotEmpty(params, "ServicePath", workflow.servicePath)
+		common.NewMapElementIfNotEmpty(params, "ServiceSelector", workflow.serviceSelector)
 
 		tags := createTagMap(&PipelineTags{
 			Type:     common.StackTypePipeline,
@@ -222,13 +229,13 @@ func (workflow *pipelineWorkflow) pipelineUpserter(namespace string, stackUpsert
 			Repo:     workflow.repoName,
 		})
 
-		err = stackUpserter.UpsertStack(pipelineStackName, common.TemplatePipeline, nil, params, tags, "", "")
+		err = stackUpserter.UpsertStack(ctx, pipelineStackName, common.TemplatePipeline, nil, params, tags, "", "")
 		if err != nil {
 			return err
 		}
 
 		log.Debugf("Waiting for stack '%s' to complete", pipelineStackName)
-		stack := stackWaiter.AwaitFinalStatus(pipelineStackName)
+		stack := stackWaiter.AwaitFinalStatus(ctx, pipelineStackName)
 		if stack == nil {
 			return fmt.Errorf("Unable to create stack %s", pipelineStackName)
 		}
@@ -243,7 +250,7 @@ func (workflow *pipelineWorkflow) pipelineUpserter(namespace string, stackUpsert
 }
 
 func (workflow *pipelineWorkflow) pipelineCatalogUpserter(namespace string, pipeline *common.Pipeline, params map[string]string, catalogProvisioner common.CatalogProvisioner, stackGetter common.StackGetter) Executor {
-	return func() error {
+	return func(ctx context.Context) error {
 		stackName := common.CreateStackName(namespace, common.StackTypeProduct, pipeline.Catalog.Name)
 		stack, err := stackGetter.GetStack(stackName)
 		if err != nil {
@@ -270,7 +277,7 @@ func (workflow *pipelineWorkflow) pipelineCatalogUpserter(namespace string, pipe
 			productParams["SourceObjectKey"] = strings.Join(repoParts[1:], "/")
 		}
 
-		return catalogProvisioner.UpsertProvisionedProduct(stack.Outputs["ProductId"], pipeline.Catalog.Version, fmt.Sprintf("%s-%s", namespace, workflow.serviceName), productParams)
+		return catalogProvisioner.UpsertProvisionedProduct(ctx, stack.Outputs["ProductId"], pipeline.Catalog.Version, fmt.Sprintf("%s-%s", namespace, workflow.serviceName), productParams)
 	}
 }
 
@@ -324,7 +331,7 @@ func PipelineParams(pipelineConfig *common.Pipeline, namespace string, serviceNa
 }
 
 func (workflow *pipelineWorkflow) pipelineNotifyUpserter(namespace string, pipeline *common.Pipeline, subManager common.SubscriptionManager) Executor {
-	return func() error {
+	return func(ctx context.Context) error {
 		if len(workflow.notificationArn) > 0 && len(pipeline.Notify) > 0 {
 			log.Noticef("Updating pipeline notifications for service '%s' ...", workflow.serviceName)
 			for _, notify := range pipeline.Notify {
@@ -1,6 +1,7 @@
 package workflows
 
 import (
+	"context"
 	"testing"
 
 	"github.com/stelligent/mu/common"
@@ -36,7 +37,7 @@ func TestServiceBucketUpsert_CreateStack(t *testing.T) {
 
 	stackParams := make(map[string]string)
 
-	err := workflow.pipelineBucket("mu", stackParams, stackManager, stackManager)()
+	err := workflow.pipelineBucket("mu", stackParams, stackManager, stackManager)(context.Background())
 	assert.Nil(err)
 
 	stackManager.AssertExpectations(t)
@@ -58,7 +59,7 @@ func TestPipelineBucket_Configured(t *testing.T) {
 	stackManager := new(mockedStackManagerForUpsert)
 
 	stackParams := make(map[string]string)
-	err := workflow.pipelineBucket("mu", stackParams, stackManager, stackManager)()
+	err := workflow.pipelineBucket("mu", stackParams, stackManager, stackManager)(context.Background())
 	assert.Nil(err)
 
 	stackManager.AssertExpectations(t)
@@ -87,9 +88,9 @@ func TestPipelineUpserter(t *testing.T) {
 	}
 
 	params := make(map[string]string)
-	err := workflow.pipelineToken("mu", tokenProvider, stackManager, params)()
+	err := workflow.pipelineToken("mu", tokenProvider, stackManager, params)(context.Background())
 	assert.Nil(err)
-	err = workflow.pipelineUpserter("mu", stackManager, stackManager, params)()
+	err = workflow.pipelineUpserter("mu", stackManager, stackManager, params)(context.Background())
 	assert.Nil(err)
 
 	stackManager.AssertExpectations(t)
@@ -101,6 +102,8 @@ func TestPipelineUpserter(t *testing.T) {
 	assert.Equal("foo/bar", stackParams["SourceRepo"])
 	assert.Equal("", stackParams["Branch"])
 	assert.Equal("my-token", stackParams["GitHubToken"])
+	_, hasSelector := stackParams["ServiceSelector"]
+	assert.False(hasSelector)
 }
 
 func TestPipelineParams(t *testing.T) {
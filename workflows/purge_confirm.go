@@ -1,6 +1,7 @@
 package workflows
 
 import (
+	"context"
 	"fmt"
 
 	"github.com/stelligent/mu/common"
@@ -18,7 +19,9 @@ func NewPurge(ctx *common.Context) Executor {
 	iamCommonStackName := fmt.Sprintf("%s-iam-common", ctx.Config.Namespace)
 
 	return newPipelineExecutor(
-		ctx.RolesetManager.UpsertCommonRoleset,
+		func(runCtx context.Context) error {
+			return ctx.RolesetManager.UpsertCommonRoleset(runCtx)
+		},
 		workflow.newStackStream(common.StackTypeProduct).foreach(workflow.terminateProduct, workflow.deleteStack),
 		workflow.newStackStream(common.StackTypePortfolio).foreach(workflow.deleteStack),
 		workflow.newStackStream(common.StackTypePipeline).foreach(workflow.terminatePipeline),
@@ -50,9 +53,9 @@ func (workflow *purgeWorkflow) terminateEnvironment(stack *common.Stack) Executo
 	return NewEnvironmentsTerminator(workflow.context, []string{stack.Tags["environment"]})
 }
 func (workflow *purgeWorkflow) terminateCommonRoleset() Executor {
-	return func() error {
+	return func(ctx context.Context) error {
 		workflow.context.StackManager.AllowDataLoss(true)
-		return workflow.context.RolesetManager.DeleteCommonRoleset()
+		return workflow.context.RolesetManager.DeleteCommonRoleset(ctx)
 	}
 }
 func excludeStackName(stackName string) stackFilter {
@@ -63,12 +66,12 @@ func excludeStackName(stackName string) stackFilter {
 
 func (workflow *purgeWorkflow) deleteStack(stack *common.Stack) Executor {
 	stackName := stack.Name
-	return func() error {
+	return func(ctx context.Context) error {
 		err := workflow.context.StackManager.DeleteStack(stackName)
 		if err != nil {
 			return err
 		}
-		status := workflow.context.StackManager.AwaitFinalStatus(stackName)
+		status := workflow.context.StackManager.AwaitFinalStatus(ctx, stackName)
 		if status != nil && !workflow.context.Config.DryRun {
 			return fmt.Errorf("Unable to delete stack '%s'", stackName)
 		}
@@ -78,14 +81,14 @@ func (workflow *purgeWorkflow) deleteStack(stack *common.Stack) Executor {
 
 func (workflow *purgeWorkflow) cleanupBucket(stack *common.Stack) Executor {
 	bucketName := stack.Outputs["Bucket"]
-	return func() error {
+	return func(ctx context.Context) error {
 		return workflow.context.ArtifactManager.EmptyBucket(bucketName)
 	}
 }
 
 func (workflow *purgeWorkflow) cleanupRepo(stack *common.Stack) Executor {
 	repoName := stack.Parameters["RepoName"]
-	return func() error {
+	return func(ctx context.Context) error {
 		return workflow.context.ClusterManager.DeleteRepository(repoName)
 	}
 }
@@ -138,7 +141,7 @@ func applyStackExecutors(stack *common.Stack, stackExecutors ...stackExecutor) E
 
 // Create an executor that can iterate over all stacks and run executors against the stacks
 func (stream *stackStream) foreach(stackExecutors ...stackExecutor) Executor {
-	return func() error {
+	return func(ctx context.Context) error {
 		log.Noticef("Purging '%s' stacks in namespace '%s'", stream.stackType, stream.namespace)
 		stacks, err := stream.stackLister.ListStacks(stream.stackType, stream.namespace)
 		if err != nil {
@@ -155,6 +158,6 @@ func (stream *stackStream) foreach(stackExecutors ...stackExecutor) Executor {
 			executors = append(executors, applyStackExecutors(stack, stackExecutors...))
 		}
 		executor := newParallelExecutor(executors...)
-		return executor()
+		return executor(ctx)
 	}
 }
@@ -1,6 +1,7 @@
 package workflows
 
 import (
+	"context"
 	"encoding/base64"
 	"fmt"
 	"os"
@@ -17,6 +18,10 @@ type serviceWorkflow struct {
 	serviceName                   string
 	serviceTag                    string
 	serviceImage                  string
+	serviceRepoName               string
+	serviceRepoURL                string
+	serviceSourceTag              string
+	imageRetagged                 bool
 	registryAuth                  string
 	priority                      int
 	codeRevision                  string
@@ -27,14 +32,17 @@ type serviceWorkflow struct {
 	databaseName                  string
 	cloudFormationRoleArn         string
 	microserviceTaskDefinitionArn string
+	previousTaskDefinitionArn     string
+	codeDeployApplication         string
+	codeDeployDeploymentGroup     string
 	ecsEventsRoleArn              string
 	kubernetesResourceManager     common.KubernetesResourceManager
 }
 
 // Find a service in config, by name and set the reference
 func (workflow *serviceWorkflow) serviceLoader(ctx *common.Context, tag string, provider string) Executor {
-	return func() error {
-		err := workflow.serviceInput(ctx, "")()
+	return func(runCtx context.Context) error {
+		err := workflow.serviceInput(ctx, "")(runCtx)
 		if err != nil {
 			return err
 		}
@@ -116,7 +124,7 @@ func (workflow *serviceWorkflow) isEc2Provider() Conditional {
 }
 
 func (workflow *serviceWorkflow) serviceInput(ctx *common.Context, serviceName string) Executor {
-	return func() error {
+	return func(context.Context) error {
 		// Repo Name
 		if serviceName != "" {
 			workflow.serviceName = serviceName
@@ -132,7 +140,7 @@ func (workflow *serviceWorkflow) serviceInput(ctx *common.Context, serviceName s
 }
 
 func (workflow *serviceWorkflow) serviceRepoUpserter(namespace string, service *common.Service, stackUpserter common.StackUpserter, stackWaiter common.StackWaiter) Executor {
-	return func() error {
+	return func(ctx context.Context) error {
 		if service.ImageRepository != "" {
 			log.Noticef("Using repo '%s' for service '%s'", service.ImageRepository, workflow.serviceName)
 			workflow.serviceImage = service.ImageRepository
@@ -154,25 +162,27 @@ func (workflow *serviceWorkflow) serviceRepoUpserter(namespace string, service *
 			Repo:     workflow.repoName,
 		})
 
-		err := stackUpserter.UpsertStack(ecrStackName, common.TemplateRepo, nil, stackParams, tags, "", "")
+		err := stackUpserter.UpsertStack(ctx, ecrStackName, common.TemplateRepo, nil, stackParams, tags, "", "")
 		if err != nil {
 			return err
 		}
 
 		log.Debugf("Waiting for stack '%s' to complete", ecrStackName)
-		stack := stackWaiter.AwaitFinalStatus(ecrStackName)
+		stack := stackWaiter.AwaitFinalStatus(ctx, ecrStackName)
 		if stack == nil {
 			return fmt.Errorf("Unable to create stack %s", ecrStackName)
 		}
 		if strings.HasSuffix(stack.Status, "ROLLBACK_COMPLETE") || !strings.HasSuffix(stack.Status, "_COMPLETE") {
 			return fmt.Errorf("Ended in failed status %s %s", stack.Status, stack.StatusReason)
 		}
+		workflow.serviceRepoName = stackParams["RepoName"]
+		workflow.serviceRepoURL = stack.Outputs["RepoUrl"]
 		workflow.serviceImage = fmt.Sprintf("%s:%s", stack.Outputs["RepoUrl"], workflow.serviceTag)
 		return nil
 	}
 }
 func (workflow *serviceWorkflow) serviceAppUpserter(namespace string, service *common.Service, stackUpserter common.StackUpserter, stackWaiter common.StackWaiter) Executor {
-	return func() error {
+	return func(ctx context.Context) error {
 		log.Noticef("Upsert app for service '%s'", workflow.serviceName)
 
 		appStackName := common.CreateStackName(namespace, common.StackTypeApp, workflow.serviceName)
@@ -187,13 +197,13 @@ func (workflow *serviceWorkflow) serviceAppUpserter(namespace string, service *c
 			Repo:        workflow.repoName,
 		})
 
-		err := stackUpserter.UpsertStack(appStackName, common.TemplateApp, nil, stackParams, tags, "", workflow.cloudFormationRoleArn)
+		err := stackUpserter.UpsertStack(ctx, appStackName, common.TemplateApp, nil, stackParams, tags, "", workflow.cloudFormationRoleArn)
 		if err != nil {
 			return err
 		}
 
 		log.Debugf("Waiting for stack '%s' to complete", appStackName)
-		stack := stackWaiter.AwaitFinalStatus(appStackName)
+		stack := stackWaiter.AwaitFinalStatus(ctx, appStackName)
 		if stack == nil {
 			return fmt.Errorf("Unable to create stack %s", appStackName)
 		}
@@ -206,7 +216,7 @@ func (workflow *serviceWorkflow) serviceAppUpserter(namespace string, service *c
 }
 
 func (workflow *serviceWorkflow) serviceBucketUpserter(namespace string, service *common.Service, stackUpserter common.StackUpserter, stackWaiter common.StackWaiter) Executor {
-	return func() error {
+	return func(ctx context.Context) error {
 
 		if service.Pipeline.Build.Bucket != "" {
 			workflow.appRevisionBucket = service.Pipeline.Build.Bucket
@@ -221,13 +231,13 @@ func (workflow *serviceWorkflow) serviceBucketUpserter(namespace string, service
 				Type: common.StackTypeBucket,
 			})
 
-			err := stackUpserter.UpsertStack(bucketStackName, common.TemplateBucket, nil, bucketParams, tags, "", workflow.cloudFormationRoleArn)
+			err := stackUpserter.UpsertStack(ctx, bucketStackName, common.TemplateBucket, nil, bucketParams, tags, "", workflow.cloudFormationRoleArn)
 			if err != nil {
 				return err
 			}
 
 			log.Debugf("Waiting for stack '%s' to complete", bucketStackName)
-			stack := stackWaiter.AwaitFinalStatus(bucketStackName)
+			stack := stackWaiter.AwaitFinalStatus(ctx, bucketStackName)
 			if stack == nil {
 				return fmt.Errorf("Unable to create stack %s", bucketStackName)
 			}
@@ -244,7 +254,7 @@ func (workflow *serviceWorkflow) serviceBucketUpserter(namespace string, service
 }
 
 func (workflow *serviceWorkflow) serviceRegistryAuthenticator(authenticator common.RepositoryAuthenticator) Executor {
-	return func() error {
+	return func(ctx context.Context) error {
 		log.Debugf("Authenticating to registry '%s'", workflow.serviceImage)
 		registryAuth, err := authenticator.AuthenticateRepository(workflow.serviceImage)
 		if err != nil {
@@ -264,7 +274,7 @@ func (workflow *serviceWorkflow) serviceRegistryAuthenticator(authenticator comm
 }
 
 func (workflow *serviceWorkflow) connectKubernetes(provider common.KubernetesResourceManagerProvider) Executor {
-	return func() error {
+	return func(ctx context.Context) error {
 		clusterName := workflow.envStack.Name
 		kubernetesResourceManager, err := provider.GetResourceManager(clusterName)
 		workflow.kubernetesResourceManager = kubernetesResourceManager
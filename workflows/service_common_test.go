@@ -1,6 +1,7 @@
 package workflows
 
 import (
+	"context"
 	"encoding/base64"
 	"testing"
 
@@ -20,7 +21,7 @@ func TestServiceLoader_FromConfig(t *testing.T) {
 	ctx.Config.Service.Name = "myservice"
 
 	workflow := new(serviceWorkflow)
-	err := workflow.serviceLoader(ctx, "2.0.0", "ecr")()
+	err := workflow.serviceLoader(ctx, "2.0.0", "ecr")(context.Background())
 	assert.Nil(err)
 	assert.Equal("myservice", workflow.serviceName)
 	assert.Equal("2.0.0", workflow.serviceTag)
@@ -37,7 +38,7 @@ func TestServiceLoader_FromRepo(t *testing.T) {
 	ctx.Config.Repo.Revision = "1.0.0"
 
 	workflow := new(serviceWorkflow)
-	err := workflow.serviceLoader(ctx, "", "ecr")()
+	err := workflow.serviceLoader(ctx, "", "ecr")(context.Background())
 	assert.Nil(err)
 	assert.Equal("myrepo", workflow.serviceName)
 	assert.Equal("1.0.0", workflow.serviceTag)
@@ -60,7 +61,7 @@ func TestServiceRegistryAuthenticator(t *testing.T) {
 	authn.On("AuthenticateRepository").Return(base64.StdEncoding.EncodeToString([]byte("user:pass")), nil)
 
 	workflow := new(serviceWorkflow)
-	err := workflow.serviceRegistryAuthenticator(authn)()
+	err := workflow.serviceRegistryAuthenticator(authn)(context.Background())
 
 	assert.Nil(err)
 	assert.NotNil(workflow.registryAuth)
@@ -78,7 +79,7 @@ type mockedRolesetManagerForService struct {
 	common.RolesetManager
 }
 
-func (m *mockedRolesetManagerForService) GetCommonRoleset() (common.Roleset, error) {
+func (m *mockedRolesetManagerForService) GetCommonRoleset(ctx context.Context) (common.Roleset, error) {
 	args := m.Called()
 	roleset := args.Get(0)
 	if roleset == nil {
@@ -86,11 +87,11 @@ func (m *mockedRolesetManagerForService) GetCommonRoleset() (common.Roleset, err
 	}
 	return roleset.(common.Roleset), args.Error(1)
 }
-func (m *mockedRolesetManagerForService) UpsertCommonRoleset() error {
+func (m *mockedRolesetManagerForService) UpsertCommonRoleset(ctx context.Context) error {
 	args := m.Called()
 	return args.Error(0)
 }
-func (m *mockedRolesetManagerForService) GetServiceRoleset(env string, svc string) (common.Roleset, error) {
+func (m *mockedRolesetManagerForService) GetServiceRoleset(ctx context.Context, env string, svc string) (common.Roleset, error) {
 	args := m.Called()
 	roleset := args.Get(0)
 	if roleset == nil {
@@ -98,7 +99,7 @@ func (m *mockedRolesetManagerForService) GetServiceRoleset(env string, svc strin
 	}
 	return roleset.(common.Roleset), args.Error(1)
 }
-func (m *mockedRolesetManagerForService) UpsertServiceRoleset(env string, svc string, codedeployBucket string, databaseName string) error {
+func (m *mockedRolesetManagerForService) UpsertServiceRoleset(ctx context.Context, env string, svc string, codedeployBucket string, databaseName string) error {
 	args := m.Called(env, svc, codedeployBucket)
 	return args.Error(0)
 }
@@ -107,7 +108,7 @@ type mockedStackManagerForService struct {
 	mock.Mock
 }
 
-func (m *mockedStackManagerForService) AwaitFinalStatus(stackName string) *common.Stack {
+func (m *mockedStackManagerForService) AwaitFinalStatus(ctx context.Context, stackName string) *common.Stack {
 	args := m.Called(stackName)
 	stack := args.Get(0)
 	if stack == nil {
@@ -115,7 +116,7 @@ func (m *mockedStackManagerForService) AwaitFinalStatus(stackName string) *commo
 	}
 	return stack.(*common.Stack)
 }
-func (m *mockedStackManagerForService) UpsertStack(stackName string, templateName string, templateData interface{}, stackParameters map[string]string, stackTags map[string]string, policy string, roleArn string) error {
+func (m *mockedStackManagerForService) UpsertStack(ctx context.Context, stackName string, templateName string, templateData interface{}, stackParameters map[string]string, stackTags map[string]string, policy string, roleArn string) error {
 	args := m.Called(stackName)
 	return args.Error(0)
 }
@@ -123,7 +124,7 @@ func (m *mockedStackManagerForService) DeleteStack(stackName string) error {
 	args := m.Called(stackName)
 	return args.Error(0)
 }
-func (m *mockedStackManagerForService) SetTerminationProtection(stackName string, enabled bool) error {
+func (m *mockedStackManagerForService) SetTerminationProtection(ctx context.Context, stackName string, enabled bool) error {
 	args := m.Called(stackName)
 	return args.Error(0)
 }
@@ -140,7 +141,7 @@ func TestServiceRepoUpserter(t *testing.T) {
 	stackManager.On("AwaitFinalStatus", "mu-repo-foo").Return(&common.Stack{Status: common.StackStatusCreateComplete})
 	stackManager.On("UpsertStack", "mu-repo-foo", mock.AnythingOfType("map[string]string")).Return(nil)
 
-	err := workflow.serviceRepoUpserter("mu", svc, stackManager, stackManager)()
+	err := workflow.serviceRepoUpserter("mu", svc, stackManager, stackManager)(context.Background())
 	assert.Nil(err)
 
 	stackManager.AssertExpectations(t)
@@ -167,7 +168,7 @@ func TestCodeDeploy_BucketUpserter(t *testing.T) {
 
 	svc := new(common.Service)
 
-	err := workflow.serviceBucketUpserter("mu", svc, stackManager, stackManager)()
+	err := workflow.serviceBucketUpserter("mu", svc, stackManager, stackManager)(context.Background())
 	assert.Nil(err)
 
 	stackManager.AssertExpectations(t)
@@ -0,0 +1,115 @@
+package workflows
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/stelligent/mu/common"
+)
+
+// serviceDependencyResolver checks that the services and databases the service depends on are deployed to the environment,
+// and adds their discovery names and endpoints to the environment variables of the service
+func (workflow *serviceWorkflow) serviceDependencyResolver(namespace string, service *common.Service, environmentName string, stackWaiter common.StackWaiter, taskLister common.TaskContainerLister) Executor {
+	return func(ctx context.Context) error {
+		for _, dependency := range service.DependsOn {
+			prefix := dependency.EnvironmentPrefix()
+
+			if dependency.Database != "" {
+				dbStackName := common.CreateStackName(namespace, common.StackTypeDatabase, dependency.Database, environmentName)
+				dbStack := stackWaiter.AwaitFinalStatus(ctx, dbStackName)
+				if err := checkDependencyStack(dbStack, workflow.serviceName, fmt.Sprintf("the database of service '%s'", dependency.Database), environmentName); err != nil {
+					return err
+				}
+				setDependencyEnvironment(service, prefix+"_DATABASE_ENDPOINT_ADDRESS", dbStack.Outputs["DatabaseEndpointAddress"])
+				setDependencyEnvironment(service, prefix+"_DATABASE_ENDPOINT_PORT", dbStack.Outputs["DatabaseEndpointPort"])
+				continue
+			}
+
+			// the deployments of EKS services are checked by serviceEksDependencyChecker once connected to the cluster
+			if workflow.isEksProvider()() {
+				setDependencyEnvironment(service, prefix+"_SERVICE_DISCOVERY_NAME", fmt.Sprintf("%s.mu-service-%s.svc.cluster.local", dependency.Service, dependency.Service))
+				continue
+			}
+
+			svcStackName := common.CreateStackName(namespace, common.StackTypeService, dependency.Service, environmentName)
+			svcStack := stackWaiter.AwaitFinalStatus(ctx, svcStackName)
+			if err := checkDependencyStack(svcStack, workflow.serviceName, fmt.Sprintf("service '%s'", dependency.Service), environmentName); err != nil {
+				return err
+			}
+			if !workflow.isEcsProvider()() {
+				continue
+			}
+
+			// a stack that completed can still have no tasks that pass their health checks
+			tasks, err := taskLister.ListTasks(namespace, environmentName, dependency.Service)
+			if err != nil {
+				return err
+			}
+			running := 0
+			for _, task := range tasks {
+				if task.Status == "RUNNING" {
+					running++
+				}
+			}
+			if running == 0 {
+				return fmt.Errorf("Service '%s' depends on service '%s', which has no running tasks in environment '%s'", workflow.serviceName, dependency.Service, environmentName)
+			}
+
+			// only the services that are registered in service discovery can be resolved by name
+			if svcStack.Outputs["ServiceDiscoveryArn"] != "" && workflow.lbStack != nil && workflow.lbStack.Outputs["ServiceDiscoveryName"] != "" {
+				setDependencyEnvironment(service, prefix+"_SERVICE_DISCOVERY_NAME", fmt.Sprintf("%s.%s", dependency.Service, workflow.lbStack.Outputs["ServiceDiscoveryName"]))
+			}
+		}
+		return nil
+	}
+}
+
+// serviceEksDependencyChecker checks that the deployments of the services the service depends on have available pods
+func (workflow *serviceWorkflow) serviceEksDependencyChecker(service *common.Service, environmentName string) Executor {
+	return func(ctx context.Context) error {
+		for _, dependency := range service.DependsOn {
+			if dependency.Service == "" {
+				continue
+			}
+
+			deployments, err := workflow.kubernetesResourceManager.ListResources("apps/v1", "Deployment", fmt.Sprintf("mu-service-%s", dependency.Service))
+			if err != nil {
+				return err
+			}
+
+			var availableReplicas int64
+			for _, deployment := range deployments.Items {
+				if common.MapGetString(deployment.Object, "metadata", "name") == fmt.Sprintf("%s-deployment", dependency.Service) {
+					availableReplicas, _ = common.MapGet(deployment.Object, "status", "availableReplicas").(int64)
+				}
+			}
+			if availableReplicas == 0 {
+				return fmt.Errorf("Service '%s' depends on service '%s', which has no available pods in environment '%s'", workflow.serviceName, dependency.Service, environmentName)
+			}
+		}
+		return nil
+	}
+}
+
+// checkDependencyStack checks that the stack of a dependency exists and that its last create or update didn't fail
+func checkDependencyStack(stack *common.Stack, serviceName string, dependencyName string, environmentName string) error {
+	if stack == nil || stack.Status == common.StackStatusDeleteComplete {
+		return fmt.Errorf("Service '%s' depends on %s, which is not deployed to environment '%s'", serviceName, dependencyName, environmentName)
+	}
+	// an update that rolled back leaves the previous revision running, a create that rolled back leaves nothing
+	if stack.Status == common.StackStatusRollbackComplete || !strings.HasSuffix(stack.Status, "_COMPLETE") {
+		return fmt.Errorf("Service '%s' depends on %s, which is not healthy in environment '%s': %s %s", serviceName, dependencyName, environmentName, stack.Status, stack.StatusReason)
+	}
+	return nil
+}
+
+// setDependencyEnvironment adds the environment variable to the service, unless it is already defined in mu.yml
+func setDependencyEnvironment(service *common.Service, name string, value string) {
+	if service.Environment == nil {
+		service.Environment = make(map[string]interface{})
+	}
+	if _, ok := service.Environment[name]; !ok {
+		service.Environment[name] = value
+	}
+}
@@ -1,11 +1,14 @@
 package workflows
 
 import (
+	"context"
 	"encoding/base64"
 	"encoding/json"
 	"fmt"
+	"sort"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/stelligent/mu/common"
 )
@@ -19,16 +22,23 @@ func NewServiceDeployer(ctx *common.Context, environmentName string, tag string)
 
 	stackParams := make(map[string]string)
 
-	return newPipelineExecutor(
+	return newServiceEnvironmentConfigExecutor(&ctx.Config, environmentName, newPipelineExecutor(
 		workflow.serviceLoader(ctx, tag, ""),
 		workflow.serviceEnvironmentLoader(ctx.Config.Namespace, environmentName, ctx.StackManager),
+		workflow.serviceSidecarsValidator(&ctx.Config.Service),
+		workflow.serviceVolumesValidator(&ctx.Config.Service, environmentName),
+		workflow.serviceCapacityProvidersValidator(&ctx.Config.Service, environmentName),
+		workflow.serviceDependencyResolver(ctx.Config.Namespace, &ctx.Config.Service, environmentName, ctx.StackManager, ctx.TaskManager),
+		newPlanSkippingExecutor(&ctx.Config, "before deploy hooks", workflow.serviceBeforeDeployHooks(ctx.ExtensionsManager, environmentName)),
 		workflow.serviceApplyCommonParams(ctx.Config.Namespace, &ctx.Config.Service, stackParams, environmentName, ctx.StackManager, ctx.ElbManager, ctx.ParamManager),
 		newConditionalExecutor(workflow.isEcsProvider(),
 			newPipelineExecutor(
 				workflow.serviceRolesetUpserter(ctx.RolesetManager, ctx.RolesetManager, environmentName),
 				workflow.serviceRepoUpserter(ctx.Config.Namespace, &ctx.Config.Service, ctx.StackManager, ctx.StackManager),
 				workflow.serviceApplyEcsParams(&ctx.Config.Service, stackParams, ctx.RolesetManager),
+				workflow.serviceApplyEcsTrafficShiftingParams(ctx.Config.Namespace, &ctx.Config.Service, stackParams, environmentName, ctx.StackManager),
 				workflow.serviceEcsDeployer(ctx.Config.Namespace, &ctx.Config.Service, stackParams, environmentName, ctx.StackManager, ctx.StackManager),
+				newPlanSkippingExecutor(&ctx.Config, "traffic shifting", workflow.serviceEcsTrafficShifter(&ctx.Config.Service, environmentName, ctx.DeploymentManager)),
 				workflow.serviceCreateSchedules(ctx.Config.Namespace, &ctx.Config.Service, environmentName, ctx.StackManager, ctx.StackManager),
 			), nil),
 		newConditionalExecutor(workflow.isEc2Provider(),
@@ -44,12 +54,48 @@ func NewServiceDeployer(ctx *common.Context, environmentName string, tag string)
 			newPipelineExecutor(
 				workflow.serviceRolesetUpserter(ctx.RolesetManager, ctx.RolesetManager, environmentName),
 				workflow.serviceRepoUpserter(ctx.Config.Namespace, &ctx.Config.Service, ctx.StackManager, ctx.StackManager),
-				workflow.connectKubernetes(ctx.KubernetesResourceManagerProvider),
-				workflow.serviceEksDBSecret(ctx.Config.Namespace, &ctx.Config.Service, stackParams, environmentName),
-				workflow.serviceEksDeployer(ctx.Config.Namespace, &ctx.Config.Service, stackParams, environmentName),
+				newPlanSkippingExecutor(&ctx.Config, "kubernetes resources",
+					newPipelineExecutor(
+						workflow.connectKubernetes(ctx.KubernetesResourceManagerProvider),
+						workflow.serviceEksDependencyChecker(&ctx.Config.Service, environmentName),
+						workflow.serviceEksDBSecret(ctx.Config.Namespace, &ctx.Config.Service, stackParams, environmentName),
+						workflow.serviceEksSecrets(&ctx.Config.Service, environmentName, ctx.ParamManager, ctx.ParamManager),
+						workflow.serviceEksDeployer(ctx.Config.Namespace, &ctx.Config.Service, stackParams, environmentName),
+					)),
 				// TODO - placeholder for doing serviceCreateSchedules for EKS, leaving out-of-scope
 			), nil),
-	)
+		newPlanSkippingExecutor(&ctx.Config, "after deploy hooks", workflow.serviceAfterDeployHooks(ctx.ExtensionsManager, environmentName)),
+	))
+}
+
+// newServiceEnvironmentConfigExecutor runs the executor with the environmentConfig of the service applied to the config
+func newServiceEnvironmentConfigExecutor(config *common.Config, environmentName string, executor Executor) Executor {
+	return func(ctx context.Context) error {
+		originalService := config.Service
+		defer func() {
+			config.Service = originalService
+		}()
+
+		service, err := originalService.GetServiceConfig(environmentName)
+		if err != nil {
+			log.Errorf("%v", err)
+			return loggedError{err}
+		}
+		config.Service = *service
+		return executor(ctx)
+	}
+}
+
+func (workflow *serviceWorkflow) serviceBeforeDeployHooks(hookRunner common.DeployHookRunner, environmentName string) Executor {
+	return func(ctx context.Context) error {
+		return hookRunner.BeforeDeploy(environmentName, workflow.serviceName)
+	}
+}
+
+func (workflow *serviceWorkflow) serviceAfterDeployHooks(hookRunner common.DeployHookRunner, environmentName string) Executor {
+	return func(ctx context.Context) error {
+		return hookRunner.AfterDeploy(environmentName, workflow.serviceName)
+	}
 }
 
 func getMaxPriority(elbRuleLister common.ElbRuleLister, listenerArn string) int {
@@ -89,12 +135,12 @@ func checkPriorityNotInUse(elbRuleLister common.ElbRuleLister, listenerArn strin
 }
 
 func (workflow *serviceWorkflow) serviceEnvironmentLoader(namespace string, environmentName string, stackWaiter common.StackWaiter) Executor {
-	return func() error {
+	return func(ctx context.Context) error {
 		lbStackName := common.CreateStackName(namespace, common.StackTypeLoadBalancer, environmentName)
-		workflow.lbStack = stackWaiter.AwaitFinalStatus(lbStackName)
+		workflow.lbStack = stackWaiter.AwaitFinalStatus(ctx, lbStackName)
 
 		envStackName := common.CreateStackName(namespace, common.StackTypeEnv, environmentName)
-		workflow.envStack = stackWaiter.AwaitFinalStatus(envStackName)
+		workflow.envStack = stackWaiter.AwaitFinalStatus(ctx, envStackName)
 
 		if workflow.envStack == nil {
 			return fmt.Errorf("Unable to find stack '%s' for environment '%s'", envStackName, environmentName)
@@ -111,25 +157,25 @@ func (workflow *serviceWorkflow) serviceEnvironmentLoader(namespace string, envi
 }
 
 func (workflow *serviceWorkflow) serviceRolesetUpserter(rolesetUpserter common.RolesetUpserter, rolesetGetter common.RolesetGetter, environmentName string) Executor {
-	return func() error {
-		err := rolesetUpserter.UpsertCommonRoleset()
+	return func(ctx context.Context) error {
+		err := rolesetUpserter.UpsertCommonRoleset(ctx)
 		if err != nil {
 			return err
 		}
 
-		commonRoleset, err := rolesetGetter.GetCommonRoleset()
+		commonRoleset, err := rolesetGetter.GetCommonRoleset(ctx)
 		if err != nil {
 			return err
 		}
 
 		workflow.cloudFormationRoleArn = commonRoleset["CloudFormationRoleArn"]
 
-		err = rolesetUpserter.UpsertServiceRoleset(environmentName, workflow.serviceName, workflow.appRevisionBucket, workflow.databaseName)
+		err = rolesetUpserter.UpsertServiceRoleset(ctx, environmentName, workflow.serviceName, workflow.appRevisionBucket, workflow.databaseName)
 		if err != nil {
 			return err
 		}
 
-		serviceRoleset, err := rolesetGetter.GetServiceRoleset(environmentName, workflow.serviceName)
+		serviceRoleset, err := rolesetGetter.GetServiceRoleset(ctx, environmentName, workflow.serviceName)
 		if err != nil {
 			return err
 		}
@@ -157,6 +203,83 @@ func matchRequestedMemory(serviceMemory int, cpu common.CPUMemory, defaultMemory
 	return defaultMemory
 }
 
+// serviceSidecarsValidator checks that the sidecars have unique names and only depend on containers listed before them,
+// which is also the order they are started in on EC2
+func (workflow *serviceWorkflow) serviceSidecarsValidator(service *common.Service) Executor {
+	return func(ctx context.Context) error {
+		containerNames := map[string]bool{workflow.serviceName: true}
+		for _, sidecar := range service.Sidecars {
+			if sidecar.Name == "" || sidecar.Image == "" {
+				return fmt.Errorf("Sidecars of service '%s' require a name and an image", workflow.serviceName)
+			}
+			if containerNames[sidecar.Name] {
+				return fmt.Errorf("Sidecar '%s' of service '%s' must have a unique name", sidecar.Name, workflow.serviceName)
+			}
+			for _, dependency := range sidecar.DependsOn {
+				if !containerNames[dependency] {
+					return fmt.Errorf("Sidecar '%s' of service '%s' depends on '%s', which must be the service or a sidecar listed before it", sidecar.Name, workflow.serviceName, dependency)
+				}
+			}
+			containerNames[sidecar.Name] = true
+		}
+		return nil
+	}
+}
+
+// serviceVolumesValidator checks that the volumes of the service are supported by the provider of the environment,
+// EFS volumes on ECS also need the filesystem that is only created by the environment once a service declares volumes
+func (workflow *serviceWorkflow) serviceVolumesValidator(service *common.Service, environmentName string) Executor {
+	return func(ctx context.Context) error {
+		if len(service.Volumes) == 0 {
+			return nil
+		}
+		if workflow.isEc2Provider()() {
+			return fmt.Errorf("Volumes of service '%s' are not supported in EC2 environments", workflow.serviceName)
+		}
+		for _, volume := range service.Volumes {
+			if workflow.isEksProvider()() {
+				if volume.IsEFS() && volume.StorageClass == "" {
+					return fmt.Errorf("EFS volume '%s' of service '%s' requires the storageClass of an EFS filesystem provisioned in the cluster", volume.Name, workflow.serviceName)
+				}
+				if !volume.IsEFS() && (service.DesiredCount > 1 || service.DeploymentStrategy != common.ReplaceDeploymentStrategy) {
+					log.Warningf("EBS volume '%s' of service '%s' can only be attached to one pod, use desiredCount 1 and deploymentStrategy '%s'", volume.Name, workflow.serviceName, common.ReplaceDeploymentStrategy)
+				}
+				continue
+			}
+			if !volume.IsEFS() {
+				return fmt.Errorf("EBS volume '%s' of service '%s' is only supported in EKS environments", volume.Name, workflow.serviceName)
+			}
+		}
+		if workflow.isEcsProvider()() && workflow.envStack.Outputs["EfsFileSystemId"] == "" {
+			return fmt.Errorf("Environment '%s' has no EFS filesystem for the volumes of service '%s', run 'mu env up %s' to create it", environmentName, workflow.serviceName, environmentName)
+		}
+		return nil
+	}
+}
+
+// serviceCapacityProvidersValidator checks that the capacity providers of the service exist in the environment, Fargate and
+// Fargate Spot in ECS Fargate environments and EC2 in ECS environments with a capacity provider
+func (workflow *serviceWorkflow) serviceCapacityProvidersValidator(service *common.Service, environmentName string) Executor {
+	return func(ctx context.Context) error {
+		if !workflow.isEcsProvider()() {
+			if len(service.CapacityProviders) > 0 {
+				log.Warningf("Service '%s' ignores its capacityProviders, they are only supported in ECS environments", workflow.serviceName)
+			}
+			return nil
+		}
+		hasEnvironmentProvider := workflow.envStack.Outputs["EcsCapacityProvider"] != ""
+		for _, item := range service.CapacityProviders {
+			if item.IsFargate() && !workflow.isFargateProvider()() {
+				return fmt.Errorf("Capacity provider %s of service '%s' is only supported in environments with provider '%s'", item.Provider, workflow.serviceName, common.EnvProviderEcsFargate)
+			}
+			if !item.IsFargate() && !hasEnvironmentProvider {
+				return fmt.Errorf("Environment '%s' has no capacity provider for service '%s', enable the capacityProvider of its cluster and run 'mu env up %s'", environmentName, workflow.serviceName, environmentName)
+			}
+		}
+		return nil
+	}
+}
+
 func getMinMaxPercentForStrategy(deploymentStrategy common.DeploymentStrategy) (string, string) {
 	var minHealthyPercent, maxPercent string
 	switch deploymentStrategy {
@@ -208,7 +331,7 @@ func getMaxUnavilableAndSurgePercentForKubernetesStrategy(
 }
 
 func (workflow *serviceWorkflow) serviceApplyEcsParams(service *common.Service, params map[string]string, rolesetGetter common.RolesetGetter) Executor {
-	return func() error {
+	return func(ctx context.Context) error {
 
 		params["EcsCluster"] = fmt.Sprintf("%s-EcsCluster", workflow.envStack.Name)
 		params["LaunchType"] = fmt.Sprintf("%s-LaunchType", workflow.envStack.Name)
@@ -244,6 +367,14 @@ func (workflow *serviceWorkflow) serviceApplyEcsParams(service *common.Service,
 
 		params["AssignPublicIp"] = strconv.FormatBool(service.AssignPublicIP)
 
+		if len(service.Volumes) > 0 {
+			params["EfsFileSystemId"] = fmt.Sprintf("%s-EfsFileSystemId", workflow.envStack.Name)
+		}
+
+		if workflow.envStack.Outputs["EcsCapacityProvider"] != "" {
+			params["EcsCapacityProvider"] = fmt.Sprintf("%s-EcsCapacityProvider", workflow.envStack.Name)
+		}
+
 		// force 'awsvpc' network mode for ecs-fargate
 		if strings.EqualFold(string(workflow.envStack.Tags["provider"]), string(common.EnvProviderEcsFargate)) {
 			params["TaskNetworkMode"] = common.NetworkModeAwsVpc
@@ -251,7 +382,7 @@ func (workflow *serviceWorkflow) serviceApplyEcsParams(service *common.Service,
 			params["TaskNetworkMode"] = string(service.NetworkMode)
 		}
 
-		serviceRoleset, err := rolesetGetter.GetServiceRoleset(workflow.envStack.Tags["environment"], workflow.serviceName)
+		serviceRoleset, err := rolesetGetter.GetServiceRoleset(ctx, workflow.envStack.Tags["environment"], workflow.serviceName)
 		if err != nil {
 			return err
 		}
@@ -261,6 +392,20 @@ func (workflow *serviceWorkflow) serviceApplyEcsParams(service *common.Service,
 		params["ApplicationAutoScalingRoleArn"] = serviceRoleset["ApplicationAutoScalingRoleArn"]
 		params["ServiceName"] = workflow.serviceName
 
+		if service.DeploymentStrategy.CodeDeployConfigName() != "" {
+			params["CodeDeployRoleArn"] = serviceRoleset["CodeDeployRoleArn"]
+		}
+
+		if workflow.lbStack != nil && workflow.lbStack.Outputs["ElbFullName"] != "" {
+			params["ElbFullName"] = fmt.Sprintf("%s-ElbFullName", workflow.lbStack.Name)
+		}
+		for _, policy := range service.Autoscaling.TargetTracking {
+			if policy.Metric == common.AutoscalingMetricRequestCount && params["ElbFullName"] == "" {
+				return fmt.Errorf("Autoscaling policy '%s' of service '%s' needs the ElbFullName output of the load balancer, run 'mu env up %s' to update the environment",
+					policy.Name, workflow.serviceName, workflow.envStack.Tags["environment"])
+			}
+		}
+
 		params["MinimumHealthyPercent"], params["MaximumPercent"] = getMinMaxPercentForStrategy(service.DeploymentStrategy)
 
 		return nil
@@ -268,7 +413,7 @@ func (workflow *serviceWorkflow) serviceApplyEcsParams(service *common.Service,
 }
 
 func (workflow *serviceWorkflow) serviceApplyEc2Params(params map[string]string, rolesetGetter common.RolesetGetter) Executor {
-	return func() error {
+	return func(ctx context.Context) error {
 
 		params["AppName"] = workflow.appName
 		params["RevisionBucket"] = workflow.appRevisionBucket
@@ -294,7 +439,7 @@ func (workflow *serviceWorkflow) serviceApplyEc2Params(params map[string]string,
 			params[key] = workflow.envStack.Parameters[key]
 		}
 
-		serviceRoleset, err := rolesetGetter.GetServiceRoleset(workflow.envStack.Tags["environment"], workflow.serviceName)
+		serviceRoleset, err := rolesetGetter.GetServiceRoleset(ctx, workflow.envStack.Tags["environment"], workflow.serviceName)
 		if err != nil {
 			return err
 		}
@@ -310,7 +455,7 @@ func (workflow *serviceWorkflow) serviceApplyEc2Params(params map[string]string,
 func (workflow *serviceWorkflow) serviceApplyCommonParams(namespace string, service *common.Service,
 	params map[string]string, environmentName string, stackWaiter common.StackWaiter,
 	elbRuleLister common.ElbRuleLister, paramGetter common.ParamGetter) Executor {
-	return func() error {
+	return func(ctx context.Context) error {
 		params["VpcId"] = fmt.Sprintf("%s-VpcId", workflow.envStack.Name)
 
 		nextAvailablePriority := 0
@@ -329,10 +474,14 @@ func (workflow *serviceWorkflow) serviceApplyCommonParams(namespace string, serv
 			}
 		}
 
+		if err := workflow.serviceApplyLoadbalancerParams(service, params); err != nil {
+			return err
+		}
+
 		common.NewMapElementIfNotZero(params, "TargetCPUUtilization", service.TargetCPUUtilization)
 
 		dbStackName := common.CreateStackName(namespace, common.StackTypeDatabase, workflow.serviceName, environmentName)
-		dbStack := stackWaiter.AwaitFinalStatus(dbStackName)
+		dbStack := stackWaiter.AwaitFinalStatus(ctx, dbStackName)
 		if dbStack != nil {
 			params["DatabaseName"] = dbStack.Outputs["DatabaseName"]
 			params["DatabaseEndpointAddress"] = dbStack.Outputs["DatabaseEndpointAddress"]
@@ -347,7 +496,7 @@ func (workflow *serviceWorkflow) serviceApplyCommonParams(namespace string, serv
 		}
 
 		svcStackName := common.CreateStackName(namespace, common.StackTypeService, workflow.serviceName, environmentName)
-		svcStack := stackWaiter.AwaitFinalStatus(svcStackName)
+		svcStack := stackWaiter.AwaitFinalStatus(ctx, svcStackName)
 
 		if workflow.priority > 0 {
 			// make sure manually specified priority is not already in use
@@ -372,7 +521,7 @@ func (workflow *serviceWorkflow) serviceApplyCommonParams(namespace string, serv
 		params["EnvironmentName"] = environmentName
 		params["ServiceName"] = workflow.serviceName
 		common.NewMapElementIfNotZero(params, "ServicePort", service.Port)
-		common.NewMapElementIfNotEmpty(params, "ServiceProtocol", string(service.Protocol))
+		common.NewMapElementIfNotEmpty(params, "ServiceProtocol", service.Protocol.TargetGroupProtocol())
 		common.NewMapElementIfNotEmpty(params, "ServiceHealthEndpoint", service.HealthEndpoint)
 		common.NewMapElementIfNotZero(params, "ServiceDesiredCount", service.DesiredCount)
 		common.NewMapElementIfNotZero(params, "ServiceMinSize", service.MinSize)
@@ -388,11 +537,70 @@ func (workflow *serviceWorkflow) serviceApplyCommonParams(namespace string, serv
 	}
 }
 
+// serviceApplyLoadbalancerParams checks that the load balancer of the environment supports the protocol of the service,
+// and sets the listener of the network load balancer for TCP, UDP and TLS services
+func (workflow *serviceWorkflow) serviceApplyLoadbalancerParams(service *common.Service, params map[string]string) error {
+	elbType := string(common.LoadbalancerTypeApplication)
+	if workflow.lbStack != nil && workflow.lbStack.Outputs["ElbType"] != "" {
+		elbType = workflow.lbStack.Outputs["ElbType"]
+	}
+
+	if service.Protocol == common.ServiceProtocolGRPC || service.Protocol.IsNetwork() {
+		if !workflow.isEcsProvider()() {
+			return fmt.Errorf("Protocol '%s' of service '%s' is only supported in ECS environments", service.Protocol, workflow.serviceName)
+		}
+	}
+
+	if !service.Protocol.IsNetwork() {
+		if len(service.PathPatterns) == 0 && len(service.HostPatterns) == 0 {
+			return nil
+		}
+		if elbType != string(common.LoadbalancerTypeApplication) {
+			return fmt.Errorf("Service '%s' with pathPatterns or hostPatterns requires an environment with loadbalancer type '%s'", workflow.serviceName, common.LoadbalancerTypeApplication)
+		}
+		if service.Protocol == common.ServiceProtocolGRPC {
+			if workflow.lbStack == nil || workflow.lbStack.Outputs["ElbHttpsListenerArn"] == "" {
+				return fmt.Errorf("Protocol '%s' of service '%s' requires a loadbalancer certificate in the environment", service.Protocol, workflow.serviceName)
+			}
+			if service.HealthEndpoint == "" {
+				params["ServiceHealthEndpoint"] = "/AWS.ALB/healthcheck"
+			}
+		}
+		return nil
+	}
+
+	if elbType != common.LoadbalancerTypeNetwork {
+		return fmt.Errorf("Protocol '%s' of service '%s' requires an environment with loadbalancer type '%s'", service.Protocol, workflow.serviceName, common.LoadbalancerTypeNetwork)
+	}
+	params["ElbArn"] = fmt.Sprintf("%s-ElbArn", workflow.lbStack.Name)
+
+	listenerPort := service.ListenerPort
+	if listenerPort == 0 {
+		listenerPort = service.Port
+	}
+	if listenerPort == 0 {
+		listenerPort = 8080
+	}
+	params["ListenerPort"] = strconv.Itoa(listenerPort)
+
+	if service.Protocol == common.ServiceProtocolTLS {
+		if workflow.lbStack.Outputs["ElbCertificateArn"] == "" {
+			return fmt.Errorf("Protocol '%s' of service '%s' requires a loadbalancer certificate in the environment", service.Protocol, workflow.serviceName)
+		}
+		params["ElbCertificateArn"] = workflow.lbStack.Outputs["ElbCertificateArn"]
+	}
+	return nil
+}
+
 func (workflow *serviceWorkflow) serviceEc2Deployer(namespace string, service *common.Service, stackParams map[string]string, environmentName string, stackUpserter common.StackUpserter, stackWaiter common.StackWaiter) Executor {
-	return func() error {
+	return func(ctx context.Context) error {
 
 		log.Noticef("Deploying service '%s' to '%s'", workflow.serviceName, environmentName)
 
+		if len(service.Autoscaling.TargetTracking) > 0 || len(service.Autoscaling.StepScaling) > 0 || len(service.Autoscaling.Scheduled) > 0 {
+			log.Warningf("Autoscaling policies of service '%s' are not supported in EC2 environments", workflow.serviceName)
+		}
+
 		svcStackName := common.CreateStackName(namespace, common.StackTypeService, workflow.serviceName, environmentName)
 
 		resolveServiceEnvironment(service, environmentName)
@@ -405,12 +613,12 @@ func (workflow *serviceWorkflow) serviceEc2Deployer(namespace string, service *c
 			Revision:    workflow.codeRevision,
 			Repo:        workflow.repoName,
 		})
-		err := stackUpserter.UpsertStack(svcStackName, common.TemplateServiceEC2, service, stackParams, tags, "", workflow.cloudFormationRoleArn)
+		err := stackUpserter.UpsertStack(ctx, svcStackName, common.TemplateServiceEC2, service, stackParams, tags, "", workflow.cloudFormationRoleArn)
 		if err != nil {
 			return err
 		}
 		log.Debugf("Waiting for stack '%s' to complete", svcStackName)
-		stack := stackWaiter.AwaitFinalStatus(svcStackName)
+		stack := stackWaiter.AwaitFinalStatus(ctx, svcStackName)
 		if stack == nil {
 			return fmt.Errorf("Unable to create stack %s", svcStackName)
 		}
@@ -423,7 +631,7 @@ func (workflow *serviceWorkflow) serviceEc2Deployer(namespace string, service *c
 }
 
 func (workflow *serviceWorkflow) serviceEcsDeployer(namespace string, service *common.Service, stackParams map[string]string, environmentName string, stackUpserter common.StackUpserter, stackWaiter common.StackWaiter) Executor {
-	return func() error {
+	return func(ctx context.Context) error {
 		log.Noticef("Deploying service '%s' to '%s' from '%s'", workflow.serviceName, environmentName, workflow.serviceImage)
 
 		svcStackName := common.CreateStackName(namespace, common.StackTypeService, workflow.serviceName, environmentName)
@@ -439,12 +647,12 @@ func (workflow *serviceWorkflow) serviceEcsDeployer(namespace string, service *c
 			Repo:        workflow.repoName,
 		})
 
-		err := stackUpserter.UpsertStack(svcStackName, common.TemplateServiceECS, service, stackParams, tags, "", workflow.cloudFormationRoleArn)
+		err := stackUpserter.UpsertStack(ctx, svcStackName, common.TemplateServiceECS, service, stackParams, tags, "", workflow.cloudFormationRoleArn)
 		if err != nil {
 			return err
 		}
 		log.Debugf("Waiting for stack '%s' to complete", svcStackName)
-		stack := stackWaiter.AwaitFinalStatus(svcStackName)
+		stack := stackWaiter.AwaitFinalStatus(ctx, svcStackName)
 		if stack == nil {
 			return fmt.Errorf("Unable to create stack %s", svcStackName)
 		}
@@ -452,13 +660,110 @@ func (workflow *serviceWorkflow) serviceEcsDeployer(namespace string, service *c
 			return fmt.Errorf("Ended in failed status %s %s", stack.Status, stack.StatusReason)
 		}
 		workflow.microserviceTaskDefinitionArn = stack.Outputs["MicroserviceTaskDefinitionArn"]
+		workflow.codeDeployApplication = stack.Outputs["CodeDeployApplication"]
+		workflow.codeDeployDeploymentGroup = stack.Outputs["CodeDeployDeploymentGroup"]
+
+		return nil
+	}
+}
 
+// serviceApplyEcsTrafficShiftingParams keeps the task definition of an existing ECS service, since
+// new task definitions of a service with the CODE_DEPLOY controller are deployed by CodeDeploy
+func (workflow *serviceWorkflow) serviceApplyEcsTrafficShiftingParams(namespace string, service *common.Service, params map[string]string, environmentName string, stackWaiter common.StackWaiter) Executor {
+	return func(ctx context.Context) error {
+		if service.DeploymentStrategy.CodeDeployConfigName() == "" {
+			return nil
+		}
+		if len(service.PathPatterns) == 0 && len(service.HostPatterns) == 0 {
+			return fmt.Errorf("Deployment strategy '%s' requires 'pathPatterns' or 'hostPatterns' for service '%s'", service.DeploymentStrategy, workflow.serviceName)
+		}
+
+		svcStackName := common.CreateStackName(namespace, common.StackTypeService, workflow.serviceName, environmentName)
+		svcStack := stackWaiter.AwaitFinalStatus(ctx, svcStackName)
+		if svcStack == nil || svcStack.Status == common.StackStatusRollbackComplete {
+			return nil
+		}
+		if svcStack.Outputs["CodeDeployDeploymentGroup"] == "" {
+			return fmt.Errorf("Service '%s' must be undeployed from '%s' before switching to deployment strategy '%s'", workflow.serviceName, environmentName, service.DeploymentStrategy)
+		}
+
+		workflow.previousTaskDefinitionArn = svcStack.Outputs["MicroserviceTaskDefinitionArn"]
+		params["DeployedTaskDefinitionArn"] = common.NewStringIfNotEmpty(workflow.previousTaskDefinitionArn, svcStack.Parameters["DeployedTaskDefinitionArn"])
+		return nil
+	}
+}
+
+// serviceEcsTrafficShifter deploys the new task definition with CodeDeploy and waits for all traffic to be shifted to it
+func (workflow *serviceWorkflow) serviceEcsTrafficShifter(service *common.Service, environmentName string, deploymentManager common.DeploymentManager) Executor {
+	return func(ctx context.Context) error {
+		configName := service.DeploymentStrategy.CodeDeployConfigName()
+		if configName == "" {
+			return nil
+		}
+		if workflow.previousTaskDefinitionArn == "" || workflow.previousTaskDefinitionArn == workflow.microserviceTaskDefinitionArn {
+			log.Debugf("No new task definition to shift traffic to for service '%s'", workflow.serviceName)
+			return nil
+		}
+
+		log.Noticef("Shifting traffic for service '%s' in '%s' to '%s' with '%s'", workflow.serviceName, environmentName, workflow.serviceImage, configName)
+
+		containerPort := service.Port
+		if containerPort == 0 {
+			containerPort = 8080
+		}
+		deploymentID, err := deploymentManager.CreateEcsDeployment(workflow.codeDeployApplication, workflow.codeDeployDeploymentGroup,
+			workflow.microserviceTaskDefinitionArn, workflow.serviceName, containerPort)
+		if err != nil {
+			return err
+		}
+		if deploymentID == "" {
+			return nil
+		}
+
+		var deployment *common.EcsDeployment
+		lastStatus := ""
+		lastTrafficWeight := -1.0
+		for {
+			deployment, err = deploymentManager.GetEcsDeployment(deploymentID)
+			if err != nil {
+				return err
+			}
+			if deployment.Status != lastStatus || deployment.TrafficWeight != lastTrafficWeight {
+				log.Noticef("  Deployment '%s' is %s with %v%% of traffic shifted", deploymentID, deployment.Status, deployment.TrafficWeight)
+				trafficWeight := deployment.TrafficWeight
+				common.EmitEvent(common.Event{
+					Type:          common.EventTypeDeployment,
+					Environment:   environmentName,
+					Service:       workflow.serviceName,
+					Deployment:    deploymentID,
+					Status:        deployment.Status,
+					Reason:        deployment.StatusMessage,
+					TrafficWeight: &trafficWeight,
+				})
+				lastStatus = deployment.Status
+				lastTrafficWeight = deployment.TrafficWeight
+			}
+			if deployment.IsComplete() {
+				break
+			}
+			if err := common.SleepWithContext(ctx, time.Duration(PollDelay)*time.Second); err != nil {
+				return err
+			}
+		}
+
+		if deployment.Status == common.EcsDeploymentReady {
+			log.Warningf("Deployment '%s' is waiting for traffic to be rerouted, continue it with 'aws deploy continue-deployment --deployment-id %s'", deploymentID, deploymentID)
+			return nil
+		}
+		if !deployment.IsSucceeded() {
+			return fmt.Errorf("Deployment '%s' ended in status %s and was rolled back: %s", deploymentID, deployment.Status, deployment.StatusMessage)
+		}
 		return nil
 	}
 }
 
 func (workflow *serviceWorkflow) serviceEksDBSecret(namespace string, service *common.Service, stackParams map[string]string, environmentName string) Executor {
-	return func() error {
+	return func(ctx context.Context) error {
 		if stackParams["DatabaseName"] == "" {
 			return nil
 		}
@@ -479,10 +784,71 @@ func (workflow *serviceWorkflow) serviceEksDBSecret(namespace string, service *c
 	}
 }
 
+// serviceEksSecrets resolves the values of the secrets of the service, and upserts them as a kubernetes Secret
+func (workflow *serviceWorkflow) serviceEksSecrets(service *common.Service, environmentName string, paramGetter common.ParamGetter, secretGetter common.SecretGetter) Executor {
+	return func(ctx context.Context) error {
+		if len(service.Secrets) == 0 {
+			return nil
+		}
+		log.Noticef("Deploying secrets for '%s' in '%s'", workflow.serviceName, environmentName)
+
+		secrets := make(map[string]string)
+		for name, ref := range service.SecretReferences() {
+			var value string
+			var err error
+			if ref.IsSecretsManager() {
+				value, err = secretGetter.GetSecret(ref.SecretID, ref.VersionStage, ref.VersionID)
+				if err == nil && ref.JSONKey != "" {
+					value, err = secretJSONValue(value, ref.JSONKey)
+				}
+			} else {
+				value, err = paramGetter.GetParam(ref.ParameterName)
+			}
+			if err != nil {
+				return fmt.Errorf("Unable to resolve secret '%s' from '%s': %v", name, ref.ValueFrom, err)
+			}
+			secrets[name] = base64.StdEncoding.EncodeToString([]byte(value))
+		}
+
+		params := map[string]interface{}{
+			"ServiceName": workflow.serviceName,
+			"Namespace":   fmt.Sprintf("mu-service-%s", workflow.serviceName),
+			"Revision":    workflow.codeRevision,
+			"MuVersion":   common.GetVersion(),
+			"SecretName":  serviceSecretName(workflow.serviceName),
+			"Secrets":     secrets,
+		}
+
+		return workflow.kubernetesResourceManager.UpsertResources(common.TemplateK8sSecrets, params)
+	}
+}
+
+func serviceSecretName(serviceName string) string {
+	return fmt.Sprintf("%s-secrets", serviceName)
+}
+
+// secretJSONValue returns the value of a key in a secret that is a json object, the same as ECS does
+func secretJSONValue(secret string, key string) (string, error) {
+	values := make(map[string]interface{})
+	err := json.Unmarshal([]byte(secret), &values)
+	if err != nil {
+		return "", err
+	}
+	value, ok := values[key]
+	if !ok {
+		return "", fmt.Errorf("Unable to find key '%s' in secret", key)
+	}
+	if s, ok := value.(string); ok {
+		return s, nil
+	}
+	valueBytes, err := json.Marshal(value)
+	return string(valueBytes), err
+}
+
 // serviceEksDeployer accepts a service and its information and upserts a kubernetes Pod file to
 // a k8s cluster
 func (workflow *serviceWorkflow) serviceEksDeployer(namespace string, service *common.Service, stackParams map[string]string, environmentName string) Executor {
-	return func() error {
+	return func(ctx context.Context) error {
 		log.Noticef("Deploying service '%s' to '%s' from '%s'", workflow.serviceName, environmentName, workflow.serviceImage)
 
 		servicePort := 8080
@@ -505,6 +871,52 @@ func (workflow *serviceWorkflow) serviceEksDeployer(namespace string, service *c
 			pathPatterns[idx] = strings.TrimRight(pattern, "*")
 		}
 
+		if len(service.Autoscaling.StepScaling) > 0 || len(service.Autoscaling.Scheduled) > 0 {
+			log.Warningf("Step scaling policies and scheduled actions of service '%s' are not supported in EKS environments", workflow.serviceName)
+		}
+		hpaPolicies := make([]common.TargetTrackingPolicy, 0)
+		for _, policy := range service.Autoscaling.TargetTracking {
+			if policy.Metric == common.AutoscalingMetricRequestCount {
+				log.Warningf("Autoscaling policy '%s' of service '%s' is not supported in EKS environments", policy.Name, workflow.serviceName)
+				continue
+			}
+			hpaPolicies = append(hpaPolicies, policy)
+		}
+		if len(service.Autoscaling.TargetTracking) == 0 && (service.TargetCPUUtilization != 0 || service.MaxSize != 0) {
+			// same default policy as the ECS services, once autoscaling is configured
+			targetCPUUtilization := 75
+			if service.TargetCPUUtilization != 0 {
+				targetCPUUtilization = service.TargetCPUUtilization
+			}
+			hpaPolicies = append(hpaPolicies, common.TargetTrackingPolicy{
+				Name:        "cpu-utilization",
+				Metric:      common.AutoscalingMetricCPU,
+				TargetValue: float64(targetCPUUtilization),
+			})
+		}
+
+		desiredCount := 2
+		if service.DesiredCount != 0 {
+			desiredCount = service.DesiredCount
+		}
+		serviceCPU := 10
+		if service.CPU != 0 {
+			serviceCPU = service.CPU
+		}
+		serviceMemory := 300
+		if service.Memory != 0 {
+			serviceMemory = service.Memory
+		}
+
+		minReplicas := 1
+		if service.MinSize != 0 {
+			minReplicas = service.MinSize
+		}
+		maxReplicas := 2
+		if service.MaxSize != 0 {
+			maxReplicas = service.MaxSize
+		}
+
 		resolveServiceEnvironment(service, environmentName)
 		templateData := map[string]interface{}{
 			"Namespace":             fmt.Sprintf("mu-service-%s", workflow.serviceName),
@@ -520,6 +932,14 @@ func (workflow *serviceWorkflow) serviceEksDeployer(namespace string, service *c
 			"MuVersion":             common.GetVersion(),
 			"EnvVariables":          service.Environment,
 			"DeploymentStrategy":    string(service.DeploymentStrategy),
+			"Sidecars":              service.Sidecars,
+			"Volumes":               service.Volumes,
+			"HpaPolicies":           hpaPolicies,
+			"DesiredCount":          desiredCount,
+			"ServiceCPU":            common.KubernetesCPU(serviceCPU),
+			"ServiceMemory":         fmt.Sprintf("%dMi", serviceMemory),
+			"MinReplicas":           minReplicas,
+			"MaxReplicas":           maxReplicas,
 		}
 		// see common/types.go DeploymentStrategy types for valid string values
 		templateData["MaxUnavailable"], templateData["MaxSurge"] = getMaxUnavilableAndSurgePercentForKubernetesStrategy(service.DeploymentStrategy)
@@ -528,12 +948,20 @@ func (workflow *serviceWorkflow) serviceEksDeployer(namespace string, service *c
 			templateData["DatabaseSecretName"] = fmt.Sprintf("%s-database", workflow.serviceName)
 		}
 
+		secretKeys := make([]string, 0, len(service.Secrets))
+		for name := range service.Secrets {
+			secretKeys = append(secretKeys, name)
+		}
+		sort.Strings(secretKeys)
+		templateData["SecretKeys"] = secretKeys
+		templateData["SecretName"] = serviceSecretName(workflow.serviceName)
+
 		return workflow.kubernetesResourceManager.UpsertResources(common.TemplateK8sDeployment, templateData)
 	}
 }
 
 func (workflow *serviceWorkflow) serviceCreateSchedules(namespace string, service *common.Service, environmentName string, stackWaiter common.StackWaiter, stackUpserter common.StackUpserter) Executor {
-	return func() error {
+	return func(ctx context.Context) error {
 		log.Noticef("Creating schedules for service '%s' to '%s'", workflow.serviceName, environmentName)
 		for _, schedule := range service.Schedule {
 			params := make(map[string]string)
@@ -560,12 +988,12 @@ func (workflow *serviceWorkflow) serviceCreateSchedules(namespace string, servic
 				Type:        common.StackTypeSchedule,
 			})
 
-			err = stackUpserter.UpsertStack(scheduleStackName, common.TemplateSchedule, service, params, tags, "", workflow.cloudFormationRoleArn)
+			err = stackUpserter.UpsertStack(ctx, scheduleStackName, common.TemplateSchedule, service, params, tags, "", workflow.cloudFormationRoleArn)
 			if err != nil {
 				return err
 			}
 			log.Debugf("Waiting for stack '%s' to complete", scheduleStackName)
-			stack := stackWaiter.AwaitFinalStatus(scheduleStackName)
+			stack := stackWaiter.AwaitFinalStatus(ctx, scheduleStackName)
 			if stack == nil {
 				return fmt.Errorf("Unable to create stack %s", scheduleStackName)
 			}
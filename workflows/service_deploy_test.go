@@ -1,9 +1,12 @@
 package workflows
 
 import (
+	"context"
+	"errors"
 	"testing"
 
 	"github.com/stelligent/mu/common"
+	"github.com/stelligent/mu/templates"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
@@ -51,7 +54,7 @@ func TestServiceApplyCommon_Create(t *testing.T) {
 	workflow.serviceName = "myservice"
 	workflow.envStack = &common.Stack{Name: "mu-environment-dev", Status: common.StackStatusCreateComplete, Outputs: outputs}
 	workflow.lbStack = &common.Stack{Name: "mu-loadbalancer-dev", Status: common.StackStatusCreateComplete, Outputs: outputs}
-	err := workflow.serviceApplyCommonParams("mu", service, params, "dev", stackManager, elbRuleLister, paramManager)()
+	err := workflow.serviceApplyCommonParams("mu", service, params, "dev", stackManager, elbRuleLister, paramManager)(context.Background())
 	assert.Nil(err)
 
 	assert.Equal("mu-environment-dev-VpcId", params["VpcId"])
@@ -89,7 +92,7 @@ func TestServiceApplyCommon_Update(t *testing.T) {
 	workflow.serviceName = "myservice"
 	workflow.envStack = &common.Stack{Name: "mu-environment-dev", Status: common.StackStatusCreateComplete, Outputs: outputs}
 	workflow.lbStack = &common.Stack{Name: "mu-loadbalancer-dev", Status: common.StackStatusCreateComplete, Outputs: outputs}
-	err := workflow.serviceApplyCommonParams("mu", service, params, "dev", stackManager, elbRuleLister, paramManager)()
+	err := workflow.serviceApplyCommonParams("mu", service, params, "dev", stackManager, elbRuleLister, paramManager)(context.Background())
 	assert.Nil(err)
 
 	assert.Equal("", params["ListenerRulePriority"])
@@ -124,7 +127,7 @@ func TestServiceApplyCommon_StaticPriority(t *testing.T) {
 	workflow.envStack = &common.Stack{Name: "mu-environment-dev", Status: common.StackStatusCreateComplete, Outputs: outputs}
 	workflow.lbStack = &common.Stack{Name: "mu-loadbalancer-dev", Status: common.StackStatusCreateComplete, Outputs: outputs}
 	workflow.priority = 77
-	err := workflow.serviceApplyCommonParams("mu", service, params, "dev", stackManager, elbRuleLister, paramManager)()
+	err := workflow.serviceApplyCommonParams("mu", service, params, "dev", stackManager, elbRuleLister, paramManager)(context.Background())
 	assert.Nil(err)
 
 	assert.Equal("77", params["PathListenerRulePriority"])
@@ -142,7 +145,7 @@ func TestServiceEnvLoader_NotFound(t *testing.T) {
 	stackManager.On("AwaitFinalStatus", "mu-loadbalancer-dev").Return(nil).Once()
 
 	workflow := new(serviceWorkflow)
-	err := workflow.serviceEnvironmentLoader("mu", "dev", stackManager)()
+	err := workflow.serviceEnvironmentLoader("mu", "dev", stackManager)(context.Background())
 
 	assert.NotNil(err)
 
@@ -187,7 +190,7 @@ func TestServiceEcsDeployer(t *testing.T) {
 	outputs["provider"] = "ecs"
 	workflow.envStack = &common.Stack{Name: "mu-environment-dev", Status: common.StackStatusCreateComplete, Outputs: outputs}
 	workflow.lbStack = &common.Stack{Name: "mu-loadbalancer-dev", Status: common.StackStatusCreateComplete, Outputs: outputs}
-	err := workflow.serviceEcsDeployer("mu", &config.Service, params, "dev", stackManager, stackManager)()
+	err := workflow.serviceEcsDeployer("mu", &config.Service, params, "dev", stackManager, stackManager)(context.Background())
 	assert.Nil(err)
 
 	stackManager.AssertExpectations(t)
@@ -242,13 +245,95 @@ func TestServiceEksDeployer(t *testing.T) {
 	workflow.envStack = &common.Stack{Name: "mu-environment-dev", Status: common.StackStatusCreateComplete, Outputs: outputs}
 	workflow.lbStack = &common.Stack{Name: "mu-loadbalancer-dev", Status: common.StackStatusCreateComplete, Outputs: outputs}
 	workflow.kubernetesResourceManager = kubernetesResourceManager
-	err := workflow.serviceEksDeployer("mu", &config.Service, params, "dev")()
+	err := workflow.serviceEksDeployer("mu", &config.Service, params, "dev")(context.Background())
 	assert.Nil(err)
 
 	kubernetesResourceManager.AssertExpectations(t)
 	kubernetesResourceManager.AssertNumberOfCalls(t, "UpsertResources", 1)
 }
 
+func TestServiceSidecarsValidator(t *testing.T) {
+	assert := assert.New(t)
+
+	workflow := new(serviceWorkflow)
+	workflow.serviceName = "foo"
+
+	service := new(common.Service)
+	service.Sidecars = []common.Sidecar{
+		{Name: "envoy", Image: "envoyproxy/envoy:v1.9.0", DependsOn: []string{"foo"}},
+		{Name: "datadog", Image: "datadog/agent:latest", DependsOn: []string{"envoy"}},
+	}
+	assert.Nil(workflow.serviceSidecarsValidator(service)(context.Background()))
+
+	service.Sidecars[0].DependsOn = []string{"datadog"}
+	assert.NotNil(workflow.serviceSidecarsValidator(service)(context.Background()))
+
+	service.Sidecars[0].DependsOn = nil
+	service.Sidecars[1].Name = "foo"
+	assert.NotNil(workflow.serviceSidecarsValidator(service)(context.Background()))
+
+	service.Sidecars[1].Name = "datadog"
+	service.Sidecars[1].Image = ""
+	assert.NotNil(workflow.serviceSidecarsValidator(service)(context.Background()))
+}
+
+type recordingKubernetesResourceManager struct {
+	mockKubernetesResourceManager
+	templateData interface{}
+}
+
+func (m *recordingKubernetesResourceManager) UpsertResources(templateName string, templateData interface{}) error {
+	m.templateData = templateData
+	return nil
+}
+
+func TestServiceEksDeployer_Resources(t *testing.T) {
+	assert := assert.New(t)
+
+	kubernetesResourceManager := new(recordingKubernetesResourceManager)
+
+	config := new(common.Config)
+	config.Service.Name = "foo"
+	config.Service.Port = 9000
+	config.Service.HealthEndpoint = "/ping"
+	config.Service.DesiredCount = 4
+	config.Service.MinSize = 2
+	config.Service.MaxSize = 8
+	config.Service.CPU = 256
+	config.Service.Memory = 512
+	config.Service.TargetCPUUtilization = 60
+
+	workflow := new(serviceWorkflow)
+	workflow.serviceName = "foo"
+	workflow.envStack = &common.Stack{Name: "mu-environment-dev", Status: common.StackStatusCreateComplete, Outputs: map[string]string{"provider": "eks"}}
+	workflow.kubernetesResourceManager = kubernetesResourceManager
+	err := workflow.serviceEksDeployer("mu", &config.Service, make(map[string]string), "dev")(context.Background())
+	assert.Nil(err)
+
+	deployment, err := templates.GetAsset(common.TemplateK8sDeployment, templates.ExecuteTemplate(kubernetesResourceManager.templateData))
+	assert.Nil(err)
+	assert.NotContains(deployment, "replicas: 4")
+	assert.Contains(deployment, "cpu: 250m")
+	assert.Contains(deployment, "memory: 512Mi")
+	assert.Contains(deployment, "path: /ping")
+	assert.Contains(deployment, "port: 9000")
+	assert.Contains(deployment, "minReplicas: 2")
+	assert.Contains(deployment, "maxReplicas: 8")
+	assert.Contains(deployment, "averageUtilization: 60")
+
+	// without autoscaling the deployment keeps the desired count
+	config.Service.MinSize = 0
+	config.Service.MaxSize = 0
+	config.Service.TargetCPUUtilization = 0
+	err = workflow.serviceEksDeployer("mu", &config.Service, make(map[string]string), "dev")(context.Background())
+	assert.Nil(err)
+
+	deployment, err = templates.GetAsset(common.TemplateK8sDeployment, templates.ExecuteTemplate(kubernetesResourceManager.templateData))
+	assert.Nil(err)
+	assert.Contains(deployment, "replicas: 4")
+	assert.NotContains(deployment, "HorizontalPodAutoscaler")
+}
+
 func stringRef(v string) *string {
 	return &v
 }
@@ -264,7 +349,7 @@ func TestServiceDeployer_serviceRolesetUpserter(t *testing.T) {
 
 	workflow := new(serviceWorkflow)
 	workflow.serviceName = "svc20"
-	err := workflow.serviceRolesetUpserter(rolesetManager, rolesetManager, "env1")()
+	err := workflow.serviceRolesetUpserter(rolesetManager, rolesetManager, "env1")(context.Background())
 	assert.Nil(err)
 	assert.Equal("bar", workflow.cloudFormationRoleArn)
 
@@ -274,3 +359,109 @@ func TestServiceDeployer_serviceRolesetUpserter(t *testing.T) {
 	rolesetManager.AssertNumberOfCalls(t, "UpsertServiceRoleset", 1)
 
 }
+
+func TestServiceApplyEcsParams_RequestCount(t *testing.T) {
+	assert := assert.New(t)
+	rolesetManager := new(mockedRolesetManagerForService)
+	rolesetManager.On("GetServiceRoleset").Return(common.Roleset{}, nil)
+
+	service := &common.Service{}
+	service.Autoscaling.TargetTracking = []common.TargetTrackingPolicy{
+		{Name: "requests", Metric: common.AutoscalingMetricRequestCount, TargetValue: 1000},
+	}
+
+	workflow := new(serviceWorkflow)
+	workflow.serviceName = "foo"
+	workflow.envStack = &common.Stack{Name: "mu-environment-dev", Tags: map[string]string{"environment": "dev"}}
+	workflow.lbStack = &common.Stack{Name: "mu-loadbalancer-dev", Outputs: map[string]string{}}
+
+	params := make(map[string]string)
+	err := workflow.serviceApplyEcsParams(service, params, rolesetManager)(context.Background())
+	assert.NotNil(err)
+	assert.Contains(err.Error(), "ElbFullName")
+
+	workflow.lbStack.Outputs["ElbFullName"] = "app/mu-loadbalancer-dev/1234"
+	err = workflow.serviceApplyEcsParams(service, params, rolesetManager)(context.Background())
+	assert.Nil(err)
+	assert.Equal("mu-loadbalancer-dev-ElbFullName", params["ElbFullName"])
+}
+
+type mockedDeployHookRunner struct {
+	mock.Mock
+}
+
+func (m *mockedDeployHookRunner) BeforeDeploy(environmentName string, serviceName string) error {
+	args := m.Called(environmentName, serviceName)
+	return args.Error(0)
+}
+func (m *mockedDeployHookRunner) AfterDeploy(environmentName string, serviceName string) error {
+	args := m.Called(environmentName, serviceName)
+	return args.Error(0)
+}
+
+func TestServiceDeployer_serviceDeployHooks(t *testing.T) {
+	assert := assert.New(t)
+	hookRunner := new(mockedDeployHookRunner)
+
+	hookRunner.On("BeforeDeploy", "env1", "svc20").Return(nil)
+	hookRunner.On("AfterDeploy", "env1", "svc20").Return(errors.New("smoke test failed"))
+
+	workflow := new(serviceWorkflow)
+	workflow.serviceName = "svc20"
+	err := workflow.serviceBeforeDeployHooks(hookRunner, "env1")(context.Background())
+	assert.Nil(err)
+	err = workflow.serviceAfterDeployHooks(hookRunner, "env1")(context.Background())
+	assert.NotNil(err)
+
+	hookRunner.AssertExpectations(t)
+}
+
+func TestServiceEksSecrets(t *testing.T) {
+	assert := assert.New(t)
+
+	kubernetesResourceManager := new(mockKubernetesResourceManager)
+	kubernetesResourceManager.On("UpsertResources", "kubernetes/secrets.yml").Return(nil)
+
+	paramManager := new(mockedParamManager)
+	paramManager.On("GetParam", "/mu/api/key").Return("secret-key", nil)
+	paramManager.On("GetSecret", "arn:aws:secretsmanager:us-east-1:123456789012:secret:db-AbCdEf").Return(`{"password":"secret-password"}`, nil)
+
+	service := &common.Service{
+		Secrets: map[string]string{
+			"API_KEY":     "/mu/api/key",
+			"DB_PASSWORD": "arn:aws:secretsmanager:us-east-1:123456789012:secret:db-AbCdEf:password::",
+		},
+	}
+
+	workflow := new(serviceWorkflow)
+	workflow.serviceName = "foo"
+	workflow.kubernetesResourceManager = kubernetesResourceManager
+	err := workflow.serviceEksSecrets(service, "dev", paramManager, paramManager)(context.Background())
+	assert.Nil(err)
+
+	kubernetesResourceManager.AssertNumberOfCalls(t, "UpsertResources", 1)
+	paramManager.AssertExpectations(t)
+
+	// no secrets, no resources
+	err = workflow.serviceEksSecrets(new(common.Service), "dev", paramManager, paramManager)(context.Background())
+	assert.Nil(err)
+	kubernetesResourceManager.AssertNumberOfCalls(t, "UpsertResources", 1)
+}
+
+func TestSecretJSONValue(t *testing.T) {
+	assert := assert.New(t)
+
+	value, err := secretJSONValue(`{"password":"bar","port":5432}`, "password")
+	assert.Nil(err)
+	assert.Equal("bar", value)
+
+	value, err = secretJSONValue(`{"password":"bar","port":5432}`, "port")
+	assert.Nil(err)
+	assert.Equal("5432", value)
+
+	_, err = secretJSONValue(`{"password":"bar"}`, "username")
+	assert.NotNil(err)
+
+	_, err = secretJSONValue(`not json`, "password")
+	assert.NotNil(err)
+}
@@ -1,6 +1,8 @@
 package workflows
 
 import (
+	"context"
+
 	"github.com/stelligent/mu/common"
 )
 
@@ -26,7 +28,7 @@ func newServiceExecutor(namespace string, taskManager common.TaskManager, task c
 }
 
 func (workflow *environmentWorkflow) serviceTaskExecutor(namespace string, taskManager common.TaskManager, task common.Task) Executor {
-	return func() error {
+	return func(ctx context.Context) error {
 		log.Notice(SvcCmdTaskExecutingLog)
 		result, err := taskManager.ExecuteCommand(namespace, task)
 		if err != nil {
@@ -1,6 +1,7 @@
 package workflows
 
 import (
+	"context"
 	"errors"
 	"github.com/stelligent/mu/common"
 	"github.com/stretchr/testify/assert"
@@ -48,7 +49,7 @@ func TestNewServiceExecutorFail(t *testing.T) {
 	}
 	executor := newServiceExecutor("mu", taskManagerMock, task)
 	assertion.NotNil(executor)
-	assertion.NotNil(executor())
+	assertion.NotNil(executor(context.Background()))
 }
 
 func TestNewServiceExecutor(t *testing.T) {
@@ -64,7 +65,7 @@ func TestNewServiceExecutor(t *testing.T) {
 	}
 	executor := newServiceExecutor("mu", taskManagerMock, task)
 	assertion.NotNil(executor)
-	assertion.Nil(executor())
+	assertion.Nil(executor(context.Background()))
 
 	taskManagerMock.AssertExpectations(t)
 	taskManagerMock.AssertNumberOfCalls(t, "ExecuteCommand", 1)
@@ -0,0 +1,298 @@
+package workflows
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/stelligent/mu/common"
+	"github.com/stelligent/mu/templates"
+	"gopkg.in/yaml.v2"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// ServiceExportFormat is the layout of the exported kubernetes resources of a service
+type ServiceExportFormat string
+
+// List of supported export formats
+const (
+	ServiceExportFormatRaw       ServiceExportFormat = "raw"
+	ServiceExportFormatKustomize ServiceExportFormat = "kustomize"
+	ServiceExportFormatHelm      ServiceExportFormat = "helm"
+)
+
+// NewServiceExporter create a new workflow for writing the kubernetes resources of a service in an environment to a directory, without applying them
+func NewServiceExporter(ctx *common.Context, environmentName string, tag string, format ServiceExportFormat, outputDir string, includeSecrets bool) Executor {
+
+	workflow := new(serviceWorkflow)
+	workflow.codeRevision = ctx.Config.Repo.Revision
+	workflow.repoName = ctx.Config.Repo.Slug
+
+	stackParams := make(map[string]string)
+	exporter := &kubernetesResourceExporter{
+		extensionsManager: ctx.ExtensionsManager,
+		resources:         make([]map[string]interface{}, 0),
+	}
+
+	return newServiceEnvironmentConfigExecutor(&ctx.Config, environmentName, newPipelineExecutor(
+		workflow.serviceExportValidator(format, outputDir),
+		workflow.serviceLoader(ctx, tag, ""),
+		workflow.serviceEnvironmentLoader(ctx.Config.Namespace, environmentName, ctx.StackManager),
+		workflow.serviceSidecarsValidator(&ctx.Config.Service),
+		newConditionalExecutor(workflow.isEksProvider(),
+			newPipelineExecutor(
+				workflow.serviceVolumesValidator(&ctx.Config.Service, environmentName),
+				workflow.serviceDependencyResolver(ctx.Config.Namespace, &ctx.Config.Service, environmentName, ctx.StackManager, ctx.TaskManager),
+				workflow.serviceRepoLoader(ctx.Config.Namespace, &ctx.Config.Service, ctx.StackManager),
+				workflow.serviceApplyCommonParams(ctx.Config.Namespace, &ctx.Config.Service, stackParams, environmentName, ctx.StackManager, ctx.ElbManager, ctx.ParamManager),
+				workflow.connectKubernetesExporter(exporter),
+				newConditionalExecutor(func() bool { return includeSecrets },
+					newPipelineExecutor(
+						workflow.serviceEksDBSecret(ctx.Config.Namespace, &ctx.Config.Service, stackParams, environmentName),
+						workflow.serviceEksSecrets(&ctx.Config.Service, environmentName, ctx.ParamManager, ctx.ParamManager),
+					),
+					workflow.serviceExportSecretsWarning(&ctx.Config.Service, stackParams)),
+				workflow.serviceEksDeployer(ctx.Config.Namespace, &ctx.Config.Service, stackParams, environmentName),
+				workflow.serviceExportWriter(exporter, format, outputDir, len(ctx.Config.Services) > 0),
+			),
+			func(ctx context.Context) error {
+				return fmt.Errorf("Export is only supported for services in EKS environments, '%s' is not an EKS environment", environmentName)
+			}),
+	))
+}
+
+func (workflow *serviceWorkflow) serviceExportValidator(format ServiceExportFormat, outputDir string) Executor {
+	return func(ctx context.Context) error {
+		switch format {
+		case ServiceExportFormatRaw, ServiceExportFormatKustomize, ServiceExportFormatHelm:
+		default:
+			return fmt.Errorf("Unsupported export format '%s', must be one of '%s', '%s' or '%s'", format, ServiceExportFormatRaw, ServiceExportFormatKustomize, ServiceExportFormatHelm)
+		}
+		if outputDir == "" {
+			return fmt.Errorf("Output directory must be provided")
+		}
+		return nil
+	}
+}
+
+// serviceRepoLoader finds the image of the service without upserting the repo, like serviceRepoUpserter does
+func (workflow *serviceWorkflow) serviceRepoLoader(namespace string, service *common.Service, stackWaiter common.StackWaiter) Executor {
+	return func(ctx context.Context) error {
+		if service.ImageRepository != "" {
+			workflow.serviceImage = service.ImageRepository
+			return nil
+		}
+
+		ecrStackName := common.CreateStackName(namespace, common.StackTypeRepo, workflow.serviceName)
+		stack := stackWaiter.AwaitFinalStatus(ctx, ecrStackName)
+		if stack == nil || stack.Outputs["RepoUrl"] == "" {
+			return fmt.Errorf("Unable to find repo for service '%s', push the service before exporting it", workflow.serviceName)
+		}
+		workflow.serviceRepoURL = stack.Outputs["RepoUrl"]
+		workflow.serviceImage = fmt.Sprintf("%s:%s", stack.Outputs["RepoUrl"], workflow.serviceTag)
+		return nil
+	}
+}
+
+func (workflow *serviceWorkflow) connectKubernetesExporter(exporter *kubernetesResourceExporter) Executor {
+	return func(ctx context.Context) error {
+		exporter.clusterName = workflow.envStack.Name
+		workflow.kubernetesResourceManager = exporter
+		return nil
+	}
+}
+
+func (workflow *serviceWorkflow) serviceExportSecretsWarning(service *common.Service, stackParams map[string]string) Executor {
+	return func(ctx context.Context) error {
+		if len(service.Secrets) > 0 || stackParams["DatabaseName"] != "" {
+			log.Warningf("Secrets of service '%s' are not exported, they need to exist in the cluster or be exported with --include-secrets", workflow.serviceName)
+		}
+		return nil
+	}
+}
+
+func (workflow *serviceWorkflow) serviceExportWriter(exporter *kubernetesResourceExporter, format ServiceExportFormat, outputDir string, multipleServices bool) Executor {
+	return func(ctx context.Context) error {
+		// each service of a mu.yml with multiple services gets its own directory
+		if multipleServices {
+			outputDir = filepath.Join(outputDir, workflow.serviceName)
+		}
+		log.Noticef("Exporting service '%s' as %s to '%s'", workflow.serviceName, format, outputDir)
+
+		switch format {
+		case ServiceExportFormatKustomize:
+			return writeKustomization(outputDir, exporter.resources)
+		case ServiceExportFormatHelm:
+			return writeHelmChart(outputDir, workflow.serviceName, workflow.serviceTag, exporter.resources)
+		}
+		_, err := writeExportedResources(outputDir, exporter.resources, nil)
+		return err
+	}
+}
+
+// kubernetesResourceExporter renders and decorates kubernetes resources the same way they are upserted to a cluster, but keeps them instead of applying them
+type kubernetesResourceExporter struct {
+	clusterName       string
+	extensionsManager common.ExtensionsManager
+	resources         []map[string]interface{}
+}
+
+// UpsertResources renders and decorates the resources of the template
+func (exporter *kubernetesResourceExporter) UpsertResources(templateName string, templateData interface{}) error {
+	templateBody, err := templates.GetAsset(templateName,
+		templates.DecorateTemplate(exporter.extensionsManager, ""),
+		templates.ExecuteTemplate(templateData))
+	if err != nil {
+		return err
+	}
+
+	resources, err := common.ParseKubernetesResources(templateBody)
+	if err != nil {
+		return err
+	}
+	resources, err = exporter.extensionsManager.DecorateKubernetesResources(exporter.clusterName, resources)
+	if err != nil {
+		return err
+	}
+
+	for _, resource := range resources {
+		resourceBody, err := yaml.Marshal(resource)
+		if err != nil {
+			return err
+		}
+
+		// same name for the template overrides in mu.yml as the resources upserted to the cluster
+		resourceURN := fmt.Sprintf("%s-%s-%s", exporter.clusterName, common.MapGetString(resource, "kind"), common.MapGetString(resource, "metadata", "name"))
+		decoratedBody, err := templates.DecorateTemplate(exporter.extensionsManager, resourceURN)("", string(resourceBody))
+		if err != nil {
+			return err
+		}
+
+		decoratedResources, err := common.ParseKubernetesResources(decoratedBody)
+		if err != nil {
+			return err
+		}
+		exporter.resources = append(exporter.resources, decoratedResources...)
+	}
+	return nil
+}
+
+// ListResources returns an empty list, nothing has been applied to the cluster by the exporter
+func (exporter *kubernetesResourceExporter) ListResources(apiVersion string, kind string, namespace string) (*unstructured.UnstructuredList, error) {
+	return &unstructured.UnstructuredList{}, nil
+}
+
+// DeleteResource is not supported by the exporter
+func (exporter *kubernetesResourceExporter) DeleteResource(apiVersion string, kind string, namespace string, name string) error {
+	return fmt.Errorf("Unable to delete resources while exporting")
+}
+
+// exportedResourceFileName is the file name of a resource, unique by kind and name
+func exportedResourceFileName(resource map[string]interface{}) string {
+	return strings.ToLower(fmt.Sprintf("%s-%s.yaml", common.MapGetString(resource, "kind"), common.MapGetString(resource, "metadata", "name")))
+}
+
+// writeExportedResources writes each resource to its own file, the filter can change the content of the file
+func writeExportedResources(outputDir string, resources []map[string]interface{}, filter func(string) string) ([]string, error) {
+	err := os.MkdirAll(outputDir, 0755)
+	if err != nil {
+		return nil, err
+	}
+
+	fileNames := make([]string, 0, len(resources))
+	for _, resource := range resources {
+		resourceBody, err := yaml.Marshal(resource)
+		if err != nil {
+			return nil, err
+		}
+		content := string(resourceBody)
+		if filter != nil {
+			content = filter(content)
+		}
+
+		fileName := exportedResourceFileName(resource)
+		err = ioutil.WriteFile(filepath.Join(outputDir, fileName), []byte(content), 0644)
+		if err != nil {
+			return nil, err
+		}
+		log.Debugf("Wrote resource '%s' to '%s'", fileName, outputDir)
+		fileNames = append(fileNames, fileName)
+	}
+	return fileNames, nil
+}
+
+func writeExportYaml(fileName string, value interface{}) error {
+	body, err := yaml.Marshal(value)
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(fileName, body, 0644)
+}
+
+// writeKustomization writes the resources with a kustomization.yaml that lists them
+func writeKustomization(outputDir string, resources []map[string]interface{}) error {
+	fileNames, err := writeExportedResources(outputDir, resources, nil)
+	if err != nil {
+		return err
+	}
+
+	return writeExportYaml(filepath.Join(outputDir, "kustomization.yaml"), map[string]interface{}{
+		"apiVersion": "kustomize.config.k8s.io/v1beta1",
+		"kind":       "Kustomization",
+		"resources":  fileNames,
+	})
+}
+
+// helmValuePlaceholder marks a value of a resource that is replaced with a reference to the values of the chart
+func helmValuePlaceholder(name string) string {
+	return fmt.Sprintf("__MU_HELM_VALUE_%s__", name)
+}
+
+// writeHelmChart writes the resources as the templates of a chart, with the image and replicas of the service as values
+func writeHelmChart(outputDir string, serviceName string, serviceTag string, resources []map[string]interface{}) error {
+	values := make(map[string]interface{})
+	for _, resource := range resources {
+		if common.MapGetString(resource, "kind") != "Deployment" || common.MapGetString(resource, "metadata", "name") != fmt.Sprintf("%s-deployment", serviceName) {
+			continue
+		}
+		if spec, ok := resource["spec"].(map[string]interface{}); ok {
+			values["replicaCount"] = spec["replicas"]
+			spec["replicas"] = helmValuePlaceholder("replicaCount")
+		}
+		for _, container := range common.MapGetSlice(resource, "spec", "template", "spec", "containers") {
+			if container, ok := container.(map[string]interface{}); ok && container["name"] == serviceName {
+				values["image"] = container["image"]
+				container["image"] = helmValuePlaceholder("image")
+			}
+		}
+	}
+
+	// rendered values that look like helm templates need to be escaped
+	escaper := strings.NewReplacer("{{", `{{ "{{" }}`, "}}", `{{ "}}" }}`)
+	filter := func(content string) string {
+		content = escaper.Replace(content)
+		for name := range values {
+			content = strings.Replace(content, helmValuePlaceholder(name), fmt.Sprintf("{{ .Values.%s }}", name), -1)
+		}
+		return content
+	}
+	_, err := writeExportedResources(filepath.Join(outputDir, "templates"), resources, filter)
+	if err != nil {
+		return err
+	}
+
+	err = writeExportYaml(filepath.Join(outputDir, "values.yaml"), values)
+	if err != nil {
+		return err
+	}
+	return writeExportYaml(filepath.Join(outputDir, "Chart.yaml"), map[string]interface{}{
+		"apiVersion":  "v1",
+		"name":        serviceName,
+		"description": fmt.Sprintf("Kubernetes resources of the mu service '%s'", serviceName),
+		"version":     "0.1.0",
+		"appVersion":  serviceTag,
+	})
+}
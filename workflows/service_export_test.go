@@ -0,0 +1,168 @@
+package workflows
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/yaml.v2"
+)
+
+func exportedTestResources() []map[string]interface{} {
+	return []map[string]interface{}{
+		{
+			"apiVersion": "apps/v1",
+			"kind":       "Deployment",
+			"metadata": map[string]interface{}{
+				"name": "api-deployment",
+			},
+			"spec": map[string]interface{}{
+				"replicas": 2,
+				"template": map[string]interface{}{
+					"spec": map[string]interface{}{
+						"containers": []interface{}{
+							map[string]interface{}{
+								"name":  "api",
+								"image": "1234.dkr.ecr.us-east-1.amazonaws.com/mu-api:abc123",
+								"args":  []interface{}{"--greeting={{name}}"},
+							},
+							map[string]interface{}{
+								"name":  "envoy",
+								"image": "envoyproxy/envoy:v1.8.0",
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			"apiVersion": "v1",
+			"kind":       "Service",
+			"metadata": map[string]interface{}{
+				"name": "api",
+			},
+		},
+	}
+}
+
+func readExportedYaml(t *testing.T, fileName string) map[string]interface{} {
+	body, err := ioutil.ReadFile(fileName)
+	assert.Nil(t, err)
+	value := make(map[string]interface{})
+	assert.Nil(t, yaml.Unmarshal(body, &value))
+	return value
+}
+
+func TestServiceExportValidator(t *testing.T) {
+	assert := assert.New(t)
+
+	workflow := new(serviceWorkflow)
+	assert.Nil(workflow.serviceExportValidator(ServiceExportFormatRaw, "out")(context.Background()))
+	assert.Nil(workflow.serviceExportValidator(ServiceExportFormatKustomize, "out")(context.Background()))
+	assert.Nil(workflow.serviceExportValidator(ServiceExportFormatHelm, "out")(context.Background()))
+	assert.NotNil(workflow.serviceExportValidator("jsonnet", "out")(context.Background()))
+	assert.NotNil(workflow.serviceExportValidator(ServiceExportFormatRaw, "")(context.Background()))
+}
+
+func TestServiceExportWriter_Raw(t *testing.T) {
+	assert := assert.New(t)
+
+	dir, err := ioutil.TempDir("", "mu-export")
+	assert.Nil(err)
+	defer os.RemoveAll(dir)
+
+	workflow := new(serviceWorkflow)
+	workflow.serviceName = "api"
+	exporter := &kubernetesResourceExporter{resources: exportedTestResources()}
+
+	err = workflow.serviceExportWriter(exporter, ServiceExportFormatRaw, dir, false)(context.Background())
+	assert.Nil(err)
+
+	files, err := ioutil.ReadDir(dir)
+	assert.Nil(err)
+	assert.Equal(2, len(files))
+	deployment := readExportedYaml(t, filepath.Join(dir, "deployment-api-deployment.yaml"))
+	assert.Equal("Deployment", deployment["kind"])
+	service := readExportedYaml(t, filepath.Join(dir, "service-api.yaml"))
+	assert.Equal("Service", service["kind"])
+
+	// each service of a mu.yml with multiple services is written to its own directory
+	err = workflow.serviceExportWriter(exporter, ServiceExportFormatRaw, dir, true)(context.Background())
+	assert.Nil(err)
+	_, err = os.Stat(filepath.Join(dir, "api", "service-api.yaml"))
+	assert.Nil(err)
+}
+
+func TestServiceExportWriter_Kustomize(t *testing.T) {
+	assert := assert.New(t)
+
+	dir, err := ioutil.TempDir("", "mu-export")
+	assert.Nil(err)
+	defer os.RemoveAll(dir)
+
+	workflow := new(serviceWorkflow)
+	workflow.serviceName = "api"
+	exporter := &kubernetesResourceExporter{resources: exportedTestResources()}
+
+	err = workflow.serviceExportWriter(exporter, ServiceExportFormatKustomize, dir, false)(context.Background())
+	assert.Nil(err)
+
+	kustomization := readExportedYaml(t, filepath.Join(dir, "kustomization.yaml"))
+	assert.Equal("Kustomization", kustomization["kind"])
+	assert.Equal([]interface{}{"deployment-api-deployment.yaml", "service-api.yaml"}, kustomization["resources"])
+	for _, fileName := range kustomization["resources"].([]interface{}) {
+		_, err = os.Stat(filepath.Join(dir, fileName.(string)))
+		assert.Nil(err)
+	}
+}
+
+func TestServiceExportWriter_Helm(t *testing.T) {
+	assert := assert.New(t)
+
+	dir, err := ioutil.TempDir("", "mu-export")
+	assert.Nil(err)
+	defer os.RemoveAll(dir)
+
+	workflow := new(serviceWorkflow)
+	workflow.serviceName = "api"
+	workflow.serviceTag = "abc123"
+	exporter := &kubernetesResourceExporter{resources: exportedTestResources()}
+
+	err = workflow.serviceExportWriter(exporter, ServiceExportFormatHelm, dir, false)(context.Background())
+	assert.Nil(err)
+
+	chart := readExportedYaml(t, filepath.Join(dir, "Chart.yaml"))
+	assert.Equal("api", chart["name"])
+	assert.Equal("abc123", chart["appVersion"])
+
+	values := readExportedYaml(t, filepath.Join(dir, "values.yaml"))
+	assert.Equal("1234.dkr.ecr.us-east-1.amazonaws.com/mu-api:abc123", values["image"])
+	assert.Equal(2, values["replicaCount"])
+
+	deployment, err := ioutil.ReadFile(filepath.Join(dir, "templates", "deployment-api-deployment.yaml"))
+	assert.Nil(err)
+	assert.Contains(string(deployment), "image: {{ .Values.image }}")
+	assert.Contains(string(deployment), "replicas: {{ .Values.replicaCount }}")
+	// only the image of the service container is a value
+	assert.Contains(string(deployment), "image: envoyproxy/envoy:v1.8.0")
+	// values that look like helm templates are escaped
+	assert.Contains(string(deployment), `--greeting={{ "{{" }}name{{ "}}" }}`)
+
+	_, err = os.Stat(filepath.Join(dir, "templates", "service-api.yaml"))
+	assert.Nil(err)
+}
+
+func TestKubernetesResourceExporter(t *testing.T) {
+	assert := assert.New(t)
+
+	exporter := &kubernetesResourceExporter{resources: exportedTestResources()}
+	list, err := exporter.ListResources("apps/v1", "Deployment", "mu-service-api")
+	assert.Nil(err)
+	assert.Empty(list.Items)
+	assert.NotNil(exporter.DeleteResource("apps/v1", "Deployment", "mu-service-api", "api-deployment"))
+
+	assert.Equal("deployment-api-deployment.yaml", exportedResourceFileName(exporter.resources[0]))
+}
@@ -2,6 +2,9 @@ package workflows
 
 import (
 	"archive/zip"
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
 	"fmt"
 	"io"
 	"io/ioutil"
@@ -12,8 +15,9 @@ import (
 	"github.com/stelligent/mu/common"
 )
 
-// NewServicePusher create a new workflow for pushing a service to a repo
-func NewServicePusher(ctx *common.Context, tag string, provider string, kmsKey string, dockerWriter io.Writer) Executor {
+// NewServicePusher create a new workflow for pushing a service to a repo.  With changedOnly, an image that was
+// already built from the same source is tagged rather than rebuilt.
+func NewServicePusher(ctx *common.Context, tag string, provider string, kmsKey string, changedOnly bool, dockerWriter io.Writer) Executor {
 
 	workflow := new(serviceWorkflow)
 
@@ -22,9 +26,13 @@ func NewServicePusher(ctx *common.Context, tag string, provider string, kmsKey s
 		newConditionalExecutor(workflow.isEcrProvider(),
 			newPipelineExecutor(
 				workflow.serviceRepoUpserter(ctx.Config.Namespace, &ctx.Config.Service, ctx.StackManager, ctx.StackManager),
-				workflow.serviceImageBuilder(ctx.DockerManager, &ctx.Config, dockerWriter),
-				workflow.serviceRegistryAuthenticator(ctx.ClusterManager),
-				workflow.serviceImagePusher(ctx.DockerManager, dockerWriter),
+				workflow.serviceImageRetagger(ctx.Config.Basedir, &ctx.Config.Service, changedOnly, ctx.ClusterManager),
+				newConditionalExecutor(workflow.isImageRetagged(), nil,
+					newPipelineExecutor(
+						workflow.serviceImageBuilder(ctx.DockerManager, &ctx.Config, dockerWriter),
+						workflow.serviceRegistryAuthenticator(ctx.ClusterManager),
+						workflow.serviceImagePusher(ctx.DockerManager, dockerWriter),
+					)),
 			),
 			newPipelineExecutor(
 				workflow.serviceBucketUpserter(ctx.Config.Namespace, &ctx.Config.Service, ctx.StackManager, ctx.StackManager),
@@ -33,22 +41,72 @@ func NewServicePusher(ctx *common.Context, tag string, provider string, kmsKey s
 
 }
 
+// serviceImageRetagger tags the image built from the same source as the service tag, if there is one.  The source is
+// the build context in basedir, plus the watchPaths of the service for files that the build depends on outside of it.
+func (workflow *serviceWorkflow) serviceImageRetagger(basedir string, service *common.Service, changedOnly bool, imageTagger common.RepositoryImageTagger) Executor {
+	return func(ctx context.Context) error {
+		if workflow.serviceRepoName == "" {
+			// images in repos not managed by mu are always rebuilt
+			return nil
+		}
+
+		sourceHash, err := hashDir(basedir, service.WatchPaths...)
+		if err != nil {
+			return err
+		}
+		workflow.serviceSourceTag = fmt.Sprintf("src-%s", sourceHash)
+		if !changedOnly {
+			return nil
+		}
+
+		workflow.imageRetagged, err = imageTagger.TagRepositoryImage(workflow.serviceRepoName, workflow.serviceSourceTag, workflow.serviceTag)
+		if err != nil {
+			return err
+		}
+		if workflow.imageRetagged {
+			log.Noticef("Service '%s' is unchanged, tagged existing image as '%s'", workflow.serviceName, workflow.serviceImage)
+		}
+		return nil
+	}
+}
+
+func (workflow *serviceWorkflow) isImageRetagged() Conditional {
+	return func() bool {
+		return workflow.imageRetagged
+	}
+}
+
+// serviceImages are the service image, plus the image tagged with the source hash
+func (workflow *serviceWorkflow) serviceImages() []string {
+	images := []string{workflow.serviceImage}
+	if workflow.serviceSourceTag != "" {
+		images = append(images, fmt.Sprintf("%s:%s", workflow.serviceRepoURL, workflow.serviceSourceTag))
+	}
+	return images
+}
+
 func (workflow *serviceWorkflow) serviceImageBuilder(imageBuilder common.DockerImageBuilder, config *common.Config, dockerWriter io.Writer) Executor {
-	return func() error {
+	return func(ctx context.Context) error {
 		log.Noticef("Building service:'%s' as image:%s'", workflow.serviceName, workflow.serviceImage)
-		return imageBuilder.ImageBuild(config.Basedir, workflow.serviceName, config.Service.Dockerfile, []string{workflow.serviceImage}, dockerWriter)
+		return imageBuilder.ImageBuild(config.Basedir, workflow.serviceName, config.Service.Dockerfile, workflow.serviceImages(), dockerWriter)
 	}
 }
 
 func (workflow *serviceWorkflow) serviceImagePusher(imagePusher common.DockerImagePusher, dockerWriter io.Writer) Executor {
-	return func() error {
-		log.Noticef("Pushing service '%s' to '%s'", workflow.serviceName, workflow.serviceImage)
-		return imagePusher.ImagePush(workflow.serviceImage, workflow.registryAuth, dockerWriter)
+	return func(ctx context.Context) error {
+		for _, image := range workflow.serviceImages() {
+			log.Noticef("Pushing service '%s' to '%s'", workflow.serviceName, image)
+			err := imagePusher.ImagePush(image, workflow.registryAuth, dockerWriter)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
 	}
 }
 
 func (workflow *serviceWorkflow) serviceArchiveUploader(basedir string, artifactCreator common.ArtifactCreator, kmsKey string) Executor {
-	return func() error {
+	return func(ctx context.Context) error {
 		destURL := fmt.Sprintf("s3://%s/%s", workflow.appRevisionBucket, workflow.appRevisionKey)
 		log.Noticef("Pushing archive '%s' to '%s'", basedir, destURL)
 
@@ -67,6 +125,44 @@ func (workflow *serviceWorkflow) serviceArchiveUploader(basedir string, artifact
 	}
 }
 
+// hashDir returns a sha1 of the names and contents of the files in basedir and the watch paths relative to it,
+// ignoring .git directories
+func hashDir(basedir string, watchPaths ...string) (string, error) {
+	hash := sha1.New()
+	walkFn := func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() && info.Name() == ".git" {
+			return filepath.SkipDir
+		}
+		if !info.Mode().IsRegular() {
+			return nil
+		}
+
+		relPath, err := filepath.Rel(basedir, path)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(hash, "%s\x00", filepath.ToSlash(relPath))
+
+		file, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		_, err = io.Copy(hash, file)
+		return err
+	}
+
+	for _, root := range append([]string{"."}, watchPaths...) {
+		if err := filepath.Walk(filepath.Join(basedir, root), walkFn); err != nil {
+			return "", err
+		}
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
 func zipDir(basedir string) (*os.File, error) {
 	zipfile, err := ioutil.TempFile("", "artifact")
 	if err != nil {
@@ -1,18 +1,21 @@
 package workflows
 
 import (
+	"context"
 	"github.com/stelligent/mu/common"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"io"
+	"io/ioutil"
 	"os"
+	"path/filepath"
 	"testing"
 )
 
 func TestNewServicePusher(t *testing.T) {
 	assert := assert.New(t)
 	ctx := common.NewContext()
-	upserter := NewServicePusher(ctx, "foo", "", "", os.Stdout)
+	upserter := NewServicePusher(ctx, "foo", "", "", false, os.Stdout)
 	assert.NotNil(upserter)
 }
 
@@ -45,7 +48,7 @@ func TestServiceBuilder(t *testing.T) {
 	config := new(common.Config)
 
 	workflow := new(serviceWorkflow)
-	err := workflow.serviceImageBuilder(builder, config, os.Stdout)()
+	err := workflow.serviceImageBuilder(builder, config, os.Stdout)(context.Background())
 	assert.Nil(err)
 
 	builder.AssertExpectations(t)
@@ -60,9 +63,98 @@ func TestServicePusher(t *testing.T) {
 	pusher.On("ImagePush").Return(nil)
 
 	workflow := new(serviceWorkflow)
-	err := workflow.serviceImagePusher(pusher, os.Stdout)()
+	err := workflow.serviceImagePusher(pusher, os.Stdout)(context.Background())
 	assert.Nil(err)
 
 	pusher.AssertExpectations(t)
 	pusher.AssertNumberOfCalls(t, "ImagePush", 1)
 }
+
+type mockRepositoryImageTagger struct {
+	mock.Mock
+	common.RepositoryImageTagger
+}
+
+func (m *mockRepositoryImageTagger) TagRepositoryImage(repoName string, existingTag string, newTag string) (bool, error) {
+	args := m.Called(repoName, existingTag, newTag)
+	return args.Bool(0), args.Error(1)
+}
+
+func TestServiceImageRetagger(t *testing.T) {
+	assert := assert.New(t)
+
+	basedir, err := ioutil.TempDir("", "mu-push")
+	assert.Nil(err)
+	defer os.RemoveAll(basedir)
+	assert.Nil(ioutil.WriteFile(filepath.Join(basedir, "Dockerfile"), []byte("FROM scratch"), 0644))
+
+	sourceHash, err := hashDir(basedir)
+	assert.Nil(err)
+	expectedSourceTag := "src-" + sourceHash
+
+	tagger := new(mockRepositoryImageTagger)
+	tagger.On("TagRepositoryImage", "mu-foo", expectedSourceTag, "abc123").Return(true, nil)
+
+	workflow := new(serviceWorkflow)
+	workflow.serviceRepoName = "mu-foo"
+	workflow.serviceRepoURL = "1234.dkr.ecr.us-east-1.amazonaws.com/mu-foo"
+	workflow.serviceTag = "abc123"
+	workflow.serviceImage = "1234.dkr.ecr.us-east-1.amazonaws.com/mu-foo:abc123"
+	err = workflow.serviceImageRetagger(basedir, new(common.Service), true, tagger)(context.Background())
+	assert.Nil(err)
+	assert.True(workflow.imageRetagged)
+	assert.Equal(expectedSourceTag, workflow.serviceSourceTag)
+	assert.Equal([]string{workflow.serviceImage, workflow.serviceRepoURL + ":" + workflow.serviceSourceTag}, workflow.serviceImages())
+	tagger.AssertExpectations(t)
+	tagger.AssertNumberOfCalls(t, "TagRepositoryImage", 1)
+
+	// a different source has a different tag
+	sourceTag := workflow.serviceSourceTag
+	assert.Nil(ioutil.WriteFile(filepath.Join(basedir, "Dockerfile"), []byte("FROM alpine"), 0644))
+	err = workflow.serviceImageRetagger(basedir, new(common.Service), false, tagger)(context.Background())
+	assert.Nil(err)
+	assert.NotEqual(sourceTag, workflow.serviceSourceTag)
+	tagger.AssertNumberOfCalls(t, "TagRepositoryImage", 1)
+}
+
+func TestHashDir(t *testing.T) {
+	assert := assert.New(t)
+
+	basedir, err := ioutil.TempDir("", "mu-hash")
+	assert.Nil(err)
+	defer os.RemoveAll(basedir)
+	assert.Nil(ioutil.WriteFile(filepath.Join(basedir, "app.py"), []byte("print('hi')"), 0644))
+
+	hash, err := hashDir(basedir)
+	assert.Nil(err)
+
+	// changes in .git are ignored
+	assert.Nil(os.Mkdir(filepath.Join(basedir, ".git"), 0755))
+	assert.Nil(ioutil.WriteFile(filepath.Join(basedir, ".git", "HEAD"), []byte("ref"), 0644))
+	gitHash, err := hashDir(basedir)
+	assert.Nil(err)
+	assert.Equal(hash, gitHash)
+
+	assert.Nil(ioutil.WriteFile(filepath.Join(basedir, "app.py"), []byte("print('bye')"), 0644))
+	changedHash, err := hashDir(basedir)
+	assert.Nil(err)
+	assert.NotEqual(hash, changedHash)
+
+	// changes in watch paths outside of basedir are included
+	shared := filepath.Join(filepath.Dir(basedir), filepath.Base(basedir)+"-shared")
+	assert.Nil(os.Mkdir(shared, 0755))
+	defer os.RemoveAll(shared)
+	assert.Nil(ioutil.WriteFile(filepath.Join(shared, "lib.py"), []byte("x = 1"), 0644))
+	watchPath := filepath.Join("..", filepath.Base(shared))
+	watchHash, err := hashDir(basedir, watchPath)
+	assert.Nil(err)
+	assert.NotEqual(changedHash, watchHash)
+
+	assert.Nil(ioutil.WriteFile(filepath.Join(shared, "lib.py"), []byte("x = 2"), 0644))
+	changedWatchHash, err := hashDir(basedir, watchPath)
+	assert.Nil(err)
+	assert.NotEqual(watchHash, changedWatchHash)
+
+	_, err = hashDir(basedir, "missing")
+	assert.NotNil(err)
+}
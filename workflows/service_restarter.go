@@ -1,7 +1,7 @@
 package workflows
 
 import (
-	"fmt"
+	"context"
 	"time"
 
 	"github.com/stelligent/mu/common"
@@ -19,7 +19,7 @@ func NewServiceRestarter(ctx *common.Context, environmentName string, serviceNam
 }
 
 func (workflow *serviceWorkflow) serviceRestarter(namespace string, taskManager common.TaskManager, environmentName string, batchSize int) Executor {
-	return func() error {
+	return func(ctx context.Context) error {
 		tasks, err := taskManager.ListTasks(namespace, environmentName, workflow.serviceName)
 
 		log.Noticef("Found %v tasks for service %s in environment %s", len(tasks), workflow.serviceName, environmentName)
@@ -28,19 +28,25 @@ func (workflow *serviceWorkflow) serviceRestarter(namespace string, taskManager
 			return err
 		}
 
+		taskStatuses := make(map[string]string)
 		for taskIdx, task := range tasks {
 			log.Noticef("Restarting task %s in environment %s", task.Name, environmentName)
 			stopErr := taskManager.StopTask(namespace, environmentName, task.Name)
 			if stopErr != nil {
-				fmt.Println(stopErr)
+				log.Warningf("Unable to stop task %s: %v", task.Name, stopErr)
+			} else {
+				taskStatuses[task.Name] = "STOPPING"
+				emitTaskEvent(environmentName, workflow.serviceName, task.Name, "STOPPING")
 			}
 
 			// Polling for same length task lists
 			if (taskIdx+1)%batchSize == 0 {
 
-				for countRunningTasks(namespace, taskManager, environmentName, workflow.serviceName) != len(tasks) {
+				for countRunningTasks(namespace, taskManager, environmentName, workflow.serviceName, taskStatuses) != len(tasks) {
 					duration := time.Duration(PollDelay) * time.Second
-					time.Sleep(duration)
+					if err := common.SleepWithContext(ctx, duration); err != nil {
+						return err
+					}
 				}
 			}
 		}
@@ -49,14 +55,29 @@ func (workflow *serviceWorkflow) serviceRestarter(namespace string, taskManager
 	}
 }
 
-func countRunningTasks(namespace string, taskManager common.TaskManager, environmentName string, serviceName string) int {
+// countRunningTasks counts the running tasks of the service, emitting events for the tasks whose status changed
+func countRunningTasks(namespace string, taskManager common.TaskManager, environmentName string, serviceName string, taskStatuses map[string]string) int {
 	newTaskList, _ := taskManager.ListTasks(namespace, environmentName, serviceName)
 	runningCount := 0
 	for _, newTask := range newTaskList {
 		if newTask.Status == "RUNNING" {
 			runningCount++
 		}
+		if taskStatuses[newTask.Name] != newTask.Status {
+			taskStatuses[newTask.Name] = newTask.Status
+			emitTaskEvent(environmentName, serviceName, newTask.Name, newTask.Status)
+		}
 	}
 	log.Debugf("Environment: %s, Service: %s, Running Tasks: %v", environmentName, serviceName, runningCount)
 	return runningCount
 }
+
+func emitTaskEvent(environmentName string, serviceName string, taskName string, status string) {
+	common.EmitEvent(common.Event{
+		Type:        common.EventTypeTask,
+		Environment: environmentName,
+		Service:     serviceName,
+		Task:        taskName,
+		Status:      status,
+	})
+}
@@ -0,0 +1,212 @@
+package workflows
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/stelligent/mu/common"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// NewServiceRollbacker create a new workflow for redeploying a previous revision of a service in an environment
+func NewServiceRollbacker(ctx *common.Context, environmentName string, revision string, listOnly bool, format string, writer io.Writer) Executor {
+
+	workflow := new(serviceWorkflow)
+	revisions := make([]common.ServiceRevision, 0)
+	var target *common.ServiceRevision
+
+	revisionSelector := newPipelineExecutor(
+		workflow.serviceLoader(ctx, "", ""),
+		workflow.serviceEnvironmentLoader(ctx.Config.Namespace, environmentName, ctx.StackManager),
+		newConditionalExecutor(workflow.isEksProvider(),
+			newPipelineExecutor(
+				workflow.connectKubernetes(ctx.KubernetesResourceManagerProvider),
+				workflow.serviceEksRevisionLister(&revisions),
+			),
+			workflow.serviceRevisionLister(ctx.Config.Namespace, environmentName, ctx.TaskManager, &revisions)),
+		workflow.serviceRevisionPrinter(environmentName, format, writer, &revisions),
+		newConditionalExecutor(func() bool { return listOnly },
+			nil,
+			workflow.serviceRevisionSelector(environmentName, revision, &revisions, &target)),
+	)
+
+	return func(runCtx context.Context) error {
+		err := revisionSelector(runCtx)
+		if err != nil || target == nil {
+			return err
+		}
+		return newServiceRevisionDeployer(ctx, environmentName, target)(runCtx)
+	}
+}
+
+// newServiceRevisionDeployer deploys the image of a revision through the normal deploy workflow
+func newServiceRevisionDeployer(ctx *common.Context, environmentName string, target *common.ServiceRevision) Executor {
+	return func(runCtx context.Context) error {
+		// the image repository is used as is, so it needs to point to the image of the revision
+		if ctx.Config.Service.ImageRepository != "" {
+			imageRepository := ctx.Config.Service.ImageRepository
+			defer func() {
+				ctx.Config.Service.ImageRepository = imageRepository
+			}()
+			ctx.Config.Service.ImageRepository = target.ImageURL
+		}
+
+		return NewServiceDeployer(ctx, environmentName, common.ImageTag(target.ImageURL))(runCtx)
+	}
+}
+
+func (workflow *serviceWorkflow) serviceRevisionLister(namespace string, environmentName string, revisionLister common.ServiceRevisionLister, revisions *[]common.ServiceRevision) Executor {
+	return func(ctx context.Context) error {
+		if workflow.isEc2Provider()() {
+			return fmt.Errorf("Rollback is not supported for service '%s' in EC2 environment '%s', use 'svc deploy -t <tag>' instead", workflow.serviceName, environmentName)
+		}
+
+		serviceRevisions, err := revisionLister.ListServiceRevisions(namespace, environmentName, workflow.serviceName)
+		if err != nil {
+			return err
+		}
+		*revisions = serviceRevisions
+		return nil
+	}
+}
+
+func (workflow *serviceWorkflow) serviceEksRevisionLister(revisions *[]common.ServiceRevision) Executor {
+	return func(ctx context.Context) error {
+		replicaSets, err := workflow.kubernetesResourceManager.ListResources("apps/v1", "ReplicaSet", fmt.Sprintf("mu-service-%s", workflow.serviceName))
+		if err != nil {
+			return err
+		}
+		*revisions = eksServiceRevisions(workflow.serviceName, replicaSets)
+		return nil
+	}
+}
+
+// eksServiceRevisions finds the revisions of a service from the replica sets its deployment has created, most recent first
+func eksServiceRevisions(serviceName string, replicaSets *unstructured.UnstructuredList) []common.ServiceRevision {
+	revisions := make([]common.ServiceRevision, 0)
+	for _, replicaSet := range replicaSets.Items {
+		if !strings.HasPrefix(common.MapGetString(replicaSet.Object, "metadata", "name"), fmt.Sprintf("%s-deployment-", serviceName)) {
+			continue
+		}
+
+		var imageURL string
+		for _, container := range common.MapGetSlice(replicaSet.Object, "spec", "template", "spec", "containers") {
+			if common.MapGetString(container, "name") == serviceName {
+				imageURL = common.MapGetString(container, "image")
+			}
+		}
+		if imageURL == "" {
+			continue
+		}
+
+		deployedAt, _ := time.Parse(time.RFC3339, common.MapGetString(replicaSet.Object, "metadata", "creationTimestamp"))
+		revisions = append(revisions, common.ServiceRevision{
+			Revision:   common.NewStringIfNotEmpty(common.ImageTag(imageURL), common.MapGetString(replicaSet.Object, "metadata", "annotations", "mu/revision")),
+			ImageURL:   imageURL,
+			DeployedAt: deployedAt,
+		})
+	}
+
+	sort.SliceStable(revisions, func(i, j int) bool {
+		return revisions[i].DeployedAt.After(revisions[j].DeployedAt)
+	})
+	return revisions
+}
+
+// revisionView is a revision of a service in an environment, the first revision is the current one
+type revisionView struct {
+	Environment string    `json:"environment" yaml:"environment"`
+	Service     string    `json:"service" yaml:"service"`
+	Revision    string    `json:"revision" yaml:"revision"`
+	ImageURL    string    `json:"imageUrl" yaml:"imageUrl"`
+	DeployedAt  time.Time `json:"deployedAt" yaml:"deployedAt"`
+	Current     bool      `json:"current" yaml:"current"`
+}
+
+func (workflow *serviceWorkflow) serviceRevisionPrinter(environmentName string, format string, writer io.Writer, revisions *[]common.ServiceRevision) Executor {
+	return func(ctx context.Context) error {
+		views := make([]*revisionView, 0, len(*revisions))
+		for i, revision := range *revisions {
+			views = append(views, &revisionView{
+				Environment: environmentName,
+				Service:     workflow.serviceName,
+				Revision:    revision.Revision,
+				ImageURL:    revision.ImageURL,
+				DeployedAt:  revision.DeployedAt,
+				Current:     i == 0,
+			})
+		}
+
+		records := func() [][]string {
+			rows := [][]string{{"environment", "service", "revision", "imageUrl", "deployedAt", "current"}}
+			for _, view := range views {
+				rows = append(rows, []string{
+					view.Environment,
+					view.Service,
+					view.Revision,
+					view.ImageURL,
+					formatViewTime(view.DeployedAt),
+					fmt.Sprintf(BoolStringFormat, view.Current),
+				})
+			}
+			return rows
+		}
+
+		renderTable := func() {
+			table := CreateTableSection(writer, SvcRevisionTableHeader)
+			for _, view := range views {
+				name := view.Revision
+				if view.Current {
+					name = Bold(fmt.Sprintf("%s (current)", name))
+				}
+				table.Append([]string{
+					name,
+					view.ImageURL,
+					view.DeployedAt.Local().Format(LastUpdateTime),
+				})
+			}
+			table.Render()
+		}
+
+		return writeView(writer, format, views, records, renderTable)
+	}
+}
+
+func (workflow *serviceWorkflow) serviceRevisionSelector(environmentName string, revision string, revisions *[]common.ServiceRevision, target **common.ServiceRevision) Executor {
+	return func(ctx context.Context) error {
+		selected, err := selectServiceRevision(*revisions, revision)
+		if err != nil {
+			return fmt.Errorf("Unable to rollback service '%s' in environment '%s': %v", workflow.serviceName, environmentName, err)
+		}
+
+		log.Noticef("Rolling back service '%s' in environment '%s' to revision '%s' from '%s'", workflow.serviceName, environmentName, selected.Revision, selected.ImageURL)
+		*target = selected
+		return nil
+	}
+}
+
+// selectServiceRevision finds the revision to rollback to, which defaults to the last revision with a different image than the current one
+func selectServiceRevision(revisions []common.ServiceRevision, revision string) (*common.ServiceRevision, error) {
+	if len(revisions) == 0 {
+		return nil, fmt.Errorf("no revisions found")
+	}
+
+	for i, r := range revisions {
+		if revision != "" {
+			if r.Revision == revision || common.ImageTag(r.ImageURL) == revision {
+				return &revisions[i], nil
+			}
+		} else if r.ImageURL != revisions[0].ImageURL {
+			return &revisions[i], nil
+		}
+	}
+
+	if revision != "" {
+		return nil, fmt.Errorf("revision '%s' not found", revision)
+	}
+	return nil, fmt.Errorf("no previous revision found")
+}
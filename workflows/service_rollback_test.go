@@ -0,0 +1,76 @@
+package workflows
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stelligent/mu/common"
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestSelectServiceRevision(t *testing.T) {
+	assert := assert.New(t)
+
+	revisions := []common.ServiceRevision{
+		{Revision: "ghi789", ImageURL: "foo:ghi789"},
+		{Revision: "ghi789", ImageURL: "foo:ghi789"},
+		{Revision: "def456", ImageURL: "foo:def456"},
+		{Revision: "abc123", ImageURL: "foo:abc123"},
+	}
+
+	revision, err := selectServiceRevision(revisions, "")
+	assert.Nil(err)
+	assert.Equal("foo:def456", revision.ImageURL)
+
+	revision, err = selectServiceRevision(revisions, "abc123")
+	assert.Nil(err)
+	assert.Equal("foo:abc123", revision.ImageURL)
+
+	_, err = selectServiceRevision(revisions, "xyz000")
+	assert.NotNil(err)
+
+	_, err = selectServiceRevision(revisions[:2], "")
+	assert.NotNil(err)
+
+	_, err = selectServiceRevision([]common.ServiceRevision{}, "")
+	assert.NotNil(err)
+}
+
+func TestEksServiceRevisions(t *testing.T) {
+	assert := assert.New(t)
+
+	replicaSet := func(name string, revision string, image string, created string) unstructured.Unstructured {
+		return unstructured.Unstructured{Object: map[string]interface{}{
+			"metadata": map[string]interface{}{
+				"name":              name,
+				"creationTimestamp": created,
+				"annotations": map[string]interface{}{
+					"mu/revision": revision,
+				},
+			},
+			"spec": map[string]interface{}{
+				"template": map[string]interface{}{
+					"spec": map[string]interface{}{
+						"containers": []interface{}{
+							map[string]interface{}{"name": "foo", "image": image},
+						},
+					},
+				},
+			},
+		}}
+	}
+
+	revisions := eksServiceRevisions("foo", &unstructured.UnstructuredList{
+		Items: []unstructured.Unstructured{
+			replicaSet("foo-deployment-1111", "abc123", "repo/foo:abc123", "2018-09-01T12:00:00Z"),
+			replicaSet("foo-deployment-2222", "", "repo/foo:def456", "2018-09-02T12:00:00Z"),
+			replicaSet("bar-deployment-3333", "ghi789", "repo/bar:ghi789", "2018-09-03T12:00:00Z"),
+		},
+	})
+
+	assert.Equal([]common.ServiceRevision{
+		{Revision: "def456", ImageURL: "repo/foo:def456", DeployedAt: time.Date(2018, 9, 2, 12, 0, 0, 0, time.UTC)},
+		{Revision: "abc123", ImageURL: "repo/foo:abc123", DeployedAt: time.Date(2018, 9, 1, 12, 0, 0, 0, time.UTC)},
+	}, revisions)
+}
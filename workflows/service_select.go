@@ -0,0 +1,87 @@
+package workflows
+
+import (
+	"context"
+	"path/filepath"
+
+	"github.com/stelligent/mu/common"
+)
+
+// NewSelectedServicesExecutor runs the workflow created by newExecutor for each service in mu.yml matching the selector
+func NewSelectedServicesExecutor(ctx *common.Context, selector string, newExecutor func() Executor) Executor {
+	return func(runCtx context.Context) error {
+		services, err := ctx.Config.SelectServices(selector)
+		if err != nil {
+			log.Errorf("%v", err)
+			return loggedError{err}
+		}
+
+		originalService := ctx.Config.Service
+		originalBasedir := ctx.Config.Basedir
+		defer func() {
+			ctx.Config.Service = originalService
+			ctx.Config.Basedir = originalBasedir
+		}()
+
+		for _, service := range services {
+			ctx.Config.Service = service
+			ctx.Config.Basedir = originalBasedir
+			if service.Path != "" {
+				ctx.Config.Basedir = filepath.Join(originalBasedir, service.Path)
+			}
+
+			if len(services) > 1 {
+				log.Noticef("Selected service '%s'", service.Name)
+			}
+			err := newExecutor()(runCtx)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+// NewOrderedServicesExecutor runs the workflow created by newExecutor for each service in mu.yml matching the selector,
+// after the services it depends on. Services that don't depend on each other run in parallel, each with its own copy
+// of the context and a deep copy of its service, since the workflows change the service while they run.
+func NewOrderedServicesExecutor(ctx *common.Context, selector string, newExecutor func(ctx *common.Context) Executor) Executor {
+	return func(runCtx context.Context) error {
+		services, err := ctx.Config.SelectServices(selector)
+		if err != nil {
+			log.Errorf("%v", err)
+			return loggedError{err}
+		}
+		groups, err := common.OrderServicesByDependencies(services)
+		if err != nil {
+			log.Errorf("%v", err)
+			return loggedError{err}
+		}
+
+		for _, group := range groups {
+			executors := make([]Executor, len(group))
+			for i, service := range group {
+				serviceCopy, err := service.Copy()
+				if err != nil {
+					return err
+				}
+				serviceCtx := *ctx
+				serviceCtx.Config.Service = *serviceCopy
+				if service.Path != "" {
+					serviceCtx.Config.Basedir = filepath.Join(ctx.Config.Basedir, service.Path)
+				}
+
+				if len(services) > 1 {
+					log.Noticef("Selected service '%s'", service.Name)
+				}
+				executors[i] = newExecutor(&serviceCtx)
+			}
+
+			err := newParallelExecutor(executors...)(runCtx)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
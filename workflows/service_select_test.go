@@ -0,0 +1,135 @@
+package workflows
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/stelligent/mu/common"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewSelectedServicesExecutor(t *testing.T) {
+	assert := assert.New(t)
+
+	ctx := common.NewContext()
+	ctx.Config.Basedir = "/src"
+	ctx.Config.Services = []common.Service{
+		{Name: "api", Path: "api"},
+		{Name: "web", Path: "web"},
+		{Name: "worker"},
+	}
+
+	var names []string
+	var basedirs []string
+	newExecutor := func() Executor {
+		return func(context.Context) error {
+			names = append(names, ctx.Config.Service.Name)
+			basedirs = append(basedirs, ctx.Config.Basedir)
+			return nil
+		}
+	}
+
+	err := NewSelectedServicesExecutor(ctx, "", newExecutor)(context.Background())
+	assert.Nil(err)
+	assert.Equal([]string{"api", "web", "worker"}, names)
+	assert.Equal([]string{"/src/api", "/src/web", "/src"}, basedirs)
+	assert.Equal("", ctx.Config.Service.Name)
+	assert.Equal("/src", ctx.Config.Basedir)
+
+	names = nil
+	basedirs = nil
+	err = NewSelectedServicesExecutor(ctx, "worker,api", newExecutor)(context.Background())
+	assert.Nil(err)
+	assert.Equal([]string{"worker", "api"}, names)
+
+	err = NewSelectedServicesExecutor(ctx, "nope", newExecutor)(context.Background())
+	assert.NotNil(err)
+}
+
+func TestNewSelectedServicesExecutor_Error(t *testing.T) {
+	assert := assert.New(t)
+
+	ctx := common.NewContext()
+	ctx.Config.Services = []common.Service{{Name: "api"}, {Name: "web"}}
+
+	calls := 0
+	err := NewSelectedServicesExecutor(ctx, "", func() Executor {
+		return func(context.Context) error {
+			calls++
+			return errors.New("failed")
+		}
+	})(context.Background())
+	assert.NotNil(err)
+	assert.Equal(1, calls)
+}
+
+func TestNewOrderedServicesExecutor(t *testing.T) {
+	assert := assert.New(t)
+
+	ctx := common.NewContext()
+	ctx.Config.Basedir = "/src"
+	ctx.Config.Services = []common.Service{
+		{Name: "web", Path: "web", DependsOn: []common.ServiceDependency{{Service: "api"}}},
+		{Name: "api", Path: "api", DependsOn: []common.ServiceDependency{{Database: "api"}}},
+		{Name: "worker", DependsOn: []common.ServiceDependency{{Service: "api"}}},
+	}
+
+	var mutex sync.Mutex
+	order := make(map[string]int)
+	basedirs := make(map[string]string)
+	newExecutor := func(serviceCtx *common.Context) Executor {
+		return func(context.Context) error {
+			mutex.Lock()
+			defer mutex.Unlock()
+			order[serviceCtx.Config.Service.Name] = len(order)
+			basedirs[serviceCtx.Config.Service.Name] = serviceCtx.Config.Basedir
+			return nil
+		}
+	}
+
+	err := NewOrderedServicesExecutor(ctx, "", newExecutor)(context.Background())
+	assert.Nil(err)
+	assert.Equal(3, len(order))
+	assert.Equal(0, order["api"])
+	assert.Equal("/src/web", basedirs["web"])
+	assert.Equal("/src/api", basedirs["api"])
+	assert.Equal("/src", basedirs["worker"])
+	assert.Equal("", ctx.Config.Service.Name)
+	assert.Equal("/src", ctx.Config.Basedir)
+
+	// dependencies outside of the selected services are not deployed
+	order = make(map[string]int)
+	err = NewOrderedServicesExecutor(ctx, "web", newExecutor)(context.Background())
+	assert.Nil(err)
+	assert.Equal(map[string]int{"web": 0}, order)
+
+	ctx.Config.Services[1].DependsOn = []common.ServiceDependency{{Service: "web"}}
+	err = NewOrderedServicesExecutor(ctx, "", newExecutor)(context.Background())
+	assert.NotNil(err)
+}
+
+func TestNewOrderedServicesExecutor_Copy(t *testing.T) {
+	assert := assert.New(t)
+
+	ctx := common.NewContext()
+	ctx.Config.Services = []common.Service{
+		{Name: "api", Environment: map[string]interface{}{"LOG_LEVEL": "info"}, PathPatterns: []string{"/api/*"}},
+		{Name: "web", Environment: map[string]interface{}{"LOG_LEVEL": "info"}, PathPatterns: []string{"/*"}},
+	}
+
+	// the workflows resolve the environment and trim the path patterns of the service they deploy
+	err := NewOrderedServicesExecutor(ctx, "", func(serviceCtx *common.Context) Executor {
+		return func(context.Context) error {
+			serviceCtx.Config.Service.Environment["LOG_LEVEL"] = "debug"
+			serviceCtx.Config.Service.PathPatterns[0] = "/"
+			return nil
+		}
+	})(context.Background())
+	assert.Nil(err)
+	for _, service := range ctx.Config.Services {
+		assert.Equal("info", service.Environment["LOG_LEVEL"])
+		assert.NotEqual("/", service.PathPatterns[0])
+	}
+}
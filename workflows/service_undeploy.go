@@ -1,6 +1,7 @@
 package workflows
 
 import (
+	"context"
 	"fmt"
 	"strings"
 
@@ -26,16 +27,16 @@ func NewServiceUndeployer(ctx *common.Context, serviceName string, environmentNa
 }
 
 func (workflow *serviceWorkflow) serviceUndeployer(namespace string, environmentName string, stackDeleter common.StackDeleter, stackWaiter common.StackWaiter) Executor {
-	return func() error {
+	return func(ctx context.Context) error {
 		log.Noticef("Undeploying service '%s' from '%s'", workflow.serviceName, environmentName)
 		svcStackName := common.CreateStackName(namespace, common.StackTypeService, workflow.serviceName, environmentName)
-		svcStack := stackWaiter.AwaitFinalStatus(svcStackName)
+		svcStack := stackWaiter.AwaitFinalStatus(ctx, svcStackName)
 		if svcStack != nil {
 			err := stackDeleter.DeleteStack(svcStackName)
 			if err != nil {
 				return err
 			}
-			svcStack = stackWaiter.AwaitFinalStatus(svcStackName)
+			svcStack = stackWaiter.AwaitFinalStatus(ctx, svcStackName)
 			if svcStack != nil && !strings.HasSuffix(svcStack.Status, "_COMPLETE") {
 				return fmt.Errorf("Ended in failed status %s %s", svcStack.Status, svcStack.StatusReason)
 			}
@@ -48,7 +49,7 @@ func (workflow *serviceWorkflow) serviceUndeployer(namespace string, environment
 }
 
 func (workflow *serviceWorkflow) serviceEksUndeployer(environmentName string) Executor {
-	return func() error {
+	return func(ctx context.Context) error {
 		log.Noticef("Undeploying service '%s' from '%s'", workflow.serviceName, environmentName)
 
 		return workflow.kubernetesResourceManager.DeleteResource("v1", "Namespace", "", fmt.Sprintf("mu-service-%s", workflow.serviceName))
@@ -56,8 +57,8 @@ func (workflow *serviceWorkflow) serviceEksUndeployer(environmentName string) Ex
 }
 
 func (workflow *serviceWorkflow) serviceRolesetTerminator(rolesetDeleter common.RolesetDeleter, environmentName string) Executor {
-	return func() error {
-		err := rolesetDeleter.DeleteServiceRoleset(environmentName, workflow.serviceName)
+	return func(ctx context.Context) error {
+		err := rolesetDeleter.DeleteServiceRoleset(ctx, environmentName, workflow.serviceName)
 		if err != nil {
 			return err
 		}
@@ -1,6 +1,7 @@
 package workflows
 
 import (
+	"context"
 	"github.com/stelligent/mu/common"
 	"github.com/stretchr/testify/assert"
 	"testing"
@@ -24,7 +25,7 @@ func TestServiceUndeployer(t *testing.T) {
 	stackManager.On("AwaitFinalStatus", "mu-service-foo-dev").Return(&common.Stack{Status: common.StackStatusDeleteComplete})
 	stackManager.On("DeleteStack", "mu-service-foo-dev").Return(nil)
 
-	err := workflow.serviceUndeployer("mu", "dev", stackManager, stackManager)()
+	err := workflow.serviceUndeployer("mu", "dev", stackManager, stackManager)(context.Background())
 	assert.Nil(err)
 
 	stackManager.AssertExpectations(t)
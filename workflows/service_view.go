@@ -1,88 +1,233 @@
 package workflows
 
 import (
+	"context"
 	"fmt"
 	"io"
 	"strings"
+	"time"
 
 	"github.com/olekukonko/tablewriter"
 	"github.com/stelligent/mu/common"
 )
 
+// ServiceDetailView representation of a service, its pipeline, deployments and tasks
+type serviceDetailView struct {
+	Name        string               `json:"service" yaml:"service"`
+	PipelineURL string               `json:"pipelineUrl,omitempty" yaml:"pipelineUrl,omitempty"`
+	Stages      []*pipelineStageView `json:"stages,omitempty" yaml:"stages,omitempty"`
+	Deployments []*deploymentView    `json:"deployments" yaml:"deployments"`
+	Tasks       []*taskView          `json:"tasks,omitempty" yaml:"tasks,omitempty"`
+}
+
+// PipelineStageView representation of the state of a stage in the pipeline
+type pipelineStageView struct {
+	Name    string                `json:"stage" yaml:"stage"`
+	Actions []*pipelineActionView `json:"actions" yaml:"actions"`
+}
+
+// PipelineActionView representation of the state of an action in a pipeline stage
+type pipelineActionView struct {
+	Name       string    `json:"action" yaml:"action"`
+	Revision   string    `json:"revision,omitempty" yaml:"revision,omitempty"`
+	Status     string    `json:"status,omitempty" yaml:"status,omitempty"`
+	Message    string    `json:"message,omitempty" yaml:"message,omitempty"`
+	LastUpdate time.Time `json:"lastUpdate" yaml:"lastUpdate"`
+}
+
+// DeploymentView representation of a deployment of the service to an environment
+type deploymentView struct {
+	Environment  string    `json:"environment" yaml:"environment"`
+	Revision     string    `json:"revision" yaml:"revision"`
+	Status       string    `json:"status" yaml:"status"`
+	StatusReason string    `json:"statusReason,omitempty" yaml:"statusReason,omitempty"`
+	LastUpdate   time.Time `json:"lastUpdate" yaml:"lastUpdate"`
+}
+
+// TaskView representation of a task of the service and where its containers run
+type taskView struct {
+	Environment    string               `json:"environment" yaml:"environment"`
+	Name           string               `json:"task" yaml:"task"`
+	Status         string               `json:"status" yaml:"status"`
+	TaskDefinition string               `json:"taskDefinition,omitempty" yaml:"taskDefinition,omitempty"`
+	Cluster        string               `json:"cluster,omitempty" yaml:"cluster,omitempty"`
+	Containers     []*taskContainerView `json:"containers" yaml:"containers"`
+}
+
+// TaskContainerView representation of a container of a task
+type taskContainerView struct {
+	Name     string `json:"container" yaml:"container"`
+	Instance string `json:"instance" yaml:"instance"`
+}
+
 // NewServiceViewer create a new workflow for showing an environment
-func NewServiceViewer(ctx *common.Context, serviceName string, writer io.Writer, showTasks bool) Executor {
+func NewServiceViewer(ctx *common.Context, format string, serviceName string, writer io.Writer, showTasks bool) Executor {
 
 	workflow := new(serviceWorkflow)
-
-	// TODO: support `showTasks` by listing each running task and where it is running
+	view := new(serviceDetailView)
+	view.Deployments = make([]*deploymentView, 0)
 
 	return newPipelineExecutor(
 		workflow.serviceInput(ctx, serviceName),
-		workflow.serviceViewer(ctx.Config.Namespace, ctx.StackManager, ctx.StackManager, ctx.PipelineManager, ctx.TaskManager, ctx.Config, writer),
+		workflow.servicePipelineLoader(ctx.Config.Namespace, ctx.StackManager, ctx.PipelineManager, view),
+		workflow.serviceDeploymentLoader(ctx.Config.Namespace, ctx.StackManager, view),
+		newConditionalExecutor(func() bool { return showTasks },
+			workflow.serviceTaskLoader(ctx.Config.Namespace, ctx.TaskManager, view),
+			nil),
+		workflow.serviceViewer(view, format, writer),
 	)
 }
 
-func (workflow *serviceWorkflow) serviceViewer(namespace string, stackLister common.StackLister, stackGetter common.StackGetter, pipelineStateLister common.PipelineStateLister, taskManager common.TaskManager, config common.Config, writer io.Writer) Executor {
+func (workflow *serviceWorkflow) servicePipelineLoader(namespace string, stackGetter common.StackGetter, pipelineStateLister common.PipelineStateLister, view *serviceDetailView) Executor {
+	return func(ctx context.Context) error {
+		view.Name = workflow.serviceName
+
+		pipelineStackName := common.CreateStackName(namespace, common.StackTypePipeline, workflow.serviceName)
+		pipelineStack, err := stackGetter.GetStack(pipelineStackName)
+		if err != nil {
+			return nil
+		}
+		view.PipelineURL = pipelineStack.Outputs[SvcCodePipelineURLKey]
+
+		states, err := pipelineStateLister.ListState(pipelineStack.Outputs[SvcCodePipelineNameKey])
+		if err != nil {
+			return err
+		}
+		view.Stages = buildPipelineStageViews(states)
+
+		return nil
+	}
+}
 
-	return func() error {
+func (workflow *serviceWorkflow) serviceDeploymentLoader(namespace string, stackLister common.StackLister, view *serviceDetailView) Executor {
+	return func(ctx context.Context) error {
 		stacks, err := stackLister.ListStacks(common.StackTypeIam, namespace)
 		if err != nil {
 			return err
 		}
 
-		pipelineStackName := common.CreateStackName(namespace, common.StackTypePipeline, workflow.serviceName)
-		pipelineStack, err := stackGetter.GetStack(pipelineStackName)
-		if err == nil {
-			fmt.Fprint(writer, NewLine)
-			fmt.Fprintf(writer, SvcPipelineFormat, Bold(SvcPipelineURLLabel), pipelineStack.Outputs[SvcCodePipelineURLKey])
+		view.Deployments = buildDeploymentViews(stacks, namespace, workflow.serviceName)
+		return nil
+	}
+}
 
-			states, err := pipelineStateLister.ListState(pipelineStack.Outputs[SvcCodePipelineNameKey])
+func (workflow *serviceWorkflow) serviceTaskLoader(namespace string, taskLister common.TaskContainerLister, view *serviceDetailView) Executor {
+	return func(ctx context.Context) error {
+		view.Tasks = make([]*taskView, 0)
+		for _, deployment := range view.Deployments {
+			tasks, err := taskLister.ListTasks(namespace, deployment.Environment, workflow.serviceName)
 			if err != nil {
 				return err
 			}
 
-			stateTable := buildPipelineStateTable(writer, states)
-			stateTable.Render()
-			fmt.Fprint(writer, NewLine)
+			for _, task := range tasks {
+				containers := make([]*taskContainerView, 0, len(task.Containers))
+				for _, container := range task.Containers {
+					containers = append(containers, &taskContainerView{container.Name, container.Instance})
+				}
+				view.Tasks = append(view.Tasks, &taskView{
+					deployment.Environment,
+					task.Name,
+					task.Status,
+					task.TaskDefinition,
+					task.Cluster,
+					containers,
+				})
+			}
+		}
+		return nil
+	}
+}
 
-		} else {
-			fmt.Fprint(writer, NewLine)
-			fmt.Fprintf(writer, SvcPipelineFormat, Bold(SvcPipelineURLLabel), NA)
+func (workflow *serviceWorkflow) serviceViewer(view *serviceDetailView, format string, writer io.Writer) Executor {
+	return func(ctx context.Context) error {
+		records := func() [][]string {
+			rows := [][]string{{"service", "environment", "revision", "status", "statusReason", "lastUpdate"}}
+			for _, deployment := range view.Deployments {
+				rows = append(rows, []string{view.Name, deployment.Environment, deployment.Revision, deployment.Status, deployment.StatusReason, formatViewTime(deployment.LastUpdate)})
+			}
+			return rows
 		}
+		return writeView(writer, format, view, records, func() { printService(view, writer) })
+	}
+}
 
-		fmt.Fprintf(writer, SvcDeploymentsFormat, Bold(SvcDeploymentsLabel))
+func printService(view *serviceDetailView, writer io.Writer) {
+	fmt.Fprint(writer, NewLine)
+	if view.PipelineURL != "" {
+		fmt.Fprintf(writer, SvcPipelineFormat, Bold(SvcPipelineURLLabel), view.PipelineURL)
 
-		table := buildEnvTable(writer, stacks, namespace, workflow.serviceName)
-		table.Render()
+		stateTable := buildPipelineStateTable(writer, view.Stages)
+		stateTable.Render()
+		fmt.Fprint(writer, NewLine)
+	} else {
+		fmt.Fprintf(writer, SvcPipelineFormat, Bold(SvcPipelineURLLabel), NA)
+	}
 
-		return nil
+	fmt.Fprintf(writer, SvcDeploymentsFormat, Bold(SvcDeploymentsLabel))
+
+	table := buildEnvTable(writer, view.Deployments)
+	table.Render()
+
+	if view.Tasks != nil {
+		fmt.Fprint(writer, NewLine)
+		fmt.Fprintf(writer, HeadNewlineHeader, Bold(SvcContainersLabel))
+
+		taskTable := buildTaskTable(writer, view.Tasks)
+		taskTable.Render()
 	}
 }
 
-func buildPipelineStateTable(writer io.Writer, stages []common.PipelineStageState) *tablewriter.Table {
-	table := CreateTableSection(writer, SvcPipelineTableHeader)
-
+func buildPipelineStageViews(stages []common.PipelineStageState) []*pipelineStageView {
+	stageViews := make([]*pipelineStageView, 0, len(stages))
 	for _, stage := range stages {
+		stageView := &pipelineStageView{
+			Name:    common.StringValue(stage.StageName),
+			Actions: make([]*pipelineActionView, 0, len(stage.ActionStates)),
+		}
 		for _, action := range stage.ActionStates {
-			revision := LineChar
+			actionView := &pipelineActionView{
+				Name: common.StringValue(action.ActionName),
+			}
 			if action.CurrentRevision != nil {
-				revision = common.StringValue(action.CurrentRevision.RevisionId)
+				actionView.Revision = common.StringValue(action.CurrentRevision.RevisionId)
 			}
-			status := LineChar
-			message := common.Empty
-			lastUpdate := LineChar
 			if action.LatestExecution != nil {
-				lastUpdate = common.TimeValue(action.LatestExecution.LastStatusChange).Local().Format(LastUpdateTime)
-				status = common.StringValue(action.LatestExecution.Status)
+				actionView.LastUpdate = common.TimeValue(action.LatestExecution.LastStatusChange)
+				actionView.Status = common.StringValue(action.LatestExecution.Status)
 				if action.LatestExecution.ErrorDetails != nil {
-					message = common.StringValue(action.LatestExecution.ErrorDetails.Message)
+					actionView.Message = common.StringValue(action.LatestExecution.ErrorDetails.Message)
 				}
 			}
+			stageView.Actions = append(stageView.Actions, actionView)
+		}
+		stageViews = append(stageViews, stageView)
+	}
+	return stageViews
+}
+
+func buildPipelineStateTable(writer io.Writer, stages []*pipelineStageView) *tablewriter.Table {
+	table := CreateTableSection(writer, SvcPipelineTableHeader)
+
+	for _, stage := range stages {
+		for _, action := range stage.Actions {
+			revision := LineChar
+			if action.Revision != "" {
+				revision = action.Revision
+			}
+			status := LineChar
+			if action.Status != "" {
+				status = action.Status
+			}
+			lastUpdate := LineChar
+			if !action.LastUpdate.IsZero() {
+				lastUpdate = action.LastUpdate.Local().Format(LastUpdateTime)
+			}
 			table.Append([]string{
-				Bold(common.StringValue(stage.StageName)),
-				common.StringValue(action.ActionName),
+				Bold(stage.Name),
+				action.Name,
 				revision,
-				fmt.Sprintf(KeyValueFormat, colorizeActionStatus(status), message),
+				fmt.Sprintf(KeyValueFormat, colorizeActionStatus(status), action.Message),
 				lastUpdate,
 			})
 		}
@@ -91,9 +236,8 @@ func buildPipelineStateTable(writer io.Writer, stages []common.PipelineStageStat
 	return table
 }
 
-func buildEnvTable(writer io.Writer, stacks []*common.Stack, namespace string, serviceName string) *tablewriter.Table {
-	table := CreateTableSection(writer, SvcEnvironmentTableHeader)
-
+func buildDeploymentViews(stacks []*common.Stack, namespace string, serviceName string) []*deploymentView {
+	deployments := make([]*deploymentView, 0)
 	for _, stack := range stacks {
 		if !strings.HasPrefix(stack.Name, fmt.Sprintf("%s-iam-service-%s-", namespace, serviceName)) {
 			continue
@@ -105,12 +249,43 @@ func buildEnvTable(writer io.Writer, stacks []*common.Stack, namespace string, s
 			continue
 		}
 
-		table.Append([]string{
-			Bold(stack.Tags[EnvTagKey]),
+		deployments = append(deployments, &deploymentView{
+			stack.Tags[EnvTagKey],
 			stack.Tags["revision"],
-			fmt.Sprintf(KeyValueFormat, colorizeStackStatus(stack.Status), stack.StatusReason),
-			stack.LastUpdateTime.Local().Format(LastUpdateTime),
+			stack.Status,
+			stack.StatusReason,
+			stack.LastUpdateTime,
+		})
+	}
+	return deployments
+}
+
+func buildEnvTable(writer io.Writer, deployments []*deploymentView) *tablewriter.Table {
+	table := CreateTableSection(writer, SvcEnvironmentTableHeader)
+
+	for _, deployment := range deployments {
+		table.Append([]string{
+			Bold(deployment.Environment),
+			deployment.Revision,
+			fmt.Sprintf(KeyValueFormat, colorizeStackStatus(deployment.Status), deployment.StatusReason),
+			deployment.LastUpdate.Local().Format(LastUpdateTime),
 		})
 	}
 	return table
 }
+
+func buildTaskTable(writer io.Writer, tasks []*taskView) *tablewriter.Table {
+	table := CreateTableSection(writer, SvcTaskContainerHeader)
+
+	for _, task := range tasks {
+		for _, container := range task.Containers {
+			table.Append([]string{
+				Bold(task.Environment),
+				container.Name,
+				task.Name,
+				container.Instance,
+			})
+		}
+	}
+	return table
+}
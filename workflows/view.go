@@ -0,0 +1,111 @@
+package workflows
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/stelligent/mu/common"
+	"gopkg.in/yaml.v2"
+)
+
+// stackView is a row of the environment, database and pipeline lists
+type stackView struct {
+	Environment  string    `json:"environment,omitempty" yaml:"environment,omitempty"`
+	Service      string    `json:"service,omitempty" yaml:"service,omitempty"`
+	StackName    string    `json:"stackName" yaml:"stackName"`
+	Status       string    `json:"status" yaml:"status"`
+	StatusReason string    `json:"statusReason,omitempty" yaml:"statusReason,omitempty"`
+	LastUpdate   time.Time `json:"lastUpdate" yaml:"lastUpdate"`
+}
+
+// stackCSVHeader is the header of the csv records of a stack list
+var stackCSVHeader = []string{"environment", "service", "stackName", "status", "statusReason", "lastUpdate"}
+
+func newStackView(stack *common.Stack) *stackView {
+	return &stackView{
+		Environment:  stack.Tags[EnvTagKey],
+		Service:      stack.Tags[SvcTagKey],
+		StackName:    stack.Name,
+		Status:       stack.Status,
+		StatusReason: stack.StatusReason,
+		LastUpdate:   stack.LastUpdateTime,
+	}
+}
+
+// writeStackList writes the stacks in the format, the table shows the stacks by the name returned from nameOf
+func writeStackList(writer io.Writer, format string, header []string, stacks []*stackView, nameOf func(*stackView) string) error {
+	records := func() [][]string {
+		rows := [][]string{stackCSVHeader}
+		for _, stack := range stacks {
+			rows = append(rows, []string{
+				stack.Environment,
+				stack.Service,
+				stack.StackName,
+				stack.Status,
+				stack.StatusReason,
+				formatViewTime(stack.LastUpdate),
+			})
+		}
+		return rows
+	}
+
+	renderTable := func() {
+		table := CreateTableSection(writer, header)
+		for _, stack := range stacks {
+			table.Append([]string{
+				Bold(nameOf(stack)),
+				stack.StackName,
+				fmt.Sprintf(KeyValueFormat, colorizeStackStatus(stack.Status), stack.StatusReason),
+				stack.LastUpdate.Local().Format(LastUpdateTime),
+			})
+		}
+		table.Render()
+	}
+
+	return writeView(writer, format, stacks, records, renderTable)
+}
+
+// writeView writes the view as json or yaml, its records as csv, or calls renderTable for a table.
+// Views of show commands have nested lists, only their main list is written as csv.
+func writeView(writer io.Writer, format string, view interface{}, records func() [][]string, renderTable func()) error {
+	switch format {
+	case JSON:
+		enc := json.NewEncoder(writer)
+		enc.SetIndent("", "  ")
+		return enc.Encode(view)
+	case YAML:
+		out, err := yaml.Marshal(view)
+		if err != nil {
+			return err
+		}
+		// start each view as a document, so views of several services are a valid stream
+		_, err = fmt.Fprintf(writer, "---\n%s", out)
+		return err
+	case CSV:
+		return csv.NewWriter(writer).WriteAll(records())
+	case TABLE, CLI, common.Empty:
+		renderTable()
+		return nil
+	}
+	return fmt.Errorf("Unknown format '%s', must be one of: %s", format, strings.Join(ViewFormats, ", "))
+}
+
+// formatLastUpdate formats the time for tables, times that aren't known are left empty
+func formatLastUpdate(t time.Time) string {
+	if t.IsZero() {
+		return common.Empty
+	}
+	return t.Local().Format(LastUpdateTime)
+}
+
+// formatViewTime formats the time for csv records, times that aren't known are left empty
+func formatViewTime(t time.Time) string {
+	if t.IsZero() {
+		return common.Empty
+	}
+	return t.UTC().Format(time.RFC3339)
+}
@@ -0,0 +1,113 @@
+package workflows
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stelligent/mu/common"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"gopkg.in/yaml.v2"
+)
+
+func TestWriteStackList(t *testing.T) {
+	assert := assert.New(t)
+
+	lastUpdate := time.Date(2018, 5, 1, 12, 0, 0, 0, time.UTC)
+	stacks := []*stackView{
+		newStackView(&common.Stack{
+			Name:           "mu-database-api-dev",
+			Status:         common.StackStatusCreateComplete,
+			LastUpdateTime: lastUpdate,
+			Tags:           map[string]string{SvcTagKey: "api", EnvTagKey: "dev"},
+		}),
+	}
+	nameOf := func(view *stackView) string { return view.Service }
+
+	out := new(bytes.Buffer)
+	assert.Nil(writeStackList(out, JSON, PipeLineServiceHeader, stacks, nameOf))
+	values := make([]map[string]interface{}, 0)
+	assert.Nil(json.Unmarshal(out.Bytes(), &values))
+	assert.Equal(1, len(values))
+	assert.Equal("api", values[0]["service"])
+	assert.Equal("dev", values[0]["environment"])
+	assert.Equal("mu-database-api-dev", values[0]["stackName"])
+	assert.Equal(common.StackStatusCreateComplete, values[0]["status"])
+	assert.NotContains(values[0], "statusReason")
+
+	out = new(bytes.Buffer)
+	assert.Nil(writeStackList(out, YAML, PipeLineServiceHeader, stacks, nameOf))
+	yamlValues := make([]map[string]interface{}, 0)
+	assert.Nil(yaml.Unmarshal(out.Bytes(), &yamlValues))
+	assert.Equal(1, len(yamlValues))
+	assert.Equal("mu-database-api-dev", yamlValues[0]["stackName"])
+
+	out = new(bytes.Buffer)
+	assert.Nil(writeStackList(out, CSV, PipeLineServiceHeader, stacks, nameOf))
+	records, err := csv.NewReader(out).ReadAll()
+	assert.Nil(err)
+	assert.Equal(2, len(records))
+	assert.Equal(stackCSVHeader, records[0])
+	assert.Equal([]string{"dev", "api", "mu-database-api-dev", common.StackStatusCreateComplete, "", "2018-05-01T12:00:00Z"}, records[1])
+
+	out = new(bytes.Buffer)
+	assert.Nil(writeStackList(out, TABLE, PipeLineServiceHeader, stacks, nameOf))
+	assert.Contains(out.String(), "mu-database-api-dev")
+
+	assert.NotNil(writeStackList(new(bytes.Buffer), "xml", PipeLineServiceHeader, stacks, nameOf))
+}
+
+type mockedTaskLister struct {
+	mock.Mock
+}
+
+func (m *mockedTaskLister) ListTasks(namespace string, environment string, serviceName string) ([]common.Task, error) {
+	args := m.Called(namespace, environment, serviceName)
+	return args.Get(0).([]common.Task), args.Error(1)
+}
+
+func TestServiceViewer_Tasks(t *testing.T) {
+	assert := assert.New(t)
+
+	workflow := new(serviceWorkflow)
+	workflow.serviceName = "api"
+
+	view := &serviceDetailView{
+		Name:        "api",
+		Deployments: []*deploymentView{{Environment: "dev", Revision: "abc", Status: common.StackStatusCreateComplete}},
+	}
+
+	taskLister := new(mockedTaskLister)
+	taskLister.On("ListTasks", "mu", "dev", "api").Return([]common.Task{
+		{Name: "task-1", Status: "RUNNING", Containers: []common.Container{{Name: "api", Instance: "i-123"}}},
+	}, nil)
+
+	err := workflow.serviceTaskLoader("mu", taskLister, view)(context.Background())
+	assert.Nil(err)
+	taskLister.AssertExpectations(t)
+
+	out := new(bytes.Buffer)
+	err = workflow.serviceViewer(view, JSON, out)(context.Background())
+	assert.Nil(err)
+
+	values := make(map[string]interface{})
+	assert.Nil(json.Unmarshal(out.Bytes(), &values))
+	assert.Equal("api", values["service"])
+	assert.NotContains(values, "pipelineUrl")
+	tasks := values["tasks"].([]interface{})
+	assert.Equal(1, len(tasks))
+	task := tasks[0].(map[string]interface{})
+	assert.Equal("dev", task["environment"])
+	assert.Equal("task-1", task["task"])
+	assert.Equal("i-123", task["containers"].([]interface{})[0].(map[string]interface{})["instance"])
+
+	out = new(bytes.Buffer)
+	err = workflow.serviceViewer(view, TABLE, out)(context.Background())
+	assert.Nil(err)
+	assert.Contains(out.String(), "task-1")
+	assert.Contains(out.String(), "i-123")
+}